@@ -21,6 +21,7 @@ import (
 	"errors"
 	"io"
 	"io/ioutil"
+	"math/big"
 
 	"github.com/MeshBoxTech/mesh-chain/accounts/keystore"
 	"github.com/MeshBoxTech/mesh-chain/common"
@@ -29,7 +30,8 @@ import (
 )
 
 // NewTransactor is a utility method to easily create a transaction signer from
-// an encrypted json key stream and the associated passphrase.
+// an encrypted json key stream and the associated passphrase. The transactions
+// are signed for the chain id of the backend.
 func NewTransactor(keyin io.Reader, passphrase string) (*TransactOpts, error) {
 	json, err := ioutil.ReadAll(keyin)
 	if err != nil {
@@ -42,8 +44,28 @@ func NewTransactor(keyin io.Reader, passphrase string) (*TransactOpts, error) {
 	return NewKeyedTransactor(key.PrivateKey), nil
 }
 
+// NewTransactorWithChainID is NewTransactor signing the transactions for the
+// given chain id.
+func NewTransactorWithChainID(keyin io.Reader, passphrase string, chainID *big.Int) (*TransactOpts, error) {
+	opts, err := NewTransactor(keyin, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	opts.ChainId = chainID
+	return opts, nil
+}
+
+// NewKeyedTransactorWithChainID is NewKeyedTransactor signing the transactions
+// for the given chain id.
+func NewKeyedTransactorWithChainID(key *ecdsa.PrivateKey, chainID *big.Int) *TransactOpts {
+	opts := NewKeyedTransactor(key)
+	opts.ChainId = chainID
+	return opts
+}
+
 // NewKeyedTransactor is a utility method to easily create a transaction signer
-// from a single private key.
+// from a single private key. The transactions are signed for the chain id of the
+// backend.
 func NewKeyedTransactor(key *ecdsa.PrivateKey) *TransactOpts {
 	keyAddr := crypto.PubkeyToAddress(key.PublicKey)
 	return &TransactOpts{
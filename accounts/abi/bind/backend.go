@@ -39,6 +39,10 @@ var (
 	// This error is returned by WaitDeployed if contract creation leaves an
 	// empty contract behind.
 	ErrNoCodeAfterDeploy = errors.New("no contract code after deployment")
+
+	// ErrNoChainID is returned by transact operations if neither the options nor
+	// the backend tell the chain id to sign the transaction for.
+	ErrNoChainID = errors.New("no chain id to sign the transaction for")
 )
 
 // ContractCaller defines the methods needed to allow operating with contract on a read
@@ -92,6 +96,14 @@ type ContractTransactor interface {
 	SendTransaction(ctx context.Context, tx *types.Transaction) error
 }
 
+// ChainIDReader defines the method transact will try to discover on the
+// transactor if the options don't set the chain id to sign for. If the backend
+// does not support it either, transact returns ErrNoChainID.
+type ChainIDReader interface {
+	// ChainID retrieves the EIP155 chain id of the backend blockchain.
+	ChainID(ctx context.Context) (*big.Int, error)
+}
+
 // ContractBackend defines the methods needed to work with contracts on a read-write basis.
 type ContractBackend interface {
 	ContractCaller
@@ -27,7 +27,6 @@ import (
 	"github.com/MeshBoxTech/mesh-chain/common"
 	"github.com/MeshBoxTech/mesh-chain/core/types"
 	"github.com/MeshBoxTech/mesh-chain/crypto"
-	"github.com/MeshBoxTech/mesh-chain/params"
 )
 
 // SignerFn is a signer function callback when a contract requires a method to
@@ -51,9 +50,10 @@ type CallOpts struct {
 // TransactOpts is the collection of authorization data required to create a
 // valid Ethereum transaction.
 type TransactOpts struct {
-	From   common.Address // Ethereum account to send the transaction from
-	Nonce  *big.Int       // Nonce to use for the transaction execution (nil = use pending state)
-	Signer SignerFn       // Method to use for signing the transaction (mandatory)
+	From    common.Address // Ethereum account to send the transaction from
+	Nonce   *big.Int       // Nonce to use for the transaction execution (nil = use pending state)
+	Signer  SignerFn       // Method to use for signing the transaction (mandatory)
+	ChainId *big.Int       // EIP155 chain id to sign the transaction for (nil = ask the backend)
 
 	Value    *big.Int // Funds to transfer along along the transaction (nil = 0 = no funds)
 	GasPrice *big.Int // Gas price to use for the transaction execution (nil = gas price oracle)
@@ -276,9 +276,15 @@ func (c *BoundContract) transact(opts *TransactOpts, contract *common.Address, i
 	}
 	// 18-09-13 : modify by liangc : change signer to eip155
 	//signedTx, err := opts.Signer(types.HomesteadSigner{}, opts.From, rawTx)
-	cid := params.MainnetChainConfig.ChainId
-	if params.IsTestnet() {
-		cid = params.TestnetChainConfig.ChainId
+	cid := opts.ChainId
+	if cid == nil {
+		reader, ok := c.transactor.(ChainIDReader)
+		if !ok {
+			return nil, ErrNoChainID
+		}
+		if cid, err = reader.ChainID(ensureContext(opts.Context)); err != nil {
+			return nil, fmt.Errorf("failed to retrieve chain id: %v", err)
+		}
 	}
 	signedTx, err := opts.Signer(types.NewEIP155Signer(cid), opts.From, rawTx)
 	if err != nil {
@@ -22,11 +22,13 @@ import (
 	"os"
 	"runtime"
 	"strconv"
+	"strings"
 	"sync/atomic"
 	"time"
 
 	"github.com/MeshBoxTech/mesh-chain/cmd/utils"
 	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/common/hexutil"
 	"github.com/MeshBoxTech/mesh-chain/console"
 	"github.com/MeshBoxTech/mesh-chain/core"
 	"github.com/MeshBoxTech/mesh-chain/core/state"
@@ -48,6 +50,7 @@ var (
 		ArgsUsage: "<genesisPath>",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.AncientFlag,
 			utils.LightModeFlag,
 		},
 		Category: "BLOCKCHAIN COMMANDS",
@@ -65,13 +68,18 @@ It expects the genesis file as argument.`,
 		ArgsUsage: "<filename> (<filename 2> ... <filename N>) ",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.AncientFlag,
 			utils.CacheFlag,
+			utils.GCModeFlag,
+			utils.TxLookupLimitFlag,
 			utils.LightModeFlag,
 		},
 		Category: "BLOCKCHAIN COMMANDS",
 		Description: `
 The import command imports blocks from an RLP-encoded form. The form can be one file
-with several RLP-encoded blocks, or several files can be used.
+with several RLP-encoded blocks, or several files can be used. Archives written by
+export --archive are detected, verified before the import and resumed where a
+previous import stopped.
 
 If only one file is used, import error will result in failure. If several files are used,
 processing will proceed even if an individual RLP-file import failure occurs.`,
@@ -83,15 +91,38 @@ processing will proceed even if an individual RLP-file import failure occurs.`,
 		ArgsUsage: "<filename> [<blockNumFirst> <blockNumLast>]",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.AncientFlag,
 			utils.CacheFlag,
 			utils.LightModeFlag,
+			exportArchiveFlag,
+			exportCompressionFlag,
+			exportReceiptsFlag,
 		},
 		Category: "BLOCKCHAIN COMMANDS",
 		Description: `
 Requires a first argument of the file to write to.
 Optional second and third arguments control the first and
 last block to write. In this mode, the file will be appended
-if already existing.`,
+if already existing.
+
+With --archive the blocks are written as a versioned archive instead, holding
+the chain id, the genesis hash and the block range in its header and the blocks
+in checksummed, optionally compressed chunks. Archives are never appended to.
+The import command verifies archives before importing them and skips the blocks
+already present, so an interrupted import can be resumed.`,
+	}
+	exportArchiveFlag = cli.BoolFlag{
+		Name:  "archive",
+		Usage: "Export a checksummed archive instead of plain RLP blocks",
+	}
+	exportCompressionFlag = cli.StringFlag{
+		Name:  "compression",
+		Usage: `Compression of the archive chunks ("none", "gzip" or "rle")`,
+		Value: core.ArchiveCompressionGzip.String(),
+	}
+	exportReceiptsFlag = cli.BoolFlag{
+		Name:  "receipts",
+		Usage: "Include the receipts of the blocks in the archive (implies --archive)",
 	}
 	copydbCommand = cli.Command{
 		Action:    utils.MigrateFlags(copyDb),
@@ -100,6 +131,7 @@ if already existing.`,
 		ArgsUsage: "<sourceChaindataDir>",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.AncientFlag,
 			utils.CacheFlag,
 			utils.SyncModeFlag,
 			utils.FakePoWFlag,
@@ -129,6 +161,7 @@ Remove blockchain and state databases`,
 		ArgsUsage: "[<blockHash> | <blockNum>]...",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.AncientFlag,
 			utils.CacheFlag,
 			utils.LightModeFlag,
 		},
@@ -137,6 +170,74 @@ Remove blockchain and state databases`,
 The arguments are interpreted as block numbers or hashes.
 Use "ethereum dump 0" to dump the genesis block.`,
 	}
+	dumpStateCommand = cli.Command{
+		Action:    utils.MigrateFlags(dumpState),
+		Name:      "dump-state",
+		Usage:     "Stream the state at a block as newline delimited JSON",
+		ArgsUsage: " ",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			utils.AncientFlag,
+			utils.CacheFlag,
+			utils.LightModeFlag,
+			dumpStateBlockFlag,
+			dumpStateStartFlag,
+			dumpStateLimitFlag,
+			dumpStateAddressesFlag,
+			dumpStateNoCodeFlag,
+			dumpStateStorageFlag,
+		},
+		Category: "BLOCKCHAIN COMMANDS",
+		Description: `
+Streams the accounts of the state at the given block, ordered by hashed address.
+The first line holds the state root and the MESH supply recorded by the MESH
+contract, every following line one account with its SMT and MESH balances
+resolved from the token contracts. Contract storage is only dumped with --storage.
+
+If the dump is limited, the hashed address to continue at is logged at the end,
+to be passed as --start to the next run.`,
+	}
+	replayBlockCommand = cli.Command{
+		Action:    utils.MigrateFlags(replayBlock),
+		Name:      "replay-block",
+		Usage:     "Re-execute a block against its parent state",
+		ArgsUsage: "<blockHash>",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			utils.AncientFlag,
+			utils.CacheFlag,
+		},
+		Category: "BLOCKCHAIN COMMANDS",
+		Description: `
+Re-executes a block of the chain, or one of the bad blocks rejected by the node,
+on top of the state of its parent and prints as JSON how the outcome differs
+from what the block commits to: state root, receipts root, gas used, logs bloom
+and, for epoch blocks, the validators announced in the extra-data.`,
+	}
+	dumpStateBlockFlag = cli.StringFlag{
+		Name:  "block",
+		Usage: "Number or hash of the block to dump the state of (default = current head)",
+	}
+	dumpStateStartFlag = cli.StringFlag{
+		Name:  "start",
+		Usage: "Hashed address to start the dump at",
+	}
+	dumpStateLimitFlag = cli.Uint64Flag{
+		Name:  "limit",
+		Usage: "Maximum number of accounts to dump (default = all)",
+	}
+	dumpStateAddressesFlag = cli.StringFlag{
+		Name:  "addresses",
+		Usage: "Comma separated list of the accounts to dump (default = all)",
+	}
+	dumpStateNoCodeFlag = cli.BoolFlag{
+		Name:  "nocode",
+		Usage: "Exclude the contract code from the dump",
+	}
+	dumpStateStorageFlag = cli.BoolFlag{
+		Name:  "storage",
+		Usage: "Include the contract storage in the dump",
+	}
 )
 
 // initGenesis will initialise the given JSON format genesis file and writes it as
@@ -164,6 +265,9 @@ func initGenesis(ctx *cli.Context) error {
 		if err != nil {
 			utils.Fatalf("Failed to open database: %v", err)
 		}
+		if name == "chaindata" {
+			chaindb = utils.MakeFreezerDatabase(ctx, stack, chaindb)
+		}
 		_, hash, err := core.SetupGenesisBlock(chaindb, genesis)
 		if err != nil {
 			utils.Fatalf("Failed to write genesis block: %v", err)
@@ -210,6 +314,8 @@ func importChain(ctx *cli.Context) error {
 			}
 		}
 	}
+	// Flush the state cached in memory
+	chain.Stop()
 
 	fmt.Printf("Import done in %v.\n\n", time.Since(start))
 
@@ -262,9 +368,27 @@ func exportChain(ctx *cli.Context) error {
 	chain, _ := utils.MakeChain(ctx, stack)
 	start := time.Now()
 
-	var err error
-	fp := ctx.Args().First()
-	if len(ctx.Args()) < 3 {
+	var (
+		err     error
+		fp      = ctx.Args().First()
+		archive = ctx.Bool(exportArchiveFlag.Name) || ctx.Bool(exportReceiptsFlag.Name)
+	)
+	if archive {
+		compression, cerr := core.ParseArchiveCompression(ctx.String(exportCompressionFlag.Name))
+		if cerr != nil {
+			utils.Fatalf("Export error: %v\n", cerr)
+		}
+		first, last := uint64(0), chain.CurrentBlock().NumberU64()
+		if len(ctx.Args()) >= 3 {
+			var ferr, lerr error
+			first, ferr = strconv.ParseUint(ctx.Args().Get(1), 10, 64)
+			last, lerr = strconv.ParseUint(ctx.Args().Get(2), 10, 64)
+			if ferr != nil || lerr != nil {
+				utils.Fatalf("Export error in parsing parameters: block number not an integer\n")
+			}
+		}
+		err = utils.ExportArchive(chain, fp, first, last, compression, ctx.Bool(exportReceiptsFlag.Name))
+	} else if len(ctx.Args()) < 3 {
 		err = utils.ExportChain(chain, fp)
 	} else {
 		// This can be improved to allow for numbers larger than 9223372036854775807
@@ -389,6 +513,99 @@ func dump(ctx *cli.Context) error {
 	return nil
 }
 
+func dumpState(ctx *cli.Context) error {
+	if len(ctx.Args()) > 0 {
+		utils.Fatalf("This command doesn't take any arguments")
+	}
+	conf := &state.DumpConfig{
+		SkipCode:    ctx.Bool(dumpStateNoCodeFlag.Name),
+		SkipStorage: !ctx.Bool(dumpStateStorageFlag.Name),
+		Max:         ctx.Uint64(dumpStateLimitFlag.Name),
+	}
+	if start := ctx.String(dumpStateStartFlag.Name); start != "" {
+		key, err := hexutil.Decode(start)
+		if err != nil || len(key) > common.HashLength {
+			utils.Fatalf("Invalid start key %q", start)
+		}
+		conf.Start = key
+	}
+	if addresses := ctx.String(dumpStateAddressesFlag.Name); addresses != "" {
+		for _, addr := range strings.Split(addresses, ",") {
+			if !common.IsHexAddress(addr) {
+				utils.Fatalf("Invalid address %q", addr)
+			}
+			conf.Addresses = append(conf.Addresses, common.HexToAddress(addr))
+		}
+	}
+	stack := makeFullNode(ctx)
+	chain, chainDb := utils.MakeChain(ctx, stack)
+	defer chainDb.Close()
+	defer chain.Stop()
+
+	block := chain.CurrentBlock()
+	if arg := ctx.String(dumpStateBlockFlag.Name); arg != "" {
+		if hashish(arg) {
+			block = chain.GetBlockByHash(common.HexToHash(arg))
+		} else {
+			num, _ := strconv.ParseUint(arg, 10, 64)
+			block = chain.GetBlockByNumber(num)
+		}
+	}
+	if block == nil {
+		utils.Fatalf("Block not found")
+	}
+	statedb, err := state.New(block.Root(), state.NewDatabase(chainDb))
+	if err != nil {
+		utils.Fatalf("Could not open the state: %v", err)
+	}
+	log.Info("Dumping state", "number", block.Number(), "hash", block.Hash(), "root", block.Root())
+
+	start := time.Now()
+	next, err := statedb.IterativeDump(conf, os.Stdout)
+	if err != nil {
+		utils.Fatalf("Failed to dump the state: %v", err)
+	}
+	if next != nil {
+		log.Info("State dump limit reached", "next", hexutil.Encode(next), "elapsed", common.PrettyDuration(time.Since(start)))
+	} else {
+		log.Info("State dump done", "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+	return nil
+}
+
+// replayBlock re-executes a canonical or bad block and prints the differences
+// between the computed results and the ones in the block.
+func replayBlock(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 || !hashish(ctx.Args().First()) {
+		utils.Fatalf("This command requires a block hash as argument.")
+	}
+	stack := makeFullNode(ctx)
+	chain, chainDb := utils.MakeChain(ctx, stack)
+	defer chainDb.Close()
+	defer chain.Stop()
+
+	hash := common.HexToHash(ctx.Args().First())
+	block := chain.GetBlockByHash(hash)
+	if block == nil {
+		bad := core.GetBadBlock(chainDb, hash)
+		if bad == nil {
+			utils.Fatalf("Block %x not found", hash)
+		}
+		log.Info("Replaying bad block", "number", bad.Block.Number(), "hash", hash, "rejected", time.Unix(int64(bad.Time), 0), "err", bad.Error, "validators", bad.Validators)
+		block = bad.Block
+	}
+	replay, err := chain.ReplayBlock(block)
+	if err != nil {
+		utils.Fatalf("Failed to replay block: %v", err)
+	}
+	out, err := json.MarshalIndent(replay, "", "  ")
+	if err != nil {
+		utils.Fatalf("Failed to encode replay: %v", err)
+	}
+	fmt.Println(string(out))
+	return nil
+}
+
 // hashish returns true for strings that look like hashes.
 func hashish(x string) bool {
 	_, err := strconv.Atoi(x)
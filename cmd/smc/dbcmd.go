@@ -0,0 +1,215 @@
+// Copyright 2018 The mesh-chain Authors
+// This file is part of mesh-chain.
+//
+// mesh-chain is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// mesh-chain is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with mesh-chain. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/MeshBoxTech/mesh-chain/cmd/utils"
+	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/core"
+	"github.com/MeshBoxTech/mesh-chain/ethdb"
+	"github.com/MeshBoxTech/mesh-chain/log"
+	"github.com/MeshBoxTech/mesh-chain/node"
+	"github.com/olekukonko/tablewriter"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	dbCommand = cli.Command{
+		Name:      "db",
+		Usage:     "Low level database operations",
+		ArgsUsage: "",
+		Category:  "DATABASE COMMANDS",
+		Description: `
+The db commands operate directly on the chain database of a stopped node. They
+can be used to look at where the disk space goes and to repair single entries.`,
+		Subcommands: []cli.Command{
+			{
+				Name:      "inspect",
+				Usage:     "Inspect the storage size for each type of data in the database",
+				ArgsUsage: " ",
+				Action:    utils.MigrateFlags(inspectDB),
+				Category:  "DATABASE COMMANDS",
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.AncientFlag,
+					utils.CacheFlag,
+					utils.LightModeFlag,
+				},
+				Description: `
+Iterates over the entire database and prints the number of entries and their
+total size per kind of data, along with the tables of the ancient store.`,
+			},
+			{
+				Name:      "get",
+				Usage:     "Show the value of a database key",
+				ArgsUsage: "<hex-encoded key>",
+				Action:    utils.MigrateFlags(dbGet),
+				Category:  "DATABASE COMMANDS",
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.CacheFlag,
+					utils.LightModeFlag,
+				},
+				Description: `
+Prints the hex encoded value stored under the key in the key-value store.
+Entries already moved into the ancient store are not visible.`,
+			},
+			{
+				Name:      "put",
+				Usage:     "Set the value of a database key (WARNING: may corrupt your database)",
+				ArgsUsage: "<hex-encoded key> <hex-encoded value>",
+				Action:    utils.MigrateFlags(dbPut),
+				Category:  "DATABASE COMMANDS",
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.CacheFlag,
+					utils.LightModeFlag,
+				},
+				Description: `
+Stores the value under the key in the key-value store, overwriting any previous
+value. This is a low level operation, use it only to repair a broken database.`,
+			},
+			{
+				Name:      "delete",
+				Usage:     "Delete a database key (WARNING: may corrupt your database)",
+				ArgsUsage: "<hex-encoded key>",
+				Action:    utils.MigrateFlags(dbDelete),
+				Category:  "DATABASE COMMANDS",
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.CacheFlag,
+					utils.LightModeFlag,
+				},
+				Description: `
+Removes the key from the key-value store. This is a low level operation, use it
+only to repair a broken database.`,
+			},
+		},
+	}
+)
+
+func inspectDB(ctx *cli.Context) error {
+	if len(ctx.Args()) > 0 {
+		utils.Fatalf("This command doesn't take any arguments")
+	}
+	stack := makeFullNode(ctx)
+	db := openKeyValueDatabase(ctx, stack)
+	if !ctx.GlobalBool(utils.LightModeFlag.Name) {
+		db = utils.MakeAncientDatabase(ctx, stack, db)
+	}
+	defer db.Close()
+
+	stats, err := core.InspectDatabase(db)
+	if err != nil {
+		utils.Fatalf("Failed to inspect database: %v", err)
+	}
+	var (
+		table = tablewriter.NewWriter(os.Stdout)
+		total common.StorageSize
+	)
+	table.SetHeader([]string{"Database", "Category", "Items", "Size"})
+	for _, stat := range stats {
+		table.Append([]string{stat.Database, stat.Category, fmt.Sprintf("%d", stat.Items), stat.Size.String()})
+		total += stat.Size
+	}
+	table.SetFooter([]string{"", "Total", "", total.String()})
+	table.Render()
+	return nil
+}
+
+// openKeyValueDatabase opens the key-value store of the chain database without
+// the ancient store, so the db commands don't move any data around.
+func openKeyValueDatabase(ctx *cli.Context, stack *node.Node) ethdb.Database {
+	name := "chaindata"
+	if ctx.GlobalBool(utils.LightModeFlag.Name) {
+		name = "lightchaindata"
+	}
+	db, err := stack.OpenDatabase(name, ctx.GlobalInt(utils.CacheFlag.Name), 0)
+	if err != nil {
+		utils.Fatalf("Could not open database: %v", err)
+	}
+	return db
+}
+
+// parseHex decodes a hex string with an optional 0x prefix.
+func parseHex(input string) []byte {
+	blob, err := hex.DecodeString(strings.TrimPrefix(strings.TrimPrefix(input, "0x"), "0X"))
+	if err != nil {
+		utils.Fatalf("Invalid hex %q: %v", input, err)
+	}
+	return blob
+}
+
+func dbGet(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		utils.Fatalf("This command requires a key as argument")
+	}
+	key := parseHex(ctx.Args().Get(0))
+
+	db := openKeyValueDatabase(ctx, makeFullNode(ctx))
+	defer db.Close()
+
+	value, err := db.Get(key)
+	if err != nil {
+		utils.Fatalf("Failed to retrieve key %#x: %v", key, err)
+	}
+	fmt.Printf("%#x\n", value)
+	return nil
+}
+
+func dbPut(ctx *cli.Context) error {
+	if len(ctx.Args()) != 2 {
+		utils.Fatalf("This command requires a key and a value as arguments")
+	}
+	var (
+		key   = parseHex(ctx.Args().Get(0))
+		value = parseHex(ctx.Args().Get(1))
+	)
+	db := openKeyValueDatabase(ctx, makeFullNode(ctx))
+	defer db.Close()
+
+	if prev, err := db.Get(key); err == nil {
+		log.Info("Overwriting database value", "key", fmt.Sprintf("%#x", key), "previous", fmt.Sprintf("%#x", prev))
+	}
+	if err := db.Put(key, value); err != nil {
+		utils.Fatalf("Failed to write key %#x: %v", key, err)
+	}
+	return nil
+}
+
+func dbDelete(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		utils.Fatalf("This command requires a key as argument")
+	}
+	key := parseHex(ctx.Args().Get(0))
+
+	db := openKeyValueDatabase(ctx, makeFullNode(ctx))
+	defer db.Close()
+
+	if prev, err := db.Get(key); err == nil {
+		log.Info("Deleting database value", "key", fmt.Sprintf("%#x", key), "previous", fmt.Sprintf("%#x", prev))
+	}
+	if err := db.Delete(key); err != nil {
+		utils.Fatalf("Failed to delete key %#x: %v", key, err)
+	}
+	return nil
+}
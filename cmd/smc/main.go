@@ -57,6 +57,7 @@ var (
 		//utils.BootnodesV4Flag,
 		//utils.BootnodesV5Flag,
 		utils.DataDirFlag,
+		utils.AncientFlag,
 		utils.KeyStoreDirFlag,
 		/*
 			utils.EthashCacheDirFlag,
@@ -107,6 +108,8 @@ var (
 		utils.EtherbaseFlag,
 		*/
 		utils.CacheFlag,
+		utils.GCModeFlag,
+		utils.TxLookupLimitFlag,
 		utils.TrieCacheGenFlag,
 		utils.ListenPortFlag,
 		utils.MaxPeersFlag,
@@ -167,6 +170,10 @@ func init() {
 		copydbCommand,
 		removedbCommand,
 		dumpCommand,
+		dumpStateCommand,
+		replayBlockCommand,
+		// See dbcmd.go:
+		dbCommand,
 		// See monitorcmd.go:
 		//monitorCommand,
 		// See accountcmd.go:
@@ -186,6 +193,8 @@ func init() {
 		dumpConfigCommand,
 		// add by liangc
 		securityCommand,
+		// See validatorscmd.go
+		validatorsCommand,
 	}
 	sort.Sort(cli.CommandsByName(app.Commands))
 
@@ -199,18 +208,10 @@ func init() {
 		// add by liangc : append testnet flag
 		if tn := ctx.GlobalBool(utils.TestnetFlag.Name); tn {
 			fmt.Println("Testnet started.")
-			os.Setenv("TESTNET", "1")
 		}
 		if tn := ctx.GlobalBool(utils.DevnetFlag.Name); tn {
 			fmt.Println("Devnet started.")
-			os.Setenv("DEVNET", "1")
 		}
-		ipc := node.DefaultIPCEndpoint(clientIdentifier)
-
-		if dir := ctx.GlobalString(utils.DataDirFlag.Name); ctx.GlobalIsSet(utils.DataDirFlag.Name) {
-			ipc = node.DefaultIPCEndpointWithDir(dir, clientIdentifier)
-		}
-		os.Setenv("IPCPATH", ipc)
 
 		runtime.GOMAXPROCS(runtime.NumCPU())
 		if err := debug.Setup(ctx); err != nil {
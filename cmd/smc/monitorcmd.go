@@ -36,7 +36,7 @@ import (
 var (
 	monitorCommandAttachFlag = cli.StringFlag{
 		Name:  "attach",
-		Value: node.DefaultIPCEndpoint(clientIdentifier),
+		Value: node.DefaultIPCEndpoint(clientIdentifier, false),
 		Usage: "API endpoint to attach to",
 	}
 	monitorCommandRowsFlag = cli.IntFlag{
@@ -76,6 +76,9 @@ func monitor(ctx *cli.Context) error {
 	)
 	// Attach to an Ethereum node over IPC or RPC
 	endpoint := ctx.String(monitorCommandAttachFlag.Name)
+	if !ctx.IsSet(monitorCommandAttachFlag.Name) {
+		endpoint = localIPCEndpoint(ctx)
+	}
 	if client, err = dialRPC(endpoint); err != nil {
 		utils.Fatalf("Unable to attach to geth node: %v", err)
 	}
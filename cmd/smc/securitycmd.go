@@ -24,14 +24,12 @@ var (
 			fmt.Println("-----------------------")
 			if ctx.Bool(utils.TestnetFlag.Name) {
 				fmt.Println("-   TESTNET ")
-				os.Setenv("TESTNET", "1")
 			} else if ctx.Bool(utils.DevnetFlag.Name) {
 				fmt.Println("-   DEVNET ")
-				os.Setenv("DEVNET", "1")
 			} else {
 				fmt.Println("-   MAINNET ")
 			}
-			security.homeDir = node.DefaultNodekeyDir()
+			security.homeDir = node.DefaultNodekeyDir(ctx.Bool(utils.TestnetFlag.Name))
 			fmt.Println("home_dir :", security.homeDir)
 			fmt.Println("-----------------------")
 			security.nodekey = filepath.Join(security.homeDir, "nodekey")
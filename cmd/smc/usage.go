@@ -67,6 +67,7 @@ var AppHelpFlagGroups = []flagGroup{
 		Name: "mesh-chain",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.AncientFlag,
 			utils.KeyStoreDirFlag,
 			utils.TestnetFlag,
 			utils.DevnetFlag,
@@ -133,6 +134,8 @@ var AppHelpFlagGroups = []flagGroup{
 		Name: "PERFORMANCE TUNING",
 		Flags: []cli.Flag{
 			utils.CacheFlag,
+			utils.GCModeFlag,
+			utils.TxLookupLimitFlag,
 			utils.TrieCacheGenFlag,
 		},
 	},
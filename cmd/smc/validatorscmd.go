@@ -0,0 +1,382 @@
+// Copyright 2018 The mesh-chain Authors
+// This file is part of mesh-chain.
+//
+// mesh-chain is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// mesh-chain is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with mesh-chain. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/MeshBoxTech/mesh-chain/accounts/abi/bind"
+	"github.com/MeshBoxTech/mesh-chain/accounts/keystore"
+	"github.com/MeshBoxTech/mesh-chain/cmd/utils"
+	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/common/hexutil"
+	"github.com/MeshBoxTech/mesh-chain/common/math"
+	"github.com/MeshBoxTech/mesh-chain/contracts/Validators"
+	"github.com/MeshBoxTech/mesh-chain/contracts/Validators/contract"
+	"github.com/MeshBoxTech/mesh-chain/core/types"
+	"github.com/MeshBoxTech/mesh-chain/ethclient"
+	"github.com/MeshBoxTech/mesh-chain/params"
+	"github.com/MeshBoxTech/mesh-chain/rlp"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	validatorsEndpointFlag = cli.StringFlag{
+		Name:  "endpoint",
+		Usage: "IPC or RPC endpoint of the node to use (default = IPC endpoint in the data directory)",
+	}
+	validatorsFromFlag = cli.StringFlag{
+		Name:  "from",
+		Usage: "Keystore account to sign the transaction with",
+	}
+	validatorsOfflineFlag = cli.BoolFlag{
+		Name:  "offline",
+		Usage: "Print the signed raw transaction instead of sending it, without contacting a node",
+	}
+	validatorsNonceFlag = cli.Uint64Flag{
+		Name:  "nonce",
+		Usage: "Nonce of the transaction (default = pending nonce of the account, required when offline)",
+	}
+	validatorsGasPriceFlag = cli.StringFlag{
+		Name:  "gasprice",
+		Usage: "Gas price of the transaction in wei (default = suggested by the node, required when offline)",
+	}
+	validatorsGasFlag = cli.Uint64Flag{
+		Name:  "gas",
+		Usage: "Gas limit of the transaction (default = estimated by the node, 1000000 when offline)",
+	}
+	validatorsChainIdFlag = cli.Uint64Flag{
+		Name:  "chainid",
+		Usage: "Chain id to sign the transaction for (default = chain id of the selected network)",
+	}
+
+	validatorsTxFlags = []cli.Flag{
+		utils.DataDirFlag,
+		utils.KeyStoreDirFlag,
+		utils.PasswordFileFlag,
+		utils.TestnetFlag,
+		utils.DevnetFlag,
+		validatorsEndpointFlag,
+		validatorsFromFlag,
+		validatorsOfflineFlag,
+		validatorsNonceFlag,
+		validatorsGasPriceFlag,
+		validatorsGasFlag,
+		validatorsChainIdFlag,
+	}
+	validatorsCallFlags = []cli.Flag{
+		utils.DataDirFlag,
+		utils.TestnetFlag,
+		utils.DevnetFlag,
+		validatorsEndpointFlag,
+	}
+
+	validatorsCommand = cli.Command{
+		Name:     "validators",
+		Usage:    "Manage the validators of the tribe consensus",
+		Category: "BLOCKCHAIN COMMANDS",
+		Description: `
+Query and govern the Validators system contract.
+
+The queries and, by default, the transactions go through the IPC endpoint of
+the local node, or the one given with --endpoint. Transactions are signed with
+the keystore account given with --from. With --offline no node is contacted:
+the nonce and the gas price have to be given, and the signed raw transaction is
+printed to be published elsewhere, e.g. with eth.sendRawTransaction.`,
+		Subcommands: []cli.Command{
+			{
+				Name:   "list",
+				Usage:  "Print the validators known to the contract",
+				Action: utils.MigrateFlags(validatorsList),
+				Flags:  validatorsCallFlags,
+				Description: `
+    smc validators list
+
+Prints every validator with its type, owner, deposit and sealing state.`,
+			},
+			{
+				Name:      "add-poa",
+				Usage:     "Add PoA validators (contract owner only)",
+				ArgsUsage: "<address> [<address>...]",
+				Action:    utils.MigrateFlags(validatorsAddPoa),
+				Flags:     validatorsTxFlags,
+				Description: `
+    smc validators add-poa --from <owner> <address> [<address>...]
+
+Sends an ownerAddPoaNode transaction adding the given validators.`,
+			},
+			{
+				Name:      "remove-poa",
+				Usage:     "Remove PoA validators (contract owner only)",
+				ArgsUsage: "<address> [<address>...]",
+				Action:    utils.MigrateFlags(validatorsRemovePoa),
+				Flags:     validatorsTxFlags,
+				Description: `
+    smc validators remove-poa --from <owner> <address> [<address>...]
+
+Sends an ownerRemovePoaNode transaction removing the given validators.`,
+			},
+			{
+				Name:      "bind",
+				Usage:     "Bind a node to an account",
+				ArgsUsage: "<signature>",
+				Action:    utils.MigrateFlags(validatorsBind),
+				Flags:     validatorsTxFlags,
+				Description: `
+    smc validators bind --from <account> <signature>
+
+Binds the node to the account, so that the rewards of the node are paid to it.
+The signature is the one returned by tribe.bindSign(<account>) on the node.`,
+			},
+			{
+				Name:      "unbind",
+				Usage:     "Unbind a node from an account",
+				ArgsUsage: "<signature>",
+				Action:    utils.MigrateFlags(validatorsUnbind),
+				Flags:     validatorsTxFlags,
+				Description: `
+    smc validators unbind --from <account> <signature>
+
+Unbinds the node from its account. The signature is the one returned by
+tribe.bindSign(<account>) on the node.`,
+			},
+			{
+				Name:   "show-punishments",
+				Usage:  "Print the validators punished by the consensus",
+				Action: utils.MigrateFlags(validatorsShowPunishments),
+				Flags:  validatorsCallFlags,
+				Description: `
+    smc validators show-punishments
+
+Prints the validators that were disabled for missing their slots or double
+signing, and the block they may start sealing again at.`,
+			},
+		},
+	}
+)
+
+// validatorsClient dials the node selected by the command line flags.
+func validatorsClient(ctx *cli.Context) *ethclient.Client {
+	endpoint := ctx.String(validatorsEndpointFlag.Name)
+	if endpoint == "" {
+		endpoint = localIPCEndpoint(ctx)
+	}
+	client, err := dialRPC(endpoint)
+	if err != nil {
+		utils.Fatalf("Unable to attach to node: %v", err)
+	}
+	return ethclient.NewClient(client)
+}
+
+// validatorsContract binds the Validators contract through the selected node.
+func validatorsContract(ctx *cli.Context) (*validators.Validators, *ethclient.Client) {
+	client := validatorsClient(ctx)
+	vals, err := validators.NewValidators(params.ValidatorsContractAddr, client)
+	if err != nil {
+		utils.Fatalf("Failed to bind the Validators contract: %v", err)
+	}
+	return vals, client
+}
+
+func validatorsList(ctx *cli.Context) error {
+	vals, _ := validatorsContract(ctx)
+	list, err := vals.List(new(bind.CallOptsWithNumber))
+	if err != nil {
+		utils.Fatalf("Failed to list the validators: %v", err)
+	}
+	fmt.Printf("%-42s %-4s %-42s %-28s %s\n", "ADDRESS", "TYPE", "OWNER", "DEPOSIT", "STATE")
+	for _, v := range list {
+		kind := "pos"
+		if v.NodeType == validators.PoaNode {
+			kind = "poa"
+		}
+		state := "sealing"
+		if v.Stopped() {
+			state = fmt.Sprintf("stopped at %v", v.StopBlock)
+		}
+		fmt.Printf("%-42s %-4s %-42s %-28v %s\n", v.Address.Hex(), kind, v.Owner.Hex(), v.Amount, state)
+	}
+	return nil
+}
+
+func validatorsShowPunishments(ctx *cli.Context) error {
+	vals, client := validatorsContract(ctx)
+	head, err := client.HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		utils.Fatalf("Failed to retrieve the head block: %v", err)
+	}
+	list, err := vals.Punishments(&bind.CallOptsWithNumber{Number: head.Number})
+	if err != nil {
+		utils.Fatalf("Failed to list the punishments: %v", err)
+	}
+	fmt.Printf("%-42s %-14s %s\n", "ADDRESS", "RECOVER BLOCK", "STATE")
+	for _, v := range list {
+		state := "recovered"
+		switch {
+		case v.Punished(head.Number):
+			state = fmt.Sprintf("disabled for %v blocks", new(big.Int).Sub(v.RecoverBlock, head.Number))
+		case v.Stopped():
+			state = "stopped"
+		}
+		fmt.Printf("%-42s %-14v %s\n", v.Address.Hex(), v.RecoverBlock, state)
+	}
+	return nil
+}
+
+func validatorsAddPoa(ctx *cli.Context) error {
+	addrs := validatorsAddressArgs(ctx)
+	return validatorsTransact(ctx, func(v *contract.ValidatorsTransactor, opts *bind.TransactOpts) (*types.Transaction, error) {
+		return v.OwnerAddPoaNode(opts, addrs)
+	})
+}
+
+func validatorsRemovePoa(ctx *cli.Context) error {
+	addrs := validatorsAddressArgs(ctx)
+	return validatorsTransact(ctx, func(v *contract.ValidatorsTransactor, opts *bind.TransactOpts) (*types.Transaction, error) {
+		return v.OwnerRemovePoaNode(opts, addrs)
+	})
+}
+
+func validatorsBind(ctx *cli.Context) error {
+	return validatorsTransact(ctx, func(v *contract.ValidatorsTransactor, opts *bind.TransactOpts) (*types.Transaction, error) {
+		sig := validatorsBindSig(ctx, opts.From)
+		return v.Bind(opts, sig.Node, sig.V, sig.R, sig.S)
+	})
+}
+
+func validatorsUnbind(ctx *cli.Context) error {
+	return validatorsTransact(ctx, func(v *contract.ValidatorsTransactor, opts *bind.TransactOpts) (*types.Transaction, error) {
+		sig := validatorsBindSig(ctx, opts.From)
+		return v.UnbindBySig(opts, sig.Node, sig.V, sig.R, sig.S)
+	})
+}
+
+// validatorsAddressArgs parses the validator addresses given as arguments.
+func validatorsAddressArgs(ctx *cli.Context) []common.Address {
+	if len(ctx.Args()) == 0 {
+		utils.Fatalf("No validators specified")
+	}
+	addrs := make([]common.Address, len(ctx.Args()))
+	for i, arg := range ctx.Args() {
+		if !common.IsHexAddress(arg) {
+			utils.Fatalf("Invalid validator address %q", arg)
+		}
+		addrs[i] = common.HexToAddress(arg)
+	}
+	return addrs
+}
+
+// validatorsBindSig parses the node signature over account given as argument.
+func validatorsBindSig(ctx *cli.Context, account common.Address) *validators.BindSig {
+	if len(ctx.Args()) != 1 {
+		utils.Fatalf("This command requires the node signature as argument")
+	}
+	raw, err := hex.DecodeString(strings.TrimPrefix(ctx.Args().First(), "0x"))
+	if err != nil {
+		utils.Fatalf("Invalid signature: %v", err)
+	}
+	sig, err := validators.NewBindSig(account, raw)
+	if err != nil {
+		utils.Fatalf("Invalid signature: %v", err)
+	}
+	fmt.Println("Node:", sig.Node.Hex())
+	return sig
+}
+
+// validatorsTransact signs the transaction built by call with the --from
+// account, and either sends it through the node or prints it when offline.
+func validatorsTransact(ctx *cli.Context, call func(*contract.ValidatorsTransactor, *bind.TransactOpts) (*types.Transaction, error)) error {
+	from := ctx.String(validatorsFromFlag.Name)
+	if from == "" {
+		utils.Fatalf("No account specified to sign with (--%s)", validatorsFromFlag.Name)
+	}
+	chainId := params.MainnetChainConfig.ChainId
+	switch {
+	case ctx.IsSet(validatorsChainIdFlag.Name):
+		chainId = new(big.Int).SetUint64(ctx.Uint64(validatorsChainIdFlag.Name))
+	case ctx.GlobalBool(utils.TestnetFlag.Name):
+		chainId = params.TestnetChainConfig.ChainId
+	case ctx.GlobalBool(utils.DevnetFlag.Name):
+		utils.Fatalf("The chain id of a devnet has to be given (--%s)", validatorsChainIdFlag.Name)
+	}
+	stack, _ := makeConfigNode(ctx)
+	ks := stack.AccountManager().Backends(keystore.KeyStoreType)[0].(*keystore.KeyStore)
+	account, _ := unlockAccount(ctx, ks, from, 0, utils.MakePasswordList(ctx))
+
+	opts := &bind.TransactOpts{
+		From:    account.Address,
+		ChainId: chainId,
+		Signer: func(signer types.Signer, address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			return ks.SignTx(account, tx, chainId)
+		},
+	}
+	if ctx.IsSet(validatorsNonceFlag.Name) {
+		opts.Nonce = new(big.Int).SetUint64(ctx.Uint64(validatorsNonceFlag.Name))
+	}
+	if price := ctx.String(validatorsGasPriceFlag.Name); price != "" {
+		gasPrice, ok := math.ParseBig256(price)
+		if !ok {
+			utils.Fatalf("Invalid gas price %q", price)
+		}
+		opts.GasPrice = gasPrice
+	}
+	if ctx.IsSet(validatorsGasFlag.Name) {
+		opts.GasLimit = new(big.Int).SetUint64(ctx.Uint64(validatorsGasFlag.Name))
+	}
+	if !ctx.Bool(validatorsOfflineFlag.Name) {
+		transactor, err := contract.NewValidatorsTransactor(params.ValidatorsContractAddr, validatorsClient(ctx))
+		if err != nil {
+			utils.Fatalf("Failed to bind the Validators contract: %v", err)
+		}
+		tx, err := call(transactor, opts)
+		if err != nil {
+			utils.Fatalf("Failed to send the transaction: %v", err)
+		}
+		fmt.Println("Transaction:", tx.Hash().Hex())
+		return nil
+	}
+	if opts.Nonce == nil || opts.GasPrice == nil {
+		utils.Fatalf("Offline transactions need --%s and --%s", validatorsNonceFlag.Name, validatorsGasPriceFlag.Name)
+	}
+	if opts.GasLimit == nil {
+		opts.GasLimit = big.NewInt(1000000)
+	}
+	offline := &validators.OfflineTransactor{
+		Nonce:    opts.Nonce.Uint64(),
+		GasPrice: opts.GasPrice,
+		GasLimit: opts.GasLimit,
+	}
+	transactor, err := contract.NewValidatorsTransactor(params.ValidatorsContractAddr, offline)
+	if err != nil {
+		utils.Fatalf("Failed to bind the Validators contract: %v", err)
+	}
+	tx, err := call(transactor, opts)
+	if err != nil {
+		utils.Fatalf("Failed to sign the transaction: %v", err)
+	}
+	raw, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		utils.Fatalf("Failed to encode the transaction: %v", err)
+	}
+	fmt.Println("Transaction:", tx.Hash().Hex())
+	fmt.Println("Raw transaction:", hexutil.Encode(raw))
+	return nil
+}
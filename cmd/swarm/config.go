@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math/big"
 	"os"
 	"reflect"
 	"strconv"
@@ -62,6 +63,7 @@ const (
 	SWARM_ENV_LISTEN_ADDR     = "SWARM_LISTEN_ADDR"
 	SWARM_ENV_PORT            = "SWARM_PORT"
 	SWARM_ENV_NETWORK_ID      = "SWARM_NETWORK_ID"
+	SWARM_ENV_CHAIN_ID        = "SWARM_CHAIN_ID"
 	SWARM_ENV_SWAP_ENABLE     = "SWARM_SWAP_ENABLE"
 	SWARM_ENV_SWAP_API        = "SWARM_SWAP_API"
 	SWARM_ENV_SYNC_ENABLE     = "SWARM_SYNC_ENABLE"
@@ -166,6 +168,12 @@ func cmdLineOverride(currentConfig *bzzapi.Config, ctx *cli.Context) *bzzapi.Con
 		}
 	}
 
+	if chainid := ctx.GlobalString(SwarmChainIdFlag.Name); chainid != "" {
+		if id, _ := strconv.Atoi(chainid); id != 0 {
+			currentConfig.ChainId = big.NewInt(int64(id))
+		}
+	}
+
 	if ctx.GlobalIsSet(utils.DataDirFlag.Name) {
 		if datadir := ctx.GlobalString(utils.DataDirFlag.Name); datadir != "" {
 			currentConfig.Path = datadir
@@ -233,6 +241,12 @@ func envVarsOverride(currentConfig *bzzapi.Config) (config *bzzapi.Config) {
 		}
 	}
 
+	if chainid := os.Getenv(SWARM_ENV_CHAIN_ID); chainid != "" {
+		if id, _ := strconv.Atoi(chainid); id != 0 {
+			currentConfig.ChainId = big.NewInt(int64(id))
+		}
+	}
+
 	if datadir := os.Getenv(GETH_ENV_DATADIR); datadir != "" {
 		currentConfig.Path = datadir
 	}
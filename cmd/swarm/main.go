@@ -91,6 +91,11 @@ var (
 		Usage:  "Network identifier (integer, default 3=swarm testnet)",
 		EnvVar: SWARM_ENV_NETWORK_ID,
 	}
+	SwarmChainIdFlag = cli.IntFlag{
+		Name:   "chainid",
+		Usage:  "Chain id to sign the ENS and SWAP transactions for (default 1=mainnet)",
+		EnvVar: SWARM_ENV_CHAIN_ID,
+	}
 	SwarmConfigPathFlag = cli.StringFlag{
 		Name:  "bzzconfig",
 		Usage: "DEPRECATED: please use --config path/to/TOML-file",
@@ -353,6 +358,7 @@ DEPRECATED: use 'swarm db clean'.
 		SwarmPortFlag,
 		SwarmAccountFlag,
 		SwarmNetworkIdFlag,
+		SwarmChainIdFlag,
 		ChequebookAddrFlag,
 		// upload flags
 		SwarmApiFlag,
@@ -0,0 +1,210 @@
+// Copyright 2018 The mesh-chain Authors
+// This file is part of mesh-chain.
+//
+// mesh-chain is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// mesh-chain is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with mesh-chain. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/core"
+	"github.com/MeshBoxTech/mesh-chain/core/types"
+	"github.com/MeshBoxTech/mesh-chain/log"
+)
+
+// ExportArchive writes the blocks between first and last of the chain into a
+// checksummed chain archive, along with their receipts if requested.
+func ExportArchive(blockchain *core.BlockChain, fn string, first uint64, last uint64, compression core.ArchiveCompression, receipts bool) error {
+	log.Info("Exporting blockchain archive", "file", fn)
+	fh, err := os.OpenFile(fn, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	writer := bufio.NewWriter(fh)
+	var out io.Writer = writer
+	if strings.HasSuffix(fn, ".gz") {
+		out = gzip.NewWriter(writer)
+	}
+	if err := blockchain.ExportArchive(out, first, last, compression, receipts); err != nil {
+		return err
+	}
+	if gz, ok := out.(*gzip.Writer); ok {
+		if err := gz.Close(); err != nil {
+			return err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	log.Info("Exported blockchain archive", "file", fn)
+	return nil
+}
+
+// openArchive opens a chain archive file for reading.
+func openArchive(fn string) (*core.ArchiveReader, io.Closer, error) {
+	fh, err := os.Open(fn)
+	if err != nil {
+		return nil, nil, err
+	}
+	var reader io.Reader = bufio.NewReader(fh)
+	if strings.HasSuffix(fn, ".gz") {
+		if reader, err = gzip.NewReader(reader); err != nil {
+			fh.Close()
+			return nil, nil, err
+		}
+	}
+	ar, err := core.NewArchiveReader(reader)
+	if err != nil {
+		fh.Close()
+		return nil, nil, err
+	}
+	return ar, fh, nil
+}
+
+// archiveResult is a chunk of an archive verified and decoded in the background.
+type archiveResult struct {
+	chunk   *core.ArchiveChunk
+	entries []core.ArchiveEntry // Decoded entries, nil if the chunk was skipped
+	err     error
+}
+
+// processArchive streams the chunks of an archive, verifying and decoding them
+// on all cores, and passes them to fn in order. Chunks for which skip returns
+// true are passed along without being decoded.
+func processArchive(ar *core.ArchiveReader, skip func(*core.ArchiveChunk) bool, fn func(*core.ArchiveChunk, []core.ArchiveEntry) error) error {
+	var (
+		queue = make(chan chan archiveResult, runtime.NumCPU())
+		abort = make(chan struct{})
+	)
+	defer close(abort)
+
+	go func() {
+		defer close(queue)
+		for {
+			chunk, err := ar.ReadChunk()
+			if err == io.EOF {
+				return
+			}
+			res := make(chan archiveResult, 1)
+			select {
+			case queue <- res:
+			case <-abort:
+				return
+			}
+			if err != nil {
+				res <- archiveResult{err: err}
+				return
+			}
+			go func() {
+				if skip != nil && skip(chunk) {
+					res <- archiveResult{chunk: chunk}
+					return
+				}
+				entries, err := chunk.Decode(ar.Header.Compression)
+				res <- archiveResult{chunk: chunk, entries: entries, err: err}
+			}()
+		}
+	}()
+	for res := range queue {
+		r := <-res
+		if r.err != nil {
+			return r.err
+		}
+		if err := fn(r.chunk, r.entries); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// VerifyArchive checks the checksums and the content of all the chunks of a
+// chain archive, returning its header.
+func VerifyArchive(fn string) (*core.ArchiveHeader, error) {
+	ar, closer, err := openArchive(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+
+	start := time.Now()
+	err = processArchive(ar, nil, func(*core.ArchiveChunk, []core.ArchiveEntry) error { return nil })
+	if err != nil {
+		return nil, err
+	}
+	log.Info("Verified blockchain archive", "file", fn, "first", ar.Header.First, "last", ar.Header.Last, "elapsed", common.PrettyDuration(time.Since(start)))
+	return &ar.Header, nil
+}
+
+// importArchive imports a chain archive after verifying all of it, so broken
+// archives are rejected before touching the chain. Chunks already present in
+// the chain are skipped, so an interrupted import can be resumed.
+func importArchive(chain *core.BlockChain, fn string, interrupted func() bool) error {
+	ar, closer, err := openArchive(fn)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	chainId := chain.Config().ChainId
+	if chainId == nil {
+		chainId = new(big.Int)
+	}
+	if ar.Header.ChainId.Cmp(chainId) != 0 {
+		return fmt.Errorf("archive of chain %v, want %v", ar.Header.ChainId, chainId)
+	}
+	if ar.Header.Genesis != chain.Genesis().Hash() {
+		return fmt.Errorf("archive of genesis %x, want %x", ar.Header.Genesis, chain.Genesis().Hash())
+	}
+	if _, err := VerifyArchive(fn); err != nil {
+		return err
+	}
+	present := func(chunk *core.ArchiveChunk) bool {
+		return chain.HasBlock(chunk.Last, chunk.First+chunk.Count-1)
+	}
+	return processArchive(ar, present, func(chunk *core.ArchiveChunk, entries []core.ArchiveEntry) error {
+		if interrupted() {
+			return fmt.Errorf("interrupted")
+		}
+		if entries == nil {
+			log.Info("Skipping chunk as all blocks present", "first", chunk.First, "last", chunk.First+chunk.Count-1)
+			return nil
+		}
+		blocks := make(types.Blocks, 0, len(entries))
+		for _, entry := range entries {
+			// don't import first block
+			if entry.Block.NumberU64() > 0 {
+				blocks = append(blocks, entry.Block)
+			}
+		}
+		if len(blocks) == 0 || hasAllBlocks(chain, blocks) {
+			return nil
+		}
+		if _, err := chain.InsertChain(blocks); err != nil {
+			return fmt.Errorf("invalid block in #%d-#%d: %v", chunk.First, chunk.First+chunk.Count-1, err)
+		}
+		return nil
+	})
+}
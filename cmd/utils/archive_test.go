@@ -0,0 +1,100 @@
+// Copyright 2018 The mesh-chain Authors
+// This file is part of mesh-chain.
+//
+// mesh-chain is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// mesh-chain is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with mesh-chain. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MeshBoxTech/mesh-chain/consensus/ethash"
+	"github.com/MeshBoxTech/mesh-chain/core"
+	"github.com/MeshBoxTech/mesh-chain/core/types"
+	"github.com/MeshBoxTech/mesh-chain/core/vm"
+	"github.com/MeshBoxTech/mesh-chain/ethdb"
+	"github.com/MeshBoxTech/mesh-chain/params"
+)
+
+// newTestChain creates a chain on top of the test genesis, inserting the given
+// number of generated blocks.
+func newTestChain(t *testing.T, n int) *core.BlockChain {
+	db, _ := ethdb.NewMemDatabase()
+	genesis := (&core.Genesis{Config: params.TestChainConfig}).MustCommit(db)
+
+	chain, err := core.NewBlockChain(db, nil, params.TestChainConfig, ethash.NewFaker(), vm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n > 0 {
+		blocks, _ := core.GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), db, n, nil)
+		if _, err := chain.InsertChain(blocks); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return chain
+}
+
+func TestArchiveImport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	source := newTestChain(t, 1200)
+	defer source.Stop()
+
+	for _, fn := range []string{"chain.mca", "chain.mca.gz"} {
+		fn = filepath.Join(dir, fn)
+		if err := ExportArchive(source, fn, 0, source.CurrentBlock().NumberU64(), core.ArchiveCompressionRLE, true); err != nil {
+			t.Fatalf("%s: export failed: %v", fn, err)
+		}
+		// Resume an import that stopped halfway
+		chain := newTestChain(t, 0)
+		partial := make(types.Blocks, 0, 700)
+		for i := uint64(1); i <= 700; i++ {
+			partial = append(partial, source.GetBlockByNumber(i))
+		}
+		if _, err := chain.InsertChain(partial); err != nil {
+			t.Fatal(err)
+		}
+		if err := ImportChain(chain, fn); err != nil {
+			t.Fatalf("%s: import failed: %v", fn, err)
+		}
+		if chain.CurrentBlock().Hash() != source.CurrentBlock().Hash() {
+			t.Errorf("%s: head mismatch: have #%d, want #%d", fn, chain.CurrentBlock().NumberU64(), source.CurrentBlock().NumberU64())
+		}
+		chain.Stop()
+	}
+	// A corrupted archive is rejected before anything is imported
+	fn := filepath.Join(dir, "chain.mca")
+	archive, _ := ioutil.ReadFile(fn)
+	archive[len(archive)-10] ^= 0x01
+	if err := ioutil.WriteFile(fn, archive, 0644); err != nil {
+		t.Fatal(err)
+	}
+	chain := newTestChain(t, 0)
+	defer chain.Stop()
+
+	if err := ImportChain(chain, fn); err == nil {
+		t.Fatalf("corrupted archive imported")
+	}
+	if head := chain.CurrentBlock().NumberU64(); head != 0 {
+		t.Errorf("blocks imported from a corrupted archive: head #%d", head)
+	}
+}
@@ -18,6 +18,7 @@
 package utils
 
 import (
+	"bufio"
 	"compress/gzip"
 	"fmt"
 	"io"
@@ -116,6 +117,11 @@ func ImportChain(chain *core.BlockChain, fn string) error {
 			return err
 		}
 	}
+	buffered := bufio.NewReader(reader)
+	if core.IsArchive(buffered) {
+		return importArchive(chain, fn, checkInterrupt)
+	}
+	reader = buffered
 
 	stream := rlp.NewStream(reader, 0)
 
@@ -115,6 +115,10 @@ var (
 		Usage: "Data directory for the databases and keystore",
 		Value: DirectoryString{node.DefaultDataDir()},
 	}
+	AncientFlag = DirectoryFlag{
+		Name:  "datadir.ancient",
+		Usage: "Data directory for ancient chain segments (default = inside chaindata)",
+	}
 	KeyStoreDirFlag = DirectoryFlag{
 		Name:  "keystore",
 		Usage: "Directory for the keystore (default = inside the datadir)",
@@ -309,6 +313,16 @@ var (
 		Usage: "Megabytes of memory allocated to internal caching (min 16MB / database forced)",
 		Value: 128,
 	}
+	GCModeFlag = cli.StringFlag{
+		Name:  "gcmode",
+		Usage: `Blockchain garbage collection mode ("full", "archive")`,
+		Value: "full",
+	}
+	TxLookupLimitFlag = cli.Uint64Flag{
+		Name:  "txlookuplimit",
+		Usage: "Number of recent blocks to maintain transactions index by-hash for (0 = index all blocks)",
+		Value: eth.DefaultConfig.TxLookupLimit,
+	}
 	TrieCacheGenFlag = cli.IntFlag{
 		Name:  "trie-cache-gens",
 		Usage: "Number of trie node generations to keep in memory",
@@ -859,13 +873,16 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	setWS(ctx, cfg)
 	setNodeUserIdent(ctx, cfg)
 
+	if ctx.GlobalBool(TestnetFlag.Name) {
+		cfg.Testnet = true
+	}
 	switch {
 	case ctx.GlobalIsSet(DataDirFlag.Name):
 		cfg.DataDir = ctx.GlobalString(DataDirFlag.Name)
 	case ctx.GlobalBool(DeveloperFlag.Name):
 		cfg.DataDir = "" // unless explicitly requested, use memory databases
-	case ctx.GlobalBool(TestnetFlag.Name):
-		cfg.DataDir = filepath.Join(node.DefaultDataDir(), "testnet")
+	case cfg.Testnet:
+		cfg.DataDir = node.TestDataDir()
 	case ctx.GlobalBool(DevnetFlag.Name):
 		cfg.DataDir = filepath.Join(node.DefaultDataDir(), "devnet")
 	}
@@ -1032,7 +1049,14 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *eth.Config) {
 	if ctx.GlobalIsSet(CacheFlag.Name) {
 		cfg.DatabaseCache = ctx.GlobalInt(CacheFlag.Name)
 	}
+	if ctx.GlobalIsSet(AncientFlag.Name) {
+		cfg.DatabaseFreezer = ctx.GlobalString(AncientFlag.Name)
+	}
 	cfg.DatabaseHandles = makeDatabaseHandles()
+	cfg.NoPruning = isArchive(ctx)
+	if ctx.GlobalIsSet(TxLookupLimitFlag.Name) {
+		cfg.TxLookupLimit = ctx.GlobalUint64(TxLookupLimitFlag.Name)
+	}
 
 	if ctx.GlobalIsSet(MinerThreadsFlag.Name) {
 		cfg.MinerThreads = ctx.GlobalInt(MinerThreadsFlag.Name)
@@ -1057,6 +1081,9 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *eth.Config) {
 		if !ctx.GlobalIsSet(NetworkIdFlag.Name) {
 			cfg.NetworkId = 3
 		}
+		if cfg.Genesis == nil {
+			cfg.Genesis = core.DefaultTestnetGenesisBlock()
+		}
 	}
 	// TODO(fjl): move trie cache generations into config
 	if gen := ctx.GlobalInt(TrieCacheGenFlag.Name); gen > 0 {
@@ -1064,6 +1091,20 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *eth.Config) {
 	}
 }
 
+// isArchive reports whether the garbage collection mode requests an archive node
+// persisting the state of every block.
+func isArchive(ctx *cli.Context) bool {
+	switch gcmode := ctx.GlobalString(GCModeFlag.Name); gcmode {
+	case "full":
+		return false
+	case "archive":
+		return true
+	default:
+		Fatalf("--%s must be either 'full' or 'archive', got %q", GCModeFlag.Name, gcmode)
+	}
+	return false
+}
+
 // SetDashboardConfig applies dashboard related command line flags to the config.
 func SetDashboardConfig(ctx *cli.Context, cfg *dashboard.Config) {
 	cfg.Host = ctx.GlobalString(DashboardAddrFlag.Name)
@@ -1147,9 +1188,50 @@ func MakeChainDatabase(ctx *cli.Context, stack *node.Node) ethdb.Database {
 	if err != nil {
 		Fatalf("Could not open database: %v", err)
 	}
+	if ctx.GlobalBool(LightModeFlag.Name) {
+		return chainDb
+	}
+	return MakeFreezerDatabase(ctx, stack, chainDb)
+}
+
+// MakeFreezerDatabase attaches the ancient store configured by the flags to a
+// full node chain database and will hard crash if it fails. Ephemeral databases
+// are returned as is.
+func MakeFreezerDatabase(ctx *cli.Context, stack *node.Node, db ethdb.Database) ethdb.Database {
+	ldb, ok := db.(*ethdb.LDBDatabase)
+	if !ok {
+		return db
+	}
+	chainDb, err := core.NewDatabaseWithFreezer(db, ancientPath(ctx, stack, ldb), "", eth.DefaultConfig.FreezerThreshold)
+	if err != nil {
+		Fatalf("Could not open ancient database: %v", err)
+	}
 	return chainDb
 }
 
+// MakeAncientDatabase attaches the ancient store configured by the flags to a
+// full node chain database like MakeFreezerDatabase, but without moving any
+// blocks into it, so tools can look at the database without modifying it.
+func MakeAncientDatabase(ctx *cli.Context, stack *node.Node, db ethdb.Database) ethdb.Database {
+	ldb, ok := db.(*ethdb.LDBDatabase)
+	if !ok {
+		return db
+	}
+	chainDb, err := core.NewDatabaseWithAncients(db, ancientPath(ctx, stack, ldb), "")
+	if err != nil {
+		Fatalf("Could not open ancient database: %v", err)
+	}
+	return chainDb
+}
+
+// ancientPath returns the directory of the ancient store of the database.
+func ancientPath(ctx *cli.Context, stack *node.Node, ldb *ethdb.LDBDatabase) string {
+	if ctx.GlobalIsSet(AncientFlag.Name) {
+		return stack.ResolvePath(ctx.GlobalString(AncientFlag.Name))
+	}
+	return filepath.Join(ldb.Path(), "ancient")
+}
+
 func MakeGenesis(ctx *cli.Context) *core.Genesis {
 	var genesis *core.Genesis
 	switch {
@@ -1184,8 +1266,13 @@ func MakeChain(ctx *cli.Context, stack *node.Node) (chain *core.BlockChain, chai
 			})
 		}
 	}
+	cache := &core.CacheConfig{
+		Disabled:      isArchive(ctx),
+		TrieNodeLimit: eth.DefaultConfig.TrieCache,
+		TxLookupLimit: ctx.GlobalUint64(TxLookupLimitFlag.Name),
+	}
 	vmcfg := vm.Config{EnablePreimageRecording: ctx.GlobalBool(VMEnableDebugFlag.Name)}
-	chain, err = core.NewBlockChain(chainDb, config, engine, vmcfg)
+	chain, err = core.NewBlockChain(chainDb, cache, config, engine, vmcfg)
 	if err != nil {
 		Fatalf("Can't create BlockChain: %v", err)
 	}
@@ -23,6 +23,7 @@ import (
 	"github.com/MeshBoxTech/mesh-chain/common"
 	"github.com/MeshBoxTech/mesh-chain/core/state"
 	"github.com/MeshBoxTech/mesh-chain/core/types"
+	"github.com/MeshBoxTech/mesh-chain/ethdb"
 	"github.com/MeshBoxTech/mesh-chain/params"
 	"github.com/MeshBoxTech/mesh-chain/rpc"
 )
@@ -105,3 +106,29 @@ type PoW interface {
 	// Hashrate returns the current mining hashrate of a PoW consensus engine.
 	Hashrate() float64
 }
+
+// PoS is a consensus engine based on a set of elected validators.
+type PoS interface {
+	Engine
+
+	// Validators retrieves the validators authorized by the local snapshot to
+	// seal the child of the given block.
+	Validators(chain ChainReader, number uint64, hash common.Hash) ([]common.Address, error)
+
+	// EpochValidators returns the validators announced in the extra-data of an
+	// epoch header along with the ones elected locally for it, both nil if the
+	// header doesn't start an epoch.
+	EpochValidators(chain ChainReader, header *types.Header) (announced []common.Address, elected []common.Address, err error)
+}
+
+// Recorder is a consensus engine keeping records of the blocks written to the
+// chain, next to their receipts.
+type Recorder interface {
+	// WriteBlockRecords stores the engine's records of a block whose state has
+	// been committed, keyed by the block hash.
+	WriteBlockRecords(chain ChainReader, db ethdb.Putter, block *types.Block) error
+
+	// BlockRecords returns the engine's records of a block written to the chain,
+	// or nil if there are none.
+	BlockRecords(header *types.Header) interface{}
+}
@@ -0,0 +1,77 @@
+// Copyright 2018 The mesh-chain Authors
+// This file is part of the mesh-chain library.
+//
+// The mesh-chain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The mesh-chain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the mesh-chain library. If not, see <http://www.gnu.org/licenses/>.
+
+package misc
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/common/math"
+	"github.com/MeshBoxTech/mesh-chain/core/types"
+	"github.com/MeshBoxTech/mesh-chain/params"
+)
+
+// VerifyEip1559Header verifies that the base fee of a header is the one
+// expected from its parent, as defined by EIP-1559.
+func VerifyEip1559Header(config *params.ChainConfig, parent, header *types.Header) error {
+	if header.BaseFee == nil {
+		return fmt.Errorf("header is missing baseFee")
+	}
+	if expected := CalcBaseFee(config, parent); header.BaseFee.Cmp(expected) != 0 {
+		return fmt.Errorf("invalid baseFee: have %s, want %s, parentBaseFee %s, parentGasUsed %s",
+			header.BaseFee, expected, parent.BaseFee, parent.GasUsed)
+	}
+	return nil
+}
+
+// CalcBaseFee calculates the base fee of the header following the parent.
+func CalcBaseFee(config *params.ChainConfig, parent *types.Header) *big.Int {
+	// The first fee market block starts at the initial base fee
+	if !config.IsLondon(parent.Number) {
+		return new(big.Int).SetUint64(params.InitialBaseFee)
+	}
+	var (
+		parentGasTarget          = new(big.Int).Div(parent.GasLimit, big.NewInt(params.ElasticityMultiplier))
+		baseFeeChangeDenominator = big.NewInt(params.BaseFeeChangeDenominator)
+	)
+	switch parent.GasUsed.Cmp(parentGasTarget) {
+	case 0:
+		// The parent used exactly its target, the base fee stays the same
+		return new(big.Int).Set(parent.BaseFee)
+
+	case 1:
+		// The parent used more than its target, the base fee increases
+		// by at least one wei
+		gasUsedDelta := new(big.Int).Sub(parent.GasUsed, parentGasTarget)
+		x := new(big.Int).Mul(parent.BaseFee, gasUsedDelta)
+		y := x.Div(x, parentGasTarget)
+		baseFeeDelta := math.BigMax(x.Div(y, baseFeeChangeDenominator), common.Big1)
+
+		return x.Add(parent.BaseFee, baseFeeDelta)
+
+	default:
+		// The parent used less than its target, the base fee decreases
+		// but never below zero
+		gasUsedDelta := new(big.Int).Sub(parentGasTarget, parent.GasUsed)
+		x := new(big.Int).Mul(parent.BaseFee, gasUsedDelta)
+		y := x.Div(x, parentGasTarget)
+		baseFeeDelta := x.Div(y, baseFeeChangeDenominator)
+
+		return math.BigMax(x.Sub(parent.BaseFee, baseFeeDelta), common.Big0)
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2018 The mesh-chain Authors
+// This file is part of the mesh-chain library.
+//
+// The mesh-chain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The mesh-chain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the mesh-chain library. If not, see <http://www.gnu.org/licenses/>.
+
+package misc
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/MeshBoxTech/mesh-chain/core/types"
+	"github.com/MeshBoxTech/mesh-chain/params"
+)
+
+// londonConfig returns a chain config with the fee market active from block 5.
+func londonConfig() *params.ChainConfig {
+	config := *params.AllEthashProtocolChanges
+	config.LondonBlock = big.NewInt(5)
+	return &config
+}
+
+// Tests that the base fee follows the gas usage of the parent block.
+func TestCalcBaseFee(t *testing.T) {
+	tests := []struct {
+		number, parentBaseFee, parentGasUsed, expectedBaseFee int64
+	}{
+		{4, 0, 0, params.InitialBaseFee},                  // first fee market block
+		{5, params.InitialBaseFee, 10000000, 18000000000}, // usage == target
+		{5, params.InitialBaseFee, 9000000, 17775000000},  // usage below target
+		{5, params.InitialBaseFee, 11000000, 18225000000}, // usage above target
+		{5, params.InitialBaseFee, 20000000, 20250000000}, // full block
+		{5, params.InitialBaseFee, 0, 15750000000},        // empty block
+		{5, 7, 10000001, 8},                               // increases by at least one wei
+		{5, 1, 0, 1},                                      // never drops to zero from rounding
+	}
+	for i, test := range tests {
+		parent := &types.Header{
+			Number:   big.NewInt(test.number),
+			GasLimit: big.NewInt(20000000),
+			GasUsed:  big.NewInt(test.parentGasUsed),
+			BaseFee:  big.NewInt(test.parentBaseFee),
+		}
+		if have, want := CalcBaseFee(londonConfig(), parent), big.NewInt(test.expectedBaseFee); have.Cmp(want) != 0 {
+			t.Errorf("test %d: base fee mismatch: have %v, want %v", i, have, want)
+		}
+	}
+}
+
+// Tests that headers are checked against the base fee expected from the parent.
+func TestVerifyEip1559Header(t *testing.T) {
+	config := londonConfig()
+	parent := &types.Header{
+		Number:   big.NewInt(5),
+		GasLimit: big.NewInt(20000000),
+		GasUsed:  big.NewInt(20000000),
+		BaseFee:  big.NewInt(params.InitialBaseFee),
+	}
+	header := &types.Header{Number: big.NewInt(6), BaseFee: CalcBaseFee(config, parent)}
+	if err := VerifyEip1559Header(config, parent, header); err != nil {
+		t.Errorf("valid header rejected: %v", err)
+	}
+	header.BaseFee = big.NewInt(params.InitialBaseFee)
+	if err := VerifyEip1559Header(config, parent, header); err == nil {
+		t.Errorf("invalid base fee accepted")
+	}
+	header.BaseFee = nil
+	if err := VerifyEip1559Header(config, parent, header); err == nil {
+		t.Errorf("missing base fee accepted")
+	}
+}
@@ -0,0 +1,37 @@
+// Copyright 2018 The mesh-chain Authors
+// This file is part of the mesh-chain library.
+//
+// The mesh-chain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The mesh-chain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the mesh-chain library. If not, see <http://www.gnu.org/licenses/>.
+
+package misc
+
+import (
+	"math/big"
+
+	"github.com/MeshBoxTech/mesh-chain/core/state"
+	"github.com/MeshBoxTech/mesh-chain/params"
+)
+
+// ApplyEvidenceFork modifies the state database at the tribe double-sign
+// evidence fork block, upgrading the Validators contract to the configured code
+// accepting evidence. The contract storage is kept, the upgraded code only
+// appends to its layout.
+func ApplyEvidenceFork(config *params.ChainConfig, number *big.Int, statedb *state.StateDB) {
+	if config.Tribe == nil || config.Tribe.EvidenceBlock == nil || config.Tribe.EvidenceBlock.Cmp(number) != 0 {
+		return
+	}
+	if len(config.Tribe.ValidatorsCode) > 0 {
+		statedb.SetCode(params.ValidatorsContractAddr, config.Tribe.ValidatorsCode)
+	}
+}
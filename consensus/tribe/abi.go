@@ -325,6 +325,25 @@ const validatorSetABI = `
 		"name": "Deposit",
 		"type": "event"
 	},
+	{
+		"anonymous": false,
+		"inputs": [
+			{
+				"indexed": true,
+				"internalType": "address",
+				"name": "miner",
+				"type": "address"
+			},
+			{
+				"indexed": false,
+				"internalType": "uint256",
+				"name": "blockNumber",
+				"type": "uint256"
+			}
+		],
+		"name": "DoubleSign",
+		"type": "event"
+	},
 	{
 		"anonymous": false,
 		"inputs": [
@@ -483,6 +502,30 @@ const validatorSetABI = `
 		"stateMutability": "view",
 		"type": "function"
 	},
+	{
+		"inputs": [
+			{
+				"internalType": "address",
+				"name": "",
+				"type": "address"
+			},
+			{
+				"internalType": "uint256",
+				"name": "",
+				"type": "uint256"
+			}
+		],
+		"name": "doubleSignPunished",
+		"outputs": [
+			{
+				"internalType": "bool",
+				"name": "",
+				"type": "bool"
+			}
+		],
+		"stateMutability": "view",
+		"type": "function"
+	},
 	{
 		"inputs": [],
 		"name": "getAllValidators",
@@ -688,6 +731,34 @@ const validatorSetABI = `
 		"stateMutability": "nonpayable",
 		"type": "function"
 	},
+	{
+		"inputs": [
+			{
+				"internalType": "address",
+				"name": "minerAddress",
+				"type": "address"
+			},
+			{
+				"internalType": "uint256",
+				"name": "blockNumber",
+				"type": "uint256"
+			},
+			{
+				"internalType": "bytes",
+				"name": "headerA",
+				"type": "bytes"
+			},
+			{
+				"internalType": "bytes",
+				"name": "headerB",
+				"type": "bytes"
+			}
+		],
+		"name": "punishDoubleSign",
+		"outputs": [],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	},
 	{
 		"inputs": [
 			{
@@ -4,13 +4,10 @@ import (
 	"context"
 	"encoding/hex"
 	"errors"
-	"github.com/MeshBoxTech/mesh-chain/ethclient"
 	"math/big"
-	"time"
 
 	"github.com/MeshBoxTech/mesh-chain/common"
 	"github.com/MeshBoxTech/mesh-chain/crypto"
-	"github.com/MeshBoxTech/mesh-chain/params"
 )
 
 func (api *API) BindSign(from *common.Address) (string, error) {
@@ -27,6 +24,24 @@ func (api *API) BindSign(from *common.Address) (string, error) {
 	return sigHex, nil
 }
 
+// Bind binds the node to the keystore account: the node key signs the account,
+// the account sends the bind transaction to the Validators contract, and the
+// bind info of the account is returned once the transaction is included.
+func (api *API) Bind(ctx context.Context, from common.Address, passwd string) (map[string]interface{}, error) {
+	if err := api.tribe.sendBind(ctx, api.chain, "bind", from, passwd); err != nil {
+		return nil, err
+	}
+	return api.BindInfo(&from, nil)
+}
+
+// Unbind is the counterpart of Bind, unbinding the node from the account.
+func (api *API) Unbind(ctx context.Context, from common.Address, passwd string) (map[string]interface{}, error) {
+	if err := api.tribe.sendBind(ctx, api.chain, "unbindBySig", from, passwd); err != nil {
+		return nil, err
+	}
+	return api.BindInfo(&from, nil)
+}
+
 func (api *API) BindInfo(addr *common.Address, num *big.Int) (map[string]interface{}, error) {
 	if addr == nil {
 		nodekey := api.tribe.getNodekey()
@@ -48,18 +63,11 @@ func (api *API) BindInfo(addr *common.Address, num *big.Int) (map[string]interfa
 }
 func (api *API) GetMiner() (*TribeMiner, error) {
 	add := api.tribe.GetMinerAddress()
-	ipcpath := params.GetIPCPath()
-	c, e := ethclient.Dial(ipcpath)
+	state, e := api.tribe.stateFn(api.chain.CurrentHeader().Root)
 	if e != nil {
 		return nil, e
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
-	defer cancel()
-	b, e := c.BalanceAt(ctx, add, nil)
-	if e != nil {
-		return nil, e
-	}
-	return &TribeMiner{add, b}, nil
+	return &TribeMiner{add, state.GetBalance(add)}, nil
 }
 
 func (api *API) GetValidators(number *big.Int) ([]common.Address, error) {
@@ -69,7 +77,7 @@ func (api *API) GetValidators(number *big.Int) ([]common.Address, error) {
 	}
 
 	num := header.Number.Uint64()
-	for num%api.tribe.config.Epoch != 0 {
+	for num%api.tribe.forkAt(new(big.Int).SetUint64(num)).Epoch != 0 {
 		num -= 1
 	}
 	if num < header.Number.Uint64(){
@@ -77,3 +85,96 @@ func (api *API) GetValidators(number *big.Int) ([]common.Address, error) {
 	}
 	return api.tribe.getNewValidators(api.chain,header)
 }
+
+// GetEvidence returns the double-sign evidence seen by this node, optionally
+// restricted to a single validator.
+func (api *API) GetEvidence(validator *common.Address) ([]*Evidence, error) {
+	known, err := loadEvidence(api.tribe.db)
+	if err != nil {
+		return nil, err
+	}
+	evidence := make([]*Evidence, 0, len(known))
+	for _, ev := range known {
+		if validator == nil || ev.Validator == *validator {
+			evidence = append(evidence, ev)
+		}
+	}
+	return evidence, nil
+}
+
+// GetValidatorStats retrieves the in-turn, out-of-turn, missed and punished
+// block counts of every validator between fromBlock and toBlock inclusive. The
+// range defaults to the whole chain up to the current head.
+func (api *API) GetValidatorStats(fromBlock, toBlock *big.Int) (map[common.Address]*ValidatorStats, error) {
+	head := api.chain.CurrentHeader().Number.Uint64()
+	from, to := uint64(1), head
+	if fromBlock != nil {
+		from = fromBlock.Uint64()
+	}
+	if toBlock != nil {
+		to = toBlock.Uint64()
+	}
+	if from > to || to > head {
+		return nil, errInvalidBlockRange
+	}
+	return api.tribe.collectStats(api.chain, from, to)
+}
+
+// GetBlockRewards returns the validator and POM rewards minted by the block,
+// defaulting to the current head.
+func (api *API) GetBlockRewards(number *big.Int) (*BlockRewards, error) {
+	header := api.chain.CurrentHeader()
+	if number != nil {
+		if header = api.chain.GetHeaderByNumber(number.Uint64()); header == nil {
+			return nil, errUnknownBlock
+		}
+	}
+	return api.tribe.getBlockRewards(api.chain, header)
+}
+
+// GetBalanceProof returns the SMT or MESH balance of addr with its merkle proof
+// against the storage root of the token contract, at the given block or the
+// current head.
+func (api *API) GetBalanceProof(addr common.Address, token string, number *big.Int) (*BalanceProof, error) {
+	header := api.chain.CurrentHeader()
+	if number != nil {
+		if header = api.chain.GetHeaderByNumber(number.Uint64()); header == nil {
+			return nil, errUnknownBlock
+		}
+	}
+	return api.tribe.balanceProof(header.Root, addr, token)
+}
+
+// GetRewardsByAddress returns the rewards of the blocks between fromBlock and
+// toBlock inclusive that were sealed by or paid to addr.
+func (api *API) GetRewardsByAddress(addr common.Address, fromBlock, toBlock *big.Int) ([]*BlockRewards, error) {
+	head := api.chain.CurrentHeader().Number.Uint64()
+	from, to := uint64(1), head
+	if fromBlock != nil {
+		from = fromBlock.Uint64()
+	}
+	if toBlock != nil {
+		to = toBlock.Uint64()
+	}
+	if from == 0 || from > to || to > head {
+		return nil, errInvalidBlockRange
+	}
+	if to-from >= maxRewardsRange {
+		return nil, errRewardsRangeTooLarge
+	}
+	rewards := make([]*BlockRewards, 0)
+	for number := from; number <= to; number++ {
+		header := api.chain.GetHeaderByNumber(number)
+		if header == nil {
+			return nil, errUnknownBlock
+		}
+		r, err := api.tribe.getBlockRewards(api.chain, header)
+		if err != nil {
+			return nil, err
+		}
+		if r.Validator == addr || r.Recipient == addr {
+			rewards = append(rewards, r)
+		}
+	}
+	return rewards, nil
+}
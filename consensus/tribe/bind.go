@@ -0,0 +1,120 @@
+package tribe
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/MeshBoxTech/mesh-chain/accounts"
+	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/consensus"
+	"github.com/MeshBoxTech/mesh-chain/consensus/misc"
+	"github.com/MeshBoxTech/mesh-chain/core"
+	"github.com/MeshBoxTech/mesh-chain/core/types"
+	"github.com/MeshBoxTech/mesh-chain/crypto"
+	"github.com/MeshBoxTech/mesh-chain/log"
+	"github.com/MeshBoxTech/mesh-chain/params"
+)
+
+const (
+	// bindGasLimit is the gas allowance of a bind or unbind transaction.
+	bindGasLimit = 300000
+
+	// bindTimeout is how long to wait for a bind or unbind transaction to be
+	// included in the chain.
+	bindTimeout = 5 * time.Minute
+
+	// bindPollInterval is the interval to check the inclusion of a bind or
+	// unbind transaction at.
+	bindPollInterval = time.Second
+)
+
+var (
+	// errNoTxPool is returned if the node can't submit transactions.
+	errNoTxPool = errors.New("no transaction pool")
+
+	// errBindFailed is returned if a bind or unbind transaction was reverted,
+	// e.g. because the node was already bound.
+	errBindFailed = errors.New("bind transaction failed")
+)
+
+// SetTxPool sets the transaction pool the bind and unbind transactions of the
+// tribe API are submitted to.
+func (t *Tribe) SetTxPool(pool *core.TxPool) {
+	t.txPool = pool
+}
+
+// packBind packs a call of the bind or unbind method of the Validators
+// contract, authorized by the node key signature over account.
+func (t *Tribe) packBind(method string, account common.Address) ([]byte, error) {
+	nodekey := t.getNodekey()
+	sig, err := crypto.Sign(crypto.Keccak256(account.Bytes()), nodekey)
+	if err != nil {
+		return nil, err
+	}
+	var r, s [32]byte
+	copy(r[:], sig[:32])
+	copy(s[:], sig[32:64])
+	return t.abi[ValidatorsContractName].Pack(method, crypto.PubkeyToAddress(nodekey.PublicKey), sig[64]+27, r, s)
+}
+
+// sendBind signs a bind or unbind transaction of the node with the keystore
+// account, submits it and waits for its inclusion.
+func (t *Tribe) sendBind(ctx context.Context, chain consensus.ChainReader, method string, account common.Address, passwd string) error {
+	if t.txPool == nil {
+		return errNoTxPool
+	}
+	wallet, err := t.accman.Find(accounts.Account{Address: account})
+	if err != nil {
+		return err
+	}
+	data, err := t.packBind(method, account)
+	if err != nil {
+		return err
+	}
+	nonce := t.txPool.State().GetNonce(account)
+	tx := types.NewTransaction(nonce, params.ValidatorsContractAddr, new(big.Int), new(big.Int).SetUint64(bindGasLimit), t.bindGasPrice(chain), data)
+	if tx, err = wallet.SignTxWithPassphrase(accounts.Account{Address: account}, passwd, tx, chain.Config().ChainId); err != nil {
+		return err
+	}
+	if err := t.txPool.AddLocal(tx); err != nil {
+		return err
+	}
+	log.Info("Submitted node bind transaction", "method", method, "account", account, "hash", tx.Hash())
+	return t.waitBind(ctx, chain, tx.Hash())
+}
+
+// bindGasPrice returns the gas price of a bind or unbind transaction: the base
+// fee of the pending block past London, the minimal price of the pool before.
+func (t *Tribe) bindGasPrice(chain consensus.ChainReader) *big.Int {
+	head := chain.CurrentHeader()
+	if !chain.Config().IsLondon(new(big.Int).Add(head.Number, common.Big1)) {
+		return t.txPool.GasPrice()
+	}
+	return misc.CalcBaseFee(chain.Config(), head)
+}
+
+// waitBind waits until the transaction is included and followed by another
+// block, so that the bind info read from the parent state of the head is the
+// one after the transaction.
+func (t *Tribe) waitBind(ctx context.Context, chain consensus.ChainReader, hash common.Hash) error {
+	ctx, cancel := context.WithTimeout(ctx, bindTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(bindPollInterval)
+	defer ticker.Stop()
+	for {
+		if receipt, _, number, _ := core.GetReceipt(t.db, hash); receipt != nil && chain.CurrentHeader().Number.Uint64() > number {
+			if len(receipt.PostState) == 0 && receipt.Status == types.ReceiptStatusFailed {
+				return errBindFailed
+			}
+			return nil
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
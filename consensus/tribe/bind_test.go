@@ -0,0 +1,88 @@
+package tribe
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/core/types"
+	"github.com/MeshBoxTech/mesh-chain/crypto"
+	"github.com/MeshBoxTech/mesh-chain/params"
+)
+
+// headChainReader is a testChainReader with a current header.
+type headChainReader struct {
+	testChainReader
+	head *types.Header
+}
+
+func (r *headChainReader) CurrentHeader() *types.Header { return r.head }
+
+func TestPackBind(t *testing.T) {
+	tribe := newEvidenceTestTribe()
+	tribe.nodeKey, _ = crypto.GenerateKey()
+	node := crypto.PubkeyToAddress(tribe.nodeKey.PublicKey)
+	account := common.HexToAddress("0x01")
+
+	for _, method := range []string{"bind", "unbindBySig"} {
+		data, err := tribe.packBind(method, account)
+		if err != nil {
+			t.Fatalf("%s: %v", method, err)
+		}
+		if id := tribe.abi[ValidatorsContractName].Methods[method].Id(); !bytes.Equal(data[:4], id) {
+			t.Errorf("%s: selector mismatch: have %x, want %x", method, data[:4], id)
+		}
+		args := data[4:]
+		if len(args) != 4*32 {
+			t.Fatalf("%s: arguments length mismatch: have %d, want %d", method, len(args), 4*32)
+		}
+		if have := common.BytesToAddress(args[:32]); have != node {
+			t.Errorf("%s: node mismatch: have %x, want %x", method, have, node)
+		}
+		// The contract recovers the node from the signature over the sender
+		v := args[63]
+		if v != 27 && v != 28 {
+			t.Fatalf("%s: invalid v %d", method, v)
+		}
+		sig := append(append(common.CopyBytes(args[64:96]), args[96:128]...), v-27)
+		pubkey, err := crypto.SigToPub(crypto.Keccak256(account.Bytes()), sig)
+		if err != nil {
+			t.Fatalf("%s: %v", method, err)
+		}
+		if signer := crypto.PubkeyToAddress(*pubkey); signer != node {
+			t.Errorf("%s: signer mismatch: have %x, want %x", method, signer, node)
+		}
+	}
+}
+
+func TestSendBindNoTxPool(t *testing.T) {
+	tribe := newEvidenceTestTribe()
+	if err := tribe.sendBind(context.Background(), nil, "bind", common.Address{}, ""); err != errNoTxPool {
+		t.Errorf("error mismatch: have %v, want %v", err, errNoTxPool)
+	}
+}
+
+func TestBindGasPrice(t *testing.T) {
+	tribe := newEvidenceTestTribe()
+	config := *params.TestChainConfig
+	config.LondonBlock = big.NewInt(0)
+
+	// The bind transactions pay the base fee of the pending block
+	tests := []struct {
+		used  int64
+		price int64
+	}{
+		{4000000, 1000000000},
+		{8000000, 1125000000},
+		{0, 875000000},
+	}
+	for i, tt := range tests {
+		head := &types.Header{Number: big.NewInt(10), GasLimit: big.NewInt(8000000), GasUsed: big.NewInt(tt.used), BaseFee: big.NewInt(1000000000)}
+		chain := &headChainReader{testChainReader{&config}, head}
+		if price := tribe.bindGasPrice(chain); price.Int64() != tt.price {
+			t.Errorf("test %d: price mismatch: have %v, want %d", i, price, tt.price)
+		}
+	}
+}
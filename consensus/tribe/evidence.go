@@ -0,0 +1,353 @@
+package tribe
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/big"
+
+	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/common/math"
+	"github.com/MeshBoxTech/mesh-chain/consensus"
+	"github.com/MeshBoxTech/mesh-chain/consensus/tribe/vmcaller"
+	"github.com/MeshBoxTech/mesh-chain/core/state"
+	"github.com/MeshBoxTech/mesh-chain/core/types"
+	"github.com/MeshBoxTech/mesh-chain/ethdb"
+	"github.com/MeshBoxTech/mesh-chain/log"
+	"github.com/MeshBoxTech/mesh-chain/params"
+	"github.com/MeshBoxTech/mesh-chain/rlp"
+)
+
+const (
+	// evidenceGasLimit is the gas allowance of a double-sign evidence submission.
+	evidenceGasLimit = 1000000
+
+	// evidenceWindow is the number of blocks double-sign evidence is kept and
+	// submitted for, matching the double-sign jail term of the Validators contract.
+	evidenceWindow = 20160
+
+	// maxEvidenceChecks is the maximum number of stored evidence checked against
+	// the Validators contract while assembling a single block.
+	maxEvidenceChecks = 4
+)
+
+// evidencePrefix is the database prefix of the double-sign evidence seen by this
+// node, followed by the height and the validator.
+var evidencePrefix = []byte("tribe-evidence-")
+
+// Evidence proves that a validator sealed two different headers on the same
+// parent.
+type Evidence struct {
+	Validator common.Address `json:"validator"`
+	Number    uint64         `json:"number"`
+	HeaderA   *types.Header  `json:"headerA"`
+	HeaderB   *types.Header  `json:"headerB"`
+}
+
+// sealKey identifies the header a validator sealed on a given parent. Headers
+// sealed at the same height on competing forks are no double sign.
+type sealKey struct {
+	validator common.Address
+	number    uint64
+	parent    common.Hash
+}
+
+// evidenceKey is the database key of the evidence of a double sign. The height
+// comes first, so the evidence is iterated oldest first.
+func evidenceKey(number uint64, validator common.Address) []byte {
+	key := make([]byte, len(evidencePrefix)+8+common.AddressLength)
+	copy(key, evidencePrefix)
+	binary.BigEndian.PutUint64(key[len(evidencePrefix):], number)
+	copy(key[len(evidencePrefix)+8:], validator.Bytes())
+	return key
+}
+
+// loadEvidence retrieves all double-sign evidence stored in the database,
+// ordered by height.
+func loadEvidence(db ethdb.Database) ([]*Evidence, error) {
+	it := db.NewIterator(evidencePrefix, nil)
+	defer it.Release()
+
+	var evidence []*Evidence
+	for it.Next() {
+		ev := new(Evidence)
+		if err := rlp.DecodeBytes(it.Value(), ev); err != nil {
+			return nil, err
+		}
+		evidence = append(evidence, ev)
+	}
+	return evidence, it.Error()
+}
+
+// pruneEvidence deletes the evidence of double signs that fell out of the
+// evidence window of the given height.
+func pruneEvidence(db ethdb.Database, number uint64) error {
+	if number <= evidenceWindow {
+		return nil
+	}
+	it := db.NewIterator(evidencePrefix, nil)
+	defer it.Release()
+
+	batch := db.NewBatch()
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(evidencePrefix)+8+common.AddressLength {
+			continue
+		}
+		if binary.BigEndian.Uint64(key[len(evidencePrefix):]) >= number-evidenceWindow {
+			break
+		}
+		if err := batch.Delete(common.CopyBytes(key)); err != nil {
+			return err
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+// observeSeal remembers the header sealed by the validator and records
+// evidence if it already sealed a different header on the same parent.
+func (t *Tribe) observeSeal(header *types.Header, validator common.Address) {
+	key := sealKey{validator, header.Number.Uint64(), header.ParentHash}
+	prev, ok := t.seals.Get(key)
+	if !ok {
+		t.seals.Add(key, header)
+		return
+	}
+	if prev := prev.(*types.Header); prev.Hash() != header.Hash() {
+		evidence := &Evidence{
+			Validator: validator,
+			Number:    key.number,
+			HeaderA:   prev,
+			HeaderB:   header,
+		}
+		if err := t.addEvidence(evidence); err != nil {
+			log.Error("Failed to store double-sign evidence", "validator", validator, "number", key.number, "err", err)
+		}
+	}
+}
+
+// addEvidence persists the evidence unless the double sign is already known,
+// dropping any evidence that fell out of the window in the meantime.
+func (t *Tribe) addEvidence(evidence *Evidence) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	key := evidenceKey(evidence.Number, evidence.Validator)
+	if known, _ := t.db.Has(key); known {
+		return nil
+	}
+	log.Warn("Validator double signed", "validator", evidence.Validator, "number", evidence.Number,
+		"a", evidence.HeaderA.Hash(), "b", evidence.HeaderB.Hash())
+
+	blob, err := rlp.EncodeToBytes(evidence)
+	if err != nil {
+		return err
+	}
+	if err := t.db.Put(key, blob); err != nil {
+		return err
+	}
+	return pruneEvidence(t.db, evidence.Number)
+}
+
+// verifyEvidence checks that both headers of the evidence extend the same
+// parent and carry a valid seal of the accused validator at the claimed height,
+// and that the validator was authorized to seal on that parent.
+func (t *Tribe) verifyEvidence(chain consensus.ChainReader, evidence *Evidence) error {
+	if evidence.HeaderA == nil || evidence.HeaderB == nil || evidence.Number == 0 {
+		return errInvalidEvidence
+	}
+	if evidence.HeaderA.Hash() == evidence.HeaderB.Hash() {
+		return errInvalidEvidence
+	}
+	if evidence.HeaderA.ParentHash != evidence.HeaderB.ParentHash {
+		return errInvalidEvidence
+	}
+	for _, header := range []*types.Header{evidence.HeaderA, evidence.HeaderB} {
+		if header.Number == nil || header.Number.Uint64() != evidence.Number {
+			return errInvalidEvidence
+		}
+		if header.Coinbase != evidence.Validator {
+			return errInvalidEvidence
+		}
+		signer, err := ecrecover(header, t)
+		if err != nil || signer != evidence.Validator {
+			return errInvalidEvidence
+		}
+	}
+	snap, err := t.snapshot(chain, evidence.Number-1, evidence.HeaderA.ParentHash, nil)
+	if err != nil {
+		return err
+	}
+	if _, ok := snap.Validators[evidence.Validator]; !ok {
+		return errInvalidEvidence
+	}
+	return nil
+}
+
+// packEvidence builds the Validators contract call submitting the evidence.
+func (t *Tribe) packEvidence(evidence *Evidence) ([]byte, error) {
+	headerA, err := rlp.EncodeToBytes(evidence.HeaderA)
+	if err != nil {
+		return nil, err
+	}
+	headerB, err := rlp.EncodeToBytes(evidence.HeaderB)
+	if err != nil {
+		return nil, err
+	}
+	return t.abi[ValidatorsContractName].Pack("punishDoubleSign", evidence.Validator, new(big.Int).SetUint64(evidence.Number), headerA, headerB)
+}
+
+// unpackEvidence decodes the evidence from a Validators contract call, or
+// returns nil if the call isn't a double-sign evidence submission.
+func (t *Tribe) unpackEvidence(data []byte) (*Evidence, error) {
+	method := t.abi[ValidatorsContractName].Methods["punishDoubleSign"]
+	if len(data) < 4 || !bytes.Equal(data[:4], method.Id()) {
+		return nil, nil
+	}
+	var args struct {
+		MinerAddress common.Address
+		BlockNumber  *big.Int
+		HeaderA      []byte
+		HeaderB      []byte
+	}
+	if err := method.Inputs.Unpack(&args, data[4:]); err != nil {
+		return nil, errInvalidEvidence
+	}
+	if !args.BlockNumber.IsUint64() {
+		return nil, errInvalidEvidence
+	}
+	evidence := &Evidence{
+		Validator: args.MinerAddress,
+		Number:    args.BlockNumber.Uint64(),
+		HeaderA:   new(types.Header),
+		HeaderB:   new(types.Header),
+	}
+	if err := rlp.DecodeBytes(args.HeaderA, evidence.HeaderA); err != nil {
+		return nil, errInvalidEvidence
+	}
+	if err := rlp.DecodeBytes(args.HeaderB, evidence.HeaderB); err != nil {
+		return nil, errInvalidEvidence
+	}
+	return evidence, nil
+}
+
+// verifyEvidenceTxs makes sure every double-sign evidence the block producer
+// submitted to the Validators contract is genuine. The contract only accepts
+// submissions from the miner and can't check tribe seals itself, so a block
+// carrying forged evidence is invalid.
+func (t *Tribe) verifyEvidenceTxs(chain consensus.ChainReader, header *types.Header, txs []*types.Transaction) error {
+	signer := types.MakeSigner(chain.Config(), header.Number)
+	for _, tx := range txs {
+		if tx.To() == nil || *tx.To() != params.ValidatorsContractAddr {
+			continue
+		}
+		evidence, err := t.unpackEvidence(tx.Data())
+		if evidence == nil && err == nil {
+			continue
+		}
+		// Submissions by anybody but the miner are reverted by the contract
+		if from, err := types.Sender(signer, tx); err != nil || from != header.Coinbase {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if evidence.Number >= header.Number.Uint64() {
+			return errInvalidEvidence
+		}
+		if err := t.verifyEvidence(chain, evidence); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isDoubleSignPunished checks whether the Validators contract already jailed
+// the validator for double signing at the given height.
+func (t *Tribe) isDoubleSignPunished(chain consensus.ChainReader, header *types.Header, state *state.StateDB, evidence *Evidence) (bool, error) {
+	method := "doubleSignPunished"
+	data, err := t.abi[ValidatorsContractName].Pack(method, evidence.Validator, new(big.Int).SetUint64(evidence.Number))
+	if err != nil {
+		return false, err
+	}
+	msg := vmcaller.NewLegacyMessage(header.Coinbase, &params.ValidatorsContractAddr, 0, new(big.Int), new(big.Int).SetUint64(math.MaxUint64), new(big.Int), data, false)
+	result, err := vmcaller.ExecuteMsg(msg, state.Copy(), header, newChainContext(chain, t), chain.Config())
+	if err != nil {
+		return false, err
+	}
+	var punished bool
+	if err := t.abi[ValidatorsContractName].Unpack(&punished, method, result); err != nil {
+		return false, err
+	}
+	return punished, nil
+}
+
+// EvidenceTransactions returns the transactions the local validator should
+// include in the block being built on header, submitting the known double-sign
+// evidence that hasn't been punished yet. Nothing is submitted before the
+// evidence fork, and at most maxEvidenceChecks evidence are checked against the
+// contract per block, oldest first. The transactions are signed with the node
+// key from its nonce in state, so they must be committed after any transaction
+// of the node key taken from the pool.
+func (t *Tribe) EvidenceTransactions(chain consensus.ChainReader, header *types.Header, state *state.StateDB) (types.Transactions, error) {
+	if !t.config.IsEvidence(header.Number) {
+		return nil, nil
+	}
+	number := header.Number.Uint64()
+	if err := pruneEvidence(t.db, number); err != nil {
+		return nil, err
+	}
+	known, err := loadEvidence(t.db)
+	if err != nil || len(known) == 0 {
+		return nil, err
+	}
+	var (
+		txs    types.Transactions
+		signer = types.MakeSigner(chain.Config(), header.Number)
+		nonce  = state.GetNonce(header.Coinbase)
+		price  = new(big.Int)
+		checks int
+	)
+	// Past London the submissions must cover the base fee to be executable
+	if header.BaseFee != nil {
+		price.Set(header.BaseFee)
+	}
+	for _, evidence := range known {
+		if evidence.Number >= number || checks >= maxEvidenceChecks {
+			break
+		}
+		key := sealKey{evidence.Validator, evidence.Number, evidence.HeaderA.ParentHash}
+		if t.punished.Contains(key) {
+			continue
+		}
+		checks++
+
+		// Evidence the chain can't back, e.g. stored before the parent checks,
+		// would make the block invalid
+		if err := t.verifyEvidence(chain, evidence); err != nil {
+			log.Debug("Skipping unverifiable double-sign evidence", "validator", evidence.Validator, "number", evidence.Number, "err", err)
+			continue
+		}
+		punished, err := t.isDoubleSignPunished(chain, header, state, evidence)
+		if err != nil {
+			return nil, err
+		}
+		if punished {
+			t.punished.Add(key, struct{}{})
+			continue
+		}
+		data, err := t.packEvidence(evidence)
+		if err != nil {
+			return nil, err
+		}
+		tx := types.NewTransaction(nonce, params.ValidatorsContractAddr, new(big.Int), new(big.Int).SetUint64(evidenceGasLimit), price, data)
+		if tx, err = types.SignTx(tx, signer, t.getNodekey()); err != nil {
+			return nil, err
+		}
+		txs = append(txs, tx)
+		nonce++
+	}
+	return txs, nil
+}
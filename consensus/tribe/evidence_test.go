@@ -0,0 +1,337 @@
+package tribe
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/core"
+	"github.com/MeshBoxTech/mesh-chain/core/state"
+	"github.com/MeshBoxTech/mesh-chain/core/types"
+	"github.com/MeshBoxTech/mesh-chain/core/vm"
+	"github.com/MeshBoxTech/mesh-chain/crypto"
+	"github.com/MeshBoxTech/mesh-chain/ethdb"
+	"github.com/MeshBoxTech/mesh-chain/params"
+)
+
+// testChainReader is a consensus.ChainReader with nothing but a config.
+type testChainReader struct {
+	config *params.ChainConfig
+}
+
+func (r *testChainReader) Config() *params.ChainConfig                 { return r.config }
+func (r *testChainReader) CurrentHeader() *types.Header                { return nil }
+func (r *testChainReader) GetHeader(common.Hash, uint64) *types.Header { return nil }
+func (r *testChainReader) GetHeaderByNumber(uint64) *types.Header      { return nil }
+func (r *testChainReader) GetHeaderByHash(common.Hash) *types.Header   { return nil }
+func (r *testChainReader) GetBlock(common.Hash, uint64) *types.Block   { return nil }
+
+// sealedHeader creates a header at the given height sealed by key. The time
+// distinguishes otherwise identical headers.
+func sealedHeader(key *ecdsa.PrivateKey, number, time int64) *types.Header {
+	return sealedChild(key, common.Hash{}, number, time)
+}
+
+// sealedChild creates a header on the given parent sealed by key.
+func sealedChild(key *ecdsa.PrivateKey, parent common.Hash, number, time int64) *types.Header {
+	header := &types.Header{
+		ParentHash: parent,
+		Coinbase:   crypto.PubkeyToAddress(key.PublicKey),
+		Number:     big.NewInt(number),
+		Difficulty: new(big.Int).Set(diffNoTurn),
+		GasLimit:   new(big.Int),
+		GasUsed:    new(big.Int),
+		Time:       big.NewInt(time),
+		Extra:      make([]byte, extraVanity+extraSeal),
+	}
+	sig, _ := crypto.Sign(sigHash(header).Bytes(), key)
+	copy(header.Extra[len(header.Extra)-extraSeal:], sig)
+	return header
+}
+
+func newEvidenceTestTribe() *Tribe {
+	db, _ := ethdb.NewMemDatabase()
+	tribe := New(nil, &params.TribeConfig{Period: 30, Epoch: 21}, db)
+	tribe.abi = GetInteractiveABI()
+	return tribe
+}
+
+// authorize makes the validators the ones allowed to seal on the given parent.
+func authorize(tribe *Tribe, parent common.Hash, number uint64, validators ...common.Address) {
+	tribe.recents.Add(parent, newSnapshot(tribe.config, number, parent, validators))
+}
+
+func TestObserveSealDoubleSign(t *testing.T) {
+	tribe := newEvidenceTestTribe()
+	key, _ := crypto.GenerateKey()
+	validator := crypto.PubkeyToAddress(key.PublicKey)
+
+	a, b, c := sealedHeader(key, 10, 100), sealedHeader(key, 10, 101), sealedHeader(key, 10, 102)
+	authorize(tribe, common.Hash{}, 9, validator)
+	chain := &testChainReader{config: params.TestChainConfig}
+
+	// Seeing the same header twice is no evidence
+	tribe.observeSeal(a, validator)
+	tribe.observeSeal(a, validator)
+	if evidence, _ := loadEvidence(tribe.db); len(evidence) != 0 {
+		t.Fatalf("evidence recorded for a single header: %d", len(evidence))
+	}
+	// A second header on the same parent is
+	tribe.observeSeal(b, validator)
+	evidence, err := loadEvidence(tribe.db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evidence) != 1 {
+		t.Fatalf("evidence count mismatch: have %d, want 1", len(evidence))
+	}
+	if ev := evidence[0]; ev.Validator != validator || ev.Number != 10 || ev.HeaderA.Hash() != a.Hash() || ev.HeaderB.Hash() != b.Hash() {
+		t.Fatalf("evidence mismatch: %+v", ev)
+	}
+	if err := tribe.verifyEvidence(chain, evidence[0]); err != nil {
+		t.Fatalf("recorded evidence doesn't verify: %v", err)
+	}
+	// Further conflicting headers at the same height are the same offence
+	tribe.observeSeal(c, validator)
+	if evidence, _ := loadEvidence(tribe.db); len(evidence) != 1 {
+		t.Fatalf("duplicate evidence recorded: %d", len(evidence))
+	}
+}
+
+// Tests that a validator sealing at the same height on competing forks, as out
+// of turn validators do, isn't taken for a double signer.
+func TestObserveSealForks(t *testing.T) {
+	tribe := newEvidenceTestTribe()
+	key, _ := crypto.GenerateKey()
+	validator := crypto.PubkeyToAddress(key.PublicKey)
+
+	tribe.observeSeal(sealedChild(key, common.Hash{0x01}, 10, 100), validator)
+	tribe.observeSeal(sealedChild(key, common.Hash{0x02}, 10, 100), validator)
+	tribe.observeSeal(sealedChild(key, common.Hash{0x03}, 10, 101), validator)
+	if evidence, _ := loadEvidence(tribe.db); len(evidence) != 0 {
+		t.Fatalf("evidence recorded for siblings on different parents: %d", len(evidence))
+	}
+	// Sealing twice on one of the forks still is a double sign
+	tribe.observeSeal(sealedChild(key, common.Hash{0x02}, 10, 101), validator)
+	evidence, _ := loadEvidence(tribe.db)
+	if len(evidence) != 1 || evidence[0].HeaderA.ParentHash != (common.Hash{0x02}) || evidence[0].HeaderB.ParentHash != (common.Hash{0x02}) {
+		t.Fatalf("evidence mismatch: %v", evidence)
+	}
+}
+
+func TestPruneEvidence(t *testing.T) {
+	tribe := newEvidenceTestTribe()
+	key, _ := crypto.GenerateKey()
+	validator := crypto.PubkeyToAddress(key.PublicKey)
+
+	doubleSign := func(number int64) {
+		tribe.observeSeal(sealedHeader(key, number, 100), validator)
+		tribe.observeSeal(sealedHeader(key, number, 101), validator)
+	}
+	doubleSign(20)
+	doubleSign(10)
+	if evidence, _ := loadEvidence(tribe.db); len(evidence) != 2 || evidence[0].Number != 10 || evidence[1].Number != 20 {
+		t.Fatalf("evidence not stored oldest first: %v", evidence)
+	}
+	// Evidence falling out of the window of a newer double sign is dropped
+	doubleSign(evidenceWindow + 15)
+
+	evidence, err := loadEvidence(tribe.db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evidence) != 2 || evidence[0].Number != 20 || evidence[1].Number != evidenceWindow+15 {
+		t.Fatalf("evidence mismatch after pruning: %v", evidence)
+	}
+	if err := pruneEvidence(tribe.db, evidenceWindow+100); err != nil {
+		t.Fatal(err)
+	}
+	if evidence, _ := loadEvidence(tribe.db); len(evidence) != 1 || evidence[0].Number != evidenceWindow+15 {
+		t.Fatalf("evidence mismatch after pruning: %v", evidence)
+	}
+}
+
+func TestVerifySealObservesValidSeals(t *testing.T) {
+	tribe := newEvidenceTestTribe()
+	key, _ := crypto.GenerateKey()
+	validator := crypto.PubkeyToAddress(key.PublicKey)
+	tribe.recents.Add(common.Hash{}, newSnapshot(tribe.config, 5, common.Hash{}, []common.Address{validator}))
+
+	// The sole validator is always in turn, a header claiming otherwise is
+	// rejected without being remembered
+	header := sealedHeader(key, 6, 100)
+	if err := tribe.verifySeal(nil, header, nil); err != errInvalidDifficulty {
+		t.Fatalf("error mismatch: have %v, want %v", err, errInvalidDifficulty)
+	}
+	if tribe.seals.Len() != 0 {
+		t.Fatalf("invalid seal observed")
+	}
+	header.Difficulty = new(big.Int).Set(diffInTurn)
+	sig, _ := crypto.Sign(sigHash(header).Bytes(), key)
+	copy(header.Extra[len(header.Extra)-extraSeal:], sig)
+	if err := tribe.verifySeal(nil, header, nil); err != nil {
+		t.Fatalf("failed to verify seal: %v", err)
+	}
+	if !tribe.seals.Contains(sealKey{validator, 6, common.Hash{}}) {
+		t.Fatalf("valid seal not observed")
+	}
+}
+
+func TestVerifyEvidence(t *testing.T) {
+	tribe := newEvidenceTestTribe()
+	key, _ := crypto.GenerateKey()
+	other, _ := crypto.GenerateKey()
+	validator := crypto.PubkeyToAddress(key.PublicKey)
+
+	forged := sealedHeader(other, 10, 101)
+	forged.Coinbase = validator
+
+	chain := &testChainReader{config: params.TestChainConfig}
+	authorize(tribe, common.Hash{}, 9, validator)
+	authorize(tribe, common.Hash{0x01}, 9, crypto.PubkeyToAddress(other.PublicKey))
+
+	tests := []struct {
+		evidence *Evidence
+		err      error
+	}{
+		{&Evidence{validator, 10, sealedHeader(key, 10, 100), sealedHeader(key, 10, 101)}, nil},
+		{&Evidence{validator, 10, sealedHeader(key, 10, 100), sealedHeader(key, 10, 100)}, errInvalidEvidence},
+		{&Evidence{validator, 10, sealedHeader(key, 10, 100), sealedHeader(key, 11, 101)}, errInvalidEvidence},
+		{&Evidence{validator, 10, sealedHeader(key, 10, 100), sealedHeader(other, 10, 101)}, errInvalidEvidence},
+		{&Evidence{validator, 10, sealedHeader(key, 10, 100), forged}, errInvalidEvidence},
+		{&Evidence{validator, 10, sealedHeader(key, 10, 100), nil}, errInvalidEvidence},
+		// Siblings on different parents are no double sign
+		{&Evidence{validator, 10, sealedHeader(key, 10, 100), sealedChild(key, common.Hash{0x01}, 10, 101)}, errInvalidEvidence},
+		// Nor are headers of a validator not allowed to seal on their parent
+		{&Evidence{validator, 10, sealedChild(key, common.Hash{0x01}, 10, 100), sealedChild(key, common.Hash{0x01}, 10, 101)}, errInvalidEvidence},
+	}
+	for i, tt := range tests {
+		if err := tribe.verifyEvidence(chain, tt.evidence); err != tt.err {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+}
+
+func TestVerifyEvidenceTxs(t *testing.T) {
+	tribe := newEvidenceTestTribe()
+	key, _ := crypto.GenerateKey()
+	other, _ := crypto.GenerateKey()
+	miner, _ := crypto.GenerateKey()
+	validator := crypto.PubkeyToAddress(key.PublicKey)
+
+	chain := &testChainReader{config: params.TestChainConfig}
+	header := &types.Header{Coinbase: crypto.PubkeyToAddress(miner.PublicKey), Number: big.NewInt(20)}
+	authorize(tribe, common.Hash{}, 9, validator)
+	signer := types.MakeSigner(chain.Config(), header.Number)
+
+	submit := func(evidence *Evidence, from *ecdsa.PrivateKey) *types.Transaction {
+		data, err := tribe.packEvidence(evidence)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tx := types.NewTransaction(0, params.ValidatorsContractAddr, new(big.Int), big.NewInt(evidenceGasLimit), new(big.Int), data)
+		tx, _ = types.SignTx(tx, signer, from)
+		return tx
+	}
+	genuine := &Evidence{validator, 10, sealedHeader(key, 10, 100), sealedHeader(key, 10, 101)}
+	forged := &Evidence{validator, 10, sealedHeader(key, 10, 100), sealedHeader(other, 10, 101)}
+	future := &Evidence{validator, 20, sealedHeader(key, 20, 100), sealedHeader(key, 20, 101)}
+
+	tests := []struct {
+		txs []*types.Transaction
+		err error
+	}{
+		{[]*types.Transaction{submit(genuine, miner)}, nil},
+		{[]*types.Transaction{submit(genuine, miner), submit(forged, miner)}, errInvalidEvidence},
+		{[]*types.Transaction{submit(future, miner)}, errInvalidEvidence},
+		// Anybody but the miner is rejected by the contract, not by consensus
+		{[]*types.Transaction{submit(forged, other)}, nil},
+	}
+	for i, tt := range tests {
+		if err := tribe.verifyEvidenceTxs(chain, header, tt.txs); err != tt.err {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+	// The submitted evidence must survive the contract call encoding
+	decoded, err := tribe.unpackEvidence(submit(genuine, miner).Data())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Validator != validator || decoded.Number != 10 || decoded.HeaderA.Hash() != genuine.HeaderA.Hash() || decoded.HeaderB.Hash() != genuine.HeaderB.Hash() {
+		t.Fatalf("evidence mismatch after encoding: %+v", decoded)
+	}
+}
+
+func TestEvidenceTransactionsBeforeFork(t *testing.T) {
+	tribe := newEvidenceTestTribe()
+	tribe.config.EvidenceBlock = big.NewInt(30)
+	key, _ := crypto.GenerateKey()
+
+	tribe.observeSeal(sealedHeader(key, 10, 100), crypto.PubkeyToAddress(key.PublicKey))
+	tribe.observeSeal(sealedHeader(key, 10, 101), crypto.PubkeyToAddress(key.PublicKey))
+
+	chain := &testChainReader{config: params.TestChainConfig}
+	header := &types.Header{Number: big.NewInt(29)}
+	txs, err := tribe.EvidenceTransactions(chain, header, nil)
+	if err != nil || len(txs) != 0 {
+		t.Fatalf("evidence submitted before the fork: %d txs, err %v", len(txs), err)
+	}
+}
+
+func TestEvidenceTransactionsAfterLondon(t *testing.T) {
+	tribe := newEvidenceTestTribe()
+	tribe.config.EvidenceBlock = big.NewInt(0)
+	tribe.nodeKey, _ = crypto.GenerateKey()
+	miner := crypto.PubkeyToAddress(tribe.nodeKey.PublicKey)
+	key, _ := crypto.GenerateKey()
+
+	tribe.observeSeal(sealedHeader(key, 10, 100), crypto.PubkeyToAddress(key.PublicKey))
+	tribe.observeSeal(sealedHeader(key, 10, 101), crypto.PubkeyToAddress(key.PublicKey))
+	authorize(tribe, common.Hash{}, 9, crypto.PubkeyToAddress(key.PublicKey))
+
+	config := *params.TestChainConfig
+	config.LondonBlock = big.NewInt(0)
+	chain := &testChainReader{config: &config}
+
+	// The Validators contract accepts any call, reporting nothing as punished
+	db, _ := ethdb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	statedb.SetCode(params.ValidatorsContractAddr, []byte{
+		byte(vm.PUSH1), 0x20, byte(vm.PUSH1), 0x00, byte(vm.RETURN),
+	})
+	statedb.AddBalance(miner, new(big.Int).Mul(big.NewInt(evidenceGasLimit), big.NewInt(params.Shannon)))
+
+	header := &types.Header{
+		Coinbase:   miner,
+		Number:     big.NewInt(20),
+		Difficulty: new(big.Int).Set(diffInTurn),
+		GasLimit:   big.NewInt(8000000),
+		GasUsed:    new(big.Int),
+		Time:       big.NewInt(1000),
+		BaseFee:    big.NewInt(params.Shannon),
+	}
+	txs, err := tribe.EvidenceTransactions(chain, header, statedb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txs) != 1 {
+		t.Fatalf("evidence transaction count mismatch: have %d, want 1", len(txs))
+	}
+	if err := tribe.verifyEvidenceTxs(chain, header, txs); err != nil {
+		t.Fatalf("evidence rejected: %v", err)
+	}
+	// The submission must be executable in the post-London block
+	msg, err := txs[0].AsMessage(types.MakeSigner(&config, header.Number), header.BaseFee)
+	if err != nil {
+		t.Fatal(err)
+	}
+	context := core.NewEVMContext(msg, header, newChainContext(chain, tribe), &header.Coinbase)
+	evm := vm.NewEVM(context, statedb, &config, vm.Config{})
+	if _, _, failed, err := core.ApplyMessage(evm, msg, new(core.GasPool).AddGas(header.GasLimit), header.Number); err != nil || failed {
+		t.Fatalf("evidence transaction not executed: failed %v, err %v", failed, err)
+	}
+	if nonce := statedb.GetNonce(miner); nonce != 1 {
+		t.Errorf("miner nonce mismatch: have %d, want 1", nonce)
+	}
+}
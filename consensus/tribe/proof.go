@@ -0,0 +1,85 @@
+package tribe
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/common/hexutil"
+	"github.com/MeshBoxTech/mesh-chain/core/state"
+	"github.com/MeshBoxTech/mesh-chain/params"
+)
+
+// errUnknownToken is returned if a balance proof is requested for a token other
+// than SMT or MESH.
+var errUnknownToken = errors.New("unknown token, want SMT or MESH")
+
+// BalanceProof is the merkle proof of an SMT or MESH balance. The balances live
+// in the storage of the token contracts, so the balance slot is proven against
+// the storage root of the contract, which is proven against the state root.
+type BalanceProof struct {
+	Address      common.Address `json:"address"`
+	Token        string         `json:"token"`
+	Contract     common.Address `json:"contract"` // Token contract holding the balance
+	Key          common.Hash    `json:"key"`      // Storage slot of the balance in the contract
+	Balance      *hexutil.Big   `json:"balance"`
+	StateRoot    common.Hash    `json:"stateRoot"`    // State root the account proof is against
+	StorageHash  common.Hash    `json:"storageHash"`  // Storage root of the contract the storage proof is against
+	AccountProof []string       `json:"accountProof"` // Proof of the contract account
+	StorageProof []string       `json:"storageProof"` // Proof of the balance slot
+}
+
+// balanceSlot returns the token contract and the storage slot holding the
+// balance of addr.
+func balanceSlot(token string, addr common.Address) (common.Address, common.Hash, error) {
+	switch strings.ToUpper(token) {
+	case "SMT":
+		return params.SmartMeshContractAddress, state.GetOVMBalanceKey(addr), nil
+	case "MESH":
+		return params.MeshContractAddress, GetMESHBalanceKey(addr), nil
+	}
+	return common.Address{}, common.Hash{}, errUnknownToken
+}
+
+// balanceProof proves the token balance of addr in the state at root.
+func (t *Tribe) balanceProof(root common.Hash, addr common.Address, token string) (*BalanceProof, error) {
+	contract, key, err := balanceSlot(token, addr)
+	if err != nil {
+		return nil, err
+	}
+	statedb, err := t.stateFn(root)
+	if err != nil {
+		return nil, err
+	}
+	accountProof, err := statedb.GetProof(contract)
+	if err != nil {
+		return nil, err
+	}
+	storageProof, err := statedb.GetStorageProof(contract, key)
+	if err != nil {
+		return nil, err
+	}
+	proof := &BalanceProof{
+		Address:      addr,
+		Token:        strings.ToUpper(token),
+		Contract:     contract,
+		Key:          key,
+		Balance:      (*hexutil.Big)(statedb.GetState(contract, key).Big()),
+		StateRoot:    root,
+		AccountProof: toHexSlice(accountProof),
+		StorageProof: toHexSlice(storageProof),
+	}
+	if storageTrie := statedb.StorageTrie(contract); storageTrie != nil {
+		proof.StorageHash = storageTrie.Hash()
+	}
+	return proof, statedb.Error()
+}
+
+// toHexSlice hex encodes the nodes of a proof.
+func toHexSlice(b [][]byte) []string {
+	r := make([]string, len(b))
+	for i := range b {
+		r[i] = hexutil.Encode(b[i])
+	}
+	return r
+}
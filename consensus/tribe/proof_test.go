@@ -0,0 +1,76 @@
+package tribe
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/common/hexutil"
+	"github.com/MeshBoxTech/mesh-chain/core/state"
+	"github.com/MeshBoxTech/mesh-chain/crypto"
+	"github.com/MeshBoxTech/mesh-chain/ethdb"
+	"github.com/MeshBoxTech/mesh-chain/params"
+	"github.com/MeshBoxTech/mesh-chain/rlp"
+	"github.com/MeshBoxTech/mesh-chain/trie"
+)
+
+func TestBalanceProof(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	sdb := state.NewDatabase(db)
+	addr := common.HexToAddress("0x0123")
+
+	statedb, _ := state.New(common.Hash{}, sdb)
+	statedb.SetState(params.SmartMeshContractAddress, state.GetOVMBalanceKey(addr), common.BigToHash(big.NewInt(100)))
+	statedb.SetState(params.MeshContractAddress, GetMESHBalanceKey(addr), common.BigToHash(big.NewInt(200)))
+	root, _ := statedb.CommitTo(sdb.TrieDB(), false)
+
+	tribe := newEvidenceTestTribe()
+	tribe.stateFn = func(root common.Hash) (*state.StateDB, error) { return state.New(root, sdb) }
+
+	// verify checks a hex encoded proof against the root, returning the proven value
+	verify := func(root common.Hash, key []byte, proof []string) []byte {
+		proofDb, _ := ethdb.NewMemDatabase()
+		for _, node := range proof {
+			blob := hexutil.MustDecode(node)
+			proofDb.Put(crypto.Keccak256(blob), blob)
+		}
+		value, err, _ := trie.VerifyProof(root, crypto.Keccak256(key), proofDb)
+		if err != nil {
+			t.Fatalf("failed to verify proof: %v", err)
+		}
+		return value
+	}
+	for _, tt := range []struct {
+		token    string
+		contract common.Address
+		balance  int64
+	}{
+		{"smt", params.SmartMeshContractAddress, 100},
+		{"MESH", params.MeshContractAddress, 200},
+	} {
+		proof, err := tribe.balanceProof(root, addr, tt.token)
+		if err != nil {
+			t.Fatalf("%s: failed to prove balance: %v", tt.token, err)
+		}
+		if proof.Contract != tt.contract || proof.Balance.ToInt().Int64() != tt.balance {
+			t.Errorf("%s: proof mismatch: have %x/%v, want %x/%d", tt.token, proof.Contract, proof.Balance, tt.contract, tt.balance)
+		}
+		var account state.Account
+		if err := rlp.DecodeBytes(verify(root, tt.contract[:], proof.AccountProof), &account); err != nil {
+			t.Fatalf("%s: failed to decode proven account: %v", tt.token, err)
+		}
+		if account.Root != proof.StorageHash {
+			t.Errorf("%s: storage root mismatch: have %x, want %x", tt.token, account.Root, proof.StorageHash)
+		}
+		var value []byte
+		if err := rlp.DecodeBytes(verify(proof.StorageHash, proof.Key[:], proof.StorageProof), &value); err != nil {
+			t.Fatalf("%s: failed to decode proven balance: %v", tt.token, err)
+		}
+		if new(big.Int).SetBytes(value).Int64() != tt.balance {
+			t.Errorf("%s: proven balance mismatch: have %x, want %d", tt.token, value, tt.balance)
+		}
+	}
+	if _, err := tribe.balanceProof(root, addr, "ETH"); err != errUnknownToken {
+		t.Errorf("unknown token error mismatch: have %v, want %v", err, errUnknownToken)
+	}
+}
@@ -0,0 +1,147 @@
+package tribe
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/consensus"
+	"github.com/MeshBoxTech/mesh-chain/core/types"
+	"github.com/MeshBoxTech/mesh-chain/ethdb"
+	"github.com/MeshBoxTech/mesh-chain/rlp"
+)
+
+// maxRewardsRange is the maximum number of blocks scanned by a single rewards
+// by address query.
+const maxRewardsRange = 10000
+
+// rewardsPrefix is the database prefix of the block reward records, followed by
+// the signature hash of the block.
+var rewardsPrefix = []byte("tribe-rewards-")
+
+// errRewardsRangeTooLarge is returned if a rewards query spans too many blocks.
+var errRewardsRangeTooLarge = errors.New("block range too large")
+
+// BlockRewards records the MESH minted by a block. The balances are credited in
+// the storage of the MESH contract without any logs, so this is the only trace
+// of why a balance increased.
+type BlockRewards struct {
+	Number     uint64         `json:"number"`
+	Validator  common.Address `json:"validator"`  // Validator that sealed the block
+	Recipient  common.Address `json:"recipient"`  // Account the validator reward was paid to, after bind resolution
+	Reward     *big.Int       `json:"reward"`     // Validator reward of the block
+	PomReward  *big.Int       `json:"pomReward"`  // Reward distributed to the POM nodes, zero but at epoch ends
+	HalvingEra uint64         `json:"halvingEra"` // Number of times the rewards have been halved
+}
+
+// blockRewards computes the rewards of the block. The recipient is the account
+// the validator is bound to in the parent state, or the validator itself.
+func (t *Tribe) blockRewards(chain consensus.ChainReader, header *types.Header) *BlockRewards {
+	fork := t.forkAt(header.Number)
+	number := header.Number.Uint64()
+	era := t.halvingEra(number)
+
+	rewards := &BlockRewards{
+		Number:     number,
+		Validator:  header.Coinbase,
+		Recipient:  header.Coinbase,
+		Reward:     new(big.Int).Rsh(fork.Reward, uint(era)),
+		PomReward:  new(big.Int),
+		HalvingEra: era,
+	}
+	//get miner bind wallet for receive rewards
+	if bindInfo, err := t.getBindInfo(chain, header, header.Coinbase); err == nil {
+		rewards.Recipient = bindInfo.From
+	}
+	if number%fork.Epoch == 0 {
+		rewards.PomReward.Rsh(MeshRewardForPom, uint(era))
+	}
+	return rewards
+}
+
+// halvingEra returns the number of times the rewards have been halved by the
+// given block. A fork changing the halving interval keeps the eras completed
+// before it and counts the new interval from its activation block.
+func (t *Tribe) halvingEra(number uint64) uint64 {
+	var (
+		era      uint64
+		start    uint64
+		interval = BlockRewardReducedInterval
+	)
+	for _, f := range t.config.Forks {
+		if f.Block == nil || f.Block.Uint64() > number {
+			break
+		}
+		if f.HalvingInterval == 0 || f.HalvingInterval == interval {
+			continue
+		}
+		era += (f.Block.Uint64() - start) / interval
+		start, interval = f.Block.Uint64(), f.HalvingInterval
+	}
+	return era + (number-start)/interval
+}
+
+// rewardsKey is the database key of the rewards of a block.
+func rewardsKey(hash common.Hash) []byte {
+	return append(append([]byte{}, rewardsPrefix...), hash.Bytes()...)
+}
+
+// WriteBlockRecords implements consensus.Recorder, storing the rewards of a
+// block written to the chain. The rewards computed when the block was finalized
+// are reused if still cached, otherwise they are computed again from the parent
+// state.
+func (t *Tribe) WriteBlockRecords(chain consensus.ChainReader, db ethdb.Putter, block *types.Block) error {
+	header := block.Header()
+	if header.Number.Sign() == 0 {
+		return nil
+	}
+	var rewards *BlockRewards
+	if cached, ok := t.rewards.Get(sigHash(header)); ok {
+		rewards = cached.(*BlockRewards)
+	} else {
+		rewards = t.blockRewards(chain, header)
+	}
+	blob, err := rlp.EncodeToBytes(rewards)
+	if err != nil {
+		return err
+	}
+	return db.Put(rewardsKey(block.Hash()), blob)
+}
+
+// StoredBlockRewards returns the rewards recorded when the block was written to
+// the chain, or nil if there are none.
+func (t *Tribe) StoredBlockRewards(header *types.Header) *BlockRewards {
+	blob, err := t.db.Get(rewardsKey(header.Hash()))
+	if err != nil {
+		return nil
+	}
+	rewards := new(BlockRewards)
+	if err := rlp.DecodeBytes(blob, rewards); err != nil {
+		return nil
+	}
+	return rewards
+}
+
+// BlockRecords implements consensus.Recorder, returning the stored rewards of
+// the block.
+func (t *Tribe) BlockRecords(header *types.Header) interface{} {
+	if rewards := t.StoredBlockRewards(header); rewards != nil {
+		return rewards
+	}
+	return nil
+}
+
+// getBlockRewards returns the recorded rewards of the block, recomputing them
+// from the parent state for blocks written before the records were kept.
+func (t *Tribe) getBlockRewards(chain consensus.ChainReader, header *types.Header) (*BlockRewards, error) {
+	if rewards := t.StoredBlockRewards(header); rewards != nil {
+		return rewards, nil
+	}
+	if header.Number.Sign() == 0 {
+		return nil, errUnknownBlock
+	}
+	if chain.GetHeader(header.ParentHash, header.Number.Uint64()-1) == nil {
+		return nil, consensus.ErrUnknownAncestor
+	}
+	return t.blockRewards(chain, header), nil
+}
@@ -0,0 +1,131 @@
+package tribe
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/MeshBoxTech/mesh-chain/consensus"
+	"github.com/MeshBoxTech/mesh-chain/core/types"
+	"github.com/MeshBoxTech/mesh-chain/crypto"
+	"github.com/MeshBoxTech/mesh-chain/params"
+)
+
+func TestBlockRewardsHalving(t *testing.T) {
+	tribe := newEvidenceTestTribe()
+	tribe.config.Forks = []*params.TribeFork{{Block: big.NewInt(0), Reward: big.NewInt(1000), HalvingInterval: 10}}
+	chain := &testChainReader{config: params.TestChainConfig}
+	key, _ := crypto.GenerateKey()
+
+	tests := []struct {
+		number int64
+		reward int64
+		pom    bool
+		era    uint64
+	}{
+		{1, 1000, false, 0},
+		{9, 1000, false, 0},
+		{10, 500, false, 1},
+		{21, 250, true, 2},
+		{25, 250, false, 2},
+		{42, 62, true, 4},
+	}
+	for _, tt := range tests {
+		header := sealedHeader(key, tt.number, 100)
+		rewards := tribe.blockRewards(chain, header)
+		if rewards.Reward.Int64() != tt.reward {
+			t.Errorf("block %d: reward mismatch: have %v, want %d", tt.number, rewards.Reward, tt.reward)
+		}
+		if rewards.HalvingEra != tt.era {
+			t.Errorf("block %d: era mismatch: have %d, want %d", tt.number, rewards.HalvingEra, tt.era)
+		}
+		// Without a parent state the validator is paid itself
+		if rewards.Validator != header.Coinbase || rewards.Recipient != header.Coinbase {
+			t.Errorf("block %d: recipient mismatch: have %x, want %x", tt.number, rewards.Recipient, header.Coinbase)
+		}
+		want := new(big.Int)
+		if tt.pom {
+			want.Rsh(MeshRewardForPom, uint(tt.era))
+		}
+		if rewards.PomReward.Cmp(want) != 0 {
+			t.Errorf("block %d: pom reward mismatch: have %v, want %v", tt.number, rewards.PomReward, want)
+		}
+	}
+}
+
+// Tests that a fork changing the halving interval keeps the eras completed
+// before it instead of recounting them from genesis.
+func TestBlockRewardsHalvingFork(t *testing.T) {
+	tribe := newEvidenceTestTribe()
+	tribe.config.Forks = []*params.TribeFork{
+		{Block: big.NewInt(0), Reward: big.NewInt(1000), HalvingInterval: 10},
+		{Block: big.NewInt(25), HalvingInterval: 20},
+	}
+	chain := &testChainReader{config: params.TestChainConfig}
+	key, _ := crypto.GenerateKey()
+
+	tests := []struct {
+		number int64
+		reward int64
+		era    uint64
+	}{
+		{24, 250, 2},
+		{25, 250, 2},
+		{30, 250, 2},
+		{44, 250, 2},
+		{45, 125, 3},
+		{65, 62, 4},
+	}
+	for _, tt := range tests {
+		rewards := tribe.blockRewards(chain, sealedHeader(key, tt.number, 100))
+		if rewards.Reward.Int64() != tt.reward {
+			t.Errorf("block %d: reward mismatch: have %v, want %d", tt.number, rewards.Reward, tt.reward)
+		}
+		if rewards.HalvingEra != tt.era {
+			t.Errorf("block %d: era mismatch: have %d, want %d", tt.number, rewards.HalvingEra, tt.era)
+		}
+	}
+}
+
+func TestWriteBlockRecords(t *testing.T) {
+	tribe := newEvidenceTestTribe()
+	chain := &testChainReader{config: params.TestChainConfig}
+	key, _ := crypto.GenerateKey()
+
+	block := types.NewBlockWithHeader(sealedHeader(key, 21, 100))
+	if rewards := tribe.StoredBlockRewards(block.Header()); rewards != nil {
+		t.Fatalf("rewards found before being written: %+v", rewards)
+	}
+	// Rewards cached at finalization are the ones recorded
+	cached := &BlockRewards{Number: 21, Reward: big.NewInt(1), PomReward: big.NewInt(2)}
+	tribe.rewards.Add(sigHash(block.Header()), cached)
+	if err := tribe.WriteBlockRecords(chain, tribe.db, block); err != nil {
+		t.Fatal(err)
+	}
+	if stored := tribe.StoredBlockRewards(block.Header()); stored == nil || stored.Reward.Cmp(cached.Reward) != 0 || stored.PomReward.Cmp(cached.PomReward) != 0 {
+		t.Errorf("rewards mismatch: have %+v, want %+v", stored, cached)
+	}
+	// Uncached rewards are computed again, and a sibling is recorded apart
+	sibling := types.NewBlockWithHeader(sealedHeader(key, 21, 101))
+	if err := tribe.WriteBlockRecords(chain, tribe.db, sibling); err != nil {
+		t.Fatal(err)
+	}
+	want := tribe.blockRewards(chain, sibling.Header())
+	if stored := tribe.StoredBlockRewards(sibling.Header()); stored == nil || stored.Number != want.Number ||
+		stored.Recipient != want.Recipient || stored.Reward.Cmp(want.Reward) != 0 || stored.PomReward.Cmp(want.PomReward) != 0 {
+		t.Errorf("rewards mismatch: have %+v, want %+v", stored, want)
+	}
+	if stored := tribe.StoredBlockRewards(block.Header()); stored == nil || stored.Reward.Cmp(cached.Reward) != 0 {
+		t.Errorf("rewards overwritten by sibling: %+v", stored)
+	}
+	if rewards := tribe.StoredBlockRewards(sealedHeader(key, 22, 100)); rewards != nil {
+		t.Errorf("rewards found for another block: %+v", rewards)
+	}
+	// The records are served to the API through consensus.Recorder
+	var recorder consensus.Recorder = tribe
+	if records, ok := recorder.BlockRecords(block.Header()).(*BlockRewards); !ok || records.Reward.Cmp(cached.Reward) != 0 {
+		t.Errorf("records mismatch: have %+v, want %+v", records, cached)
+	}
+	if records := recorder.BlockRecords(sealedHeader(key, 22, 100)); records != nil {
+		t.Errorf("records found for another block: %+v", records)
+	}
+}
@@ -34,6 +34,7 @@ type Snapshot struct {
 	Number     uint64                      `json:"number"`     // Block number where the snapshot was created
 	Hash       common.Hash                 `json:"hash"`       // Block hash where the snapshot was created
 	Validators map[common.Address]struct{} `json:"validators"` // Set of authorized validators at this moment
+	Recents    map[uint64]common.Address   `json:"recents"`    // Set of recent validators for spam protections
 }
 
 // validatorsAscending implements the sort interface to allow sorting a list of addresses
@@ -52,6 +53,7 @@ func newSnapshot(config *params.TribeConfig, number uint64, hash common.Hash, va
 		Number:     number,
 		Hash:       hash,
 		Validators: make(map[common.Address]struct{}),
+		Recents:    make(map[uint64]common.Address),
 	}
 	for _, validator := range validators {
 		snap.Validators[validator] = struct{}{}
@@ -71,6 +73,10 @@ func loadSnapshot(config *params.TribeConfig, db ethdb.Database, hash common.Has
 	}
 	snap.config = config
 
+	// Snapshots stored before the recent signer tracking have no such field
+	if snap.Recents == nil {
+		snap.Recents = make(map[uint64]common.Address)
+	}
 	return snap, nil
 }
 
@@ -90,10 +96,14 @@ func (s *Snapshot) copy() *Snapshot {
 		Number:     s.Number,
 		Hash:       s.Hash,
 		Validators: make(map[common.Address]struct{}),
+		Recents:    make(map[uint64]common.Address),
 	}
 	for validator := range s.Validators {
 		cpy.Validators[validator] = struct{}{}
 	}
+	for block, validator := range s.Recents {
+		cpy.Recents[block] = validator
+	}
 
 	return cpy
 }
@@ -127,9 +137,24 @@ func (s *Snapshot) apply(headers []*types.Header, chain consensus.ChainReader, p
 		if _, ok := snap.Validators[validator]; !ok {
 			return nil, errUnauthorizedValidator
 		}
+		// Delete the oldest validators from the recent list to allow them sealing again
+		limit := uint64(len(snap.Validators)/2 + 1)
+		for block := range snap.Recents {
+			if block+limit <= number {
+				delete(snap.Recents, block)
+			}
+		}
+		if s.config.IsRecents(header.Number) {
+			for _, recent := range snap.Recents {
+				if recent == validator {
+					return nil, errRecentlySigned
+				}
+			}
+			snap.Recents[number] = validator
+		}
 
 		// update validators at the first block at epoch
-		if number > 0 && number%s.config.Epoch == 0 {
+		if number > 0 && number%s.config.ForkAt(header.Number).Epoch == 0 {
 			checkpointHeader := header
 			// get validators from headers and use that for new validator set
 			validators := make([]common.Address, (len(checkpointHeader.Extra)-extraVanity-extraSeal-extraVrf)/common.AddressLength)
@@ -169,6 +194,18 @@ func (s *Snapshot) inturn(validator common.Address) bool {
 	return validators[offset] == validator
 }
 
+// signedRecently returns whether the validator is among the recent signers and
+// a block at the given number would not shift it out of the list yet.
+func (s *Snapshot) signedRecently(validator common.Address, number uint64) bool {
+	limit := uint64(len(s.Validators)/2 + 1)
+	for seen, recent := range s.Recents {
+		if recent == validator && (number < limit || seen > number-limit) {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *Snapshot) indexOfVal(validator common.Address) int {
 	validators := s.validators()
 	for idx, val := range validators {
@@ -0,0 +1,290 @@
+package tribe
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/common/math"
+	"github.com/MeshBoxTech/mesh-chain/consensus"
+	"github.com/MeshBoxTech/mesh-chain/consensus/tribe/vmcaller"
+	"github.com/MeshBoxTech/mesh-chain/core"
+	"github.com/MeshBoxTech/mesh-chain/core/types"
+	"github.com/MeshBoxTech/mesh-chain/ethdb"
+	"github.com/MeshBoxTech/mesh-chain/params"
+	"github.com/MeshBoxTech/mesh-chain/rlp"
+)
+
+const (
+	// statsSectionSize is the number of blocks aggregated into one section of
+	// the validator statistics index.
+	statsSectionSize = 4096
+
+	// statsConfirms is the number of confirmation blocks before a section of
+	// the validator statistics index is generated, to avoid reorg churn.
+	statsConfirms = 256
+
+	// statsThrottling is the time to wait between processing two consecutive
+	// sections of the validator statistics index.
+	statsThrottling = 100 * time.Millisecond
+
+	// posNodeType is the Validators contract node type of PoS validators, the
+	// only ones it actually stops when punishing.
+	posNodeType = 2
+)
+
+// statsIndexPrefix is the database table of the validator statistics index.
+var statsIndexPrefix = []byte("tribe-stats-")
+
+// errInvalidBlockRange is returned if the requested block range of the
+// validator statistics or rewards is empty or beyond the local chain.
+var errInvalidBlockRange = errors.New("invalid block range")
+
+// ValidatorStats counts the performance of a validator over a range of blocks.
+type ValidatorStats struct {
+	InTurn    uint64 `json:"inTurn"`    // Blocks sealed in the validator's own slot
+	OutOfTurn uint64 `json:"outOfTurn"` // Blocks sealed after backing off in somebody else's slot
+	Missed    uint64 `json:"missed"`    // Own slots sealed by somebody else
+	Punished  uint64 `json:"punished"`  // Punishments for missed slots and double signs
+}
+
+// validatorStats is the statistics of every validator seen in a range of blocks.
+type validatorStats map[common.Address]*ValidatorStats
+
+// get returns the statistics of the validator, creating them if needed.
+func (s validatorStats) get(validator common.Address) *ValidatorStats {
+	stats, ok := s[validator]
+	if !ok {
+		stats = new(ValidatorStats)
+		s[validator] = stats
+	}
+	return stats
+}
+
+// add accumulates the statistics of other into s.
+func (s validatorStats) add(other validatorStats) {
+	for validator, o := range other {
+		stats := s.get(validator)
+		stats.InTurn += o.InTurn
+		stats.OutOfTurn += o.OutOfTurn
+		stats.Missed += o.Missed
+		stats.Punished += o.Punished
+	}
+}
+
+// statsEntry is the RLP representation of the statistics of one validator.
+type statsEntry struct {
+	Validator common.Address
+	Stats     ValidatorStats
+}
+
+// encodeStats flattens the statistics into a list sorted by validator so the
+// encoding is deterministic.
+func encodeStats(s validatorStats) ([]byte, error) {
+	entries := make([]statsEntry, 0, len(s))
+	for validator, stats := range s {
+		entries = append(entries, statsEntry{validator, *stats})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i].Validator[:], entries[j].Validator[:]) < 0
+	})
+	return rlp.EncodeToBytes(entries)
+}
+
+// decodeStats is the inverse of encodeStats.
+func decodeStats(blob []byte) (validatorStats, error) {
+	var entries []statsEntry
+	if err := rlp.DecodeBytes(blob, &entries); err != nil {
+		return nil, err
+	}
+	s := make(validatorStats, len(entries))
+	for i := range entries {
+		s[entries[i].Validator] = &entries[i].Stats
+	}
+	return s, nil
+}
+
+// statsKey is the index key of the statistics of a section.
+func statsKey(section uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, section)
+	return key
+}
+
+// accumulateStats adds the statistics of a single block to s. The sealer of an
+// in-turn block gets the credit for its slot, otherwise the sealer backed off
+// and the validator whose slot it was missed it. Missed slots and double sign
+// evidence submitted within the block count as punishments of PoS validators,
+// the Validators contract leaves PoA ones alone.
+func (t *Tribe) accumulateStats(chain consensus.ChainReader, header *types.Header, s validatorStats) error {
+	number := header.Number.Uint64()
+	if number == 0 {
+		return nil
+	}
+	sealer, err := ecrecover(header, t)
+	if err != nil {
+		return err
+	}
+	if header.Difficulty.Cmp(diffInTurn) == 0 {
+		s.get(sealer).InTurn++
+	} else {
+		s.get(sealer).OutOfTurn++
+
+		snap, err := t.snapshot(chain, number-1, header.ParentHash, nil)
+		if err != nil {
+			return err
+		}
+		validators := snap.validators()
+		validator := validators[number%uint64(len(validators))]
+		s.get(validator).Missed++
+		if err := t.accumulatePunishment(chain, header, validator, s); err != nil {
+			return err
+		}
+	}
+	block := chain.GetBlock(header.Hash(), number)
+	if block == nil {
+		return consensus.ErrUnknownAncestor
+	}
+	signer := types.MakeSigner(chain.Config(), header.Number)
+	for _, tx := range block.Transactions() {
+		if tx.To() == nil || *tx.To() != params.ValidatorsContractAddr {
+			continue
+		}
+		if from, err := types.Sender(signer, tx); err != nil || from != header.Coinbase {
+			continue
+		}
+		if evidence, err := t.unpackEvidence(tx.Data()); err == nil && evidence != nil {
+			if err := t.accumulatePunishment(chain, header, evidence.Validator, s); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// accumulatePunishment counts a punishment of the validator within the block,
+// provided it was a PoS node the Validators contract actually punished.
+func (t *Tribe) accumulatePunishment(chain consensus.ChainReader, header *types.Header, validator common.Address, s validatorStats) error {
+	nodeType, err := t.validatorNodeType(chain, header, validator)
+	if err != nil {
+		return err
+	}
+	if nodeType.Cmp(big.NewInt(posNodeType)) == 0 {
+		s.get(validator).Punished++
+	}
+	return nil
+}
+
+// validatorNodeType retrieves the node type the Validators contract registered
+// the validator with, in the state after the block.
+func (t *Tribe) validatorNodeType(chain consensus.ChainReader, header *types.Header, validator common.Address) (*big.Int, error) {
+	statedb, err := t.stateFn(header.Root)
+	if err != nil {
+		return nil, err
+	}
+	method := "getValidatorState"
+	data, err := t.abi[ValidatorsContractName].Pack(method, validator)
+	if err != nil {
+		return nil, err
+	}
+	msg := vmcaller.NewLegacyMessage(header.Coinbase, &params.ValidatorsContractAddr, 0, new(big.Int), new(big.Int).SetUint64(math.MaxUint64), new(big.Int), data, false)
+	result, err := vmcaller.ExecuteMsg(msg, statedb, header, newChainContext(chain, t), chain.Config())
+	if err != nil {
+		return nil, err
+	}
+	var (
+		amount, stopBlock, recoverBlock, nodeType *big.Int
+		owner                                     common.Address
+	)
+	out := &[]interface{}{&amount, &stopBlock, &recoverBlock, &owner, &nodeType}
+	if err := t.abi[ValidatorsContractName].Unpack(out, method, result); err != nil {
+		return nil, err
+	}
+	return nodeType, nil
+}
+
+// StatsIndexer implements a core.ChainIndexer, aggregating the performance of
+// the validators per section of the canonical chain for fast statistics queries.
+type StatsIndexer struct {
+	tribe *Tribe
+	chain consensus.ChainReader
+	db    ethdb.Database // Index table to write the section statistics into
+
+	section uint64         // Section number being processed currently
+	stats   validatorStats // Statistics accumulated for the current section
+}
+
+// NewStatsIndexer returns a chain indexer that generates the validator
+// statistics of the canonical chain, and makes it available to the tribe API.
+func (t *Tribe) NewStatsIndexer(chain consensus.ChainReader) *core.ChainIndexer {
+	table := ethdb.NewTable(t.db, string(statsIndexPrefix))
+	backend := &StatsIndexer{
+		tribe: t,
+		chain: chain,
+		db:    table,
+	}
+	t.statsIndexer = core.NewChainIndexer(t.db, table, backend, statsSectionSize, statsConfirms, statsThrottling, "tribestats")
+	return t.statsIndexer
+}
+
+// Reset implements core.ChainIndexerBackend, starting a new statistics section.
+func (b *StatsIndexer) Reset(section uint64, prevHead common.Hash) error {
+	b.section, b.stats = section, make(validatorStats)
+	return nil
+}
+
+// Process implements core.ChainIndexerBackend, adding the block to the
+// statistics of the section.
+func (b *StatsIndexer) Process(header *types.Header) error {
+	return b.tribe.accumulateStats(b.chain, header, b.stats)
+}
+
+// Commit implements core.ChainIndexerBackend, storing the section statistics.
+func (b *StatsIndexer) Commit() error {
+	blob, err := encodeStats(b.stats)
+	if err != nil {
+		return err
+	}
+	return b.db.Put(statsKey(b.section), blob)
+}
+
+// collectStats aggregates the statistics of the blocks from..to inclusive,
+// using the indexed sections where the range fully covers them.
+func (t *Tribe) collectStats(chain consensus.ChainReader, from, to uint64) (validatorStats, error) {
+	var (
+		sections uint64
+		table    ethdb.Database
+	)
+	if t.statsIndexer != nil {
+		sections, _, _ = t.statsIndexer.Sections()
+		table = ethdb.NewTable(t.db, string(statsIndexPrefix))
+	}
+	s := make(validatorStats)
+	for number := from; number <= to; {
+		if section := number / statsSectionSize; number%statsSectionSize == 0 && section < sections && number+statsSectionSize-1 <= to {
+			blob, err := table.Get(statsKey(section))
+			if err == nil {
+				indexed, err := decodeStats(blob)
+				if err != nil {
+					return nil, err
+				}
+				s.add(indexed)
+				number += statsSectionSize
+				continue
+			}
+		}
+		header := chain.GetHeaderByNumber(number)
+		if header == nil {
+			return nil, errUnknownBlock
+		}
+		if err := t.accumulateStats(chain, header, s); err != nil {
+			return nil, err
+		}
+		number++
+	}
+	return s, nil
+}
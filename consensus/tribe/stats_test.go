@@ -0,0 +1,153 @@
+package tribe
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"math/big"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/core/state"
+	"github.com/MeshBoxTech/mesh-chain/core/types"
+	"github.com/MeshBoxTech/mesh-chain/core/vm"
+	"github.com/MeshBoxTech/mesh-chain/crypto"
+	"github.com/MeshBoxTech/mesh-chain/ethdb"
+	"github.com/MeshBoxTech/mesh-chain/params"
+)
+
+// testBlockChain is a consensus.ChainReader over a fixed list of blocks.
+type testBlockChain struct {
+	testChainReader
+	blocks []*types.Block
+}
+
+func (c *testBlockChain) CurrentHeader() *types.Header {
+	return c.blocks[len(c.blocks)-1].Header()
+}
+
+func (c *testBlockChain) GetHeaderByNumber(number uint64) *types.Header {
+	if number >= uint64(len(c.blocks)) {
+		return nil
+	}
+	return c.blocks[number].Header()
+}
+
+func (c *testBlockChain) GetBlock(hash common.Hash, number uint64) *types.Block {
+	if number >= uint64(len(c.blocks)) || c.blocks[number].Hash() != hash {
+		return nil
+	}
+	return c.blocks[number]
+}
+
+// nodeTypeState returns a state function whose Validators contract reports pos
+// as a PoS node and every other validator as a PoA one.
+func nodeTypeState(pos common.Address) StateFn {
+	db, _ := ethdb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+
+	// getValidatorState(addr) returning (0, 0, 0, 0, addr == pos ? 2 : 1)
+	code := append([]byte{byte(vm.PUSH1), 0x04, byte(vm.CALLDATALOAD), byte(vm.PUSH20)}, pos.Bytes()...)
+	code = append(code, byte(vm.EQ), byte(vm.PUSH1), 0x01, byte(vm.ADD), byte(vm.PUSH1), 0x80, byte(vm.MSTORE),
+		byte(vm.PUSH1), 0xa0, byte(vm.PUSH1), 0x00, byte(vm.RETURN))
+	statedb.SetCode(params.ValidatorsContractAddr, code)
+
+	return func(common.Hash) (*state.StateDB, error) {
+		return statedb.Copy(), nil
+	}
+}
+
+func TestValidatorStats(t *testing.T) {
+	tribe := newEvidenceTestTribe()
+
+	keys := make([]*ecdsa.PrivateKey, 3)
+	validators := make([]common.Address, len(keys))
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := crypto.PubkeyToAddress(keys[i].PublicKey), crypto.PubkeyToAddress(keys[j].PublicKey)
+		return bytes.Compare(a[:], b[:]) < 0
+	})
+	for i, key := range keys {
+		validators[i] = crypto.PubkeyToAddress(key.PublicKey)
+	}
+	chain := &testBlockChain{testChainReader: testChainReader{config: params.TestChainConfig}}
+	signer := types.MakeSigner(chain.Config(), common.Big0)
+
+	// Only the third validator is a PoS node, the others are PoA ones
+	tribe.stateFn = nodeTypeState(validators[2])
+
+	// Slot n belongs to validators[n%3]. Block 2 is sealed out of turn by the
+	// first validator, which also submits evidence against the third one. The
+	// second validator seals the slots of the others in blocks 5 and 6.
+	sealers := []int{1, 0, 0, 1, 1, 1}
+	evidence := &Evidence{validators[2], 1, sealedHeader(keys[2], 1, 100), sealedHeader(keys[2], 1, 101)}
+
+	chain.blocks = []*types.Block{types.NewBlockWithHeader(&types.Header{Number: new(big.Int)})}
+	for i, sealer := range sealers {
+		number := uint64(i + 1)
+		parent := chain.blocks[number-1]
+		tribe.recents.Add(parent.Hash(), newSnapshot(tribe.config, number-1, parent.Hash(), validators))
+
+		difficulty := diffNoTurn
+		if number%3 == uint64(sealer) {
+			difficulty = diffInTurn
+		}
+		header := &types.Header{
+			ParentHash: parent.Hash(),
+			Coinbase:   validators[sealer],
+			Number:     new(big.Int).SetUint64(number),
+			Difficulty: new(big.Int).Set(difficulty),
+			GasLimit:   new(big.Int),
+			GasUsed:    new(big.Int),
+			Time:       new(big.Int),
+			Extra:      make([]byte, extraVanity+extraSeal),
+		}
+		var txs []*types.Transaction
+		if number == 2 {
+			data, err := tribe.packEvidence(evidence)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tx := types.NewTransaction(0, params.ValidatorsContractAddr, new(big.Int), big.NewInt(evidenceGasLimit), new(big.Int), data)
+			tx, _ = types.SignTx(tx, signer, keys[sealer])
+			txs = append(txs, tx)
+		}
+		block := types.NewBlock(header, txs, nil, nil)
+		header = block.Header()
+		sig, _ := crypto.Sign(sigHash(header).Bytes(), keys[sealer])
+		copy(header.Extra[len(header.Extra)-extraSeal:], sig)
+		chain.blocks = append(chain.blocks, block.WithSeal(header))
+	}
+	stats, err := tribe.collectStats(chain, 1, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := validatorStats{
+		validators[0]: {InTurn: 1, OutOfTurn: 1, Missed: 1, Punished: 0},
+		validators[1]: {InTurn: 2, OutOfTurn: 2, Missed: 0, Punished: 0},
+		validators[2]: {InTurn: 0, OutOfTurn: 0, Missed: 2, Punished: 3},
+	}
+	if !reflect.DeepEqual(stats, want) {
+		t.Errorf("stats mismatch:\nhave %v\nwant %v", stats, want)
+	}
+	// Sections are stored and summed up losslessly
+	blob, err := encodeStats(stats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := decodeStats(blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded.add(stats)
+	for validator, s := range want {
+		s.InTurn, s.OutOfTurn, s.Missed, s.Punished = 2*s.InTurn, 2*s.OutOfTurn, 2*s.Missed, 2*s.Punished
+		want[validator] = s
+	}
+	if !reflect.DeepEqual(decoded, want) {
+		t.Errorf("accumulated stats mismatch:\nhave %v\nwant %v", decoded, want)
+	}
+}
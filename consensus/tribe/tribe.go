@@ -48,7 +48,7 @@ type StateFn func(hash common.Hash) (*state.StateDB, error)
 func sigHash(header *types.Header) (hash common.Hash) {
 	hasher := sha3.NewKeccak256()
 
-	err := rlp.Encode(hasher, []interface{}{
+	enc := []interface{}{
 		header.ParentHash,
 		header.UncleHash,
 		header.Coinbase,
@@ -64,7 +64,11 @@ func sigHash(header *types.Header) (hash common.Hash) {
 		header.Extra[:len(header.Extra)-65], // Yes, this will panic if extra is too short
 		header.MixDigest,
 		header.Nonce,
-	})
+	}
+	if header.BaseFee != nil {
+		enc = append(enc, header.BaseFee)
+	}
+	err := rlp.Encode(hasher, enc)
 	if err != nil {
 		panic(err)
 	}
@@ -110,6 +114,9 @@ func ecrecover(header *types.Header, t *Tribe) (common.Address, error) {
 func New(accman *accounts.Manager, config *params.TribeConfig, db ethdb.Database) *Tribe {
 	sigcache, err := lru.NewARC(historyLimit)
 	recents, _ := lru.NewARC(historyLimit)
+	seals, _ := lru.NewARC(historyLimit)
+	punished, _ := lru.NewARC(historyLimit)
+	rewards, _ := lru.NewARC(historyLimit)
 	if err != nil {
 		panic(err)
 	}
@@ -122,6 +129,10 @@ func New(accman *accounts.Manager, config *params.TribeConfig, db ethdb.Database
 		config:   &conf,
 		sigcache: sigcache,
 		recents:  recents,
+		seals:    seals,
+		punished: punished,
+		rewards:  rewards,
+		abi:      GetInteractiveABI(),
 		db:       db,
 	}
 	return tribe
@@ -213,9 +224,16 @@ func (t *Tribe) verifyHeader(chain consensus.ChainReader, header *types.Header,
 		return errMissingSignature
 	}
 	// check extra data
-	isEpoch := number%t.config.Epoch == 0
+	isEpoch := number%t.forkAt(header.Number).Epoch == 0
+	isVRFMix := t.config.IsVRFMix(header.Number)
 	signersBytes := len(header.Extra) - extraVanity - extraSeal
-	if !isEpoch && signersBytes != 0 {
+	if (isEpoch || isVRFMix) && signersBytes < extraVrf {
+		return errMissingVrf
+	}
+	if !isEpoch && !isVRFMix && signersBytes != 0 {
+		return errExtraValidators
+	}
+	if !isEpoch && isVRFMix && signersBytes != extraVrf {
 		return errExtraValidators
 	}
 
@@ -223,8 +241,9 @@ func (t *Tribe) verifyHeader(chain consensus.ChainReader, header *types.Header,
 		return errInvalidSpanValidators
 	}
 
-	// Ensure that the mix digest is zero as we don't have fork protection currently
-	if header.MixDigest != (common.Hash{}) {
+	// Ensure that the mix digest is zero before the VRF mix fork, afterwards it
+	// carries the accumulated randomness and is checked against the parent in verifySeal
+	if !isVRFMix && header.MixDigest != (common.Hash{}) {
 		return errInvalidMixDigest
 	}
 	// Ensure that the block doesn't contain any uncles which are meaningless in PoA
@@ -296,6 +315,14 @@ func (t *Tribe) verifyCascadingFields(chain consensus.ChainReader, header *types
 	if diff.Cmp(limit) >= 0 || header.GasLimit.Cmp(minGasLimit) < 0 {
 		return fmt.Errorf("invalid gas limit: have %v, want %v += %v", header.GasLimit, parent.GasLimit, limit)
 	}
+	// Verify the base fee once the fee market is active
+	if !chain.Config().IsLondon(header.Number) {
+		if header.BaseFee != nil {
+			return fmt.Errorf("invalid baseFee before fork: have %v, want <nil>", header.BaseFee)
+		}
+	} else if err := misc.VerifyEip1559Header(chain.Config(), parent, header); err != nil {
+		return err
+	}
 
 	// Verify that the block number is parent's +1
 	if diff := new(big.Int).Sub(header.Number, parent.Number); diff.Cmp(big.NewInt(1)) != 0 {
@@ -346,18 +373,8 @@ func (t *Tribe) verifySeal(chain consensus.ChainReader, header *types.Header, pa
 		return errInvalidCoinbase
 	}
 
-	if number%t.config.Epoch == 0 {
-		//verify vrf
-		msg := header.Number.Bytes()
-		sig := header.Extra[len(header.Extra)-extraSeal:]
-		pubbuf, err := ecrecoverPubkey(header, sig)
-		if err != nil {
-			return err
-		}
-		x, y := elliptic.Unmarshal(crypto.S256(), pubbuf)
-		pubkey := ecdsa.PublicKey{Curve: crypto.S256(), X: x, Y: y}
-		err = crypto.SimpleVRFVerify(&pubkey, msg, header.Extra[extraVanity:extraVanity+extraVrf])
-		if err != nil {
+	if number%t.forkAt(header.Number).Epoch == 0 || t.config.IsVRFMix(header.Number) {
+		if err := t.verifyVRF(chain, header, parents); err != nil {
 			return err
 		}
 	}
@@ -365,6 +382,9 @@ func (t *Tribe) verifySeal(chain consensus.ChainReader, header *types.Header, pa
 	if _, ok := snap.Validators[signer]; !ok {
 		return errUnauthorizedValidator
 	}
+	if t.config.IsRecents(header.Number) && snap.signedRecently(signer, number) {
+		return errRecentlySigned
+	}
 	inturn := snap.inturn(signer)
 	if inturn && header.Difficulty.Cmp(diffInTurn) != 0 {
 		return errInvalidDifficulty
@@ -372,11 +392,49 @@ func (t *Tribe) verifySeal(chain consensus.ChainReader, header *types.Header, pa
 	if !inturn && header.Difficulty.Cmp(diffNoTurn) != 0 {
 		return errInvalidDifficulty
 	}
+	// Only a fully valid seal can serve as double-sign evidence
+	t.observeSeal(header, signer)
 
 	log.Debug("verifySeal", "number", number, "signer", signer.Hex())
 	return nil
 }
 
+// verifyVRF checks the VRF contribution carried in the extra-data of a header.
+// Before the VRF mix fork only epoch blocks carry one, evaluated over the block
+// number. Afterwards every block evaluates it over the accumulated randomness
+// of its parent, and the mix digest must fold the new output into it.
+func (t *Tribe) verifyVRF(chain consensus.ChainReader, header *types.Header, parents []*types.Header) error {
+	var (
+		number = header.Number.Uint64()
+		msg    = header.Number.Bytes()
+		parent *types.Header
+	)
+	if t.config.IsVRFMix(header.Number) {
+		if len(parents) > 0 {
+			parent = parents[len(parents)-1]
+		} else {
+			parent = chain.GetHeader(header.ParentHash, number-1)
+		}
+		if parent == nil || parent.Number.Uint64() != number-1 || parent.Hash() != header.ParentHash {
+			return consensus.ErrUnknownAncestor
+		}
+		msg = parent.MixDigest.Bytes()
+	}
+	// Check the cheap mix digest before the VRF proof
+	vrf := header.Extra[extraVanity : extraVanity+extraVrf]
+	if parent != nil && header.MixDigest != mixRandomness(parent.MixDigest, vrf) {
+		return errInvalidVrfMix
+	}
+	sig := header.Extra[len(header.Extra)-extraSeal:]
+	pubbuf, err := ecrecoverPubkey(header, sig)
+	if err != nil {
+		return err
+	}
+	x, y := elliptic.Unmarshal(crypto.S256(), pubbuf)
+	pubkey := ecdsa.PublicKey{Curve: crypto.S256(), X: x, Y: y}
+	return crypto.SimpleVRFVerify(&pubkey, msg, vrf)
+}
+
 // Prepare implements consensus.Engine, preparing all the consensus fields of the
 // header for running the transactions on top.
 func (t *Tribe) Prepare(chain consensus.ChainReader, header *types.Header) error {
@@ -394,12 +452,31 @@ func (t *Tribe) Prepare(chain consensus.ChainReader, header *types.Header) error
 		header.Extra = append(header.Extra, bytes.Repeat([]byte{0x00}, extraVanity-len(header.Extra))...)
 	}
 	header.Extra = header.Extra[:extraVanity]
-	if number%t.config.Epoch == 0 {
-		vrf, err := crypto.SimpleVRF2Bytes(t.nodeKey, header.Number.Bytes())
+
+	// Mix digest is reserved until the VRF mix fork, set to empty
+	header.MixDigest = common.Hash{}
+
+	parent := chain.GetHeader(header.ParentHash, number-1)
+	if parent == nil {
+		return consensus.ErrUnknownAncestor
+	}
+	fork := t.forkAt(header.Number)
+	isVRFMix := t.config.IsVRFMix(header.Number)
+	if number%fork.Epoch == 0 || isVRFMix {
+		msg := header.Number.Bytes()
+		if isVRFMix {
+			msg = parent.MixDigest.Bytes()
+		}
+		vrf, err := crypto.SimpleVRF2Bytes(t.nodeKey, msg)
 		if err != nil {
 			return err
 		}
 		header.Extra = append(header.Extra, vrf...)
+		if isVRFMix {
+			header.MixDigest = mixRandomness(parent.MixDigest, vrf)
+		}
+	}
+	if number%fork.Epoch == 0 {
 		newValidators, err := t.getNewValidators(chain, header)
 		if err != nil {
 			return err
@@ -412,20 +489,12 @@ func (t *Tribe) Prepare(chain consensus.ChainReader, header *types.Header) error
 
 	// Extra : append sig to last 65 bytes <<<<
 
-	// Mix digest is reserved for now, set to empty
-	header.MixDigest = common.Hash{}
-
-	// Ensure the timestamp has the correct delay
-	parent := chain.GetHeader(header.ParentHash, number-1)
-	if parent == nil {
-		return consensus.ErrUnknownAncestor
-	}
 	// Set the correct difficulty
 	header.Difficulty = t.CalcDifficulty(chain, header.Time.Uint64(), parent)
-	header.Time = new(big.Int).Add(parent.Time, new(big.Int).SetUint64(t.config.Period))
+	header.Time = new(big.Int).Add(parent.Time, new(big.Int).SetUint64(fork.Period))
 
 	//替补出块延迟
-	delay := backOffTime(snap, t.GetMinerAddress())
+	delay := backOffTime(snap, fork, t.GetMinerAddress())
 	header.Time = new(big.Int).Add(header.Time, new(big.Int).SetUint64(delay))
 
 	if header.Time.Int64() < time.Now().Unix() {
@@ -445,13 +514,19 @@ func (t *Tribe) Finalize(chain consensus.ChainReader, header *types.Header, stat
 		}
 	}
 
+	if t.config.IsEvidence(header.Number) {
+		if err := t.verifyEvidenceTxs(chain, header, txs); err != nil {
+			return nil, err
+		}
+	}
+
 	if header.Difficulty.Cmp(diffInTurn) != 0 {
 		if err := t.tryPunishValidator(chain, header, state); err != nil {
 			return nil, err
 		}
 	}
 
-	if header.Number.Uint64()%t.config.Epoch == 0 {
+	if header.Number.Uint64()%t.forkAt(header.Number).Epoch == 0 {
 		newValidators, err := t.doSomethingAtEpoch(chain, header, state)
 		if err != nil {
 			return nil, err
@@ -466,11 +541,18 @@ func (t *Tribe) Finalize(chain consensus.ChainReader, header *types.Header, stat
 			return nil, errInvalidExtraValidators
 		}
 	}
-	t.accumulateRewards(chain, state, header)
+	rewards := t.accumulateRewards(chain, state, header)
+
+	// The base fee is burned by default, past the POM fee fork it funds the
+	// POM reward pool instead.
+	if header.BaseFee != nil && t.config.IsPomFee(header.Number) {
+		t.accumulatePomFee(state, new(big.Int).Mul(header.BaseFee, header.GasUsed))
+	}
 
 	header.Root = state.IntermediateRoot(chain.Config().IsEIP158(header.Number))
 	//there is no uncle in triple
 	header.UncleHash = types.CalcUncleHash(nil)
+	t.rewards.Add(sigHash(header), rewards)
 	return types.NewBlock(header, txs, nil, receipts), nil
 }
 
@@ -492,13 +574,25 @@ func (t *Tribe) Seal(chain consensus.ChainReader, block *types.Block, stop <-cha
 	if _, authorized := snap.Validators[t.GetMinerAddress()]; !authorized {
 		return nil, errUnauthorizedValidator
 	}
+	// If we're amongst the recent signers, wait for the next block
+	if t.config.IsRecents(header.Number) && snap.signedRecently(t.GetMinerAddress(), number) {
+		log.Info("Signed recently, must wait for others", "number", number)
+		<-stop
+		return nil, nil
+	}
 
 	now := time.Now()
 	delay := time.Unix(header.Time.Int64(), 0).Sub(now)
 	log.Info(fmt.Sprintf("Seal -> num=%d, diff=%d, miner=%s, delay=%d", number, header.Difficulty, header.Coinbase.Hex(), delay))
 	select {
 	case <-stop:
-		log.Warn(fmt.Sprintf("🐦 cancel -> num=%d, diff=%d, miner=%s, delay=%d", number, header.Difficulty, header.Coinbase.Hex(), delay))
+		// The worker aborts the seal whenever it rebuilds the pending block,
+		// only losing the height to another block is worth a warning
+		if head := chain.CurrentHeader(); head != nil && head.Number.Uint64() >= number {
+			log.Warn(fmt.Sprintf("🐦 cancel -> num=%d, diff=%d, miner=%s, delay=%d", number, header.Difficulty, header.Coinbase.Hex(), delay))
+		} else {
+			log.Debug("Sealing aborted for a rebuilt block", "number", number, "delay", delay)
+		}
 		return nil, nil
 	case <-time.After(delay):
 	}
@@ -531,6 +625,28 @@ func calcDifficulty(snap *Snapshot, validator common.Address) *big.Int {
 	}
 	return new(big.Int).Set(diffNoTurn)
 }
+
+// InturnValidators returns the validators in turn for the count blocks following
+// the given header, assuming the validator set of its snapshot does not change.
+func (t *Tribe) InturnValidators(chain consensus.ChainReader, header *types.Header, count int) ([]common.Address, error) {
+	snap, err := t.snapshot(chain, header.Number.Uint64(), header.Hash(), nil)
+	if err != nil {
+		return nil, err
+	}
+	validators := snap.validators()
+	if len(validators) == 0 {
+		return nil, nil
+	}
+	if count > len(validators) {
+		count = len(validators)
+	}
+	inturn := make([]common.Address, 0, count)
+	for i := 1; i <= count; i++ {
+		inturn = append(inturn, validators[(snap.Number+uint64(i))%uint64(len(validators))])
+	}
+	return inturn, nil
+}
+
 func (self *Tribe) GetMinerAddress() common.Address {
 	if self.nodeKey == nil {
 		panic(errors.New("GetMinerAddress but nodekey not ready"))
@@ -566,7 +682,7 @@ func (t *Tribe) initializeSystemContracts(chain consensus.ChainReader, header *t
 	}
 
 	genesisValidators := snap.validators()
-	if len(genesisValidators) == 0 || len(genesisValidators) > maxValidators {
+	if len(genesisValidators) == 0 || uint64(len(genesisValidators)) > t.forkAt(common.Big0).MaxValidators {
 		return errInvalidValidatorsLength
 	}
 
@@ -585,13 +701,9 @@ func (t *Tribe) initializeSystemContracts(chain consensus.ChainReader, header *t
 		if err != nil {
 			return err
 		}
-		chainConfig := params.MainnetChainConfig
-		if params.IsTestnet() {
-			chainConfig = params.TestChainConfig
-		}
 		nonce := state.GetNonce(header.Coinbase)
 		msg := vmcaller.NewLegacyMessage(header.Coinbase, &contract.addr, nonce, new(big.Int), new(big.Int).SetUint64(math.MaxUint64), new(big.Int), data, true)
-		if _, err := vmcaller.ExecuteMsg(msg, state, header, newChainContext(chain, t), chainConfig); err != nil {
+		if _, err := vmcaller.ExecuteMsg(msg, state, header, newChainContext(chain, t), chain.Config()); err != nil {
 			return err
 		}
 	}
@@ -599,6 +711,42 @@ func (t *Tribe) initializeSystemContracts(chain consensus.ChainReader, header *t
 	return nil
 }
 
+// Validators implements consensus.PoS, returning the validators of the local
+// snapshot authorized to seal the child of the given block.
+func (t *Tribe) Validators(chain consensus.ChainReader, number uint64, hash common.Hash) ([]common.Address, error) {
+	snap, err := t.snapshot(chain, number, hash, nil)
+	if err != nil {
+		return nil, err
+	}
+	return snap.validators(), nil
+}
+
+// EpochValidators implements consensus.PoS, returning the validators announced
+// in the extra-data of an epoch header along with the ones elected for it from
+// the parent state, which Finalize requires to be identical.
+func (t *Tribe) EpochValidators(chain consensus.ChainReader, header *types.Header) ([]common.Address, []common.Address, error) {
+	number := header.Number.Uint64()
+	if number == 0 || number%t.forkAt(header.Number).Epoch != 0 {
+		return nil, nil, nil
+	}
+	if len(header.Extra) < extraVanity+extraVrf+extraSeal {
+		return nil, nil, errMissingSignature
+	}
+	validatorsBytes := header.Extra[extraVanity+extraVrf : len(header.Extra)-extraSeal]
+	if len(validatorsBytes)%common.AddressLength != 0 {
+		return nil, nil, errInvalidSpanValidators
+	}
+	announced := make([]common.Address, len(validatorsBytes)/common.AddressLength)
+	for i := range announced {
+		copy(announced[i][:], validatorsBytes[i*common.AddressLength:])
+	}
+	elected, err := t.getNewValidators(chain, header)
+	if err != nil {
+		return announced, nil, err
+	}
+	return announced, elected, nil
+}
+
 func (t *Tribe) getNewValidators(chain consensus.ChainReader, header *types.Header) ([]common.Address, error) {
 	if header.Number.Uint64() == 0 {
 		validators := make([]common.Address, (len(header.Extra)-extraVanity-extraVrf-extraSeal)/common.AddressLength)
@@ -612,7 +760,8 @@ func (t *Tribe) getNewValidators(chain consensus.ChainReader, header *types.Head
 		return []common.Address{}, consensus.ErrUnknownAncestor
 	}
 	number := header.Number.Uint64()
-	if number%t.config.Epoch != 0 {
+	fork := t.forkAt(header.Number)
+	if number%fork.Epoch != 0 {
 		return []common.Address{}, consensus.ErrInvalidNumber
 	}
 
@@ -623,9 +772,7 @@ func (t *Tribe) getNewValidators(chain consensus.ChainReader, header *types.Head
 
 	// method
 	method := "getNewValidators"
-	vrf := header.Extra[extraVanity : extraVanity+extraVrf]
-	rand := new(big.Int).SetBytes(vrf)
-	v, _ := uint256.FromBig(rand)
+	v, _ := uint256.FromBig(t.epochSeed(header, parent))
 	data, err := t.abi[ValidatorsContractName].Pack(method, v.ToBig())
 	if err != nil {
 		return nil, err
@@ -635,11 +782,7 @@ func (t *Tribe) getNewValidators(chain consensus.ChainReader, header *types.Head
 	nonce := statedb.GetNonce(header.Coinbase)
 	msg := vmcaller.NewLegacyMessage(header.Coinbase, &params.ValidatorsContractAddr, nonce, new(big.Int), new(big.Int).SetUint64(math.MaxUint64), new(big.Int), data, false)
 	//
-	chainConfig := params.MainnetChainConfig
-	if params.IsTestnet() {
-		chainConfig = params.TestChainConfig
-	}
-	result, err := vmcaller.ExecuteMsg(msg, statedb, parent, newChainContext(chain, t), chainConfig)
+	result, err := vmcaller.ExecuteMsg(msg, statedb, parent, newChainContext(chain, t), chain.Config())
 	if err != nil {
 		log.Error("Can't decrease missed blocks counter for validator", "err", err)
 		return nil, err
@@ -648,9 +791,32 @@ func (t *Tribe) getNewValidators(chain consensus.ChainReader, header *types.Head
 	if err := t.abi[ValidatorsContractName].Unpack(&out, method, result); err != nil {
 		return []common.Address{}, err
 	}
+	if uint64(len(out)) > fork.MaxValidators {
+		out = out[:fork.MaxValidators]
+	}
 	sort.Sort(validatorsAscending(out))
 	return out, nil
 }
+
+// epochSeed returns the randomness used to elect the validators of the epoch
+// starting at header. Once the VRF mix fork is active on the parent it is the
+// accumulated mix of every VRF output up to and including the last block of the
+// round, so the epoch block sealer can no longer influence the election by
+// withholding its own block. Before that the epoch sealer's own VRF is used.
+func (t *Tribe) epochSeed(header, parent *types.Header) *big.Int {
+	if t.config.IsVRFMix(parent.Number) {
+		return new(big.Int).SetBytes(parent.MixDigest.Bytes())
+	}
+	vrf := header.Extra[extraVanity : extraVanity+extraVrf]
+	return new(big.Int).SetBytes(vrf)
+}
+
+// mixRandomness folds the VRF output of a block into the accumulated randomness
+// of its parent.
+func mixRandomness(parentMix common.Hash, vrf []byte) common.Hash {
+	return crypto.Keccak256Hash(parentMix.Bytes(), vrf[:32])
+}
+
 func (t *Tribe) punishValidator(val common.Address, chain consensus.ChainReader, header *types.Header, state *state.StateDB) error {
 	// method
 	method := "punishValidator"
@@ -662,12 +828,8 @@ func (t *Tribe) punishValidator(val common.Address, chain consensus.ChainReader,
 	log.Debug("tryPunishValidator", "addr=", val, "number=", header.Number.Uint64())
 	// call contract
 	nonce := state.GetNonce(header.Coinbase)
-	chainConfig := params.MainnetChainConfig
-	if params.IsTestnet() {
-		chainConfig = params.TestChainConfig
-	}
 	msg := vmcaller.NewLegacyMessage(header.Coinbase, &params.ValidatorsContractAddr, nonce, new(big.Int), new(big.Int).SetUint64(math.MaxUint64), new(big.Int), data, true)
-	if _, err := vmcaller.ExecuteMsg(msg, state, header, newChainContext(chain, t), chainConfig); err != nil {
+	if _, err := vmcaller.ExecuteMsg(msg, state, header, newChainContext(chain, t), chain.Config()); err != nil {
 		log.Error("Can't punish validator", "err", err)
 		return err
 	}
@@ -792,7 +954,8 @@ func (t *Tribe) snapshot(chain consensus.ChainReader, number uint64, hash common
 	return snap, err
 }
 func (t *Tribe) blockTimeVerify(snap *Snapshot, header, parent *types.Header) error {
-	if header.Time.Uint64() < parent.Time.Uint64()+t.config.Period+backOffTime(snap, header.Coinbase) {
+	fork := t.forkAt(header.Number)
+	if header.Time.Uint64() < parent.Time.Uint64()+fork.Period+backOffTime(snap, fork, header.Coinbase) {
 		return consensus.ErrFutureBlock
 	}
 	return nil
@@ -854,11 +1017,7 @@ func (t *Tribe) getBindInfo(chain consensus.ChainReader, header *types.Header, a
 	nonce := statedb.GetNonce(header.Coinbase)
 	msg := vmcaller.NewLegacyMessage(header.Coinbase, &params.ValidatorsContractAddr, nonce, new(big.Int), new(big.Int).SetUint64(math.MaxUint64), new(big.Int), data, false)
 
-	chainConfig := params.MainnetChainConfig
-	if params.IsTestnet() {
-		chainConfig = params.TestChainConfig
-	}
-	result, err := vmcaller.ExecuteMsg(msg, statedb, parent, newChainContext(chain, t), chainConfig)
+	result, err := vmcaller.ExecuteMsg(msg, statedb, parent, newChainContext(chain, t), chain.Config())
 	if err != nil {
 		log.Error("Can't decrease missed blocks counter for validator", "err", err)
 		return bindInfo{}, err
@@ -870,26 +1029,22 @@ func (t *Tribe) getBindInfo(chain consensus.ChainReader, header *types.Header, a
 	}
 	return out, nil
 }
-func (t *Tribe) accumulateAccountsBalance(chain consensus.ChainReader, header *types.Header, state *state.StateDB, blockReward *big.Int, addr common.Address) {
-	//get miner bind wallet for receive rewards
-	bindInfo, err := t.getBindInfo(chain, header, addr)
-	if err == nil {
-		addr = bindInfo.From
-	}
+func (t *Tribe) accumulateAccountsBalance(state *state.StateDB, blockReward *big.Int, addr common.Address) {
 	key := GetMESHBalanceKey(addr)
 	val := state.GetState(params.MeshContractAddress, key)
 	newVal := val.Big().Add(val.Big(), blockReward)
 	state.SetState(params.MeshContractAddress, key, common.BytesToHash(newVal.Bytes()))
 }
 
-func (t *Tribe) accumulatePOMRewards(chain consensus.ChainReader, state *state.StateDB, header *types.Header) {
-	// Select the correct block reward based on chain progression
-	blockReward := new(big.Int).Set(MeshRewardForPom)
-
-	number := new(big.Int).Set(header.Number)
-	number = number.Div(number, big.NewInt(int64(BlockRewardReducedInterval)))
-	blockReward = blockReward.Rsh(blockReward, uint(number.Int64()))
+// accumulatePomFee credits the base fee of a block to the MESH balance of the POM
+// contract, the pool the epoch POM rewards are distributed from. The fee is paid
+// in SMT and burned like before the fork, the pool receives it as MESH.
+func (t *Tribe) accumulatePomFee(state *state.StateDB, fee *big.Int) {
+	accumulateTotalBalance(state, fee)
+	t.accumulateAccountsBalance(state, fee, params.PomContractAddr)
+}
 
+func (t *Tribe) accumulatePOMRewards(chain consensus.ChainReader, state *state.StateDB, header *types.Header, blockReward *big.Int) {
 	accumulateTotalBalance(state, blockReward)
 
 	// Miner will send tx to deposit block rewards to pom contract, add to his balance first.
@@ -908,11 +1063,7 @@ func (t *Tribe) accumulatePOMRewards(chain consensus.ChainReader, state *state.S
 
 	nonce := state.GetNonce(header.Coinbase)
 	msg := vmcaller.NewLegacyMessage(header.Coinbase, &params.PomContractAddr, nonce, new(big.Int), new(big.Int).SetUint64(math.MaxUint64), new(big.Int), data, true)
-	chainConfig := params.MainnetChainConfig
-	if params.IsTestnet() {
-		chainConfig = params.TestChainConfig
-	}
-	if _, err := vmcaller.ExecuteMsg(msg, state, header, newChainContext(chain, t), chainConfig); err != nil {
+	if _, err := vmcaller.ExecuteMsg(msg, state, header, newChainContext(chain, t), chain.Config()); err != nil {
 		log.Error("can't ExecuteMsg", "err", err)
 		return
 	}
@@ -920,23 +1071,42 @@ func (t *Tribe) accumulatePOMRewards(chain consensus.ChainReader, state *state.S
 	return
 
 }
-func (t *Tribe) accumulateRewards(chain consensus.ChainReader, state *state.StateDB, header *types.Header) {
-	// Select the correct block reward based on chain progression
-	blockReward := new(big.Int).Set(MeshRewardForValidator)
-	number := new(big.Int).Set(header.Number)
-	number = number.Div(number, big.NewInt(int64(BlockRewardReducedInterval)))
-	blockReward = blockReward.Rsh(blockReward, uint(number.Int64()))
+func (t *Tribe) accumulateRewards(chain consensus.ChainReader, state *state.StateDB, header *types.Header) *BlockRewards {
+	rewards := t.blockRewards(chain, header)
 
-	accumulateTotalBalance(state, blockReward)
-	t.accumulateAccountsBalance(chain, header, state, blockReward, header.Coinbase)
+	accumulateTotalBalance(state, rewards.Reward)
+	t.accumulateAccountsBalance(state, rewards.Reward, rewards.Recipient)
 
 	//每个epoch最后一个区块发放pom奖励
-	if header.Number.Uint64()%t.config.Epoch == 0 {
-		t.accumulatePOMRewards(chain, state, header)
+	if rewards.PomReward.Sign() > 0 {
+		t.accumulatePOMRewards(chain, state, header, rewards.PomReward)
+	}
+	return rewards
+}
+
+// forkAt returns the consensus parameters in effect at the given block, with
+// the package defaults for everything the chain config doesn't schedule.
+func (t *Tribe) forkAt(number *big.Int) *params.TribeFork {
+	fork := t.config.ForkAt(number)
+	if fork.MaxValidators == 0 {
+		fork.MaxValidators = maxValidators
+	}
+	if fork.WiggleTime == 0 {
+		fork.WiggleTime = wiggleTime
+	}
+	if fork.BackOffTime == 0 {
+		fork.BackOffTime = initialBackOffTime
+	}
+	if fork.Reward == nil {
+		fork.Reward = MeshRewardForValidator
+	}
+	if fork.HalvingInterval == 0 {
+		fork.HalvingInterval = BlockRewardReducedInterval
 	}
+	return fork
 }
 
-func backOffTime(snap *Snapshot, val common.Address) uint64 {
+func backOffTime(snap *Snapshot, fork *params.TribeFork, val common.Address) uint64 {
 	if snap.inturn(val) {
 		return 0
 	} else {
@@ -955,7 +1125,7 @@ func backOffTime(snap *Snapshot, val common.Address) uint64 {
 		r.Shuffle(n, func(i, j int) {
 			backOffSteps[i], backOffSteps[j] = backOffSteps[j], backOffSteps[i]
 		})
-		delay := initialBackOffTime + backOffSteps[idx]*wiggleTime
+		delay := fork.BackOffTime + backOffSteps[idx]*fork.WiggleTime
 		return delay
 	}
 }
@@ -5,12 +5,21 @@ import (
 	"crypto/elliptic"
 	"crypto/rand"
 	"encoding/hex"
+	"math"
+	"math/big"
 	mrand "math/rand"
+	"reflect"
 	"testing"
 	"time"
 
 	"github.com/MeshBoxTech/mesh-chain/accounts/keystore"
+	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/consensus"
+	"github.com/MeshBoxTech/mesh-chain/core/state"
+	"github.com/MeshBoxTech/mesh-chain/core/types"
 	"github.com/MeshBoxTech/mesh-chain/crypto"
+	"github.com/MeshBoxTech/mesh-chain/ethdb"
+	"github.com/MeshBoxTech/mesh-chain/params"
 )
 
 func TestNormal(t *testing.T) {
@@ -97,4 +106,350 @@ func TestExtra(t *testing.T) {
 	t.Log(len(extra))
 }
 
+func TestEpochSeed(t *testing.T) {
+	tribe := New(nil, &params.TribeConfig{Period: 30, Epoch: 21, VRFMixBlock: big.NewInt(10)}, nil)
 
+	vrf := make([]byte, extraVrf)
+	vrf[0] = 0x01
+	extra := append(append(make([]byte, extraVanity), vrf...), make([]byte, extraSeal)...)
+
+	// Before the fork the epoch sealer's own VRF elects the validators
+	parent := &types.Header{Number: big.NewInt(8), MixDigest: common.HexToHash("0x02")}
+	header := &types.Header{Number: big.NewInt(9), Extra: extra}
+	if seed, want := tribe.epochSeed(header, parent), new(big.Int).SetBytes(vrf); seed.Cmp(want) != 0 {
+		t.Fatalf("pre-fork seed mismatch: have %x, want %x", seed, want)
+	}
+	// The first epoch after the fork still uses it, the parent mix is still empty
+	parent = &types.Header{Number: big.NewInt(9)}
+	header = &types.Header{Number: big.NewInt(10), Extra: extra}
+	if seed, want := tribe.epochSeed(header, parent), new(big.Int).SetBytes(vrf); seed.Cmp(want) != 0 {
+		t.Fatalf("fork block seed mismatch: have %x, want %x", seed, want)
+	}
+	// Afterwards the accumulated mix of the round is used, not the sealer's own output
+	parent = &types.Header{Number: big.NewInt(41), MixDigest: mixRandomness(common.HexToHash("0x02"), vrf)}
+	header = &types.Header{Number: big.NewInt(42), Extra: extra}
+	if seed, want := tribe.epochSeed(header, parent), parent.MixDigest.Big(); seed.Cmp(want) != 0 {
+		t.Fatalf("post-fork seed mismatch: have %x, want %x", seed, want)
+	}
+}
+
+// simulateEpochSeed models one round of the validator election: every block of
+// the round contributes the output of its sealer to the accumulated randomness
+// and the seed selects a single seat. If withhold is set, the in-turn sealer of
+// the last block in the round (the only one that learns the final seed before
+// anybody else) skips its slot whenever its own output doesn't elect it, and a
+// backoff validator seals in its place.
+func simulateEpochSeed(r *mrand.Rand, mix common.Hash, validators, epoch int, withhold bool) (common.Hash, int) {
+	output := func(validator int, input common.Hash) []byte {
+		return crypto.Keccak256([]byte{byte(validator)}, input.Bytes(), []byte{byte(r.Intn(256))})
+	}
+	elected := func(seed common.Hash) int {
+		return int(new(big.Int).Mod(seed.Big(), big.NewInt(int64(validators))).Int64())
+	}
+	for i := 0; i < epoch-1; i++ {
+		mix = mixRandomness(mix, output(r.Intn(validators), mix))
+	}
+	attacker := r.Intn(validators)
+	next := mixRandomness(mix, output(attacker, mix))
+	if withhold && elected(next) != attacker {
+		backoff := (attacker + 1 + r.Intn(validators-1)) % validators
+		next = mixRandomness(mix, output(backoff, mix))
+	}
+	if elected(next) == attacker {
+		return next, 1
+	}
+	return next, 0
+}
+
+// TestVRFMixSeedBias quantifies how much a single validator can bias the epoch
+// seed by withholding the last block of the round. Every other contribution is
+// unknown until it is sealed, so the best it can do is a one-time re-roll.
+func TestVRFMixSeedBias(t *testing.T) {
+	const (
+		validators = 21
+		epoch      = 21
+		rounds     = 5000
+	)
+	r := mrand.New(mrand.NewSource(1))
+
+	var honest, biased int
+	mix := common.Hash{}
+	for i := 0; i < rounds; i++ {
+		var won int
+		mix, won = simulateEpochSeed(r, mix, validators, epoch, false)
+		honest += won
+	}
+	mix = common.Hash{}
+	for i := 0; i < rounds; i++ {
+		var won int
+		mix, won = simulateEpochSeed(r, mix, validators, epoch, true)
+		biased += won
+	}
+	fair := 1.0 / validators
+	pHonest := float64(honest) / rounds
+	pBiased := float64(biased) / rounds
+	t.Logf("fair %.4f, honest %.4f, withholding %.4f", fair, pHonest, pBiased)
+
+	if math.Abs(pHonest-fair) > 0.01 {
+		t.Errorf("honest election skewed: have %.4f, want %.4f", pHonest, fair)
+	}
+	// One re-roll can at most double the chance: 1 - (1-p)^2
+	if bound := 1 - (1-fair)*(1-fair); pBiased > bound+0.01 {
+		t.Errorf("withholding bias above one re-roll: have %.4f, bound %.4f", pBiased, bound)
+	}
+}
+
+func TestSnapshotRecents(t *testing.T) {
+	keys := make([]*ecdsa.PrivateKey, 3)
+	validators := make([]common.Address, len(keys))
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		validators[i] = crypto.PubkeyToAddress(keys[i].PublicKey)
+	}
+	sealed := func(number int64, parent common.Hash, key *ecdsa.PrivateKey) *types.Header {
+		header := &types.Header{
+			ParentHash: parent,
+			Number:     big.NewInt(number),
+			Difficulty: new(big.Int),
+			GasLimit:   new(big.Int),
+			GasUsed:    new(big.Int),
+			Time:       new(big.Int),
+			Extra:      make([]byte, extraVanity+extraSeal),
+		}
+		sig, _ := crypto.Sign(sigHash(header).Bytes(), key)
+		copy(header.Extra[len(header.Extra)-extraSeal:], sig)
+		return header
+	}
+	tests := []struct {
+		fork    *big.Int
+		signers []int
+		err     error
+	}{
+		// Before the fork a validator may seal back to back
+		{nil, []int{0, 0, 0}, nil},
+		// Afterwards it has to wait len/2+1 blocks
+		{big.NewInt(0), []int{0, 1, 0}, nil},
+		{big.NewInt(0), []int{0, 0}, errRecentlySigned},
+		{big.NewInt(0), []int{0, 1, 1}, errRecentlySigned},
+		// Blocks before the fork don't count against the limit
+		{big.NewInt(2), []int{0, 0, 1, 0}, nil},
+		{big.NewInt(2), []int{0, 1, 1}, errRecentlySigned},
+	}
+	for i, tt := range tests {
+		config := &params.TribeConfig{Period: 30, Epoch: 30000, RecentsBlock: tt.fork}
+		tribe := New(nil, config, nil)
+		snap := newSnapshot(config, 0, common.Hash{}, validators)
+
+		var headers []*types.Header
+		parent := common.Hash{}
+		for j, signer := range tt.signers {
+			header := sealed(int64(j+1), parent, keys[signer])
+			headers = append(headers, header)
+			parent = header.Hash()
+		}
+		res, err := snap.apply(headers, nil, nil, tribe)
+		if err != tt.err {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if limit := uint64(len(validators)/2 + 1); uint64(len(res.Recents)) > limit {
+			t.Errorf("test %d: recents not trimmed: have %d, limit %d", i, len(res.Recents), limit)
+		}
+		last := len(tt.signers) - 1
+		if recent := res.signedRecently(validators[tt.signers[last]], uint64(last+2)); recent != (tt.fork != nil) {
+			t.Errorf("test %d: last signer recent mismatch: have %v, want %v", i, recent, tt.fork != nil)
+		}
+	}
+}
+
+func TestSnapshotRecentsStore(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	config := &params.TribeConfig{Period: 30, Epoch: 30000}
+
+	snap := newSnapshot(config, 7, common.HexToHash("0x07"), []common.Address{common.HexToAddress("0x01")})
+	snap.Recents[7] = common.HexToAddress("0x01")
+	if err := snap.store(db); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := loadSnapshot(config, db, snap.Hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded.Recents, snap.Recents) {
+		t.Fatalf("recents mismatch: have %v, want %v", loaded.Recents, snap.Recents)
+	}
+	cpy := loaded.copy()
+	delete(cpy.Recents, 7)
+	if len(loaded.Recents) != 1 {
+		t.Fatalf("copy shares recents with the original")
+	}
+}
+
+func TestForkAtDefaults(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	tribe := New(nil, &params.TribeConfig{
+		Period: 15,
+		Epoch:  21,
+		Forks:  []*params.TribeFork{{Block: big.NewInt(10), WiggleTime: 2, HalvingInterval: 1000}},
+	}, db)
+
+	before := tribe.forkAt(big.NewInt(9))
+	if before.MaxValidators != maxValidators || before.WiggleTime != wiggleTime || before.BackOffTime != initialBackOffTime ||
+		before.Reward.Cmp(MeshRewardForValidator) != 0 || before.HalvingInterval != BlockRewardReducedInterval {
+		t.Errorf("defaults mismatch before fork: %+v", before)
+	}
+	after := tribe.forkAt(big.NewInt(10))
+	if after.WiggleTime != 2 || after.HalvingInterval != 1000 || after.BackOffTime != initialBackOffTime || after.Period != 15 {
+		t.Errorf("parameters mismatch after fork: %+v", after)
+	}
+}
+
+// vrfHeader creates a header on top of parent sealed by key, carrying the VRF
+// output over msg if any and the mix digest derived from it.
+func vrfHeader(key *ecdsa.PrivateKey, parent *types.Header, msg []byte, mix func(vrf []byte) common.Hash) *types.Header {
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		UncleHash:  uncleHash,
+		Coinbase:   crypto.PubkeyToAddress(key.PublicKey),
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		Difficulty: new(big.Int).Set(diffInTurn),
+		GasLimit:   new(big.Int),
+		GasUsed:    new(big.Int),
+		Time:       big.NewInt(1),
+		Extra:      make([]byte, extraVanity),
+	}
+	var vrf []byte
+	if msg != nil {
+		vrf, _ = crypto.SimpleVRF2Bytes(key, msg)
+		header.Extra = append(header.Extra, vrf...)
+	}
+	header.Extra = append(header.Extra, make([]byte, extraSeal)...)
+	header.MixDigest = mix(vrf)
+
+	sig, _ := crypto.Sign(sigHash(header).Bytes(), key)
+	copy(header.Extra[len(header.Extra)-extraSeal:], sig)
+	return header
+}
+
+// Tests that the mix digest must be empty before the VRF mix fork, and is not
+// rejected by the standalone header checks afterwards.
+func TestVerifyHeaderMixDigest(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	tribe := New(nil, &params.TribeConfig{Period: 30, Epoch: 30000, VRFMixBlock: big.NewInt(10)}, nil)
+	chain := &testChainReader{config: params.TestChainConfig}
+
+	mix := func(hash common.Hash) func([]byte) common.Hash {
+		return func([]byte) common.Hash { return hash }
+	}
+	tests := []struct {
+		number int64
+		msg    []byte
+		mix    common.Hash
+		err    error
+	}{
+		// Before the fork the mix digest has to be empty
+		{9, nil, common.Hash{}, consensus.ErrUnknownAncestor},
+		{9, nil, common.HexToHash("0x01"), errInvalidMixDigest},
+		// Afterwards it carries the accumulated randomness, checked in verifySeal
+		{10, []byte{0x01}, common.Hash{}, consensus.ErrUnknownAncestor},
+		{10, []byte{0x01}, common.HexToHash("0x01"), consensus.ErrUnknownAncestor},
+	}
+	for i, tt := range tests {
+		parent := &types.Header{Number: big.NewInt(tt.number - 1)}
+		header := vrfHeader(key, parent, tt.msg, mix(tt.mix))
+		if err := tribe.verifyHeader(chain, header, nil); err != tt.err {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+}
+
+// Tests that the mix digest of a sealed header is ignored before the VRF mix
+// fork, and has to fold the VRF output into the parent's mix digest afterwards.
+func TestVerifySealMixDigest(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	signer := crypto.PubkeyToAddress(key.PublicKey)
+	parentMix := common.HexToHash("0x02")
+
+	fixed := func(mix common.Hash) func([]byte) common.Hash {
+		return func([]byte) common.Hash { return mix }
+	}
+	fold := func(mix common.Hash) func([]byte) common.Hash {
+		return func(vrf []byte) common.Hash { return mixRandomness(mix, vrf) }
+	}
+	tests := []struct {
+		number int64
+		msg    []byte
+		mix    func(vrf []byte) common.Hash
+		err    error
+	}{
+		// Before the fork blocks outside of the epoch carry no VRF, the mix
+		// digest is left to the header checks
+		{6, nil, fixed(common.Hash{}), nil},
+		{6, nil, fixed(parentMix), nil},
+		// Afterwards it has to fold the VRF into the mix of the parent
+		{12, parentMix.Bytes(), fixed(common.Hash{}), errInvalidVrfMix},
+		{12, parentMix.Bytes(), fixed(parentMix), errInvalidVrfMix},
+		{12, parentMix.Bytes(), fold(common.Hash{}), errInvalidVrfMix},
+	}
+	for i, tt := range tests {
+		db, _ := ethdb.NewMemDatabase()
+		config := &params.TribeConfig{Period: 30, Epoch: 5, VRFMixBlock: big.NewInt(10)}
+		tribe := New(nil, config, db)
+
+		parent := &types.Header{Number: big.NewInt(tt.number - 1), MixDigest: parentMix}
+		tribe.recents.Add(parent.Hash(), newSnapshot(config, parent.Number.Uint64(), parent.Hash(), []common.Address{signer}))
+
+		header := vrfHeader(key, parent, tt.msg, tt.mix)
+		if err := tribe.verifySeal(nil, header, []*types.Header{parent}); err != tt.err {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+}
+
+// Tests that the base fee of a block is burned before the POM fee fork and
+// credited in MESH to the POM reward pool after it, leaving its SMT balance be.
+func TestFinalizePomFee(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	chain := &testChainReader{config: params.TestChainConfig}
+
+	tests := []struct {
+		fork *big.Int
+		fee  int64
+	}{
+		{nil, 0},
+		{big.NewInt(6), 0},
+		{big.NewInt(5), 21000 * 7},
+		{big.NewInt(1), 21000 * 7},
+	}
+	for i, tt := range tests {
+		tribe := newEvidenceTestTribe()
+		tribe.config.PomFeeBlock = tt.fork
+
+		db, _ := ethdb.NewMemDatabase()
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+		statedb.SetCode(params.MeshContractAddress, []byte{0x00}) // keep the token from being swept as empty
+
+		header := sealedHeader(key, 5, 100)
+		header.Difficulty = diffInTurn
+		header.BaseFee = big.NewInt(7)
+		header.GasUsed = big.NewInt(21000)
+		reward := tribe.blockRewards(chain, header).Reward
+
+		if _, err := tribe.Finalize(chain, header, statedb, nil, nil, nil); err != nil {
+			t.Fatalf("test %d: failed to finalize: %v", i, err)
+		}
+		if pool := statedb.GetState(params.MeshContractAddress, GetMESHBalanceKey(params.PomContractAddr)).Big(); pool.Int64() != tt.fee {
+			t.Errorf("test %d: POM pool mismatch: have %v, want %d", i, pool, tt.fee)
+		}
+		total := statedb.GetState(params.MeshContractAddress, params.TotalMeshHash).Big()
+		if want := new(big.Int).Add(reward, big.NewInt(tt.fee)); total.Cmp(want) != 0 {
+			t.Errorf("test %d: total MESH mismatch: have %v, want %v", i, total, want)
+		}
+		if balance := statedb.GetBalance(params.PomContractAddr); balance.Sign() != 0 {
+			t.Errorf("test %d: POM contract credited in SMT: %v", i, balance)
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/MeshBoxTech/mesh-chain/common"
 	"github.com/MeshBoxTech/mesh-chain/common/hexutil"
 	"github.com/MeshBoxTech/mesh-chain/consensus"
+	"github.com/MeshBoxTech/mesh-chain/core"
 	"github.com/MeshBoxTech/mesh-chain/core/types"
 	"github.com/MeshBoxTech/mesh-chain/ethdb"
 	"github.com/MeshBoxTech/mesh-chain/params"
@@ -36,8 +37,8 @@ var (
 	diffInTurn           = big.NewInt(2) // Block difficulty for in-turn Sub
 	diffNoTurn           = big.NewInt(1) // Block difficulty for out-of-turn Other
 	// less than SIP100 <<<<<<<<<<<<<
-	diff          = int64(6) // SIP100 max diff is 6
-	maxValidators = 21
+	diff          = int64(6)   // SIP100 max diff is 6
+	maxValidators = uint64(21) // Default until a tribe fork schedules otherwise
 )
 
 // Various error messages to mark blocks invalid. These should be private to
@@ -51,6 +52,10 @@ var (
 	// errUnauthorizedValidator is returned if a header is signed by a non-authorized entity.
 	errUnauthorizedValidator = errors.New("unauthorized validator")
 
+	// errRecentlySigned is returned if a header is signed by an authorized entity
+	// that already signed a header recently, thus is temporarily not allowed to.
+	errRecentlySigned = errors.New("recently signed")
+
 	// errUnknownBlock is returned when the list of signers is requested for a block
 	// that is not part of the local blockchain.
 	errUnknownBlock = errors.New("unknown block")
@@ -81,6 +86,14 @@ var (
 	// errInvalidMixDigest is returned if a block's mix digest is non-zero.
 	errInvalidMixDigest = errors.New("non-zero mix digest")
 
+	// errMissingVrf is returned if a block that must carry a VRF contribution
+	// doesn't have room for the 161 byte proof in its extra-data.
+	errMissingVrf = errors.New("extra-data 161 byte vrf missing")
+
+	// errInvalidVrfMix is returned if a block's mix digest is not the parent's
+	// accumulated randomness mixed with the block's own VRF output.
+	errInvalidVrfMix = errors.New("invalid vrf mix digest")
+
 	// errInvalidUncleHash is returned if a block contains an non-empty uncle list.
 	errInvalidUncleHash = errors.New("non empty uncle hash")
 
@@ -107,6 +120,10 @@ var (
 	// errInvalidCoinbase is returned if the coinbase isn't the validator of the block.
 	errInvalidCoinbase = errors.New("Invalid coin base")
 
+	// errInvalidEvidence is returned if a block submits double-sign evidence
+	// whose headers weren't both sealed by the accused validator at the same height.
+	errInvalidEvidence = errors.New("invalid double sign evidence")
+
 	// errInvalidValidatorLen is returned if validators length is zero or bigger than maxValidators.
 	errInvalidValidatorsLength = errors.New("Invalid validators length")
 
@@ -123,7 +140,7 @@ var (
 	ErrTribeChiefTxSignerAndBlockSignerNotMatch = errors.New("tribe chief update tx signer and block signer not match")
 	ErrTribeValdateTxSenderCannotInSignerList   = errors.New("tx sender cannot in signerlist")
 
-	BlockRewardReducedInterval = 2102400
+	BlockRewardReducedInterval = uint64(2102400)
 	MeshRewardForValidator, _  = new(big.Int).SetString("114000000000000000000", 10)   //Block reward in wei for successfully mining a block
 	MeshRewardForPom, _        = new(big.Int).SetString("17580000000000000000000", 10) //Block reward in wei for successfully mining a block
 )
@@ -134,13 +151,18 @@ type Tribe struct {
 	sigcache *lru.ARCCache       // mapping block.hash -> signer
 	//Status   *TribeStatus
 	//SealErrorCounter uint32     // less then 3 , retry commit new work
-	isInit  bool
-	lock    sync.Mutex
-	stateFn StateFn            // Function to get state by state root
-	abi     map[string]abi.ABI // Interactive with system contracts
-	recents *lru.ARCCache      // Snapshots for recent block to speed up reorgs
-	db      ethdb.Database     // Database to store and retrieve snapshot checkpoints
-	nodeKey *ecdsa.PrivateKey  //miner
+	isInit       bool
+	lock         sync.Mutex
+	stateFn      StateFn            // Function to get state by state root
+	abi          map[string]abi.ABI // Interactive with system contracts
+	recents      *lru.ARCCache      // Snapshots for recent block to speed up reorgs
+	seals        *lru.ARCCache      // Headers sealed by each validator per height to detect double signing
+	punished     *lru.ARCCache      // Double signs already punished on chain, skipped when submitting evidence
+	rewards      *lru.ARCCache      // Rewards of the recently finalized blocks, pending their write to the chain
+	statsIndexer *core.ChainIndexer // Validator statistics of the canonical chain, nil if not indexed
+	txPool       *core.TxPool       // Transaction pool to submit bind transactions to
+	db           ethdb.Database     // Database to store and retrieve snapshot checkpoints
+	nodeKey      *ecdsa.PrivateKey  //miner
 }
 
 type API struct {
@@ -28,5 +28,5 @@ func ExecuteMsg(msg core.Message, state *state.StateDB, header *types.Header, ch
 
 // NewLegacyMessage builds a message for consensus and system governance actions, it will not consumes any fee.
 func NewLegacyMessage(from common.Address, to *common.Address, nonce uint64, amount *big.Int, gasLimit *big.Int, gasPrice *big.Int, data []byte, checkNonce bool) types.Message {
-	return types.NewMessage(from, to, nonce, amount, gasLimit, gasPrice, data, checkNonce)
+	return types.NewMessage(from, to, nonce, amount, gasLimit, gasPrice, data, nil, checkNonce)
 }
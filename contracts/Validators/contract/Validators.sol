@@ -14,8 +14,15 @@ contract Validators is POC,Anmap,Ownable{
 
 	uint256 constant epoch = 21;//出块周期，21个出块人为一个周期
 	uint256 constant disableNumber = 2880;//黑名单锁定区块时间
+	uint256 constant doubleSignDisableNumber = 20160;//双签黑名单锁定区块时间
     bool public initialized;//初始化标记
 
+	//双签处罚记录 矿工地址 => 区块号 => 是否已处罚
+	mapping(address => mapping(uint256 => bool)) public doubleSignPunished;
+
+	//双签事件
+	event DoubleSign(address indexed miner, uint256 blockNumber);
+
 	modifier onlyMiner() virtual {
 		require(msg.sender == block.coinbase, "Miner only");
 		_;
@@ -31,6 +38,20 @@ contract Validators is POC,Anmap,Ownable{
 		}
 	}
 
+	//当前出块节点提交双签证据, 证据中的两个区块头由共识层校验
+	function punishDoubleSign(address minerAddress, uint256 blockNumber, bytes calldata headerA, bytes calldata headerB) external onlyMiner {
+		require(!doubleSignPunished[minerAddress][blockNumber], "Already punished");
+		require(keccak256(headerA) != keccak256(headerB), "Identical headers");
+
+		doubleSignPunished[minerAddress][blockNumber] = true;
+
+		//只能禁用POS节点
+		if(minerMap[minerAddress].node_type == 2){
+			stopAndDisable(minerAddress, block.number + doubleSignDisableNumber);
+		}
+		emit DoubleSign(minerAddress, blockNumber);
+	}
+
 	//在当前出块周期的最后一个区块充值签名人列表
 	function getNewValidators(uint256 randNum) external view returns(address[] memory){
 
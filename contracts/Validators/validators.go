@@ -0,0 +1,186 @@
+// Copyright 2018 The mesh-chain Authors
+// This file is part of the mesh-chain library.
+//
+// The mesh-chain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The mesh-chain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the mesh-chain library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package validators is a client of the Validators system contract, which
+// keeps the PoA and PoS validators of the tribe consensus.
+package validators
+
+//go:generate abigen --abi contract/Validators.abi --pkg contract --type Validators --out contract/validators.go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/MeshBoxTech/mesh-chain"
+	"github.com/MeshBoxTech/mesh-chain/accounts/abi/bind"
+	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/contracts/Validators/contract"
+	"github.com/MeshBoxTech/mesh-chain/core/types"
+	"github.com/MeshBoxTech/mesh-chain/crypto"
+)
+
+// Node types of the validators.
+const (
+	PoaNode = 1 // Validator added by the contract owner
+	PosNode = 2 // Validator that made a deposit
+)
+
+var errInvalidResult = errors.New("invalid contract result")
+
+// Validator is the state of a validator in the contract.
+type Validator struct {
+	Address      common.Address `json:"address"`
+	Owner        common.Address `json:"owner"`
+	Amount       *big.Int       `json:"amount"`
+	NodeType     uint64         `json:"nodeType"`
+	StopBlock    *big.Int       `json:"stopBlock"`    // Block the validator was stopped at, zero if sealing
+	RecoverBlock *big.Int       `json:"recoverBlock"` // Block a punished validator may start again at, zero if never punished
+}
+
+// Stopped returns whether the validator is not sealing.
+func (v *Validator) Stopped() bool {
+	return v.StopBlock.Sign() > 0
+}
+
+// Punished returns whether the validator is still disabled by a punishment at
+// the given block.
+func (v *Validator) Punished(number *big.Int) bool {
+	return v.RecoverBlock.Cmp(number) > 0
+}
+
+// Validators wraps the generated binding of the Validators contract.
+type Validators struct {
+	*contract.Validators
+}
+
+// NewValidators binds the Validators contract deployed at address.
+func NewValidators(address common.Address, backend bind.ContractBackend) (*Validators, error) {
+	validators, err := contract.NewValidators(address, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &Validators{validators}, nil
+}
+
+// List returns all the validators known to the contract, stopped ones first.
+func (v *Validators) List(opts *bind.CallOptsWithNumber) ([]*Validator, error) {
+	addrs, amounts, stopBlocks, nodeTypes, recoverBlocks, owners, err := v.GetAllValidators(opts)
+	if err != nil {
+		return nil, err
+	}
+	n := len(addrs)
+	if len(amounts) != n || len(stopBlocks) != n || len(nodeTypes) != n || len(recoverBlocks) != n || len(owners) != n {
+		return nil, errInvalidResult
+	}
+	list := make([]*Validator, n)
+	for i := range list {
+		list[i] = &Validator{
+			Address:      addrs[i],
+			Owner:        owners[i],
+			Amount:       amounts[i],
+			NodeType:     nodeTypes[i].Uint64(),
+			StopBlock:    stopBlocks[i],
+			RecoverBlock: recoverBlocks[i],
+		}
+	}
+	return list, nil
+}
+
+// Punishments returns the validators that have been punished by the consensus
+// engine, either for missed slots or for double signing.
+func (v *Validators) Punishments(opts *bind.CallOptsWithNumber) ([]*Validator, error) {
+	list, err := v.List(opts)
+	if err != nil {
+		return nil, err
+	}
+	var punished []*Validator
+	for _, validator := range list {
+		if validator.RecoverBlock.Sign() > 0 {
+			punished = append(punished, validator)
+		}
+	}
+	return punished, nil
+}
+
+// BindSig is a node key signature over the account binding or unbinding the
+// node, as produced by tribe_bindSign.
+type BindSig struct {
+	Node common.Address // Address of the node key, recovered from the signature
+	V    uint8
+	R    [32]byte
+	S    [32]byte
+}
+
+// NewBindSig recovers the node from its signature over account and splits the
+// signature into the form the contract expects.
+func NewBindSig(account common.Address, sig []byte) (*BindSig, error) {
+	if len(sig) != 65 {
+		return nil, fmt.Errorf("invalid signature length %d", len(sig))
+	}
+	sig = common.CopyBytes(sig)
+	if sig[64] >= 27 {
+		sig[64] -= 27
+	}
+	pubkey, err := crypto.SigToPub(crypto.Keccak256(account.Bytes()), sig)
+	if err != nil {
+		return nil, err
+	}
+	bs := &BindSig{Node: crypto.PubkeyToAddress(*pubkey), V: sig[64] + 27}
+	copy(bs.R[:], sig[:32])
+	copy(bs.S[:], sig[32:64])
+	return bs, nil
+}
+
+// OfflineTransactor is a bind.ContractTransactor that signs transactions with
+// preset nonce, gas price and gas limit instead of asking a node, collecting
+// them rather than sending them.
+type OfflineTransactor struct {
+	Nonce    uint64
+	GasPrice *big.Int
+	GasLimit *big.Int
+
+	Signed []*types.Transaction // Transactions signed through the transactor
+}
+
+// PendingCodeAt implements bind.ContractTransactor. The code of the system
+// contracts is always there, so a non-empty placeholder is returned.
+func (t *OfflineTransactor) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	return []byte{0}, nil
+}
+
+// PendingNonceAt implements bind.ContractTransactor, returning the next nonce.
+func (t *OfflineTransactor) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return t.Nonce + uint64(len(t.Signed)), nil
+}
+
+// SuggestGasPrice implements bind.ContractTransactor.
+func (t *OfflineTransactor) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return t.GasPrice, nil
+}
+
+// EstimateGas implements bind.ContractTransactor.
+func (t *OfflineTransactor) EstimateGas(ctx context.Context, call ethereum.CallMsg) (*big.Int, error) {
+	return t.GasLimit, nil
+}
+
+// SendTransaction implements bind.ContractTransactor, keeping the signed
+// transaction for the caller to publish.
+func (t *OfflineTransactor) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	t.Signed = append(t.Signed, tx)
+	return nil
+}
@@ -0,0 +1,110 @@
+// Copyright 2018 The mesh-chain Authors
+// This file is part of the mesh-chain library.
+//
+// The mesh-chain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The mesh-chain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the mesh-chain library. If not, see <http://www.gnu.org/licenses/>.
+
+package validators
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/MeshBoxTech/mesh-chain/accounts/abi/bind"
+	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/contracts/Validators/contract"
+	"github.com/MeshBoxTech/mesh-chain/core/types"
+	"github.com/MeshBoxTech/mesh-chain/crypto"
+	"github.com/MeshBoxTech/mesh-chain/params"
+)
+
+func TestNewBindSig(t *testing.T) {
+	nodekey, _ := crypto.GenerateKey()
+	account := common.HexToAddress("0x01")
+
+	// tribe_bindSign returns the plain [R || S || V] signature with V in 0/1
+	sig, _ := crypto.Sign(crypto.Keccak256(account.Bytes()), nodekey)
+	bs, err := NewBindSig(account, sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node := crypto.PubkeyToAddress(nodekey.PublicKey); bs.Node != node {
+		t.Errorf("node mismatch: have %x, want %x", bs.Node, node)
+	}
+	if bs.V != sig[64]+27 {
+		t.Errorf("v mismatch: have %d, want %d", bs.V, sig[64]+27)
+	}
+	if common.BytesToHash(sig[:32]) != common.Hash(bs.R) || common.BytesToHash(sig[32:64]) != common.Hash(bs.S) {
+		t.Error("r and s mismatch")
+	}
+	// Signatures over another account recover another node
+	other, err := NewBindSig(common.HexToAddress("0x02"), sig)
+	if err == nil && other.Node == bs.Node {
+		t.Error("signature recovered the node for another account")
+	}
+	if _, err := NewBindSig(account, sig[:64]); err == nil {
+		t.Error("short signature: no error")
+	}
+}
+
+func TestOfflineTransactor(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	offline := &OfflineTransactor{Nonce: 7, GasPrice: big.NewInt(1), GasLimit: big.NewInt(100000)}
+	transactor, err := contract.NewValidatorsTransactor(params.ValidatorsContractAddr, offline)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := bind.NewKeyedTransactor(key)
+	opts.ChainId = params.TestnetChainConfig.ChainId
+
+	validators := []common.Address{common.HexToAddress("0x01")}
+	for i := 0; i < 2; i++ {
+		tx, err := transactor.OwnerAddPoaNode(opts, validators)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tx.Nonce() != 7+uint64(i) {
+			t.Errorf("tx %d: nonce mismatch: have %d, want %d", i, tx.Nonce(), 7+i)
+		}
+		if tx.Gas().Cmp(offline.GasLimit) != 0 || tx.GasPrice().Cmp(offline.GasPrice) != 0 {
+			t.Errorf("tx %d: gas mismatch: have %v at %v", i, tx.Gas(), tx.GasPrice())
+		}
+		if *tx.To() != params.ValidatorsContractAddr {
+			t.Errorf("tx %d: recipient mismatch: have %x", i, tx.To())
+		}
+		from, err := types.Sender(types.NewEIP155Signer(opts.ChainId), tx)
+		if err != nil || from != opts.From {
+			t.Errorf("tx %d: sender mismatch: have %x, %v", i, from, err)
+		}
+	}
+	if len(offline.Signed) != 2 {
+		t.Errorf("signed transactions mismatch: have %d, want 2", len(offline.Signed))
+	}
+}
+
+// Tests that a transaction isn't signed for a guessed network if neither the
+// options nor the offline transactor know the chain id.
+func TestOfflineTransactorNoChainID(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	offline := &OfflineTransactor{Nonce: 7, GasPrice: big.NewInt(1), GasLimit: big.NewInt(100000)}
+	transactor, err := contract.NewValidatorsTransactor(params.ValidatorsContractAddr, offline)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := transactor.OwnerAddPoaNode(bind.NewKeyedTransactor(key), []common.Address{common.HexToAddress("0x01")}); err != bind.ErrNoChainID {
+		t.Fatalf("error mismatch: have %v, want %v", err, bind.ErrNoChainID)
+	}
+	if len(offline.Signed) != 0 {
+		t.Errorf("signed transactions mismatch: have %d, want 0", len(offline.Signed))
+	}
+}
@@ -90,6 +90,7 @@ var ContractParams = &Params{contract.ChequebookBin, contract.ChequebookABI}
 type Chequebook struct {
 	path     string                      // path to chequebook file
 	prvKey   *ecdsa.PrivateKey           // private key to sign cheque with
+	chainId  *big.Int                    // chain id to sign transactions for
 	lock     sync.Mutex                  //
 	backend  Backend                     // blockchain API
 	quit     chan bool                   // when closed causes autodeposit to stop
@@ -113,8 +114,8 @@ func (self *Chequebook) String() string {
 	return fmt.Sprintf("contract: %s, owner: %s, balance: %v, signer: %x", self.contractAddr.Hex(), self.owner.Hex(), self.balance, self.prvKey.PublicKey)
 }
 
-// NewChequebook creates a new Chequebook.
-func NewChequebook(path string, contractAddr common.Address, prvKey *ecdsa.PrivateKey, backend Backend) (self *Chequebook, err error) {
+// NewChequebook creates a new Chequebook, sending transactions for the given chain id.
+func NewChequebook(path string, contractAddr common.Address, prvKey *ecdsa.PrivateKey, chainId *big.Int, backend Backend) (self *Chequebook, err error) {
 	balance := new(big.Int)
 	sent := make(map[common.Address]*big.Int)
 
@@ -122,7 +123,7 @@ func NewChequebook(path string, contractAddr common.Address, prvKey *ecdsa.Priva
 	if err != nil {
 		return nil, err
 	}
-	transactOpts := bind.NewKeyedTransactor(prvKey)
+	transactOpts := bind.NewKeyedTransactorWithChainID(prvKey, chainId)
 	session := &contract.ChequebookSession{
 		Contract:     chbook,
 		TransactOpts: *transactOpts,
@@ -130,6 +131,7 @@ func NewChequebook(path string, contractAddr common.Address, prvKey *ecdsa.Priva
 
 	self = &Chequebook{
 		prvKey:       prvKey,
+		chainId:      chainId,
 		balance:      balance,
 		contractAddr: contractAddr,
 		sent:         sent,
@@ -158,13 +160,13 @@ func (self *Chequebook) setBalanceFromBlockChain() {
 }
 
 // LoadChequebook loads a chequebook from disk (file path).
-func LoadChequebook(path string, prvKey *ecdsa.PrivateKey, backend Backend, checkBalance bool) (self *Chequebook, err error) {
+func LoadChequebook(path string, prvKey *ecdsa.PrivateKey, chainId *big.Int, backend Backend, checkBalance bool) (self *Chequebook, err error) {
 	var data []byte
 	data, err = ioutil.ReadFile(path)
 	if err != nil {
 		return
 	}
-	self, _ = NewChequebook(path, common.Address{}, prvKey, backend)
+	self, _ = NewChequebook(path, common.Address{}, prvKey, chainId, backend)
 
 	err = json.Unmarshal(data, self)
 	if err != nil {
@@ -336,7 +338,7 @@ func (self *Chequebook) Deposit(amount *big.Int) (string, error) {
 // The caller must hold self.lock.
 func (self *Chequebook) deposit(amount *big.Int) (string, error) {
 	// since the amount is variable here, we do not use sessions
-	depositTransactor := bind.NewKeyedTransactor(self.prvKey)
+	depositTransactor := bind.NewKeyedTransactorWithChainID(self.prvKey, self.chainId)
 	depositTransactor.Value = amount
 	chbookRaw := &contract.ChequebookRaw{Contract: self.contract}
 	tx, err := chbookRaw.Transfer(depositTransactor)
@@ -444,8 +446,9 @@ type Inbox struct {
 }
 
 // NewInbox creates an Inbox. An Inboxes is not persisted, the cumulative sum is updated
-// from blockchain when first cheque is received.
-func NewInbox(prvKey *ecdsa.PrivateKey, contractAddr, beneficiary common.Address, signer *ecdsa.PublicKey, abigen bind.ContractBackend) (self *Inbox, err error) {
+// from blockchain when first cheque is received. Cheques are cashed in transactions
+// for the given chain id.
+func NewInbox(prvKey *ecdsa.PrivateKey, chainId *big.Int, contractAddr, beneficiary common.Address, signer *ecdsa.PublicKey, abigen bind.ContractBackend) (self *Inbox, err error) {
 	if signer == nil {
 		return nil, fmt.Errorf("signer is null")
 	}
@@ -453,7 +456,7 @@ func NewInbox(prvKey *ecdsa.PrivateKey, contractAddr, beneficiary common.Address
 	if err != nil {
 		return nil, err
 	}
-	transactOpts := bind.NewKeyedTransactor(prvKey)
+	transactOpts := bind.NewKeyedTransactorWithChainID(prvKey, chainId)
 	transactOpts.GasLimit = gasToCash
 	session := &contract.ChequebookSession{
 		Contract:     chbook,
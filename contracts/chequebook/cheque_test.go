@@ -67,7 +67,7 @@ func TestIssueAndReceive(t *testing.T) {
 	if err != nil {
 		t.Fatalf("deploy contract: expected no error, got %v", err)
 	}
-	chbook, err := NewChequebook(path, addr0, key0, backend)
+	chbook, err := NewChequebook(path, addr0, key0, nil, backend)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -92,7 +92,7 @@ func TestIssueAndReceive(t *testing.T) {
 		t.Errorf("expected: %v, got %v", "0", chbook.Balance())
 	}
 
-	chbox, err := NewInbox(key1, addr0, addr1, &key0.PublicKey, backend)
+	chbox, err := NewInbox(key1, nil, addr0, addr1, &key0.PublicKey, backend)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -111,7 +111,7 @@ func TestIssueAndReceive(t *testing.T) {
 func TestCheckbookFile(t *testing.T) {
 	path := filepath.Join(os.TempDir(), "chequebook-test.json")
 	backend := newTestBackend()
-	chbook, err := NewChequebook(path, addr0, key0, backend)
+	chbook, err := NewChequebook(path, addr0, key0, nil, backend)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -120,7 +120,7 @@ func TestCheckbookFile(t *testing.T) {
 
 	chbook.Save()
 
-	chbook, err = LoadChequebook(path, key0, backend, false)
+	chbook, err = LoadChequebook(path, key0, nil, backend, false)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -149,14 +149,14 @@ func TestVerifyErrors(t *testing.T) {
 	if err != nil {
 		t.Errorf("expected no error, got %v", err)
 	}
-	chbook0, err := NewChequebook(path0, contr0, key0, backend)
+	chbook0, err := NewChequebook(path0, contr0, key0, nil, backend)
 	if err != nil {
 		t.Errorf("expected no error, got %v", err)
 	}
 
 	path1 := filepath.Join(os.TempDir(), "chequebook-test-1.json")
 	contr1, _ := deploy(key1, common.Big2, backend)
-	chbook1, err := NewChequebook(path1, contr1, key1, backend)
+	chbook1, err := NewChequebook(path1, contr1, key1, nil, backend)
 	if err != nil {
 		t.Errorf("expected no error, got %v", err)
 	}
@@ -170,7 +170,7 @@ func TestVerifyErrors(t *testing.T) {
 		t.Fatalf("expected no error, got %v", err)
 	}
 
-	chbox, err := NewInbox(key1, contr0, addr1, &key0.PublicKey, backend)
+	chbox, err := NewInbox(key1, nil, contr0, addr1, &key0.PublicKey, backend)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -224,7 +224,7 @@ func TestDeposit(t *testing.T) {
 	backend := newTestBackend()
 	contr0, _ := deploy(key0, new(big.Int), backend)
 
-	chbook, err := NewChequebook(path0, contr0, key0, backend)
+	chbook, err := NewChequebook(path0, contr0, key0, nil, backend)
 	if err != nil {
 		t.Errorf("expected no error, got %v", err)
 	}
@@ -363,7 +363,7 @@ func TestCash(t *testing.T) {
 	backend := newTestBackend()
 	contr0, _ := deploy(key0, common.Big2, backend)
 
-	chbook, err := NewChequebook(path, contr0, key0, backend)
+	chbook, err := NewChequebook(path, contr0, key0, nil, backend)
 	if err != nil {
 		t.Errorf("expected no error, got %v", err)
 	}
@@ -375,7 +375,7 @@ func TestCash(t *testing.T) {
 		t.Fatalf("expected no error, got %v", err)
 	}
 	backend.Commit()
-	chbox, err := NewInbox(key1, contr0, addr1, &key0.PublicKey, backend)
+	chbox, err := NewInbox(key1, nil, contr0, addr1, &key0.PublicKey, backend)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
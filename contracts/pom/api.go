@@ -0,0 +1,140 @@
+// Copyright 2018 The mesh-chain Authors
+// This file is part of the mesh-chain library.
+//
+// The mesh-chain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The mesh-chain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the mesh-chain library. If not, see <http://www.gnu.org/licenses/>.
+
+package pom
+
+import (
+	"context"
+	"strings"
+
+	"github.com/MeshBoxTech/mesh-chain/accounts"
+	"github.com/MeshBoxTech/mesh-chain/accounts/abi"
+	"github.com/MeshBoxTech/mesh-chain/accounts/abi/bind"
+	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/common/hexutil"
+	"github.com/MeshBoxTech/mesh-chain/contracts/pom/contract"
+	"github.com/MeshBoxTech/mesh-chain/core/types"
+	"github.com/MeshBoxTech/mesh-chain/params"
+)
+
+// PrivatePOMAPI offers the POM challenge lifecycle of the local node: listing
+// the MeshBox devices and challenges, checking a challenger's cooldown and
+// submitting the witnessed receipts. It is private as it signs with the
+// unlocked accounts of the node.
+type PrivatePOMAPI struct {
+	pom           *POM
+	abi           abi.ABI
+	am            *accounts.Manager
+	config        *params.ChainConfig
+	currentHeader func() *types.Header
+}
+
+// NewPrivatePOMAPI creates the POM API over the system contract of the chain.
+func NewPrivatePOMAPI(backend bind.ContractBackend, am *accounts.Manager, config *params.ChainConfig, currentHeader func() *types.Header) (*PrivatePOMAPI, error) {
+	pom, err := NewPOM(params.PomContractAddr, backend)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := abi.JSON(strings.NewReader(contract.POMABI))
+	if err != nil {
+		return nil, err
+	}
+	return &PrivatePOMAPI{
+		pom:           pom,
+		abi:           parsed,
+		am:            am,
+		config:        config,
+		currentHeader: currentHeader,
+	}, nil
+}
+
+// MeshBoxes returns the MeshBox devices registered in the network.
+func (api *PrivatePOMAPI) MeshBoxes(ctx context.Context) ([]MeshBox, error) {
+	return api.pom.MeshBoxes(ctx, api.currentHeader().Number)
+}
+
+// Challenges returns the receipts submitted in the current epoch.
+func (api *PrivatePOMAPI) Challenges(ctx context.Context) ([]Challenge, error) {
+	return api.pom.Challenges(ctx, api.currentHeader().Number)
+}
+
+// Cooldown returns when challenger may pick its next target and until when the
+// receipt of its last one is accepted.
+func (api *PrivatePOMAPI) Cooldown(ctx context.Context, challenger common.Address) (*Cooldown, error) {
+	return api.pom.Cooldown(ctx, challenger, api.currentHeader().Number)
+}
+
+// WitnessHash returns the hash a witness has to sign to attest target.
+func (api *PrivatePOMAPI) WitnessHash(target common.Address) common.Hash {
+	return WitnessHash(target)
+}
+
+// ReceiptArgs is the sendPOMReceipt call built from the witness signatures.
+type ReceiptArgs struct {
+	To        common.Address   `json:"to"`
+	Witnesses []common.Address `json:"witnesses"`
+	Data      hexutil.Bytes    `json:"data"`
+}
+
+// BuildReceipt verifies the witness signatures over target and returns the
+// call data of the sendPOMReceipt transaction, to be signed elsewhere.
+func (api *PrivatePOMAPI) BuildReceipt(target common.Address, sigs []hexutil.Bytes) (*ReceiptArgs, error) {
+	receipt, err := newReceipt(target, sigs)
+	if err != nil {
+		return nil, err
+	}
+	data, err := api.abi.Pack("sendPOMReceipt", receipt.Target, receipt.V, receipt.R, receipt.S)
+	if err != nil {
+		return nil, err
+	}
+	return &ReceiptArgs{To: params.PomContractAddr, Witnesses: receipt.Witnesses, Data: data}, nil
+}
+
+// SendReceipt verifies the witness signatures over target and submits them in
+// a sendPOMReceipt transaction signed by the unlocked challenger account.
+func (api *PrivatePOMAPI) SendReceipt(ctx context.Context, challenger common.Address, target common.Address, sigs []hexutil.Bytes) (common.Hash, error) {
+	receipt, err := newReceipt(target, sigs)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	account := accounts.Account{Address: challenger}
+	wallet, err := api.am.Find(account)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	opts := &bind.TransactOpts{
+		From:    challenger,
+		ChainId: api.config.ChainId,
+		Signer: func(signer types.Signer, address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			return wallet.SignTx(account, tx, api.config.ChainId)
+		},
+		Context: ctx,
+	}
+	tx, err := api.pom.SendPOMReceipt(opts, receipt.Target, receipt.V, receipt.R, receipt.S)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return tx.Hash(), nil
+}
+
+// newReceipt is NewReceipt over the signatures as received over RPC.
+func newReceipt(target common.Address, sigs []hexutil.Bytes) (*Receipt, error) {
+	raw := make([][]byte, len(sigs))
+	for i, sig := range sigs {
+		raw[i] = sig
+	}
+	return NewReceipt(target, raw)
+}
@@ -0,0 +1,317 @@
+// Copyright 2018 The mesh-chain Authors
+// This file is part of the mesh-chain library.
+//
+// The mesh-chain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The mesh-chain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the mesh-chain library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package pom is a client of the proof of MeshBox (POM) system contract.
+package pom
+
+//go:generate abigen --abi contract/Pom.abi --pkg contract --type POM --out contract/pom.go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/MeshBoxTech/mesh-chain"
+	"github.com/MeshBoxTech/mesh-chain/accounts/abi/bind"
+	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/contracts/pom/contract"
+	"github.com/MeshBoxTech/mesh-chain/crypto"
+)
+
+// The abi package can't unpack struct arrays, so the selectors of the list
+// getters are called directly and their results decoded by hand.
+var (
+	getMeshBoxListId   = crypto.Keccak256([]byte("getMeshBoxList()"))[:4]
+	getChallengeListId = crypto.Keccak256([]byte("getChallengeList()"))[:4]
+)
+
+var (
+	errInvalidResult    = errors.New("invalid contract result")
+	errNoWitness        = errors.New("no witness signatures")
+	errDuplicateWitness = errors.New("duplicate witness")
+)
+
+// MeshBox is a device registered in the POM contract.
+type MeshBox struct {
+	Id   string         `json:"id"`
+	Addr common.Address `json:"addr"`
+}
+
+// Challenge is a POM receipt submitted during the current epoch.
+type Challenge struct {
+	Challenger common.Address   `json:"challenger"`
+	Target     common.Address   `json:"target"`
+	Witness    []common.Address `json:"witness"`
+}
+
+// Cooldown is the challenge schedule of a challenger at a given block.
+type Cooldown struct {
+	LastChallengeBlock *big.Int `json:"lastChallengeBlock"` // Block the last target was picked at
+	ChallengeInterval  *big.Int `json:"challengeInterval"`  // Blocks to wait between two challenges
+	NextChallengeBlock *big.Int `json:"nextChallengeBlock"` // First block a new target may be picked at
+	ReceiptDeadline    *big.Int `json:"receiptDeadline"`    // Last block the receipt of the last target is accepted at
+	Ready              bool     `json:"ready"`              // Whether a new target may be picked at the queried block
+}
+
+// POM wraps the generated binding of the POM contract with the calls it can't
+// express.
+type POM struct {
+	*contract.POM
+	address common.Address
+	backend bind.ContractBackend
+}
+
+// NewPOM binds the POM contract deployed at address.
+func NewPOM(address common.Address, backend bind.ContractBackend) (*POM, error) {
+	pom, err := contract.NewPOM(address, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &POM{POM: pom, address: address, backend: backend}, nil
+}
+
+// call invokes a parameterless contract method at the given block, nil meaning
+// the latest one.
+func (p *POM) call(ctx context.Context, id []byte, number *big.Int) ([]byte, error) {
+	return p.backend.CallContract(ctx, ethereum.CallMsg{To: &p.address, Data: id}, number)
+}
+
+// MeshBoxes returns the MeshBox devices registered at the given block.
+func (p *POM) MeshBoxes(ctx context.Context, number *big.Int) ([]MeshBox, error) {
+	output, err := p.call(ctx, getMeshBoxListId, number)
+	if err != nil {
+		return nil, err
+	}
+	return decodeMeshBoxes(output)
+}
+
+// Challenges returns the POM receipts submitted in the epoch of the given block.
+func (p *POM) Challenges(ctx context.Context, number *big.Int) ([]Challenge, error) {
+	output, err := p.call(ctx, getChallengeListId, number)
+	if err != nil {
+		return nil, err
+	}
+	return decodeChallenges(output)
+}
+
+// Cooldown returns the challenge schedule of challenger as of the given block.
+func (p *POM) Cooldown(ctx context.Context, challenger common.Address, number *big.Int) (*Cooldown, error) {
+	opts := &bind.CallOptsWithNumber{CallOpts: bind.CallOpts{Context: ctx}, Number: number}
+	last, err := p.GetLastChallengeBlock(opts, challenger)
+	if err != nil {
+		return nil, err
+	}
+	interval, err := p.ChallengeInterval(opts)
+	if err != nil {
+		return nil, err
+	}
+	delay, err := p.ChallengeDelay(opts)
+	if err != nil {
+		return nil, err
+	}
+	return newCooldown(last, interval, delay, number), nil
+}
+
+// newCooldown computes the challenge schedule from the contract parameters.
+func newCooldown(last, interval, delay, number *big.Int) *Cooldown {
+	next := new(big.Int).Add(last, interval)
+	return &Cooldown{
+		LastChallengeBlock: last,
+		ChallengeInterval:  interval,
+		NextChallengeBlock: next,
+		ReceiptDeadline:    new(big.Int).Add(last, delay),
+		Ready:              number.Cmp(next) >= 0,
+	}
+}
+
+// WitnessHash returns the hash a witness signs to attest that it has seen
+// target, the same the contract recovers the witness from.
+func WitnessHash(target common.Address) common.Hash {
+	return crypto.Keccak256Hash(target.Bytes())
+}
+
+// Receipt is the input of a sendPOMReceipt call.
+type Receipt struct {
+	Target    common.Address
+	Witnesses []common.Address
+	V         []uint8
+	R         [][32]byte
+	S         [][32]byte
+}
+
+// NewReceipt splits the witness signatures over WitnessHash(target) into the
+// form the contract expects, recovering the witnesses to reject invalid or
+// repeated signatures before they are paid for on chain.
+func NewReceipt(target common.Address, sigs [][]byte) (*Receipt, error) {
+	if len(sigs) == 0 {
+		return nil, errNoWitness
+	}
+	var (
+		hash    = WitnessHash(target)
+		receipt = &Receipt{Target: target}
+		seen    = make(map[common.Address]bool)
+	)
+	for i, sig := range sigs {
+		if len(sig) != 65 {
+			return nil, fmt.Errorf("signature %d: invalid length %d", i, len(sig))
+		}
+		sig = common.CopyBytes(sig)
+		if sig[64] >= 27 {
+			sig[64] -= 27
+		}
+		pubkey, err := crypto.SigToPub(hash.Bytes(), sig)
+		if err != nil {
+			return nil, fmt.Errorf("signature %d: %v", i, err)
+		}
+		witness := crypto.PubkeyToAddress(*pubkey)
+		if seen[witness] {
+			return nil, fmt.Errorf("signature %d: %v %x", i, errDuplicateWitness, witness)
+		}
+		seen[witness] = true
+
+		var r, s [32]byte
+		copy(r[:], sig[:32])
+		copy(s[:], sig[32:64])
+
+		receipt.Witnesses = append(receipt.Witnesses, witness)
+		receipt.V = append(receipt.V, sig[64]+27)
+		receipt.R = append(receipt.R, r)
+		receipt.S = append(receipt.S, s)
+	}
+	return receipt, nil
+}
+
+// word returns the 32 byte word at offset of the output.
+func word(output []byte, offset uint64) ([]byte, error) {
+	if offset > uint64(len(output)) || uint64(len(output))-offset < 32 {
+		return nil, errInvalidResult
+	}
+	return output[offset : offset+32], nil
+}
+
+// readUint reads the word at offset as a pointer or length within the output,
+// neither of which can exceed the size of the output itself.
+func readUint(output []byte, offset uint64) (uint64, error) {
+	w, err := word(output, offset)
+	if err != nil {
+		return 0, err
+	}
+	v := new(big.Int).SetBytes(w)
+	if v.Cmp(big.NewInt(int64(len(output)))) > 0 {
+		return 0, errInvalidResult
+	}
+	return v.Uint64(), nil
+}
+
+// readAddress reads the word at offset as an address.
+func readAddress(output []byte, offset uint64) (common.Address, error) {
+	w, err := word(output, offset)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return common.BytesToAddress(w), nil
+}
+
+// readArray follows the pointer at offset, relative to base, to a dynamic
+// array and returns the offset of its first element with its length.
+func readArray(output []byte, base, offset uint64) (uint64, uint64, error) {
+	ptr, err := readUint(output, base+offset)
+	if err != nil {
+		return 0, 0, err
+	}
+	size, err := readUint(output, base+ptr)
+	if err != nil {
+		return 0, 0, err
+	}
+	start := base + ptr + 32
+	if size > (uint64(len(output))-start)/32 {
+		return 0, 0, errInvalidResult
+	}
+	return start, size, nil
+}
+
+// readString follows the pointer at offset, relative to base, to a string.
+func readString(output []byte, base, offset uint64) (string, error) {
+	ptr, err := readUint(output, base+offset)
+	if err != nil {
+		return "", err
+	}
+	size, err := readUint(output, base+ptr)
+	if err != nil {
+		return "", err
+	}
+	start := base + ptr + 32
+	if start > uint64(len(output)) || size > uint64(len(output))-start {
+		return "", errInvalidResult
+	}
+	return string(output[start : start+size]), nil
+}
+
+// decodeMeshBoxes decodes the MeshBoxInfo[] returned by getMeshBoxList.
+func decodeMeshBoxes(output []byte) ([]MeshBox, error) {
+	start, size, err := readArray(output, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	boxes := make([]MeshBox, size)
+	for i := range boxes {
+		elem, err := readUint(output, start+uint64(i)*32)
+		if err != nil {
+			return nil, err
+		}
+		if boxes[i].Id, err = readString(output, start+elem, 0); err != nil {
+			return nil, err
+		}
+		if boxes[i].Addr, err = readAddress(output, start+elem+32); err != nil {
+			return nil, err
+		}
+	}
+	return boxes, nil
+}
+
+// decodeChallenges decodes the POMChallenge[] returned by getChallengeList.
+func decodeChallenges(output []byte) ([]Challenge, error) {
+	start, size, err := readArray(output, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	challenges := make([]Challenge, size)
+	for i := range challenges {
+		elem, err := readUint(output, start+uint64(i)*32)
+		if err != nil {
+			return nil, err
+		}
+		base := start + elem
+		if challenges[i].Challenger, err = readAddress(output, base); err != nil {
+			return nil, err
+		}
+		if challenges[i].Target, err = readAddress(output, base+32); err != nil {
+			return nil, err
+		}
+		witnesses, count, err := readArray(output, base, 64)
+		if err != nil {
+			return nil, err
+		}
+		challenges[i].Witness = make([]common.Address, count)
+		for j := range challenges[i].Witness {
+			if challenges[i].Witness[j], err = readAddress(output, witnesses+uint64(j)*32); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return challenges, nil
+}
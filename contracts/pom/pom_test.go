@@ -0,0 +1,138 @@
+// Copyright 2018 The mesh-chain Authors
+// This file is part of the mesh-chain library.
+//
+// The mesh-chain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The mesh-chain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the mesh-chain library. If not, see <http://www.gnu.org/licenses/>.
+
+package pom
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/crypto"
+)
+
+// words concatenates ABI words made of integers, addresses and short strings.
+func words(values ...interface{}) []byte {
+	var out []byte
+	for _, v := range values {
+		switch v := v.(type) {
+		case int:
+			out = append(out, common.LeftPadBytes(big.NewInt(int64(v)).Bytes(), 32)...)
+		case common.Address:
+			out = append(out, common.LeftPadBytes(v.Bytes(), 32)...)
+		case string:
+			out = append(out, common.RightPadBytes([]byte(v), 32)...)
+		}
+	}
+	return out
+}
+
+func TestDecodeMeshBoxes(t *testing.T) {
+	a, b := common.HexToAddress("0x01"), common.HexToAddress("0x02")
+	output := words(
+		0x20, 2, 0x40, 0xc0,
+		0x40, a, 4, "box1",
+		0x40, b, 4, "box2",
+	)
+	boxes, err := decodeMeshBoxes(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []MeshBox{{"box1", a}, {"box2", b}}
+	if !reflect.DeepEqual(boxes, want) {
+		t.Errorf("meshboxes mismatch: have %v, want %v", boxes, want)
+	}
+	if boxes, err := decodeMeshBoxes(words(0x20, 0)); err != nil || len(boxes) != 0 {
+		t.Errorf("empty list: have %v, %v", boxes, err)
+	}
+	// Outputs cut before the end of the last string must be rejected
+	for i := 0; i <= len(output)-32; i += 16 {
+		if _, err := decodeMeshBoxes(output[:i]); err == nil {
+			t.Errorf("truncated to %d bytes: no error", i)
+		}
+	}
+	if _, err := decodeMeshBoxes(words(0x20, 1<<20)); err == nil {
+		t.Error("oversized list: no error")
+	}
+}
+
+func TestDecodeChallenges(t *testing.T) {
+	challenger, target := common.HexToAddress("0x01"), common.HexToAddress("0x02")
+	w1, w2 := common.HexToAddress("0x03"), common.HexToAddress("0x04")
+	output := words(
+		0x20, 1, 0x20,
+		challenger, target, 0x60,
+		2, w1, w2,
+	)
+	challenges, err := decodeChallenges(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Challenge{{challenger, target, []common.Address{w1, w2}}}
+	if !reflect.DeepEqual(challenges, want) {
+		t.Errorf("challenges mismatch: have %v, want %v", challenges, want)
+	}
+	if _, err := decodeChallenges(output[:len(output)-32]); err == nil {
+		t.Error("truncated witnesses: no error")
+	}
+}
+
+func TestNewReceipt(t *testing.T) {
+	target := common.HexToAddress("0x01")
+	key1, _ := crypto.GenerateKey()
+	key2, _ := crypto.GenerateKey()
+
+	sig1, _ := crypto.Sign(WitnessHash(target).Bytes(), key1)
+	sig2, _ := crypto.Sign(WitnessHash(target).Bytes(), key2)
+	sig2[64] += 27 // witnesses signing with web3 produce 27/28
+
+	receipt, err := NewReceipt(target, [][]byte{sig1, sig2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	witnesses := []common.Address{crypto.PubkeyToAddress(key1.PublicKey), crypto.PubkeyToAddress(key2.PublicKey)}
+	if !reflect.DeepEqual(receipt.Witnesses, witnesses) {
+		t.Errorf("witnesses mismatch: have %x, want %x", receipt.Witnesses, witnesses)
+	}
+	for i, v := range receipt.V {
+		if v != 27 && v != 28 {
+			t.Errorf("signature %d: invalid v %d", i, v)
+		}
+	}
+	if common.BytesToHash(sig1[:32]) != common.Hash(receipt.R[0]) || common.BytesToHash(sig1[32:64]) != common.Hash(receipt.S[0]) {
+		t.Error("signature 0: r and s mismatch")
+	}
+	if _, err := NewReceipt(target, [][]byte{sig1, sig1}); err == nil {
+		t.Error("duplicate witness: no error")
+	}
+	if _, err := NewReceipt(target, nil); err != errNoWitness {
+		t.Errorf("no witness: have %v, want %v", err, errNoWitness)
+	}
+	if _, err := NewReceipt(target, [][]byte{sig1[:64]}); err == nil {
+		t.Error("short signature: no error")
+	}
+}
+
+func TestCooldown(t *testing.T) {
+	cooldown := newCooldown(big.NewInt(100), big.NewInt(120), big.NewInt(20), big.NewInt(219))
+	if cooldown.NextChallengeBlock.Int64() != 220 || cooldown.ReceiptDeadline.Int64() != 120 || cooldown.Ready {
+		t.Errorf("cooldown mismatch: %+v", cooldown)
+	}
+	if !newCooldown(big.NewInt(100), big.NewInt(120), big.NewInt(20), big.NewInt(220)).Ready {
+		t.Error("challenger not ready after the interval")
+	}
+}
@@ -0,0 +1,344 @@
+// Copyright 2018 The mesh-chain Authors
+// This file is part of the mesh-chain library.
+//
+// The mesh-chain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The mesh-chain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the mesh-chain library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+
+	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/compression/rle"
+	"github.com/MeshBoxTech/mesh-chain/core/types"
+	"github.com/MeshBoxTech/mesh-chain/crypto"
+	"github.com/MeshBoxTech/mesh-chain/log"
+	"github.com/MeshBoxTech/mesh-chain/rlp"
+)
+
+// ArchiveVersion is the version of the chain archive format written by
+// ExportArchive.
+const ArchiveVersion = 1
+
+// archiveChunkSize is the number of blocks in a checksummed chunk of a chain
+// archive.
+const archiveChunkSize = 512
+
+// archiveMagic marks the start of a chain archive, telling it apart from the
+// plain RLP block stream written by Export.
+var archiveMagic = []byte("MESHCHAIN-ARCHIVE")
+
+var (
+	// ErrArchiveChecksum is returned if the payload of an archive chunk doesn't
+	// match its checksum.
+	ErrArchiveChecksum = errors.New("archive chunk checksum mismatch")
+
+	// ErrArchiveTruncated is returned if an archive ends before the last block
+	// announced in its header.
+	ErrArchiveTruncated = errors.New("archive truncated")
+)
+
+// ArchiveCompression is the algorithm the chunks of a chain archive are
+// compressed with.
+type ArchiveCompression uint64
+
+const (
+	ArchiveCompressionNone ArchiveCompression = iota
+	ArchiveCompressionGzip
+	ArchiveCompressionRLE
+)
+
+var archiveCompressionNames = map[ArchiveCompression]string{
+	ArchiveCompressionNone: "none",
+	ArchiveCompressionGzip: "gzip",
+	ArchiveCompressionRLE:  "rle",
+}
+
+func (c ArchiveCompression) String() string {
+	if name, ok := archiveCompressionNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", uint64(c))
+}
+
+// ParseArchiveCompression returns the compression algorithm of the given name.
+func ParseArchiveCompression(name string) (ArchiveCompression, error) {
+	for c, n := range archiveCompressionNames {
+		if n == name {
+			return c, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown archive compression %q", name)
+}
+
+// compress compresses a chunk payload.
+func (c ArchiveCompression) compress(data []byte) ([]byte, error) {
+	switch c {
+	case ArchiveCompressionNone:
+		return data, nil
+	case ArchiveCompressionGzip:
+		buf := new(bytes.Buffer)
+		w := gzip.NewWriter(buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case ArchiveCompressionRLE:
+		return rle.Compress(data), nil
+	}
+	return nil, fmt.Errorf("unknown archive compression %v", c)
+}
+
+// decompress decompresses a chunk payload.
+func (c ArchiveCompression) decompress(data []byte) ([]byte, error) {
+	switch c {
+	case ArchiveCompressionNone:
+		return data, nil
+	case ArchiveCompressionGzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		return ioutil.ReadAll(r)
+	case ArchiveCompressionRLE:
+		return rle.Decompress(data)
+	}
+	return nil, fmt.Errorf("unknown archive compression %v", c)
+}
+
+// ArchiveHeader describes the content of a chain archive.
+type ArchiveHeader struct {
+	Version     uint64
+	ChainId     *big.Int
+	Genesis     common.Hash // Hash of the genesis block of the exported chain
+	First       uint64      // Number of the first block in the archive
+	Last        uint64      // Number of the last block in the archive
+	Compression ArchiveCompression
+	Receipts    bool // Whether the blocks are accompanied by their receipts
+}
+
+// ArchiveChunk is a checksummed run of consecutive blocks of a chain archive.
+type ArchiveChunk struct {
+	First    uint64
+	Count    uint64
+	Last     common.Hash // Hash of the last block, to skip present chunks without decoding them
+	Checksum common.Hash // Keccak256 hash of the payload
+	Payload  []byte      // Compressed RLP list of the archive entries
+}
+
+// ArchiveEntry is a block of a chain archive, along with its receipts if the
+// archive carries them.
+type ArchiveEntry struct {
+	Block    *types.Block
+	Receipts []*types.ReceiptForStorage
+}
+
+// Decode verifies the checksum of the chunk and decodes its entries.
+func (c *ArchiveChunk) Decode(compression ArchiveCompression) ([]ArchiveEntry, error) {
+	if crypto.Keccak256Hash(c.Payload) != c.Checksum {
+		return nil, fmt.Errorf("blocks #%d-#%d: %v", c.First, c.First+c.Count-1, ErrArchiveChecksum)
+	}
+	data, err := compression.decompress(c.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("blocks #%d-#%d: %v", c.First, c.First+c.Count-1, err)
+	}
+	var entries []ArchiveEntry
+	if err := rlp.DecodeBytes(data, &entries); err != nil {
+		return nil, fmt.Errorf("blocks #%d-#%d: %v", c.First, c.First+c.Count-1, err)
+	}
+	if len(entries) == 0 || uint64(len(entries)) != c.Count {
+		return nil, fmt.Errorf("blocks #%d-#%d: have %d blocks", c.First, c.First+c.Count-1, len(entries))
+	}
+	for i, entry := range entries {
+		if number := entry.Block.NumberU64(); number != c.First+uint64(i) {
+			return nil, fmt.Errorf("blocks #%d-#%d: block #%d out of order", c.First, c.First+c.Count-1, number)
+		}
+	}
+	if hash := entries[len(entries)-1].Block.Hash(); hash != c.Last {
+		return nil, fmt.Errorf("blocks #%d-#%d: last hash mismatch: have %x, want %x", c.First, c.First+c.Count-1, hash, c.Last)
+	}
+	return entries, nil
+}
+
+// ArchiveWriter writes a chain archive, cutting the appended blocks into
+// checksummed chunks.
+type ArchiveWriter struct {
+	w       io.Writer
+	header  ArchiveHeader
+	next    uint64         // Number of the next block expected
+	entries []ArchiveEntry // Entries of the chunk being assembled
+}
+
+// NewArchiveWriter writes the header of a chain archive and returns a writer to
+// append its blocks with.
+func NewArchiveWriter(w io.Writer, header ArchiveHeader) (*ArchiveWriter, error) {
+	if header.First > header.Last {
+		return nil, fmt.Errorf("first (%d) is greater than last (%d)", header.First, header.Last)
+	}
+	header.Version = ArchiveVersion
+	if _, err := w.Write(archiveMagic); err != nil {
+		return nil, err
+	}
+	if err := rlp.Encode(w, &header); err != nil {
+		return nil, err
+	}
+	return &ArchiveWriter{w: w, header: header, next: header.First}, nil
+}
+
+// Append adds the next block of the archive, with its receipts if the archive
+// carries them.
+func (aw *ArchiveWriter) Append(block *types.Block, receipts types.Receipts) error {
+	if number := block.NumberU64(); number != aw.next || number > aw.header.Last {
+		return fmt.Errorf("unexpected block #%d, want #%d", number, aw.next)
+	}
+	entry := ArchiveEntry{Block: block, Receipts: []*types.ReceiptForStorage{}}
+	if aw.header.Receipts {
+		for _, receipt := range receipts {
+			entry.Receipts = append(entry.Receipts, (*types.ReceiptForStorage)(receipt))
+		}
+	}
+	aw.entries = append(aw.entries, entry)
+	aw.next++
+
+	if len(aw.entries) >= archiveChunkSize || aw.next > aw.header.Last {
+		return aw.flush()
+	}
+	return nil
+}
+
+// Close checks that all the blocks announced in the header were appended.
+func (aw *ArchiveWriter) Close() error {
+	if aw.next <= aw.header.Last {
+		return fmt.Errorf("archive incomplete: have #%d, want #%d", aw.next-1, aw.header.Last)
+	}
+	return nil
+}
+
+// flush writes out the chunk being assembled.
+func (aw *ArchiveWriter) flush() error {
+	data, err := rlp.EncodeToBytes(aw.entries)
+	if err != nil {
+		return err
+	}
+	payload, err := aw.header.Compression.compress(data)
+	if err != nil {
+		return err
+	}
+	chunk := &ArchiveChunk{
+		First:    aw.entries[0].Block.NumberU64(),
+		Count:    uint64(len(aw.entries)),
+		Last:     aw.entries[len(aw.entries)-1].Block.Hash(),
+		Checksum: crypto.Keccak256Hash(payload),
+		Payload:  payload,
+	}
+	aw.entries = aw.entries[:0]
+	return rlp.Encode(aw.w, chunk)
+}
+
+// IsArchive reports whether the stream starts with a chain archive, without
+// consuming anything from it.
+func IsArchive(r *bufio.Reader) bool {
+	magic, err := r.Peek(len(archiveMagic))
+	return err == nil && bytes.Equal(magic, archiveMagic)
+}
+
+// ArchiveReader reads the chunks of a chain archive.
+type ArchiveReader struct {
+	Header ArchiveHeader
+
+	stream *rlp.Stream
+	next   uint64 // Number of the first block of the next chunk
+}
+
+// NewArchiveReader reads the header of a chain archive and returns a reader
+// for its chunks.
+func NewArchiveReader(r io.Reader) (*ArchiveReader, error) {
+	magic := make([]byte, len(archiveMagic))
+	if _, err := io.ReadFull(r, magic); err != nil || !bytes.Equal(magic, archiveMagic) {
+		return nil, errors.New("not a chain archive")
+	}
+	ar := &ArchiveReader{stream: rlp.NewStream(r, 0)}
+	if err := ar.stream.Decode(&ar.Header); err != nil {
+		return nil, fmt.Errorf("invalid archive header: %v", err)
+	}
+	if ar.Header.Version != ArchiveVersion {
+		return nil, fmt.Errorf("unsupported archive version %d, want %d", ar.Header.Version, ArchiveVersion)
+	}
+	ar.next = ar.Header.First
+	return ar, nil
+}
+
+// ReadChunk returns the next chunk of the archive, without verifying its
+// payload. It returns io.EOF once all the blocks of the archive were read.
+func (ar *ArchiveReader) ReadChunk() (*ArchiveChunk, error) {
+	if ar.next > ar.Header.Last {
+		return nil, io.EOF
+	}
+	chunk := new(ArchiveChunk)
+	if err := ar.stream.Decode(chunk); err == io.EOF || err == io.ErrUnexpectedEOF || err == rlp.ErrValueTooLarge {
+		return nil, fmt.Errorf("%v at block #%d", ErrArchiveTruncated, ar.next)
+	} else if err != nil {
+		return nil, fmt.Errorf("invalid chunk at block #%d: %v", ar.next, err)
+	}
+	if chunk.First != ar.next || chunk.Count == 0 || chunk.First+chunk.Count-1 > ar.Header.Last {
+		return nil, fmt.Errorf("unexpected chunk #%d+%d at block #%d", chunk.First, chunk.Count, ar.next)
+	}
+	ar.next += chunk.Count
+	return chunk, nil
+}
+
+// ExportArchive writes the blocks between first and last of the active chain as
+// a chain archive, along with their receipts if requested.
+func (bc *BlockChain) ExportArchive(w io.Writer, first uint64, last uint64, compression ArchiveCompression, receipts bool) error {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+
+	aw, err := NewArchiveWriter(w, ArchiveHeader{
+		ChainId:     bc.config.ChainId,
+		Genesis:     bc.genesisBlock.Hash(),
+		First:       first,
+		Last:        last,
+		Compression: compression,
+		Receipts:    receipts,
+	})
+	if err != nil {
+		return fmt.Errorf("export failed: %v", err)
+	}
+	log.Info("Exporting archive of blocks", "count", last-first+1, "compression", compression, "receipts", receipts)
+
+	for nr := first; nr <= last; nr++ {
+		block := bc.GetBlockByNumber(nr)
+		if block == nil {
+			return fmt.Errorf("export failed on #%d: not found", nr)
+		}
+		var blockReceipts types.Receipts
+		if receipts {
+			blockReceipts = GetBlockReceipts(bc.chainDb, block.Hash(), nr)
+		}
+		if err := aw.Append(block, blockReceipts); err != nil {
+			return err
+		}
+	}
+	return aw.Close()
+}
@@ -0,0 +1,165 @@
+// Copyright 2018 The mesh-chain Authors
+// This file is part of the mesh-chain library.
+//
+// The mesh-chain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The mesh-chain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the mesh-chain library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/consensus/ethash"
+	"github.com/MeshBoxTech/mesh-chain/core/types"
+	"github.com/MeshBoxTech/mesh-chain/core/vm"
+	"github.com/MeshBoxTech/mesh-chain/ethdb"
+	"github.com/MeshBoxTech/mesh-chain/params"
+)
+
+// newArchiveTestChain creates a chain of the given length, with a receipt
+// stored for every tenth block.
+func newArchiveTestChain(t *testing.T, n int) (*Genesis, *BlockChain) {
+	var (
+		gspec = &Genesis{Config: params.TestChainConfig}
+		db, _ = ethdb.NewMemDatabase()
+	)
+	genesis := gspec.MustCommit(db)
+	blocks, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, n, nil)
+
+	chain, err := NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatal(err)
+	}
+	for _, block := range blocks {
+		if block.NumberU64()%10 == 0 {
+			receipt := types.NewReceipt(nil, false, new(big.Int).SetUint64(block.NumberU64()))
+			WriteBlockReceipts(db, block.Hash(), block.NumberU64(), types.Receipts{receipt})
+		}
+	}
+	return gspec, chain
+}
+
+// readArchive decodes all the entries of an archive.
+func readArchive(r io.Reader) (*ArchiveHeader, []ArchiveEntry, error) {
+	ar, err := NewArchiveReader(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	var entries []ArchiveEntry
+	for {
+		chunk, err := ar.ReadChunk()
+		if err == io.EOF {
+			return &ar.Header, entries, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		decoded, err := chunk.Decode(ar.Header.Compression)
+		if err != nil {
+			return nil, nil, err
+		}
+		entries = append(entries, decoded...)
+	}
+}
+
+func TestArchiveRoundtrip(t *testing.T) {
+	gspec, chain := newArchiveTestChain(t, 2*archiveChunkSize+10)
+	defer chain.Stop()
+
+	for _, compression := range []ArchiveCompression{ArchiveCompressionNone, ArchiveCompressionGzip, ArchiveCompressionRLE} {
+		buf := new(bytes.Buffer)
+		if err := chain.ExportArchive(buf, 0, chain.CurrentBlock().NumberU64(), compression, true); err != nil {
+			t.Fatalf("%v: export failed: %v", compression, err)
+		}
+		if !IsArchive(bufio.NewReader(bytes.NewReader(buf.Bytes()))) {
+			t.Fatalf("%v: archive not recognized", compression)
+		}
+		header, entries, err := readArchive(buf)
+		if err != nil {
+			t.Fatalf("%v: failed to read archive: %v", compression, err)
+		}
+		if header.Genesis != chain.Genesis().Hash() || header.ChainId.Cmp(gspec.Config.ChainId) != 0 || !header.Receipts {
+			t.Errorf("%v: header mismatch: have %+v", compression, header)
+		}
+		if uint64(len(entries)) != chain.CurrentBlock().NumberU64()+1 {
+			t.Fatalf("%v: block count mismatch: have %d, want %d", compression, len(entries), chain.CurrentBlock().NumberU64()+1)
+		}
+		for i, entry := range entries {
+			block := chain.GetBlockByNumber(uint64(i))
+			if entry.Block.Hash() != block.Hash() {
+				t.Fatalf("%v: block #%d mismatch: have %x, want %x", compression, i, entry.Block.Hash(), block.Hash())
+			}
+			want := len(GetBlockReceipts(chain.chainDb, block.Hash(), block.NumberU64()))
+			if len(entry.Receipts) != want {
+				t.Fatalf("%v: block #%d receipts mismatch: have %d, want %d", compression, i, len(entry.Receipts), want)
+			}
+			if want > 0 && entry.Receipts[0].CumulativeGasUsed.Uint64() != uint64(i) {
+				t.Fatalf("%v: block #%d receipt mismatch: have %v, want %d", compression, i, entry.Receipts[0].CumulativeGasUsed, i)
+			}
+		}
+		// The exported blocks must be importable into a fresh chain
+		db, _ := ethdb.NewMemDatabase()
+		gspec.MustCommit(db)
+		imported, _ := NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{})
+		blocks := make(types.Blocks, 0, len(entries)-1)
+		for _, entry := range entries[1:] {
+			blocks = append(blocks, entry.Block)
+		}
+		if _, err := imported.InsertChain(blocks); err != nil {
+			t.Fatalf("%v: import failed: %v", compression, err)
+		}
+		if imported.CurrentBlock().Hash() != chain.CurrentBlock().Hash() {
+			t.Errorf("%v: imported head mismatch", compression)
+		}
+		imported.Stop()
+	}
+}
+
+func TestArchiveCorruption(t *testing.T) {
+	_, chain := newArchiveTestChain(t, archiveChunkSize+10)
+	defer chain.Stop()
+
+	buf := new(bytes.Buffer)
+	if err := chain.ExportArchive(buf, 1, chain.CurrentBlock().NumberU64(), ArchiveCompressionGzip, false); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	archive := buf.Bytes()
+
+	// A flipped bit in the payload of the last chunk is caught by the checksum
+	corrupt := common.CopyBytes(archive)
+	corrupt[len(corrupt)-10] ^= 0x01
+	if _, _, err := readArchive(bytes.NewReader(corrupt)); err == nil || !strings.Contains(err.Error(), ErrArchiveChecksum.Error()) {
+		t.Errorf("corruption error mismatch: have %v, want %v", err, ErrArchiveChecksum)
+	}
+	// A missing chunk is reported as truncation
+	if _, _, err := readArchive(bytes.NewReader(archive[:len(archive)-100])); err == nil || !strings.Contains(err.Error(), ErrArchiveTruncated.Error()) {
+		t.Errorf("truncation error mismatch: have %v, want %v", err, ErrArchiveTruncated)
+	}
+	// Plain RLP exports aren't mistaken for archives
+	buf.Reset()
+	if err := chain.ExportN(buf, 1, 2); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if IsArchive(bufio.NewReader(buf)) {
+		t.Errorf("plain export recognized as archive")
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright 2018 The mesh-chain Authors
+// This file is part of the mesh-chain library.
+//
+// The mesh-chain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The mesh-chain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the mesh-chain library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/consensus"
+	"github.com/MeshBoxTech/mesh-chain/core/types"
+	"github.com/MeshBoxTech/mesh-chain/ethdb"
+	"github.com/MeshBoxTech/mesh-chain/log"
+	"github.com/MeshBoxTech/mesh-chain/rlp"
+)
+
+// badBlockKey tracks the list of the most recent bad blocks seen by the node.
+var badBlockKey = []byte("InvalidBlock")
+
+// BadBlock is a block rejected by the local node, kept along with the reason of
+// the rejection and the validators the node expected to seal it.
+type BadBlock struct {
+	Block      *types.Block
+	Error      string
+	Validators []common.Address // Local validator snapshot of the parent, empty if unavailable
+	Time       uint64           // Unix time the block was rejected at
+}
+
+// badBlocksByNumber implements the sort interface to order bad blocks by
+// descending block number.
+type badBlocksByNumber []*BadBlock
+
+func (s badBlocksByNumber) Len() int { return len(s) }
+func (s badBlocksByNumber) Less(i, j int) bool {
+	return s[i].Block.NumberU64() > s[j].Block.NumberU64()
+}
+func (s badBlocksByNumber) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+
+// GetBadBlocks retrieves the persisted bad blocks, highest first.
+func GetBadBlocks(db DatabaseReader) []*BadBlock {
+	data, _ := db.Get(badBlockKey)
+	if len(data) == 0 {
+		return nil
+	}
+	var blocks []*BadBlock
+	if err := rlp.DecodeBytes(data, &blocks); err != nil {
+		log.Error("Invalid bad block list RLP", "err", err)
+		return nil
+	}
+	return blocks
+}
+
+// GetBadBlock retrieves a persisted bad block by hash, or nil if not found.
+func GetBadBlock(db DatabaseReader, hash common.Hash) *BadBlock {
+	for _, bad := range GetBadBlocks(db) {
+		if bad.Block.Hash() == hash {
+			return bad
+		}
+	}
+	return nil
+}
+
+// WriteBadBlock adds a bad block to the persisted list, evicting the lowest ones
+// beyond badBlockLimit. Blocks already in the list are not overwritten.
+func WriteBadBlock(db ethdb.Database, bad *BadBlock) error {
+	blocks := GetBadBlocks(db)
+	for _, b := range blocks {
+		if b.Block.Hash() == bad.Block.Hash() {
+			return nil
+		}
+	}
+	blocks = append(blocks, bad)
+	sort.Stable(badBlocksByNumber(blocks))
+	if len(blocks) > badBlockLimit {
+		blocks = blocks[:badBlockLimit]
+	}
+	data, err := rlp.EncodeToBytes(blocks)
+	if err != nil {
+		return err
+	}
+	return db.Put(badBlockKey, data)
+}
+
+// DeleteBadBlocks removes all the persisted bad blocks.
+func DeleteBadBlocks(db DatabaseDeleter) {
+	db.Delete(badBlockKey)
+}
+
+// ReplayDiff compares a value the block commits to with the one computed when
+// re-executing it.
+type ReplayDiff struct {
+	Expected interface{} `json:"expected"`
+	Computed interface{} `json:"computed"`
+	Match    bool        `json:"match"`
+}
+
+// ReceiptDiff compares a receipt computed when re-executing a block with the
+// one stored for it, if the block was imported before.
+type ReceiptDiff struct {
+	Index    int            `json:"index"`
+	TxHash   common.Hash    `json:"transactionHash"`
+	Expected *types.Receipt `json:"expected,omitempty"`
+	Computed *types.Receipt `json:"computed"`
+	Match    bool           `json:"match"` // Always true if no receipt was stored
+}
+
+// BlockReplay is the outcome of re-executing a block on top of its parent state.
+type BlockReplay struct {
+	Number     uint64         `json:"number"`
+	Hash       common.Hash    `json:"hash"`
+	Error      string         `json:"error,omitempty"` // First verification or processing failure
+	StateRoot  *ReplayDiff    `json:"stateRoot,omitempty"`
+	Receipts   *ReplayDiff    `json:"receiptsRoot,omitempty"`
+	GasUsed    *ReplayDiff    `json:"gasUsed,omitempty"`
+	Bloom      *ReplayDiff    `json:"logsBloom,omitempty"`
+	Validators *ReplayDiff    `json:"validators,omitempty"` // Extra-data validators, epoch blocks only
+	TxReceipts []*ReceiptDiff `json:"receipts,omitempty"`
+}
+
+// ReplayBlock re-executes a block, which doesn't need to be part of the chain,
+// on top of its parent state and reports how the result differs from what the
+// block commits to. Failures of the block itself are reported in the result,
+// only a missing parent or parent state is returned as an error.
+func (bc *BlockChain) ReplayBlock(block *types.Block) (*BlockReplay, error) {
+	parent := bc.GetBlock(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil, consensus.ErrUnknownAncestor
+	}
+	statedb, err := bc.StateAt(parent.Root())
+	if err != nil {
+		return nil, fmt.Errorf("parent state unavailable: %v", err)
+	}
+	header := block.Header()
+	replay := &BlockReplay{Number: block.NumberU64(), Hash: block.Hash()}
+
+	if pos, ok := bc.engine.(consensus.PoS); ok {
+		announced, elected, err := pos.EpochValidators(bc, header)
+		if err != nil {
+			replay.Error = fmt.Sprintf("validator election failed: %v", err)
+		} else if announced != nil || elected != nil {
+			replay.Validators = &ReplayDiff{Expected: announced, Computed: elected, Match: addressesEqual(announced, elected)}
+		}
+	}
+	if err := bc.engine.VerifyHeader(bc, header, true); err != nil && replay.Error == "" {
+		replay.Error = fmt.Sprintf("invalid header: %v", err)
+	}
+	if err := bc.Validator().ValidateBody(parent, block); err != nil && err != ErrKnownBlock && replay.Error == "" {
+		replay.Error = fmt.Sprintf("invalid body: %v", err)
+	}
+	receipts, _, usedGas, err := bc.processor.Process(block, statedb, bc.vmConfig)
+	if err != nil {
+		if replay.Error == "" {
+			replay.Error = fmt.Sprintf("processing failed: %v", err)
+		}
+		return replay, nil
+	}
+	root := statedb.IntermediateRoot(bc.config.IsEIP158(header.Number))
+	replay.StateRoot = &ReplayDiff{Expected: header.Root, Computed: root, Match: header.Root == root}
+
+	receiptSha := types.DeriveSha(receipts)
+	replay.Receipts = &ReplayDiff{Expected: header.ReceiptHash, Computed: receiptSha, Match: header.ReceiptHash == receiptSha}
+	replay.GasUsed = &ReplayDiff{Expected: header.GasUsed, Computed: usedGas, Match: header.GasUsed.Cmp(usedGas) == 0}
+
+	bloom := types.CreateBloom(receipts)
+	replay.Bloom = &ReplayDiff{Expected: header.Bloom, Computed: bloom, Match: header.Bloom == bloom}
+
+	stored := GetBlockReceipts(bc.chainDb, block.Hash(), block.NumberU64())
+	for i, receipt := range receipts {
+		diff := &ReceiptDiff{Index: i, TxHash: block.Transactions()[i].Hash(), Computed: receipt, Match: true}
+		if i < len(stored) {
+			diff.Expected = stored[i]
+			diff.Match = receiptsEqual(stored[i], receipt)
+		}
+		replay.TxReceipts = append(replay.TxReceipts, diff)
+	}
+	return replay, nil
+}
+
+// receiptsEqual reports whether two receipts have the same consensus encoding.
+func receiptsEqual(a, b *types.Receipt) bool {
+	encA, errA := rlp.EncodeToBytes(a)
+	encB, errB := rlp.EncodeToBytes(b)
+	return errA == nil && errB == nil && bytes.Equal(encA, encB)
+}
+
+// addressesEqual reports whether two address lists are identical.
+func addressesEqual(a, b []common.Address) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
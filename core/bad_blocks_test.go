@@ -0,0 +1,118 @@
+// Copyright 2018 The mesh-chain Authors
+// This file is part of the mesh-chain library.
+//
+// The mesh-chain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The mesh-chain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the mesh-chain library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/consensus/ethash"
+	"github.com/MeshBoxTech/mesh-chain/core/types"
+	"github.com/MeshBoxTech/mesh-chain/core/vm"
+	"github.com/MeshBoxTech/mesh-chain/ethdb"
+	"github.com/MeshBoxTech/mesh-chain/params"
+)
+
+func TestBadBlockStorage(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	if blocks := GetBadBlocks(db); len(blocks) != 0 {
+		t.Fatalf("non existent bad blocks returned: %v", blocks)
+	}
+	for i := 1; i <= badBlockLimit+2; i++ {
+		block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(int64(i)), Extra: []byte("bad block")})
+		if err := WriteBadBlock(db, &BadBlock{Block: block, Error: "invalid", Validators: []common.Address{{byte(i)}}}); err != nil {
+			t.Fatalf("failed to write bad block #%d: %v", i, err)
+		}
+		// Rewriting a known block is a noop
+		if err := WriteBadBlock(db, &BadBlock{Block: block, Error: "duplicate"}); err != nil {
+			t.Fatalf("failed to rewrite bad block #%d: %v", i, err)
+		}
+	}
+	blocks := GetBadBlocks(db)
+	if len(blocks) != badBlockLimit {
+		t.Fatalf("bad block count mismatch: have %d, want %d", len(blocks), badBlockLimit)
+	}
+	for i, bad := range blocks {
+		if want := uint64(badBlockLimit + 2 - i); bad.Block.NumberU64() != want {
+			t.Errorf("bad block %d: number mismatch: have %d, want %d", i, bad.Block.NumberU64(), want)
+		}
+		if bad.Error != "invalid" || len(bad.Validators) != 1 || bad.Validators[0] != (common.Address{byte(bad.Block.NumberU64())}) {
+			t.Errorf("bad block %d: content mismatch: have %q/%v", i, bad.Error, bad.Validators)
+		}
+	}
+	if bad := GetBadBlock(db, blocks[3].Block.Hash()); bad == nil || bad.Block.Hash() != blocks[3].Block.Hash() {
+		t.Errorf("bad block lookup mismatch: have %v, want #%d", bad, blocks[3].Block.NumberU64())
+	}
+	DeleteBadBlocks(db)
+	if blocks := GetBadBlocks(db); len(blocks) != 0 {
+		t.Fatalf("deleted bad blocks returned: %v", blocks)
+	}
+}
+
+func TestBadBlockReplay(t *testing.T) {
+	var (
+		gspec = &Genesis{Config: params.TestChainConfig}
+		db, _ = ethdb.NewMemDatabase()
+	)
+	genesis := gspec.MustCommit(db)
+	blocks, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 4, nil)
+
+	chain, _ := NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{})
+	if _, err := chain.InsertChain(blocks[:3]); err != nil {
+		t.Fatal(err)
+	}
+	// Commit to a bogus state root, which is only caught when processing the block
+	header := blocks[3].Header()
+	header.Root = common.HexToHash("0xbad")
+	bad := types.NewBlockWithHeader(header).WithBody(blocks[3].Transactions(), blocks[3].Uncles())
+
+	if _, err := chain.InsertChain(types.Blocks{bad}); err == nil {
+		t.Fatalf("bad block inserted")
+	}
+	chain.Stop()
+
+	// The bad block must survive a restart
+	chain, _ = NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{})
+	defer chain.Stop()
+
+	reported, _ := chain.BadBlocks()
+	if len(reported) != 1 || reported[0].Hash != bad.Hash() || reported[0].Error == "" {
+		t.Fatalf("bad blocks mismatch: have %+v, want %x", reported, bad.Hash())
+	}
+	replay, err := chain.ReplayBlock(GetBadBlock(db, bad.Hash()).Block)
+	if err != nil {
+		t.Fatalf("failed to replay bad block: %v", err)
+	}
+	if replay.StateRoot == nil || replay.StateRoot.Match {
+		t.Fatalf("state root mismatch not reported: %+v", replay.StateRoot)
+	}
+	if replay.StateRoot.Expected != header.Root || replay.StateRoot.Computed != blocks[3].Root() {
+		t.Errorf("state root diff mismatch: have %v/%v, want %x/%x", replay.StateRoot.Expected, replay.StateRoot.Computed, header.Root, blocks[3].Root())
+	}
+	if !replay.Receipts.Match || !replay.GasUsed.Match || !replay.Bloom.Match {
+		t.Errorf("unexpected diff: receipts %+v, gas %+v, bloom %+v", replay.Receipts, replay.GasUsed, replay.Bloom)
+	}
+	// Replaying a valid block reports no differences
+	replay, err = chain.ReplayBlock(blocks[2])
+	if err != nil {
+		t.Fatalf("failed to replay block: %v", err)
+	}
+	if replay.Error != "" || !replay.StateRoot.Match {
+		t.Errorf("valid block replay mismatch: error %q, root %+v", replay.Error, replay.StateRoot)
+	}
+}
@@ -31,6 +31,7 @@ import (
 	"github.com/MeshBoxTech/mesh-chain/common/mclock"
 	"github.com/MeshBoxTech/mesh-chain/consensus"
 	"github.com/MeshBoxTech/mesh-chain/core/state"
+	"github.com/MeshBoxTech/mesh-chain/core/state/snapshot"
 	"github.com/MeshBoxTech/mesh-chain/core/types"
 	"github.com/MeshBoxTech/mesh-chain/core/vm"
 	"github.com/MeshBoxTech/mesh-chain/crypto"
@@ -42,6 +43,7 @@ import (
 	"github.com/MeshBoxTech/mesh-chain/rlp"
 	"github.com/MeshBoxTech/mesh-chain/trie"
 	lru "github.com/hashicorp/golang-lru"
+	"gopkg.in/karalabe/cookiejar.v2/collections/prque"
 )
 
 var (
@@ -56,11 +58,27 @@ const (
 	maxFutureBlocks     = 256
 	maxTimeFutureBlocks = 30
 	badBlockLimit       = 10
+	triesInMemory       = 128
 
 	// BlockChainVersion ensures that an incompatible database forces a resync from scratch.
 	BlockChainVersion = 3
 )
 
+// CacheConfig contains the configuration values for the trie caching/pruning
+// that's resident in a blockchain.
+type CacheConfig struct {
+	Disabled          bool   // Whether to disable trie write caching (archive node)
+	TrieNodeLimit     int    // Memory limit (MB) at which to flush the current in-memory trie to disk
+	TrieFlushInterval uint64 // Number of blocks after which to flush the current in-memory trie to disk
+	TxLookupLimit     uint64 // Number of recent blocks to keep transaction lookups for (0 = all blocks)
+}
+
+// DefaultCacheConfig is the trie caching used if none or only parts are given.
+var DefaultCacheConfig = &CacheConfig{
+	TrieNodeLimit:     256,
+	TrieFlushInterval: 3600,
+}
+
 // BlockChain represents the canonical chain given a database with a genesis
 // block. The Blockchain manages chain imports, reverts, chain reorganisations.
 //
@@ -76,7 +94,8 @@ const (
 // included in the canonical one where as GetBlockByNumber always represents the
 // canonical chain.
 type BlockChain struct {
-	config *params.ChainConfig // chain & network configuration
+	config      *params.ChainConfig // chain & network configuration
+	cacheConfig *CacheConfig        // Cache configuration for pruning
 
 	hc            *HeaderChain
 	chainDb       ethdb.Database
@@ -97,6 +116,9 @@ type BlockChain struct {
 	currentFastBlock *types.Block // Current head of the fast-sync chain (may be above the block chain!)
 
 	stateCache   state.Database // State database to reuse between imports (contains state cache)
+	snaps        *snapshot.Tree // Flat snapshot of the recent states for fast account and storage reads
+	triegc       *prque.Prque   // Priority queue mapping block numbers to tries to gc
+	lastWrite    uint64         // Block number of the last state trie flushed to disk
 	bodyCache    *lru.Cache     // Cache for the most recent block bodies
 	bodyRLPCache *lru.Cache     // Cache for the most recent block bodies in RLP encoded format
 	blockCache   *lru.Cache     // Cache for the most recent entire blocks
@@ -112,25 +134,36 @@ type BlockChain struct {
 	processor Processor // block processor interface
 	validator Validator // block and state validator interface
 	vmConfig  vm.Config
-
-	badBlocks *lru.Cache // Bad block cache
-
 }
 
 // NewBlockChain returns a fully initialised block chain using information
 // available in the database. It initialises the default Ethereum Validator and
-// Processor.
-func NewBlockChain(chainDb ethdb.Database, config *params.ChainConfig, engine consensus.Engine, vmConfig vm.Config) (*BlockChain, error) {
+// Processor. A nil cacheConfig uses DefaultCacheConfig.
+func NewBlockChain(chainDb ethdb.Database, cacheConfig *CacheConfig, config *params.ChainConfig, engine consensus.Engine, vmConfig vm.Config) (*BlockChain, error) {
+	if cacheConfig == nil {
+		cacheConfig = DefaultCacheConfig
+	}
+	if cacheConfig.TrieNodeLimit == 0 || cacheConfig.TrieFlushInterval == 0 {
+		cfg := *cacheConfig
+		if cfg.TrieNodeLimit == 0 {
+			cfg.TrieNodeLimit = DefaultCacheConfig.TrieNodeLimit
+		}
+		if cfg.TrieFlushInterval == 0 {
+			cfg.TrieFlushInterval = DefaultCacheConfig.TrieFlushInterval
+		}
+		cacheConfig = &cfg
+	}
 	bodyCache, _ := lru.New(bodyCacheLimit)
 	bodyRLPCache, _ := lru.New(bodyCacheLimit)
 	blockCache, _ := lru.New(blockCacheLimit)
 	futureBlocks, _ := lru.New(maxFutureBlocks)
-	badBlocks, _ := lru.New(badBlockLimit)
 
 	bc := &BlockChain{
 		config:       config,
+		cacheConfig:  cacheConfig,
 		chainDb:      chainDb,
 		stateCache:   state.NewDatabase(chainDb),
+		triegc:       prque.New(),
 		quit:         make(chan struct{}),
 		bodyCache:    bodyCache,
 		bodyRLPCache: bodyRLPCache,
@@ -138,7 +171,6 @@ func NewBlockChain(chainDb ethdb.Database, config *params.ChainConfig, engine co
 		futureBlocks: futureBlocks,
 		engine:       engine,
 		vmConfig:     vmConfig,
-		badBlocks:    badBlocks,
 	}
 	bc.SetValidator(NewBlockValidator(config, bc, engine))
 	bc.SetProcessor(NewStateProcessor(config, bc, engine))
@@ -155,6 +187,19 @@ func NewBlockChain(chainDb ethdb.Database, config *params.ChainConfig, engine co
 	if err := bc.loadLastState(); err != nil {
 		return nil, err
 	}
+	// The head block may have been rolled back below the ancient store by the
+	// state repair, truncate the frozen blocks past it
+	if ancients, ok := bc.chainDb.(ethdb.AncientReader); ok {
+		if frozen, err := ancients.Ancients(); err == nil && frozen > 0 {
+			if head := bc.CurrentBlock(); head.Hash() != bc.genesisBlock.Hash() && head.NumberU64() < frozen-1 {
+				log.Warn("Ancient store ahead of the chain, truncating", "frozen", frozen, "head", head.NumberU64())
+				bc.SetHead(head.NumberU64())
+			}
+		}
+	}
+	// Load the flat state snapshot, generating it in the background if missing
+	bc.snaps = snapshot.New(bc.chainDb, bc.stateCache.TrieDB(), bc.CurrentBlock().Root())
+
 	// Check the current state of the block hashes and make sure that we do not have any of the bad blocks in our chain
 	for hash := range BadHashes {
 		if header := bc.GetHeaderByHash(hash); header != nil {
@@ -170,6 +215,10 @@ func NewBlockChain(chainDb ethdb.Database, config *params.ChainConfig, engine co
 	}
 	// Take ownership of this particular state
 	go bc.update()
+
+	// Keep the transaction index in line with the lookup limit
+	bc.wg.Add(1)
+	go bc.maintainTxIndex()
 	return bc, nil
 }
 
@@ -196,9 +245,13 @@ func (bc *BlockChain) loadLastState() error {
 	}
 	// Make sure the state associated with the block is available
 	if _, err := state.New(currentBlock.Root(), bc.stateCache); err != nil {
-		// Dangling block without a state associated, init from scratch
-		log.Warn("Head state missing, resetting chain", "number", currentBlock.Number(), "hash", currentBlock.Hash())
-		return bc.Reset()
+		// Dangling block without a state associated, e.g. after a crash lost
+		// the tries cached in memory, rewind to the last persisted state
+		log.Warn("Head state missing, repairing chain", "number", currentBlock.Number(), "hash", currentBlock.Hash())
+		if currentBlock = bc.repair(currentBlock); currentBlock == nil {
+			log.Warn("No state to repair from, resetting chain")
+			return bc.Reset()
+		}
 	}
 	// Everything seems to be fine, set as the head block
 	bc.currentBlock = currentBlock
@@ -261,8 +314,9 @@ func (bc *BlockChain) SetHead(head uint64) error {
 	}
 	if bc.currentBlock != nil {
 		if _, err := state.New(bc.currentBlock.Root(), bc.stateCache); err != nil {
-			// Rewound state missing, rolled back to before pivot, reset to genesis
-			bc.currentBlock = nil
+			// Rewound state missing, rewind further to the last persisted state,
+			// or to genesis if rolled back to before pivot
+			bc.currentBlock = bc.repair(bc.currentBlock)
 		}
 	}
 	// Rewind the fast block in a simpleton way to the target head
@@ -282,7 +336,35 @@ func (bc *BlockChain) SetHead(head uint64) error {
 	if err := WriteHeadFastBlockHash(bc.chainDb, bc.currentFastBlock.Hash()); err != nil {
 		log.Crit("Failed to reset head fast block", "err", err)
 	}
-	return bc.loadLastState()
+	err := bc.loadLastState()
+	bc.rebuildSnapshot()
+	return err
+}
+
+// rebuildSnapshot regenerates the state snapshot at the current head block if
+// the snapshot tree doesn't retain a layer for its state. This method assumes
+// that the chain manager mutex is held.
+func (bc *BlockChain) rebuildSnapshot() {
+	if bc.snaps != nil && bc.snaps.Snapshot(bc.currentBlock.Root()) == nil {
+		bc.snaps.Rebuild(bc.currentBlock.Root())
+	}
+}
+
+// repair rolls back the given block until one with an associated state is found,
+// returning nil if there is none. This is needed to fix incomplete database
+// writes caused either by crashes or by state tries not yet flushed from memory.
+func (bc *BlockChain) repair(head *types.Block) *types.Block {
+	for head != nil {
+		if _, err := state.New(head.Root(), bc.stateCache); err == nil {
+			log.Info("Rewound blockchain to past state", "number", head.Number(), "hash", head.Hash())
+			return head
+		}
+		if head.NumberU64() == 0 {
+			return nil
+		}
+		head = bc.GetBlock(head.ParentHash(), head.NumberU64()-1)
+	}
+	return nil
 }
 
 // FastSyncCommitHead sets the current head block to the one defined by the hash
@@ -299,6 +381,7 @@ func (bc *BlockChain) FastSyncCommitHead(hash common.Hash) error {
 	// If all checks out, manually set the head block
 	bc.mu.Lock()
 	bc.currentBlock = block
+	bc.rebuildSnapshot()
 	bc.mu.Unlock()
 
 	log.Info("Committed new head block", "number", block.Number(), "hash", hash)
@@ -383,7 +466,12 @@ func (bc *BlockChain) State() (*state.StateDB, error) {
 
 // StateAt returns a new mutable state based on a particular point in time.
 func (bc *BlockChain) StateAt(root common.Hash) (*state.StateDB, error) {
-	return state.New(root, bc.stateCache)
+	return state.NewWithSnapshot(root, bc.stateCache, bc.snaps)
+}
+
+// StateCache returns the caching database underpinning the blockchain instance.
+func (bc *BlockChain) StateCache() state.Database {
+	return bc.stateCache
 }
 
 // Reset purges the entire blockchain, restoring it to its genesis state.
@@ -414,6 +502,7 @@ func (bc *BlockChain) ResetWithGenesisBlock(genesis *types.Block) error {
 	bc.hc.SetGenesis(bc.genesisBlock.Header())
 	bc.hc.SetCurrentHeader(bc.genesisBlock.Header())
 	bc.currentFastBlock = bc.genesisBlock
+	bc.rebuildSnapshot()
 
 	return nil
 }
@@ -520,8 +609,7 @@ func (bc *BlockChain) HasBlock(hash common.Hash, number uint64) bool {
 	if bc.blockCache.Contains(hash) {
 		return true
 	}
-	ok, _ := bc.chainDb.Has(blockBodyKey(hash, number))
-	return ok
+	return HasBody(bc.chainDb, hash, number)
 }
 
 // HasBlockAndState checks if a block and associated state trie is fully present
@@ -533,7 +621,12 @@ func (bc *BlockChain) HasBlockAndState(hash common.Hash) bool {
 		return false
 	}
 	// Ensure the associated state is also present
-	_, err := bc.stateCache.OpenTrie(block.Root())
+	return bc.HasState(block.Root())
+}
+
+// HasState checks if the state trie is fully present in the database or not.
+func (bc *BlockChain) HasState(root common.Hash) bool {
+	_, err := bc.stateCache.OpenTrie(root)
 	return err == nil
 }
 
@@ -607,6 +700,37 @@ func (bc *BlockChain) Stop() {
 	atomic.StoreInt32(&bc.procInterrupt, 1)
 
 	bc.wg.Wait()
+
+	// Ensure the state of the recent blocks is also stored to disk before exiting.
+	// The head and its parent are enough for a restart, the block before the
+	// in-memory retention covers reorgs right after it.
+	if !bc.cacheConfig.Disabled {
+		triedb := bc.stateCache.TrieDB()
+
+		for _, offset := range []uint64{0, 1, triesInMemory - 1} {
+			if number := bc.CurrentBlock().NumberU64(); number > offset {
+				recent := bc.GetBlockByNumber(number - offset)
+				if recent == nil {
+					continue
+				}
+				log.Info("Writing cached state to disk", "block", recent.Number(), "hash", recent.Hash(), "root", recent.Root())
+				if err := triedb.Commit(recent.Root(), true); err != nil {
+					log.Error("Failed to commit recent state trie", "err", err)
+				}
+			}
+		}
+		for !bc.triegc.Empty() {
+			triedb.Dereference(bc.triegc.PopItem().(common.Hash))
+		}
+	}
+	// Flatten the state snapshot into the disk, the head state was just written
+	// out above, so the snapshot can be picked up on restart
+	if bc.snaps != nil {
+		if err := bc.snaps.Cap(bc.CurrentBlock().Root(), 0); err != nil {
+			log.Error("Failed to persist state snapshot", "err", err)
+		}
+		bc.snaps.Stop()
+	}
 	log.Info("Blockchain manager stopped")
 }
 
@@ -811,12 +935,17 @@ func (bc *BlockChain) WriteBlockAndState(block *types.Block, receipts []*types.R
 	if err := WriteBlock(batch, block); err != nil {
 		return NonStatTy, err
 	}
-	if _, err := state.CommitTo(batch, bc.config.IsEIP158(block.Number())); err != nil {
+	if err := bc.writeState(block, state); err != nil {
 		return NonStatTy, err
 	}
 	if err := WriteBlockReceipts(batch, block.Hash(), block.NumberU64(), receipts); err != nil {
 		return NonStatTy, err
 	}
+	if recorder, ok := bc.engine.(consensus.Recorder); ok {
+		if err := recorder.WriteBlockRecords(bc, batch, block); err != nil {
+			return NonStatTy, err
+		}
+	}
 
 	// If the total difficulty is higher than our known, add it to the canonical chain
 	// Second clause in the if statement reduces the vulnerability to selfish mining.
@@ -857,6 +986,78 @@ func (bc *BlockChain) WriteBlockAndState(block *types.Block, receipts []*types.R
 	return status, nil
 }
 
+// writeState commits the state of the block into the trie database. Archive nodes
+// write it straight through to disk, others keep the recent tries in memory and
+// garbage collect the ones falling out of the retention window.
+func (bc *BlockChain) writeState(block *types.Block, state *state.StateDB) error {
+	triedb := bc.stateCache.TrieDB()
+
+	root, err := state.CommitTo(triedb, bc.config.IsEIP158(block.Number()))
+	if err != nil {
+		return err
+	}
+	// Keep the snapshot layers of the states retained in memory, flattening the
+	// older ones into the disk
+	if bc.snaps != nil {
+		if err := bc.snaps.Cap(root, triesInMemory-1); err != nil {
+			log.Debug("Failed to cap snapshot tree", "root", root, "err", err)
+		}
+	}
+	if bc.cacheConfig.Disabled {
+		return triedb.Commit(root, false)
+	}
+	// Full but not archive node, reference the trie to keep it alive until it
+	// falls out of the retention window
+	triedb.Reference(root, common.Hash{})
+	bc.triegc.Push(root, -float32(block.NumberU64()))
+
+	current := block.NumberU64()
+	if current <= triesInMemory {
+		return nil
+	}
+	// If we exceeded our memory allowance, flush matured singleton nodes to disk
+	limit := common.StorageSize(bc.cacheConfig.TrieNodeLimit) * 1024 * 1024
+	if triedb.Size() > limit {
+		if err := triedb.Cap(limit - ethdb.IdealBatchSize); err != nil {
+			return err
+		}
+	}
+	// Flush the canonical trie leaving the retention window every so often, so
+	// a crash loses at most the blocks since
+	chosen := current - triesInMemory
+	if chosen >= bc.lastWrite+bc.cacheConfig.TrieFlushInterval {
+		if header := bc.GetHeaderByNumber(chosen); header != nil {
+			if err := triedb.Commit(header.Root, true); err != nil {
+				return err
+			}
+			bc.lastWrite = chosen
+		}
+	}
+	// Garbage collect anything below our required write retention
+	for !bc.triegc.Empty() {
+		root, number := bc.triegc.Pop()
+		if uint64(-number) > chosen {
+			bc.triegc.Push(root, number)
+			break
+		}
+		triedb.Dereference(root.(common.Hash))
+	}
+	return nil
+}
+
+// WriteBlockWithoutState writes only the block and its metadata to the database,
+// but does not write any state. This is used to construct competing side forks
+// up to the point where they exceed the canonical total difficulty.
+func (bc *BlockChain) WriteBlockWithoutState(block *types.Block, td *big.Int) error {
+	bc.wg.Add(1)
+	defer bc.wg.Done()
+
+	if err := bc.hc.WriteTd(block.Hash(), block.NumberU64(), td); err != nil {
+		return err
+	}
+	return WriteBlock(bc.chainDb, block)
+}
+
 // InsertChain attempts to insert the given batch of blocks in to the canonical
 // chain or, otherwise, create a fork. If an error is returned it will return
 // the index number of the failing block as well an error describing what went
@@ -933,6 +1134,43 @@ func (bc *BlockChain) insertChain(chain types.Blocks) (int, []interface{}, []*ty
 		if err == nil {
 			err = bc.Validator().ValidateBody(bc.GetBlockByHash(block.ParentHash()), block)
 		}
+		if err == consensus.ErrPrunedAncestor {
+			// Block competing with the canonical chain, store in the db, but
+			// don't process until its td exceeds the canonical one
+			currentBlock := bc.CurrentBlock()
+			localTd := bc.GetTd(currentBlock.Hash(), currentBlock.NumberU64())
+			externTd := new(big.Int).Add(bc.GetTd(block.ParentHash(), block.NumberU64()-1), block.Difficulty())
+			if localTd.Cmp(externTd) > 0 {
+				if err := bc.WriteBlockWithoutState(block, externTd); err != nil {
+					return i, events, coalescedLogs, err
+				}
+				stats.queued++
+				continue
+			}
+			// Competitor chain beat canonical, gather all blocks from the
+			// last ancestor with a state and reimport them
+			var winner []*types.Block
+
+			parent := bc.GetBlock(block.ParentHash(), block.NumberU64()-1)
+			for parent != nil && !bc.HasState(parent.Root()) {
+				winner = append(winner, parent)
+				parent = bc.GetBlock(parent.ParentHash(), parent.NumberU64()-1)
+			}
+			for j := 0; j < len(winner)/2; j++ {
+				winner[j], winner[len(winner)-1-j] = winner[len(winner)-1-j], winner[j]
+			}
+			log.Info("Reimporting pruned side chain", "number", block.Number(), "hash", block.Hash(), "ancestors", len(winner))
+
+			bc.chainmu.Unlock()
+			_, evs, logs, ierr := bc.insertChain(winner)
+			bc.chainmu.Lock()
+
+			events, coalescedLogs = append(events, evs...), append(coalescedLogs, logs...)
+			if ierr != nil {
+				return i, events, coalescedLogs, ierr
+			}
+			err = nil
+		}
 		if err != nil {
 			if err == ErrKnownBlock {
 				stats.ignored++
@@ -976,7 +1214,7 @@ func (bc *BlockChain) insertChain(chain types.Blocks) (int, []interface{}, []*ty
 		} else {
 			parent = chain[i-1]
 		}
-		state, err := state.New(parent.Root(), bc.stateCache)
+		state, err := state.NewWithSnapshot(parent.Root(), bc.stateCache, bc.snaps)
 		if err != nil {
 			return i, events, coalescedLogs, err
 		}
@@ -1230,30 +1468,42 @@ func (bc *BlockChain) update() {
 
 // BadBlockArgs represents the entries in the list returned when bad blocks are queried.
 type BadBlockArgs struct {
-	Hash   common.Hash   `json:"hash"`
-	Header *types.Header `json:"header"`
+	Hash       common.Hash      `json:"hash"`
+	Header     *types.Header    `json:"header"`
+	Error      string           `json:"error"`
+	Validators []common.Address `json:"validators"`
+	Time       uint64           `json:"time"`
 }
 
 // BadBlocks returns a list of the last 'bad blocks' that the client has seen on the network
 func (bc *BlockChain) BadBlocks() ([]BadBlockArgs, error) {
-	headers := make([]BadBlockArgs, 0, bc.badBlocks.Len())
-	for _, hash := range bc.badBlocks.Keys() {
-		if hdr, exist := bc.badBlocks.Peek(hash); exist {
-			header := hdr.(*types.Header)
-			headers = append(headers, BadBlockArgs{header.Hash(), header})
-		}
+	blocks := GetBadBlocks(bc.chainDb)
+	headers := make([]BadBlockArgs, 0, len(blocks))
+	for _, bad := range blocks {
+		headers = append(headers, BadBlockArgs{bad.Block.Hash(), bad.Block.Header(), bad.Error, bad.Validators, bad.Time})
 	}
 	return headers, nil
 }
 
-// addBadBlock adds a bad block to the bad-block LRU cache
-func (bc *BlockChain) addBadBlock(block *types.Block) {
-	bc.badBlocks.Add(block.Header().Hash(), block.Header())
+// addBadBlock persists a bad block along with the reason it was rejected and the
+// local validator snapshot it was checked against.
+func (bc *BlockChain) addBadBlock(block *types.Block, err error) {
+	bad := &BadBlock{Block: block, Error: err.Error(), Time: uint64(time.Now().Unix())}
+	if pos, ok := bc.engine.(consensus.PoS); ok && block.NumberU64() > 0 {
+		validators, err := pos.Validators(bc, block.NumberU64()-1, block.ParentHash())
+		if err != nil {
+			log.Debug("Failed to retrieve validators of bad block", "number", block.Number(), "hash", block.Hash(), "err", err)
+		}
+		bad.Validators = validators
+	}
+	if err := WriteBadBlock(bc.chainDb, bad); err != nil {
+		log.Error("Failed to store bad block", "number", block.Number(), "hash", block.Hash(), "err", err)
+	}
 }
 
 // reportBlock logs a bad block error.
 func (bc *BlockChain) reportBlock(block *types.Block, receipts types.Receipts, err error) {
-	bc.addBadBlock(block)
+	bc.addBadBlock(block, err)
 
 	var receiptString string
 	for _, receipt := range receipts {
@@ -46,7 +46,7 @@ func newTestBlockChain(fake bool) *BlockChain {
 	if !fake {
 		engine = ethash.NewTester()
 	}
-	blockchain, err := NewBlockChain(db, gspec.Config, engine, vm.Config{})
+	blockchain, err := NewBlockChain(db, nil, gspec.Config, engine, vm.Config{})
 	if err != nil {
 		panic(err)
 	}
@@ -496,7 +496,7 @@ func testReorgBadHashes(t *testing.T, full bool) {
 	}
 
 	// Create a new BlockChain and check that it rolled back the state.
-	ncm, err := NewBlockChain(bc.chainDb, bc.config, ethash.NewFaker(), vm.Config{})
+	ncm, err := NewBlockChain(bc.chainDb, nil, bc.config, ethash.NewFaker(), vm.Config{})
 	if err != nil {
 		t.Fatalf("failed to create new chain manager: %v", err)
 	}
@@ -609,7 +609,7 @@ func TestFastVsFullChains(t *testing.T) {
 	// Import the chain as an archive node for the comparison baseline
 	archiveDb, _ := ethdb.NewMemDatabase()
 	gspec.MustCommit(archiveDb)
-	archive, _ := NewBlockChain(archiveDb, gspec.Config, ethash.NewFaker(), vm.Config{})
+	archive, _ := NewBlockChain(archiveDb, nil, gspec.Config, ethash.NewFaker(), vm.Config{})
 	defer archive.Stop()
 
 	if n, err := archive.InsertChain(blocks); err != nil {
@@ -618,7 +618,7 @@ func TestFastVsFullChains(t *testing.T) {
 	// Fast import the chain as a non-archive node to test
 	fastDb, _ := ethdb.NewMemDatabase()
 	gspec.MustCommit(fastDb)
-	fast, _ := NewBlockChain(fastDb, gspec.Config, ethash.NewFaker(), vm.Config{})
+	fast, _ := NewBlockChain(fastDb, nil, gspec.Config, ethash.NewFaker(), vm.Config{})
 	defer fast.Stop()
 
 	headers := make([]*types.Header, len(blocks))
@@ -696,7 +696,7 @@ func TestLightVsFastVsFullChainHeads(t *testing.T) {
 	archiveDb, _ := ethdb.NewMemDatabase()
 	gspec.MustCommit(archiveDb)
 
-	archive, _ := NewBlockChain(archiveDb, gspec.Config, ethash.NewFaker(), vm.Config{})
+	archive, _ := NewBlockChain(archiveDb, nil, gspec.Config, ethash.NewFaker(), vm.Config{})
 	if n, err := archive.InsertChain(blocks); err != nil {
 		t.Fatalf("failed to process block %d: %v", n, err)
 	}
@@ -709,7 +709,7 @@ func TestLightVsFastVsFullChainHeads(t *testing.T) {
 	// Import the chain as a non-archive node and ensure all pointers are updated
 	fastDb, _ := ethdb.NewMemDatabase()
 	gspec.MustCommit(fastDb)
-	fast, _ := NewBlockChain(fastDb, gspec.Config, ethash.NewFaker(), vm.Config{})
+	fast, _ := NewBlockChain(fastDb, nil, gspec.Config, ethash.NewFaker(), vm.Config{})
 	defer fast.Stop()
 
 	headers := make([]*types.Header, len(blocks))
@@ -730,7 +730,7 @@ func TestLightVsFastVsFullChainHeads(t *testing.T) {
 	lightDb, _ := ethdb.NewMemDatabase()
 	gspec.MustCommit(lightDb)
 
-	light, _ := NewBlockChain(lightDb, gspec.Config, ethash.NewFaker(), vm.Config{})
+	light, _ := NewBlockChain(lightDb, nil, gspec.Config, ethash.NewFaker(), vm.Config{})
 	if n, err := light.InsertHeaderChain(headers, 1); err != nil {
 		t.Fatalf("failed to insert header %d: %v", n, err)
 	}
@@ -799,7 +799,7 @@ func TestChainTxReorgs(t *testing.T) {
 		}
 	})
 	// Import the chain. This runs all block validation rules.
-	blockchain, _ := NewBlockChain(db, gspec.Config, ethash.NewFaker(), vm.Config{})
+	blockchain, _ := NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{})
 	if i, err := blockchain.InsertChain(chain); err != nil {
 		t.Fatalf("failed to insert original chain[%d]: %v", i, err)
 	}
@@ -870,7 +870,7 @@ func TestLogReorgs(t *testing.T) {
 		signer  = types.NewEIP155Signer(gspec.Config.ChainId)
 	)
 
-	blockchain, _ := NewBlockChain(db, gspec.Config, ethash.NewFaker(), vm.Config{})
+	blockchain, _ := NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{})
 	defer blockchain.Stop()
 
 	rmLogsCh := make(chan RemovedLogsEvent)
@@ -917,7 +917,7 @@ func TestReorgSideEvent(t *testing.T) {
 		signer  = types.NewEIP155Signer(gspec.Config.ChainId)
 	)
 
-	blockchain, _ := NewBlockChain(db, gspec.Config, ethash.NewFaker(), vm.Config{})
+	blockchain, _ := NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{})
 	defer blockchain.Stop()
 
 	chain, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 3, func(i int, gen *BlockGen) {})
@@ -1043,7 +1043,7 @@ func TestEIP155Transition(t *testing.T) {
 		genesis = gspec.MustCommit(db)
 	)
 
-	blockchain, _ := NewBlockChain(db, gspec.Config, ethash.NewFaker(), vm.Config{})
+	blockchain, _ := NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{})
 	defer blockchain.Stop()
 
 	blocks, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 4, func(i int, block *BlockGen) {
@@ -1151,7 +1151,7 @@ func TestEIP161AccountRemoval(t *testing.T) {
 		}
 		genesis = gspec.MustCommit(db)
 	)
-	blockchain, _ := NewBlockChain(db, gspec.Config, ethash.NewFaker(), vm.Config{})
+	blockchain, _ := NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{})
 	defer blockchain.Stop()
 
 	blocks, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 3, func(i int, block *BlockGen) {
@@ -1197,3 +1197,134 @@ func TestEIP161AccountRemoval(t *testing.T) {
 		t.Error("account should not exist")
 	}
 }
+
+// makeTransferChain generates a chain of n blocks, each with a transaction of
+// the same sender so that every block has a distinct state.
+func makeTransferChain(t *testing.T, n int) (*Genesis, []*types.Block) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		gspec   = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  GenesisAlloc{address: {Balance: big.NewInt(1000000000)}},
+		}
+		signer = types.HomesteadSigner{}
+		engine = ethash.NewFaker()
+	)
+	db, _ := ethdb.NewMemDatabase()
+	genesis := gspec.MustCommit(db)
+
+	// BlockGen.AddTx runs without a chain context, which the EVM needs for the
+	// block author, so the transfers are applied on top of a chain here
+	chain, _ := NewBlockChain(db, nil, gspec.Config, engine, vm.Config{})
+	defer chain.Stop()
+
+	blocks, _ := GenerateChain(gspec.Config, genesis, engine, db, n, func(i int, b *BlockGen) {
+		b.SetCoinbase(common.Address{1})
+
+		tx, err := types.SignTx(types.NewTransaction(b.TxNonce(address), common.BigToAddress(big.NewInt(int64(i+1))), new(big.Int), big.NewInt(21000), new(big.Int), nil), signer, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.statedb.Prepare(tx.Hash(), common.Hash{}, len(b.txs))
+		receipt, _, err := ApplyTransaction(b.config, chain, &b.header.Coinbase, b.gasPool, b.statedb, b.header, tx, b.header.GasUsed, vm.Config{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.txs = append(b.txs, tx)
+		b.receipts = append(b.receipts, receipt)
+	})
+	return gspec, blocks
+}
+
+// Tests that the state of the blocks falling out of the in-memory retention
+// window is garbage collected on full nodes, while archive nodes persist every
+// state and both keep the head state across a restart.
+func TestTrieGarbageCollection(t *testing.T) {
+	var (
+		engine = ethash.NewFaker()
+		gendb  = ethdb.NewMemDatabase
+	)
+	gspec, blocks := makeTransferChain(t, 2*triesInMemory)
+
+	for _, archive := range []bool{false, true} {
+		diskdb, _ := gendb()
+		gspec.MustCommit(diskdb)
+
+		chain, err := NewBlockChain(diskdb, &CacheConfig{Disabled: archive}, gspec.Config, engine, vm.Config{})
+		if err != nil {
+			t.Fatalf("archive %v: failed to create chain: %v", archive, err)
+		}
+		if n, err := chain.InsertChain(blocks); err != nil {
+			t.Fatalf("archive %v: block %d: failed to insert: %v", archive, n, err)
+		}
+		for i, block := range blocks {
+			var (
+				live   = chain.HasState(block.Root())
+				ok, _  = diskdb.Has(block.Root().Bytes())
+				number = block.NumberU64()
+			)
+			switch {
+			case archive:
+				if !ok {
+					t.Errorf("archive: block %d: state not persisted", number)
+				}
+			case i < len(blocks)-triesInMemory:
+				if live {
+					t.Errorf("full: block %d: state not garbage collected", number)
+				}
+			default:
+				if !live {
+					t.Errorf("full: block %d: recent state missing", number)
+				}
+				if ok {
+					t.Errorf("full: block %d: recent state persisted", number)
+				}
+			}
+		}
+		chain.Stop()
+
+		head := blocks[len(blocks)-1]
+		if ok, _ := diskdb.Has(head.Root().Bytes()); !ok {
+			t.Errorf("archive %v: head state not persisted on stop", archive)
+		}
+		chain, err = NewBlockChain(diskdb, &CacheConfig{Disabled: archive}, gspec.Config, engine, vm.Config{})
+		if err != nil {
+			t.Fatalf("archive %v: failed to reopen chain: %v", archive, err)
+		}
+		if have := chain.CurrentBlock().Hash(); have != head.Hash() {
+			t.Errorf("archive %v: head mismatch after restart: have %x, want %x", archive, have, head.Hash())
+		}
+		chain.Stop()
+	}
+}
+
+// Tests that a chain whose head state was lost, e.g. in a crash before the
+// cached tries were flushed, is rewound to the last persisted state on startup.
+func TestHeadStateRepair(t *testing.T) {
+	engine := ethash.NewFaker()
+	gspec, blocks := makeTransferChain(t, 10)
+
+	diskdb, _ := ethdb.NewMemDatabase()
+	genesis := gspec.MustCommit(diskdb)
+	chain, _ := NewBlockChain(diskdb, nil, gspec.Config, engine, vm.Config{})
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert: %v", n, err)
+	}
+	// Reopen without stopping, losing the cached states
+	chain, err := NewBlockChain(diskdb, nil, gspec.Config, engine, vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to reopen chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if head := chain.CurrentBlock(); head.Hash() != genesis.Hash() {
+		t.Errorf("head mismatch: have #%d, want genesis", head.NumberU64())
+	}
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to reimport: %v", n, err)
+	}
+	if head := chain.CurrentBlock(); head.Hash() != blocks[len(blocks)-1].Hash() {
+		t.Errorf("head mismatch after reimport: have #%d, want #%d", head.NumberU64(), len(blocks))
+	}
+}
@@ -0,0 +1,297 @@
+// Copyright 2018 The mesh-chain Authors
+// This file is part of the mesh-chain library.
+//
+// The mesh-chain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The mesh-chain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the mesh-chain library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/ethdb"
+	"github.com/MeshBoxTech/mesh-chain/log"
+)
+
+const (
+	// freezerHeaderTable indicates the name of the freezer header table.
+	freezerHeaderTable = "headers"
+
+	// freezerHashTable indicates the name of the freezer canonical hash table.
+	freezerHashTable = "hashes"
+
+	// freezerBodiesTable indicates the name of the freezer block body table.
+	freezerBodiesTable = "bodies"
+
+	// freezerReceiptTable indicates the name of the freezer receipts table.
+	freezerReceiptTable = "receipts"
+
+	// freezerDifficultyTable indicates the name of the freezer total difficulty table.
+	freezerDifficultyTable = "diffs"
+)
+
+// freezerNoSnappy configures whether compression is disabled for the ancient
+// tables. Hashes and total difficulties don't compress well.
+var freezerNoSnappy = map[string]bool{
+	freezerHeaderTable:     false,
+	freezerHashTable:       true,
+	freezerBodiesTable:     false,
+	freezerReceiptTable:    false,
+	freezerDifficultyTable: true,
+}
+
+const (
+	// DefaultFreezerThreshold is the number of recent blocks kept in the key-value
+	// store, older canonical blocks are migrated into the ancient store.
+	DefaultFreezerThreshold = 90000
+
+	// freezerRecheckInterval is the frequency to check the key-value database for
+	// chain progression that might permit new blocks to be frozen into immutable
+	// storage.
+	freezerRecheckInterval = time.Minute
+
+	// freezerBatchLimit is the maximum number of blocks to freeze in one batch
+	// before doing an fsync and deleting it from the key-value store.
+	freezerBatchLimit = 30000
+)
+
+// errAncientExtracted is returned if the key-value store has already migrated
+// blocks into an ancient store other than the one it's opened with.
+var errAncientExtracted = errors.New("ancient chain segments already extracted, please set --datadir.ancient to the correct path")
+
+// freezerDatabase is a database wrapper that enables ancient chain segment
+// freezing. Canonical blocks older than the threshold are periodically moved
+// from the key-value store into the freezer.
+type freezerDatabase struct {
+	ethdb.Database
+	*ethdb.Freezer
+
+	threshold uint64 // Number of recent blocks to keep in the key-value store
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewDatabaseWithFreezer creates a chain database with an ancient store in the
+// freezer directory backing the given key-value store. The two stores are cross
+// checked to be from the same chain and contiguous before blocks older than the
+// threshold start moving into the freezer.
+func NewDatabaseWithFreezer(db ethdb.Database, freezer string, namespace string, threshold uint64) (ethdb.Database, error) {
+	fdb, err := openFreezerDatabase(db, freezer, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if threshold == 0 {
+		threshold = DefaultFreezerThreshold
+	}
+	fdb.threshold = threshold
+
+	fdb.wg.Add(1)
+	go fdb.freeze()
+
+	return fdb, nil
+}
+
+// NewDatabaseWithAncients creates a chain database serving the ancient store in
+// the freezer directory next to the given key-value store, without moving any
+// blocks into the freezer. It's meant for tools looking at the database.
+func NewDatabaseWithAncients(db ethdb.Database, freezer string, namespace string) (ethdb.Database, error) {
+	return openFreezerDatabase(db, freezer, namespace)
+}
+
+// openFreezerDatabase opens the ancient store in the freezer directory and
+// checks it against the key-value store, leaving the freezing to the caller.
+func openFreezerDatabase(db ethdb.Database, freezer string, namespace string) (*freezerDatabase, error) {
+	frdb, err := ethdb.NewFreezer(freezer, namespace, freezerNoSnappy)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkFreezer(db, frdb); err != nil {
+		frdb.Close()
+		return nil, err
+	}
+	return &freezerDatabase{Database: db, Freezer: frdb, quit: make(chan struct{})}, nil
+}
+
+// checkFreezer ensures the key-value store and the freezer belong to the same
+// chain and that there's no gap between them, so neither gets corrupted by
+// serving conflicting data. The freezer being ahead of the key-value store is
+// accepted, the blockchain truncates it on startup.
+func checkFreezer(db ethdb.Database, frdb *ethdb.Freezer) error {
+	kvgenesis, _ := db.Get(canonicalHashKey(0))
+	if len(kvgenesis) == 0 {
+		return nil
+	}
+	head := GetBlockNumber(db, GetHeadHeaderHash(db))
+
+	frozen, _ := frdb.Ancients()
+	if frozen == 0 {
+		// An empty freezer must not be paired with an already migrated database
+		if kvblob, _ := db.Get(canonicalHashKey(1)); len(kvblob) == 0 && head != missingNumber && head > 0 {
+			return errAncientExtracted
+		}
+		return nil
+	}
+	if frgenesis, _ := frdb.Ancient(freezerHashTable, 0); !bytes.Equal(kvgenesis, frgenesis) {
+		return fmt.Errorf("genesis mismatch: %#x (leveldb) != %#x (ancients)", kvgenesis, frgenesis)
+	}
+	if kvhash, _ := db.Get(canonicalHashKey(frozen)); len(kvhash) == 0 && head != missingNumber && head > frozen-1 {
+		return fmt.Errorf("gap (#%d) in the chain between ancients and leveldb", frozen)
+	}
+	return nil
+}
+
+// Close stops the freezing and closes both the freezer and the key-value store.
+func (db *freezerDatabase) Close() {
+	close(db.quit)
+	db.wg.Wait()
+
+	if err := db.Freezer.Close(); err != nil {
+		log.Error("Failed to close ancient database", "err", err)
+	}
+	db.Database.Close()
+}
+
+// freeze is a background thread that periodically checks the blockchain for any
+// import progress and moves ancient data from the fast database into the freezer.
+func (db *freezerDatabase) freeze() {
+	defer db.wg.Done()
+
+	backoff := false
+	for {
+		if backoff {
+			select {
+			case <-time.After(freezerRecheckInterval):
+			case <-db.quit:
+				return
+			}
+		} else {
+			select {
+			case <-db.quit:
+				return
+			default:
+			}
+		}
+		backoff = true
+
+		// Avoid database thrashing with tiny writes
+		if db.freezeBatch() >= freezerBatchLimit {
+			backoff = false
+		}
+	}
+}
+
+// freezeBatch moves the canonical blocks older than the threshold from the
+// key-value store into the freezer, at most freezerBatchLimit of them, and
+// returns the number of blocks frozen.
+func (db *freezerDatabase) freezeBatch() uint64 {
+	// Retrieve the freezing threshold from the head of the key-value store
+	hash := GetHeadBlockHash(db.Database)
+	if hash == (common.Hash{}) {
+		log.Debug("Current full block hash unavailable")
+		return 0
+	}
+	number := GetBlockNumber(db.Database, hash)
+	if number == missingNumber {
+		log.Error("Current full block number unavailable", "hash", hash)
+		return 0
+	}
+	frozen, _ := db.Ancients()
+	if number <= db.threshold || number-db.threshold <= frozen {
+		log.Debug("Ancient blocks frozen already", "number", number, "frozen", frozen)
+		return 0
+	}
+	limit := number - db.threshold
+	if limit-frozen > freezerBatchLimit {
+		limit = frozen + freezerBatchLimit
+	}
+	// Move the canonical blocks over into the freezer
+	var (
+		start  = time.Now()
+		first  = frozen
+		hashes []common.Hash
+	)
+	for ; frozen < limit; frozen++ {
+		items, hash, err := db.ancientItems(frozen)
+		if err != nil {
+			log.Error("Failed to freeze block", "number", frozen, "err", err)
+			break
+		}
+		if err := db.AppendAncient(frozen, items); err != nil {
+			log.Error("Failed to deep freeze chain segment", "err", err)
+			break
+		}
+		hashes = append(hashes, hash)
+	}
+	if err := db.Sync(); err != nil {
+		log.Crit("Failed to flush frozen tables", "err", err)
+	}
+	// Wipe out the frozen blocks from the key-value store, keeping the genesis
+	// and the hash to number mappings around
+	batch := db.Database.NewBatch()
+	for i, hash := range hashes {
+		if number := first + uint64(i); number != 0 {
+			batch.Delete(canonicalHashKey(number))
+			batch.Delete(headerKey(hash, number))
+			batch.Delete(headerTdKey(hash, number))
+			batch.Delete(blockBodyKey(hash, number))
+			batch.Delete(blockReceiptsKey(hash, number))
+		}
+	}
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to delete frozen canonical blocks", "err", err)
+	}
+	if len(hashes) > 0 {
+		log.Info("Deep froze chain segment", "blocks", len(hashes), "elapsed", common.PrettyDuration(time.Since(start)),
+			"number", frozen-1, "hash", hashes[len(hashes)-1])
+	}
+	return frozen - first
+}
+
+// ancientItems gathers the raw data of the canonical block at number from the
+// key-value store, keyed by freezer table.
+func (db *freezerDatabase) ancientItems(number uint64) (map[string][]byte, common.Hash, error) {
+	hash := GetCanonicalHash(db.Database, number)
+	if hash == (common.Hash{}) {
+		return nil, hash, errors.New("canonical hash missing")
+	}
+	header := GetHeaderRLP(db.Database, hash, number)
+	if len(header) == 0 {
+		return nil, hash, errors.New("block header missing")
+	}
+	body := GetBodyRLP(db.Database, hash, number)
+	if len(body) == 0 {
+		return nil, hash, errors.New("block body missing")
+	}
+	receipts, _ := db.Database.Get(blockReceiptsKey(hash, number))
+	if len(receipts) == 0 {
+		return nil, hash, errors.New("block receipts missing")
+	}
+	td, _ := db.Database.Get(headerTdKey(hash, number))
+	if len(td) == 0 {
+		return nil, hash, errors.New("total difficulty missing")
+	}
+	items := map[string][]byte{
+		freezerHashTable:       hash[:],
+		freezerHeaderTable:     header,
+		freezerBodiesTable:     body,
+		freezerReceiptTable:    receipts,
+		freezerDifficultyTable: td,
+	}
+	return items, hash, nil
+}
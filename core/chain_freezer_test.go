@@ -0,0 +1,184 @@
+// Copyright 2018 The mesh-chain Authors
+// This file is part of the mesh-chain library.
+//
+// The mesh-chain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The mesh-chain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the mesh-chain library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/MeshBoxTech/mesh-chain/consensus/ethash"
+	"github.com/MeshBoxTech/mesh-chain/core/vm"
+	"github.com/MeshBoxTech/mesh-chain/ethdb"
+	"github.com/MeshBoxTech/mesh-chain/params"
+)
+
+// newTestFreezerDatabase wraps db with a freezer in dir without starting the
+// background freezing, so tests can drive it.
+func newTestFreezerDatabase(t *testing.T, db ethdb.Database, dir string, threshold uint64) *freezerDatabase {
+	frdb, err := ethdb.NewFreezer(dir, "", freezerNoSnappy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkFreezer(db, frdb); err != nil {
+		t.Fatal(err)
+	}
+	return &freezerDatabase{Database: db, Freezer: frdb, threshold: threshold, quit: make(chan struct{})}
+}
+
+func TestChainFreezer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ancient")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	kvdb, _ := ethdb.NewMemDatabase()
+	gspec := &Genesis{Config: params.TestChainConfig}
+	genesis := gspec.MustCommit(kvdb)
+
+	db := newTestFreezerDatabase(t, kvdb, dir, 4)
+	defer db.Close()
+
+	chain, err := NewBlockChain(db, nil, params.TestChainConfig, ethash.NewFaker(), vm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chain.Stop()
+
+	blocks, _ := GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), kvdb, 10, nil)
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatal(err)
+	}
+	if n := db.freezeBatch(); n != 6 {
+		t.Fatalf("frozen block count mismatch: have %d, want 6", n)
+	}
+	if n := db.freezeBatch(); n != 0 {
+		t.Fatalf("blocks frozen twice: %d", n)
+	}
+	// The frozen blocks are gone from the key-value store but still served
+	if GetCanonicalHash(kvdb, 0) != genesis.Hash() {
+		t.Errorf("genesis dropped from the key-value store")
+	}
+	td := GetTd(db, genesis.Hash(), 0)
+	for _, block := range blocks {
+		hash, number := block.Hash(), block.NumberU64()
+		if frozen := number < 6; frozen != (GetHeader(kvdb, hash, number) == nil) {
+			t.Errorf("block %d: frozen %v, but header in key-value store %v", number, frozen, !frozen)
+		}
+		if GetCanonicalHash(db, number) != hash {
+			t.Errorf("block %d: canonical hash mismatch", number)
+		}
+		if header := GetHeader(db, hash, number); header == nil || header.Hash() != hash {
+			t.Errorf("block %d: header mismatch: %v", number, header)
+		}
+		if body := GetBody(db, hash, number); body == nil {
+			t.Errorf("block %d: body missing", number)
+		}
+		if receipts := GetBlockReceipts(db, hash, number); receipts == nil {
+			t.Errorf("block %d: receipts missing", number)
+		}
+		td = new(big.Int).Add(td, block.Difficulty())
+		if have := GetTd(db, hash, number); have == nil || have.Cmp(td) != 0 {
+			t.Errorf("block %d: td mismatch: have %v, want %v", number, have, td)
+		}
+		if !chain.HasBlock(hash, number) || !chain.HasHeader(hash, number) {
+			t.Errorf("block %d: not found by the chain", number)
+		}
+	}
+	// Rewinding below the frozen blocks truncates the freezer
+	if err := chain.SetHead(3); err != nil {
+		t.Fatal(err)
+	}
+	if frozen, _ := db.Ancients(); frozen != 4 {
+		t.Errorf("frozen count mismatch after rewind: have %d, want 4", frozen)
+	}
+	if head := chain.CurrentBlock(); head.Hash() != blocks[2].Hash() {
+		t.Errorf("head mismatch after rewind: have %d, want 3", head.NumberU64())
+	}
+	if GetHeader(db, blocks[4].Hash(), 5) != nil {
+		t.Errorf("rewound block still served")
+	}
+}
+
+func TestChainFreezerMismatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ancient")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	kvdb, _ := ethdb.NewMemDatabase()
+	genesis := (&Genesis{Config: params.TestChainConfig}).MustCommit(kvdb)
+
+	db := newTestFreezerDatabase(t, kvdb, dir, 4)
+	chain, err := NewBlockChain(db, nil, params.TestChainConfig, ethash.NewFaker(), vm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks, _ := GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), kvdb, 10, nil)
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatal(err)
+	}
+	db.freezeBatch()
+	chain.Stop()
+	db.Freezer.Close()
+
+	// A migrated database must not be opened with an empty freezer
+	empty, err := ioutil.TempDir("", "ancient")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(empty)
+
+	if _, err := NewDatabaseWithFreezer(kvdb, empty, "", 4); err != errAncientExtracted {
+		t.Errorf("empty freezer: have error %v, want %v", err, errAncientExtracted)
+	}
+	// The freezer must not be paired with a database of another chain
+	otherdb, _ := ethdb.NewMemDatabase()
+	(&Genesis{Config: params.TestChainConfig, ExtraData: []byte("other")}).MustCommit(otherdb)
+
+	if _, err := NewDatabaseWithFreezer(otherdb, dir, "", 4); err == nil {
+		t.Errorf("freezer of another chain accepted")
+	}
+	// Opening the matching freezer works
+	fdb, err := NewDatabaseWithFreezer(kvdb, dir, "", 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash := GetCanonicalHash(fdb, 3); hash != blocks[2].Hash() {
+		t.Errorf("frozen block not served after reopen")
+	}
+	fdb.Close()
+
+	// Attaching the ancients without freezing serves the frozen blocks too
+	adb, err := NewDatabaseWithAncients(kvdb, dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := adb.(*freezerDatabase); !ok {
+		t.Fatalf("ancient store not attached: %T", adb)
+	}
+	if frozen, _ := adb.(ethdb.AncientReader).Ancients(); frozen != 6 {
+		t.Errorf("frozen count mismatch: have %d, want 6", frozen)
+	}
+	if hash := GetCanonicalHash(adb, 3); hash != blocks[2].Hash() {
+		t.Errorf("frozen block not served without freezing")
+	}
+	adb.Close()
+}
@@ -17,6 +17,7 @@
 package core
 
 import (
+	"bytes"
 	"math/big"
 	"testing"
 
@@ -45,7 +46,7 @@ func TestDAOForkRangeExtradata(t *testing.T) {
 	proConf.DAOForkBlock = forkBlock
 	proConf.DAOForkSupport = true
 
-	proBc, _ := NewBlockChain(proDb, &proConf, ethash.NewFaker(), vm.Config{})
+	proBc, _ := NewBlockChain(proDb, nil, &proConf, ethash.NewFaker(), vm.Config{})
 	defer proBc.Stop()
 
 	conDb, _ := ethdb.NewMemDatabase()
@@ -55,7 +56,7 @@ func TestDAOForkRangeExtradata(t *testing.T) {
 	conConf.DAOForkBlock = forkBlock
 	conConf.DAOForkSupport = false
 
-	conBc, _ := NewBlockChain(conDb, &conConf, ethash.NewFaker(), vm.Config{})
+	conBc, _ := NewBlockChain(conDb, nil, &conConf, ethash.NewFaker(), vm.Config{})
 	defer conBc.Stop()
 
 	if _, err := proBc.InsertChain(prefix); err != nil {
@@ -69,7 +70,7 @@ func TestDAOForkRangeExtradata(t *testing.T) {
 		// Create a pro-fork block, and try to feed into the no-fork chain
 		db, _ = ethdb.NewMemDatabase()
 		gspec.MustCommit(db)
-		bc, _ := NewBlockChain(db, &conConf, ethash.NewFaker(), vm.Config{})
+		bc, _ := NewBlockChain(db, nil, &conConf, ethash.NewFaker(), vm.Config{})
 		defer bc.Stop()
 
 		blocks := conBc.GetBlocksFromHash(conBc.CurrentBlock().Hash(), int(conBc.CurrentBlock().NumberU64()))
@@ -91,7 +92,7 @@ func TestDAOForkRangeExtradata(t *testing.T) {
 		// Create a no-fork block, and try to feed into the pro-fork chain
 		db, _ = ethdb.NewMemDatabase()
 		gspec.MustCommit(db)
-		bc, _ = NewBlockChain(db, &proConf, ethash.NewFaker(), vm.Config{})
+		bc, _ = NewBlockChain(db, nil, &proConf, ethash.NewFaker(), vm.Config{})
 		defer bc.Stop()
 
 		blocks = proBc.GetBlocksFromHash(proBc.CurrentBlock().Hash(), int(proBc.CurrentBlock().NumberU64()))
@@ -114,7 +115,7 @@ func TestDAOForkRangeExtradata(t *testing.T) {
 	// Verify that contra-forkers accept pro-fork extra-datas after forking finishes
 	db, _ = ethdb.NewMemDatabase()
 	gspec.MustCommit(db)
-	bc, _ := NewBlockChain(db, &conConf, ethash.NewFaker(), vm.Config{})
+	bc, _ := NewBlockChain(db, nil, &conConf, ethash.NewFaker(), vm.Config{})
 	defer bc.Stop()
 
 	blocks := conBc.GetBlocksFromHash(conBc.CurrentBlock().Hash(), int(conBc.CurrentBlock().NumberU64()))
@@ -131,7 +132,7 @@ func TestDAOForkRangeExtradata(t *testing.T) {
 	// Verify that pro-forkers accept contra-fork extra-datas after forking finishes
 	db, _ = ethdb.NewMemDatabase()
 	gspec.MustCommit(db)
-	bc, _ = NewBlockChain(db, &proConf, ethash.NewFaker(), vm.Config{})
+	bc, _ = NewBlockChain(db, nil, &proConf, ethash.NewFaker(), vm.Config{})
 	defer bc.Stop()
 
 	blocks = proBc.GetBlocksFromHash(proBc.CurrentBlock().Hash(), int(proBc.CurrentBlock().NumberU64()))
@@ -146,3 +147,51 @@ func TestDAOForkRangeExtradata(t *testing.T) {
 		t.Fatalf("pro-fork chain didn't accept contra-fork block post-fork: %v", err)
 	}
 }
+
+// Tests that the Validators contract is upgraded exactly at the tribe evidence
+// fork block, and that blocks built without the upgrade are rejected.
+func TestEvidenceForkValidatorsCode(t *testing.T) {
+	code := []byte{0x60, 0x00, 0x60, 0x00, 0xf3}
+
+	config := *params.TestChainConfig
+	config.Tribe = &params.TribeConfig{Period: 15, Epoch: 30, EvidenceBlock: big.NewInt(2), ValidatorsCode: code}
+
+	db, _ := ethdb.NewMemDatabase()
+	gspec := &Genesis{Config: &config}
+	genesis := gspec.MustCommit(db)
+	blocks, _ := GenerateChain(&config, genesis, ethash.NewFaker(), db, 3, func(i int, gen *BlockGen) {})
+
+	chainDb, _ := ethdb.NewMemDatabase()
+	gspec.MustCommit(chainDb)
+	chain, _ := NewBlockChain(chainDb, nil, &config, ethash.NewFaker(), vm.Config{})
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to import forked chain: %v", err)
+	}
+	for i, block := range blocks {
+		statedb, err := chain.StateAt(block.Root())
+		if err != nil {
+			t.Fatalf("block %d: failed to open state: %v", block.NumberU64(), err)
+		}
+		want := []byte(nil)
+		if i > 0 {
+			want = code
+		}
+		if have := statedb.GetCode(params.ValidatorsContractAddr); !bytes.Equal(have, want) {
+			t.Errorf("block %d: validators code mismatch: have %x, want %x", block.NumberU64(), have, want)
+		}
+	}
+	// A chain upgraded to different code diverges at the fork block
+	other := config
+	other.Tribe = &params.TribeConfig{Period: 15, Epoch: 30, EvidenceBlock: big.NewInt(2), ValidatorsCode: []byte{0x00}}
+
+	otherDb, _ := ethdb.NewMemDatabase()
+	gspec.MustCommit(otherDb)
+	otherChain, _ := NewBlockChain(otherDb, nil, &other, ethash.NewFaker(), vm.Config{})
+	defer otherChain.Stop()
+
+	if n, err := otherChain.InsertChain(blocks); err == nil || n != 1 {
+		t.Fatalf("block with different validators code imported: index %d, err %v", n, err)
+	}
+}
@@ -0,0 +1,203 @@
+// Copyright 2018 The mesh-chain Authors
+// This file is part of the mesh-chain library.
+//
+// The mesh-chain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The mesh-chain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the mesh-chain library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"sort"
+	"time"
+
+	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/core/state/snapshot"
+	"github.com/MeshBoxTech/mesh-chain/ethdb"
+	"github.com/MeshBoxTech/mesh-chain/log"
+	"github.com/MeshBoxTech/mesh-chain/rlp"
+)
+
+var (
+	// Key prefixes of the consensus engines, see the Snapshot.store methods
+	tribeSnapshotPrefix  = []byte("tribe-")
+	tribeRecordPrefixes  = [][]byte{[]byte("tribe-rewards-"), []byte("tribe-stats-"), []byte("tribe-evidence-")}
+	cliqueSnapshotPrefix = []byte("clique-")
+
+	// Singleton keys tracking the state of the database
+	metadataKeys = [][]byte{headHeaderKey, headBlockKey, headFastKey, []byte("BlockchainVersion"), badBlockKey, txIndexTailKey, snapshot.SnapshotRootKey, snapshot.SnapshotGeneratorKey}
+)
+
+// DatabaseStat is the number of items of a category of database content, along
+// with their total size including the keys.
+type DatabaseStat struct {
+	Database string             // Store holding the data, key-value or ancient
+	Category string             // Kind of data stored
+	Items    uint64             // Number of entries
+	Size     common.StorageSize // Total size of the keys and values
+}
+
+// databaseCounter accumulates the entries of a category during inspection.
+type databaseCounter struct {
+	category string
+	items    uint64
+	size     common.StorageSize
+}
+
+func (c *databaseCounter) add(size int) {
+	c.items++
+	c.size += common.StorageSize(size)
+}
+
+// InspectDatabase traverses the entire key-value store and reports the number and
+// size of the entries per category, followed by the tables of the ancient store
+// if db has one. Keys that don't belong to any known category are reported as
+// unaccounted.
+func InspectDatabase(db ethdb.Database) ([]DatabaseStat, error) {
+	it := db.NewIterator(nil, nil)
+	defer it.Release()
+
+	var (
+		headers     = &databaseCounter{category: "Headers"}
+		bodies      = &databaseCounter{category: "Bodies"}
+		receipts    = &databaseCounter{category: "Receipts"}
+		tds         = &databaseCounter{category: "Difficulties"}
+		numHashes   = &databaseCounter{category: "Block number->hash"}
+		hashNums    = &databaseCounter{category: "Block hash->number"}
+		txLookups   = &databaseCounter{category: "Transaction lookups"}
+		bloomBits   = &databaseCounter{category: "Bloom bits"}
+		tries       = &databaseCounter{category: "Trie nodes"}
+		codes       = &databaseCounter{category: "Contract codes"}
+		preimages   = &databaseCounter{category: "Trie preimages"}
+		snapAccs    = &databaseCounter{category: "Account snapshot"}
+		snapSlots   = &databaseCounter{category: "Storage snapshot"}
+		tribeSnaps  = &databaseCounter{category: "Tribe snapshots"}
+		tribeRecs   = &databaseCounter{category: "Tribe rewards and statistics"}
+		cliqueSnaps = &databaseCounter{category: "Clique snapshots"}
+		configs     = &databaseCounter{category: "Chain configs"}
+		metadata    = &databaseCounter{category: "Singleton metadata"}
+		unaccounted = &databaseCounter{category: "Unaccounted"}
+
+		count  uint64
+		start  = time.Now()
+		logged = time.Now()
+	)
+	for it.Next() {
+		var (
+			key  = it.Key()
+			size = len(key) + len(it.Value())
+		)
+		switch {
+		case bytes.HasPrefix(key, headerPrefix) && len(key) == len(headerPrefix)+8+common.HashLength:
+			headers.add(size)
+		case bytes.HasPrefix(key, headerPrefix) && len(key) == len(headerPrefix)+8+common.HashLength+len(tdSuffix) && bytes.HasSuffix(key, tdSuffix):
+			tds.add(size)
+		case bytes.HasPrefix(key, headerPrefix) && len(key) == len(headerPrefix)+8+len(numSuffix) && bytes.HasSuffix(key, numSuffix):
+			numHashes.add(size)
+		case bytes.HasPrefix(key, blockHashPrefix) && len(key) == len(blockHashPrefix)+common.HashLength:
+			hashNums.add(size)
+		case bytes.HasPrefix(key, bodyPrefix) && len(key) == len(bodyPrefix)+8+common.HashLength:
+			bodies.add(size)
+		case bytes.HasPrefix(key, blockReceiptsPrefix) && len(key) == len(blockReceiptsPrefix)+8+common.HashLength:
+			receipts.add(size)
+		case bytes.HasPrefix(key, lookupPrefix) && len(key) == len(lookupPrefix)+common.HashLength:
+			txLookups.add(size)
+		case bytes.HasPrefix(key, bloomBitsPrefix) && len(key) == len(bloomBitsPrefix)+2+8+common.HashLength:
+			bloomBits.add(size)
+		case bytes.HasPrefix(key, BloomBitsIndexPrefix):
+			bloomBits.add(size)
+		case bytes.HasPrefix(key, snapshot.AccountPrefix) && len(key) == len(snapshot.AccountPrefix)+common.HashLength:
+			snapAccs.add(size)
+		case bytes.HasPrefix(key, snapshot.StoragePrefix) && len(key) == len(snapshot.StoragePrefix)+2*common.HashLength:
+			snapSlots.add(size)
+		case bytes.HasPrefix(key, []byte(preimagePrefix)) && len(key) == len(preimagePrefix)+common.HashLength:
+			preimages.add(size)
+		case bytes.HasPrefix(key, configPrefix) && len(key) == len(configPrefix)+common.HashLength:
+			configs.add(size)
+		case hasAnyPrefix(key, tribeRecordPrefixes):
+			tribeRecs.add(size)
+		case bytes.HasPrefix(key, tribeSnapshotPrefix) && len(key) == len(tribeSnapshotPrefix)+common.HashLength:
+			tribeSnaps.add(size)
+		case bytes.HasPrefix(key, cliqueSnapshotPrefix) && len(key) == len(cliqueSnapshotPrefix)+common.HashLength:
+			cliqueSnaps.add(size)
+		case len(key) == common.HashLength:
+			// Trie nodes and contract codes are both keyed by their hash, only
+			// trie nodes are RLP lists
+			if _, rest, err := rlp.SplitList(it.Value()); err == nil && len(rest) == 0 {
+				tries.add(size)
+			} else {
+				codes.add(size)
+			}
+		case hasAnyKey(key, metadataKeys):
+			metadata.add(size)
+		default:
+			unaccounted.add(size)
+		}
+		count++
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Inspecting database", "count", count, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	var stats []DatabaseStat
+	for _, c := range []*databaseCounter{
+		headers, bodies, receipts, tds, numHashes, hashNums, txLookups, bloomBits, tries, codes,
+		preimages, snapAccs, snapSlots, tribeSnaps, tribeRecs, cliqueSnaps, configs, metadata, unaccounted,
+	} {
+		stats = append(stats, DatabaseStat{Database: "Key-Value store", Category: c.category, Items: c.items, Size: c.size})
+	}
+	// Append the ancient tables, all holding one item per frozen block
+	if ancients, ok := db.(ethdb.AncientReader); ok {
+		frozen, err := ancients.Ancients()
+		if err != nil {
+			return nil, err
+		}
+		kinds := make([]string, 0, len(freezerNoSnappy))
+		for kind := range freezerNoSnappy {
+			kinds = append(kinds, kind)
+		}
+		sort.Strings(kinds)
+		for _, kind := range kinds {
+			size, err := ancients.AncientSize(kind)
+			if err != nil {
+				return nil, err
+			}
+			stats = append(stats, DatabaseStat{Database: "Ancient store", Category: kind, Items: frozen, Size: common.StorageSize(size)})
+		}
+	}
+	log.Info("Inspected database", "count", count, "elapsed", common.PrettyDuration(time.Since(start)))
+	return stats, nil
+}
+
+// hasAnyPrefix reports whether key starts with any of the prefixes.
+func hasAnyPrefix(key []byte, prefixes [][]byte) bool {
+	for _, prefix := range prefixes {
+		if bytes.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasAnyKey reports whether key equals any of the keys.
+func hasAnyKey(key []byte, keys [][]byte) bool {
+	for _, k := range keys {
+		if bytes.Equal(key, k) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+// Copyright 2018 The mesh-chain Authors
+// This file is part of the mesh-chain library.
+//
+// The mesh-chain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The mesh-chain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the mesh-chain library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/consensus/ethash"
+	"github.com/MeshBoxTech/mesh-chain/core/vm"
+	"github.com/MeshBoxTech/mesh-chain/ethdb"
+	"github.com/MeshBoxTech/mesh-chain/params"
+)
+
+func TestInspectDatabase(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ancient")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	kvdb, _ := ethdb.NewMemDatabase()
+	gspec := &Genesis{
+		Config: params.TestChainConfig,
+		Alloc:  GenesisAlloc{common.HexToAddress("0x01"): {Code: []byte{0x60, 0x00}, Balance: new(big.Int)}},
+	}
+	genesis := gspec.MustCommit(kvdb)
+
+	db := newTestFreezerDatabase(t, kvdb, dir, 4)
+	defer db.Close()
+
+	chain, err := NewBlockChain(db, nil, params.TestChainConfig, ethash.NewFaker(), vm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chain.Stop()
+
+	blocks, _ := GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), kvdb, 10, nil)
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatal(err)
+	}
+	db.freezeBatch()
+
+	kvdb.Put(append([]byte("tribe-"), genesis.Hash().Bytes()...), []byte("{}"))
+	kvdb.Put([]byte("tribe-evidence-"), []byte{0x01})
+	kvdb.Put(common.Hex2Bytes("deadbeef"), []byte{0x02})
+
+	stats, err := InspectDatabase(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := make(map[string]uint64)
+	for _, stat := range stats {
+		items[stat.Database+"/"+stat.Category] = stat.Items
+		if stat.Items > 0 && stat.Size == 0 {
+			t.Errorf("%s/%s: size missing for %d items", stat.Database, stat.Category, stat.Items)
+		}
+	}
+	// Genesis and the 5 blocks above the frozen ones remain in the key-value store
+	for category, want := range map[string]uint64{
+		"Key-Value store/Headers":                      6,
+		"Key-Value store/Bodies":                       6,
+		"Key-Value store/Receipts":                     6,
+		"Key-Value store/Difficulties":                 6,
+		"Key-Value store/Block number->hash":           6,
+		"Key-Value store/Contract codes":               1,
+		"Key-Value store/Block hash->number":           11,
+		"Key-Value store/Chain configs":                1,
+		"Key-Value store/Tribe snapshots":              1,
+		"Key-Value store/Tribe rewards and statistics": 1,
+		"Key-Value store/Unaccounted":                  1,
+		"Ancient store/headers":                        6,
+		"Ancient store/receipts":                       6,
+	} {
+		if have := items[category]; have != want {
+			t.Errorf("%s: item count mismatch: have %d, want %d", category, have, want)
+		}
+	}
+	if items["Key-Value store/Trie nodes"] == 0 {
+		t.Errorf("no trie nodes found")
+	}
+	if items["Key-Value store/Singleton metadata"] == 0 {
+		t.Errorf("no metadata found")
+	}
+}
@@ -47,6 +47,9 @@ var (
 	headBlockKey  = []byte("LastBlock")
 	headFastKey   = []byte("LastFast")
 
+	// txIndexTailKey tracks the oldest block whose transactions are indexed.
+	txIndexTailKey = []byte("TransactionIndexTail")
+
 	// Data item prefixes (use single byte to avoid mixing data types, avoid `i`).
 	headerPrefix        = []byte("h") // headerPrefix + num (uint64 big endian) + hash -> header
 	tdSuffix            = []byte("t") // headerPrefix + num (uint64 big endian) + hash + tdSuffix -> td
@@ -90,7 +93,12 @@ func encodeBlockNumber(number uint64) []byte {
 
 // GetCanonicalHash retrieves a hash assigned to a canonical block number.
 func GetCanonicalHash(db DatabaseReader, number uint64) common.Hash {
-	data, _ := db.Get(append(append(headerPrefix, encodeBlockNumber(number)...), numSuffix...))
+	if ancients, ok := db.(ethdb.AncientReader); ok {
+		if data, _ := ancients.Ancient(freezerHashTable, number); len(data) > 0 {
+			return common.BytesToHash(data)
+		}
+	}
+	data, _ := db.Get(canonicalHashKey(number))
 	if len(data) == 0 {
 		return common.Hash{}
 	}
@@ -148,10 +156,22 @@ func GetHeadFastBlockHash(db DatabaseReader) common.Hash {
 // GetHeaderRLP retrieves a block header in its raw RLP database encoding, or nil
 // if the header's not found.
 func GetHeaderRLP(db DatabaseReader, hash common.Hash, number uint64) rlp.RawValue {
+	if data := getAncient(db, freezerHeaderTable, hash, number); len(data) > 0 {
+		return data
+	}
 	data, _ := db.Get(headerKey(hash, number))
 	return data
 }
 
+// HasHeader verifies the existence of a block header corresponding to the hash.
+func HasHeader(db ethdb.Database, hash common.Hash, number uint64) bool {
+	if hasAncient(db, hash, number) {
+		return true
+	}
+	ok, _ := db.Has(headerKey(hash, number))
+	return ok
+}
+
 // GetHeader retrieves the block header corresponding to the hash, nil if none
 // found.
 func GetHeader(db DatabaseReader, hash common.Hash, number uint64) *types.Header {
@@ -169,18 +189,64 @@ func GetHeader(db DatabaseReader, hash common.Hash, number uint64) *types.Header
 
 // GetBodyRLP retrieves the block body (transactions and uncles) in RLP encoding.
 func GetBodyRLP(db DatabaseReader, hash common.Hash, number uint64) rlp.RawValue {
+	if data := getAncient(db, freezerBodiesTable, hash, number); len(data) > 0 {
+		return data
+	}
 	data, _ := db.Get(blockBodyKey(hash, number))
 	return data
 }
 
+// HasBody verifies the existence of a block body corresponding to the hash.
+func HasBody(db ethdb.Database, hash common.Hash, number uint64) bool {
+	if hasAncient(db, hash, number) {
+		return true
+	}
+	ok, _ := db.Has(blockBodyKey(hash, number))
+	return ok
+}
+
+func canonicalHashKey(number uint64) []byte {
+	return append(append(headerPrefix, encodeBlockNumber(number)...), numSuffix...)
+}
+
 func headerKey(hash common.Hash, number uint64) []byte {
 	return append(append(headerPrefix, encodeBlockNumber(number)...), hash.Bytes()...)
 }
 
+func headerTdKey(hash common.Hash, number uint64) []byte {
+	return append(headerKey(hash, number), tdSuffix...)
+}
+
 func blockBodyKey(hash common.Hash, number uint64) []byte {
 	return append(append(bodyPrefix, encodeBlockNumber(number)...), hash.Bytes()...)
 }
 
+func blockReceiptsKey(hash common.Hash, number uint64) []byte {
+	return append(append(blockReceiptsPrefix, encodeBlockNumber(number)...), hash.Bytes()...)
+}
+
+// getAncient retrieves an item of a block from the ancient store backing db, nil
+// if there's no ancient store or the block with the given hash hasn't been frozen.
+func getAncient(db DatabaseReader, kind string, hash common.Hash, number uint64) []byte {
+	if !hasAncient(db, hash, number) {
+		return nil
+	}
+	data, _ := db.(ethdb.AncientReader).Ancient(kind, number)
+	return data
+}
+
+// hasAncient checks whether the block with the given hash and number has been
+// frozen into the ancient store backing db. Only canonical blocks are frozen, so
+// the hash is checked against the frozen one.
+func hasAncient(db DatabaseReader, hash common.Hash, number uint64) bool {
+	ancients, ok := db.(ethdb.AncientReader)
+	if !ok {
+		return false
+	}
+	data, _ := ancients.Ancient(freezerHashTable, number)
+	return bytes.Equal(data, hash[:])
+}
+
 // GetBody retrieves the block body (transactons, uncles) corresponding to the
 // hash, nil if none found.
 func GetBody(db DatabaseReader, hash common.Hash, number uint64) *types.Body {
@@ -199,7 +265,10 @@ func GetBody(db DatabaseReader, hash common.Hash, number uint64) *types.Body {
 // GetTd retrieves a block's total difficulty corresponding to the hash, nil if
 // none found.
 func GetTd(db DatabaseReader, hash common.Hash, number uint64) *big.Int {
-	data, _ := db.Get(append(append(append(headerPrefix, encodeBlockNumber(number)...), hash[:]...), tdSuffix...))
+	data := getAncient(db, freezerDifficultyTable, hash, number)
+	if len(data) == 0 {
+		data, _ = db.Get(headerTdKey(hash, number))
+	}
 	if len(data) == 0 {
 		return nil
 	}
@@ -234,7 +303,10 @@ func GetBlock(db DatabaseReader, hash common.Hash, number uint64) *types.Block {
 // GetBlockReceipts retrieves the receipts generated by the transactions included
 // in a block given by its hash.
 func GetBlockReceipts(db DatabaseReader, hash common.Hash, number uint64) types.Receipts {
-	data, _ := db.Get(append(append(blockReceiptsPrefix, encodeBlockNumber(number)...), hash[:]...))
+	data := getAncient(db, freezerReceiptTable, hash, number)
+	if len(data) == 0 {
+		data, _ = db.Get(blockReceiptsKey(hash, number))
+	}
 	if len(data) == 0 {
 		return nil
 	}
@@ -250,6 +322,17 @@ func GetBlockReceipts(db DatabaseReader, hash common.Hash, number uint64) types.
 	return receipts
 }
 
+// GetTxIndexTail retrieves the number of the oldest block whose transactions are
+// indexed, or nil if the tail was never set, meaning all blocks are indexed.
+func GetTxIndexTail(db DatabaseReader) *uint64 {
+	data, _ := db.Get(txIndexTailKey)
+	if len(data) != 8 {
+		return nil
+	}
+	number := binary.BigEndian.Uint64(data)
+	return &number
+}
+
 // GetTxLookupEntry retrieves the positional metadata associated with a transaction
 // hash to allow retrieving the transaction or receipt by hash.
 func GetTxLookupEntry(db DatabaseReader, hash common.Hash) (common.Hash, uint64, uint64) {
@@ -479,6 +562,15 @@ func WriteTxLookupEntries(db ethdb.Putter, block *types.Block) error {
 	return nil
 }
 
+// WriteTxIndexTail stores the number of the oldest block whose transactions are
+// indexed.
+func WriteTxIndexTail(db ethdb.Putter, number uint64) error {
+	if err := db.Put(txIndexTailKey, encodeBlockNumber(number)); err != nil {
+		log.Crit("Failed to store transaction index tail", "err", err)
+	}
+	return nil
+}
+
 // WriteBloomBits writes the compressed bloom bits vector belonging to the given
 // section and bit index.
 func WriteBloomBits(db ethdb.Putter, bit uint, section uint64, head common.Hash, bits []byte) {
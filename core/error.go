@@ -33,5 +33,17 @@ var (
 	// next one expected based on the local chain.
 	ErrNonceTooHigh = errors.New("nonce too high")
 
+	// ErrSponsorNotAllowed is returned if the sponsor of a transaction has not
+	// been allowed to pay for calls to its destination.
+	ErrSponsorNotAllowed = errors.New("sponsor not allowed for destination")
+
+	// ErrTipAboveFeeCap is returned if a transaction's tip cap is higher than
+	// its fee cap.
+	ErrTipAboveFeeCap = errors.New("max priority fee per gas higher than max fee per gas")
+
+	// ErrFeeCapTooLow is returned if a transaction's fee cap is lower than the
+	// base fee of the block.
+	ErrFeeCapTooLow = errors.New("max fee per gas less than block base fee")
+
 
 )
@@ -49,6 +49,10 @@ func NewEVMContext(msg Message, header *types.Header, chain ChainContext, author
 		}
 	*/
 	log.Debug("<<NewEVMContext.SetCoinbase>>", "num", header.Number, "ignore_author", author, "beneficiary", beneficiary)
+	var baseFee *big.Int
+	if header.BaseFee != nil {
+		baseFee = new(big.Int).Set(header.BaseFee)
+	}
 	return vm.Context{
 		CanTransfer: CanTransfer,
 		Transfer:    Transfer,
@@ -60,6 +64,7 @@ func NewEVMContext(msg Message, header *types.Header, chain ChainContext, author
 		Difficulty:  new(big.Int).Set(header.Difficulty),
 		GasLimit:    new(big.Int).Set(header.GasLimit),
 		GasPrice:    new(big.Int).Set(msg.GasPrice()),
+		BaseFee:     baseFee,
 	}
 }
 
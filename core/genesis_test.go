@@ -118,7 +118,7 @@ func TestSetupGenesis(t *testing.T) {
 				// Commit the 'old' genesis block with Homestead transition at #2.
 				// Advance to block #4, past the homestead transition block of customg.
 				genesis := oldcustomg.MustCommit(db)
-				bc, _ := NewBlockChain(db, oldcustomg.Config, ethash.NewFullFaker(), vm.Config{})
+				bc, _ := NewBlockChain(db, nil, oldcustomg.Config, ethash.NewFullFaker(), vm.Config{})
 				defer bc.Stop()
 				//bc.SetValidator(bproc{})
 				bc.InsertChain(makeBlockChainWithDiff(genesis, []int{2, 3, 4, 5}, 0))
@@ -160,6 +160,28 @@ func TestSetupGenesis(t *testing.T) {
 	}
 }
 
+// Tests that a genesis scheduling the transaction forks out of the order their
+// signers assume is rejected before anything is written.
+func TestSetupGenesisForkOrder(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	genesis := &Genesis{Config: &params.ChainConfig{
+		ChainId:     big.NewInt(1),
+		BerlinBlock: big.NewInt(0),
+		LondonBlock: big.NewInt(5),
+		Sponsor:     &params.SponsorConfig{Block: big.NewInt(10)},
+	}}
+	if _, _, err := SetupGenesisBlock(db, genesis); err == nil {
+		t.Fatalf("sponsor fork after london accepted")
+	}
+	if hash := GetCanonicalHash(db, 0); hash != (common.Hash{}) {
+		t.Fatalf("genesis written despite the error: %x", hash)
+	}
+	genesis.Config.Sponsor.Block = big.NewInt(5)
+	if _, _, err := SetupGenesisBlock(db, genesis); err != nil {
+		t.Fatalf("ordered forks rejected: %v", err)
+	}
+}
+
 func TestDecodePrealloc(t *testing.T) {
 	fn := func(alloc string) {
 		mal := decodePrealloc(alloc)
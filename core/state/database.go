@@ -17,11 +17,13 @@
 package state
 
 import (
+	"bytes"
 	"fmt"
 	"sync"
 
 	"github.com/MeshBoxTech/mesh-chain/common"
 	"github.com/MeshBoxTech/mesh-chain/ethdb"
+	"github.com/MeshBoxTech/mesh-chain/rlp"
 	"github.com/MeshBoxTech/mesh-chain/trie"
 	lru "github.com/hashicorp/golang-lru"
 )
@@ -50,6 +52,8 @@ type Database interface {
 	ContractCodeSize(addrHash, codeHash common.Hash) (int, error)
 	// CopyTrie returns an independent copy of the given trie.
 	CopyTrie(Trie) Trie
+	// TrieDB retrieves the trie node database caching the committed tries.
+	TrieDB() *trie.NodeDatabase
 }
 
 // Trie is a Ethereum Merkle Trie.
@@ -61,17 +65,37 @@ type Trie interface {
 	Hash() common.Hash
 	NodeIterator(startKey []byte) trie.NodeIterator
 	GetKey([]byte) []byte // TODO(fjl): remove this when SecureTrie is removed
+	Prove(key []byte, fromLevel uint, proofDb trie.DatabaseWriter) error
 }
 
 // NewDatabase creates a backing store for state. The returned database is safe for
-// concurrent use and retains cached trie nodes in memory.
+// concurrent use and retains cached trie nodes in memory. Tries committed to its
+// TrieDB are held in memory until they are explicitly flushed to db.
 func NewDatabase(db ethdb.Database) Database {
 	csc, _ := lru.New(codeSizeCacheSize)
-	return &cachingDB{db: db, codeSizeCache: csc}
+	return &cachingDB{
+		db:            trie.NewNodeDatabase(db, accountReferences),
+		codeSizeCache: csc,
+	}
+}
+
+// accountReferences returns the storage trie root and code hash of an account
+// leaf, so the trie node database keeps them alive along with the account.
+// Storage trie leaves are not lists and yield no references.
+func accountReferences(leaf []byte) []common.Hash {
+	var account Account
+	if err := rlp.DecodeBytes(leaf, &account); err != nil {
+		return nil
+	}
+	refs := []common.Hash{account.Root}
+	if !bytes.Equal(account.CodeHash, emptyCodeHash) {
+		refs = append(refs, common.BytesToHash(account.CodeHash))
+	}
+	return refs
 }
 
 type cachingDB struct {
-	db            ethdb.Database
+	db            *trie.NodeDatabase
 	mu            sync.Mutex
 	pastTries     []*trie.SecureTrie
 	codeSizeCache *lru.Cache
@@ -120,6 +144,10 @@ func (db *cachingDB) CopyTrie(t Trie) Trie {
 	}
 }
 
+func (db *cachingDB) TrieDB() *trie.NodeDatabase {
+	return db.db
+}
+
 func (db *cachingDB) ContractCode(addrHash, codeHash common.Hash) ([]byte, error) {
 	code, err := db.db.Get(codeHash[:])
 	if err == nil {
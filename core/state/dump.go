@@ -17,10 +17,17 @@
 package state
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"math/big"
+	"sort"
 
 	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/common/hexutil"
+	"github.com/MeshBoxTech/mesh-chain/crypto"
+	"github.com/MeshBoxTech/mesh-chain/params"
 	"github.com/MeshBoxTech/mesh-chain/rlp"
 	"github.com/MeshBoxTech/mesh-chain/trie"
 )
@@ -79,3 +86,199 @@ func (self *StateDB) Dump() []byte {
 
 	return json
 }
+
+// DumpConfig selects the accounts and the fields of an iterative state dump.
+type DumpConfig struct {
+	SkipCode    bool
+	SkipStorage bool
+	Start       []byte           // Hashed address to start the dump at, inclusive
+	Max         uint64           // Maximum number of accounts to dump, 0 for all
+	Addresses   []common.Address // Accounts to dump, all of them if empty
+}
+
+// IterativeAccount is an account of an iterative state dump. Besides the native
+// balance kept in the account itself, the SMT and MESH balances are resolved
+// from the storage of the token contracts. The address, and the token balances
+// derived from it, are missing if the preimage of the hashed address is unknown.
+type IterativeAccount struct {
+	Address     *common.Address             `json:"address,omitempty"`
+	Key         hexutil.Bytes               `json:"key"`
+	Nonce       uint64                      `json:"nonce"`
+	Balance     string                      `json:"balance"`
+	SMTBalance  string                      `json:"smtBalance,omitempty"`
+	MESHBalance string                      `json:"meshBalance,omitempty"`
+	Root        common.Hash                 `json:"root"`
+	CodeHash    hexutil.Bytes               `json:"codeHash"`
+	Code        hexutil.Bytes               `json:"code,omitempty"`
+	Storage     map[common.Hash]common.Hash `json:"storage,omitempty"`
+}
+
+// IteratorDump is a page of accounts of an iterative state dump, ordered by
+// hashed address. Next is the key to continue the dump at, nil once done.
+type IteratorDump struct {
+	Root      common.Hash        `json:"root"`
+	TotalMesh string             `json:"totalMesh"`
+	Accounts  []IterativeAccount `json:"accounts"`
+	Next      hexutil.Bytes      `json:"next,omitempty"`
+}
+
+// DumpToCollector iterates over the accounts selected by the config in hashed
+// address order, passing them to onAccount one by one. It returns the key of
+// the account following the last one dumped, or nil if all were dumped.
+func (self *StateDB) DumpToCollector(conf *DumpConfig, onAccount func(IterativeAccount) error) ([]byte, error) {
+	// Both token contracts keep their balances in a mapping at slot 1
+	smt := self.StorageTrie(params.SmartMeshContractAddress)
+	mesh := self.StorageTrie(params.MeshContractAddress)
+
+	dumped := uint64(0)
+	emit := func(key, value []byte, addr *common.Address) error {
+		var data Account
+		if err := rlp.DecodeBytes(value, &data); err != nil {
+			return err
+		}
+		account := IterativeAccount{
+			Key:      common.CopyBytes(key),
+			Nonce:    data.Nonce,
+			Balance:  data.Balance.String(),
+			Root:     data.Root,
+			CodeHash: data.CodeHash,
+		}
+		if addr == nil {
+			if preimage := self.trie.GetKey(key); preimage != nil {
+				addr = new(common.Address)
+				*addr = common.BytesToAddress(preimage)
+			}
+		}
+		if addr != nil {
+			account.Address = addr
+			account.SMTBalance = dumpBalance(smt, *addr).String()
+			account.MESHBalance = dumpBalance(mesh, *addr).String()
+		}
+		addrHash := common.BytesToHash(key)
+		if !conf.SkipCode && !bytes.Equal(data.CodeHash, emptyCodeHash) {
+			code, err := self.db.ContractCode(addrHash, common.BytesToHash(data.CodeHash))
+			if err != nil {
+				return err
+			}
+			account.Code = code
+		}
+		if !conf.SkipStorage {
+			storageTrie, err := self.db.OpenStorageTrie(addrHash, data.Root)
+			if err != nil {
+				return err
+			}
+			account.Storage = make(map[common.Hash]common.Hash)
+			storageIt := trie.NewIterator(storageTrie.NodeIterator(nil))
+			for storageIt.Next() {
+				_, content, _, err := rlp.Split(storageIt.Value)
+				if err != nil {
+					return err
+				}
+				slot := common.BytesToHash(storageIt.Key)
+				if preimage := self.trie.GetKey(storageIt.Key); preimage != nil {
+					slot = common.BytesToHash(preimage)
+				}
+				account.Storage[slot] = common.BytesToHash(content)
+			}
+			if storageIt.Err != nil {
+				return storageIt.Err
+			}
+		}
+		dumped++
+		return onAccount(account)
+	}
+	// Dump the requested accounts only if there's a filter
+	if len(conf.Addresses) > 0 {
+		addrs := make(map[string]common.Address)
+		keys := make([][]byte, 0, len(conf.Addresses))
+		for _, addr := range conf.Addresses {
+			key := crypto.Keccak256(addr[:])
+			if _, ok := addrs[string(key)]; ok || bytes.Compare(key, conf.Start) < 0 {
+				continue
+			}
+			addrs[string(key)] = addr
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i], keys[j]) < 0 })
+		for i, key := range keys {
+			if conf.Max > 0 && dumped >= conf.Max {
+				return keys[i], nil
+			}
+			addr := addrs[string(key)]
+			value, err := self.trie.TryGet(addr[:])
+			if err != nil {
+				return nil, err
+			}
+			if len(value) == 0 {
+				continue
+			}
+			if err := emit(key, value, &addr); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	}
+	it := trie.NewIterator(self.trie.NodeIterator(conf.Start))
+	for it.Next() {
+		if conf.Max > 0 && dumped >= conf.Max {
+			return common.CopyBytes(it.Key), nil
+		}
+		if err := emit(it.Key, it.Value, nil); err != nil {
+			return nil, err
+		}
+	}
+	return nil, it.Err
+}
+
+// dumpBalance reads the balance of addr from the storage trie of a token
+// contract, zero if the contract doesn't exist.
+func dumpBalance(tr Trie, addr common.Address) *big.Int {
+	if tr == nil {
+		return new(big.Int)
+	}
+	enc, err := tr.TryGet(GetOVMBalanceKey(addr).Bytes())
+	if err != nil || len(enc) == 0 {
+		return new(big.Int)
+	}
+	_, content, _, _ := rlp.Split(enc)
+	return new(big.Int).SetBytes(content)
+}
+
+// totalMesh returns the MESH supply recorded by the MESH contract.
+func (self *StateDB) totalMesh() *big.Int {
+	return self.GetState(params.MeshContractAddress, params.TotalMeshHash).Big()
+}
+
+// IteratorDump dumps a page of accounts selected by the config.
+func (self *StateDB) IteratorDump(conf *DumpConfig) (IteratorDump, error) {
+	dump := IteratorDump{
+		Root:      self.trie.Hash(),
+		TotalMesh: self.totalMesh().String(),
+		Accounts:  make([]IterativeAccount, 0),
+	}
+	next, err := self.DumpToCollector(conf, func(account IterativeAccount) error {
+		dump.Accounts = append(dump.Accounts, account)
+		return nil
+	})
+	dump.Next = next
+	return dump, err
+}
+
+// IterativeDump streams the accounts selected by the config as newline
+// delimited JSON. The first line holds the state root and the MESH supply, the
+// rest one account each. It returns the key to continue the dump at, nil once
+// all the accounts were dumped.
+func (self *StateDB) IterativeDump(conf *DumpConfig, w io.Writer) ([]byte, error) {
+	enc := json.NewEncoder(w)
+	header := struct {
+		Root      common.Hash `json:"root"`
+		TotalMesh string      `json:"totalMesh"`
+	}{self.trie.Hash(), self.totalMesh().String()}
+
+	if err := enc.Encode(header); err != nil {
+		return nil, err
+	}
+	return self.DumpToCollector(conf, func(account IterativeAccount) error {
+		return enc.Encode(account)
+	})
+}
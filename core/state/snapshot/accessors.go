@@ -0,0 +1,93 @@
+// Copyright 2018 The mesh-chain Authors
+// This file is part of the mesh-chain library.
+//
+// The mesh-chain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The mesh-chain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the mesh-chain library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/ethdb"
+	"github.com/MeshBoxTech/mesh-chain/log"
+)
+
+var (
+	// SnapshotRootKey tracks the state root the persisted snapshot belongs to.
+	SnapshotRootKey = []byte("SnapshotRoot")
+
+	// SnapshotGeneratorKey tracks the generation progress of the persisted
+	// snapshot, it's missing once the snapshot is complete.
+	SnapshotGeneratorKey = []byte("SnapshotGenerator")
+
+	// Data item prefixes (use single byte to avoid mixing data types, avoid `i`).
+	AccountPrefix = []byte("a") // AccountPrefix + account hash -> account trie value
+	StoragePrefix = []byte("o") // StoragePrefix + account hash + storage hash -> storage trie value
+)
+
+// accountKey = AccountPrefix + hash
+func accountKey(hash common.Hash) []byte {
+	return append(append([]byte{}, AccountPrefix...), hash[:]...)
+}
+
+// storageKey = StoragePrefix + account hash + storage hash
+func storageKey(accountHash, storageHash common.Hash) []byte {
+	key := append(append([]byte{}, StoragePrefix...), accountHash[:]...)
+	return append(key, storageHash[:]...)
+}
+
+// storagePrefix = StoragePrefix + account hash
+func storagePrefix(accountHash common.Hash) []byte {
+	return append(append([]byte{}, StoragePrefix...), accountHash[:]...)
+}
+
+// readSnapshotRoot retrieves the root of the persisted snapshot, or an empty
+// hash if there's none.
+func readSnapshotRoot(db ethdb.Database) common.Hash {
+	data, _ := db.Get(SnapshotRootKey)
+	if len(data) != common.HashLength {
+		return common.Hash{}
+	}
+	return common.BytesToHash(data)
+}
+
+// writeSnapshotRoot stores the root of the persisted snapshot.
+func writeSnapshotRoot(db ethdb.Putter, root common.Hash) {
+	if err := db.Put(SnapshotRootKey, root[:]); err != nil {
+		log.Crit("Failed to store snapshot root", "err", err)
+	}
+}
+
+// readGenerator retrieves the generation progress of the persisted snapshot,
+// nil if it's fully generated.
+func readGenerator(db ethdb.Database) []byte {
+	if has, _ := db.Has(SnapshotGeneratorKey); !has {
+		return nil
+	}
+	marker, _ := db.Get(SnapshotGeneratorKey)
+	return append([]byte{}, marker...)
+}
+
+// writeGenerator stores the generation progress of the persisted snapshot, a
+// nil marker flags it as complete.
+func writeGenerator(db ethdb.Writer, marker []byte) {
+	var err error
+	if marker == nil {
+		err = db.Delete(SnapshotGeneratorKey)
+	} else {
+		err = db.Put(SnapshotGeneratorKey, marker)
+	}
+	if err != nil {
+		log.Crit("Failed to store snapshot generator marker", "err", err)
+	}
+}
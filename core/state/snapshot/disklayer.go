@@ -0,0 +1,153 @@
+// Copyright 2018 The mesh-chain Authors
+// This file is part of the mesh-chain library.
+//
+// The mesh-chain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The mesh-chain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the mesh-chain library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/ethdb"
+	"github.com/MeshBoxTech/mesh-chain/trie"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// diskCacheSize is the number of snapshot entries kept in memory by the disk
+// layer, shared by all the disk layers following each other.
+const diskCacheSize = 256 * 1024
+
+// diskLayer is a low level persistent snapshot built on top of a key-value store.
+type diskLayer struct {
+	diskdb ethdb.Database     // Key-value store containing the base snapshot
+	triedb *trie.NodeDatabase // Trie node cache for reconstuction purposes
+	cache  *lru.Cache         // Cache to avoid hitting the disk for direct access
+
+	root  common.Hash // Root hash of the base snapshot
+	stale bool        // Signals that the layer became stale (state progressed)
+
+	genMarker []byte             // Marker for the state that's indexed during initial layer generation, nil once done
+	genAbort  chan chan struct{} // Notification channel to abort generating the snapshot in this layer, nil if not running
+
+	lock sync.RWMutex
+}
+
+// newDiskLayer creates a disk layer at root, generated up to the marker. The
+// cache of the previous disk layer is carried over if given.
+func newDiskLayer(diskdb ethdb.Database, triedb *trie.NodeDatabase, cache *lru.Cache, root common.Hash, marker []byte) *diskLayer {
+	if cache == nil {
+		cache, _ = lru.New(diskCacheSize)
+	}
+	return &diskLayer{
+		diskdb:    diskdb,
+		triedb:    triedb,
+		cache:     cache,
+		root:      root,
+		genMarker: marker,
+	}
+}
+
+// Root returns root hash for which this snapshot was made.
+func (dl *diskLayer) Root() common.Hash {
+	return dl.root
+}
+
+// Parent always returns nil as there's no layer below the disk.
+func (dl *diskLayer) Parent() snapshot {
+	return nil
+}
+
+// Stale return whether this layer has become stale (was flattened across) or if
+// it's still live.
+func (dl *diskLayer) Stale() bool {
+	dl.lock.RLock()
+	defer dl.lock.RUnlock()
+
+	return dl.stale
+}
+
+// markStale flags the layer as unusable.
+func (dl *diskLayer) markStale() {
+	dl.lock.Lock()
+	defer dl.lock.Unlock()
+
+	dl.stale = true
+}
+
+// Account directly retrieves the account RLP associated with a particular hash
+// in the snapshot.
+func (dl *diskLayer) Account(hash common.Hash) ([]byte, error) {
+	dl.lock.RLock()
+	defer dl.lock.RUnlock()
+
+	// If the layer was flattened into, consider it invalid (any live reference to
+	// the original should be marked as unusable).
+	if dl.stale {
+		return nil, ErrSnapshotStale
+	}
+	// If the layer is being generated, ensure the requested hash has already been
+	// covered by the generator.
+	if dl.genMarker != nil && compareMarker(hash[:], dl.genMarker) > 0 {
+		return nil, ErrNotCoveredYet
+	}
+	return dl.get(accountKey(hash)), nil
+}
+
+// Storage directly retrieves the storage data associated with a particular hash,
+// within a particular account.
+func (dl *diskLayer) Storage(accountHash, storageHash common.Hash) ([]byte, error) {
+	dl.lock.RLock()
+	defer dl.lock.RUnlock()
+
+	if dl.stale {
+		return nil, ErrSnapshotStale
+	}
+	key := storageKey(accountHash, storageHash)
+	if dl.genMarker != nil && bytes.Compare(key[len(StoragePrefix):], dl.genMarker) > 0 {
+		return nil, ErrNotCoveredYet
+	}
+	return dl.get(key), nil
+}
+
+// get retrieves a snapshot entry from the cache or the disk, nil if missing.
+func (dl *diskLayer) get(key []byte) []byte {
+	if blob, ok := dl.cache.Get(string(key)); ok {
+		return blob.([]byte)
+	}
+	blob, _ := dl.diskdb.Get(key)
+	dl.cache.Add(string(key), blob)
+	return blob
+}
+
+// startGeneration starts generating the snapshot from the tries in the
+// background, continuing from the generator marker.
+func (dl *diskLayer) startGeneration() {
+	dl.genAbort = make(chan chan struct{})
+	go dl.generate()
+}
+
+// stopGeneration aborts the background generation, if running, and waits for
+// the generator to persist its progress.
+func (dl *diskLayer) stopGeneration() {
+	if dl.genAbort == nil {
+		return
+	}
+	abort := make(chan struct{})
+	dl.genAbort <- abort
+	<-abort
+
+	dl.genAbort = nil
+}
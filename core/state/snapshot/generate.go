@@ -0,0 +1,214 @@
+// Copyright 2018 The mesh-chain Authors
+// This file is part of the mesh-chain library.
+//
+// The mesh-chain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The mesh-chain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the mesh-chain library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/ethdb"
+	"github.com/MeshBoxTech/mesh-chain/log"
+	"github.com/MeshBoxTech/mesh-chain/rlp"
+	"github.com/MeshBoxTech/mesh-chain/trie"
+)
+
+// emptyRoot is the known root hash of an empty trie.
+var emptyRoot = common.HexToHash("56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421")
+
+// account is the consensus representation of an account in the state trie, only
+// needed by the generator to find the storage trie.
+type account struct {
+	Nonce    uint64
+	Balance  *big.Int
+	Root     common.Hash
+	CodeHash []byte
+}
+
+// generateSnapshot wipes any previous snapshot from the database and starts the
+// generation of a new one at root in the background.
+func generateSnapshot(diskdb ethdb.Database, triedb *trie.NodeDatabase, root common.Hash) *diskLayer {
+	// Drop the root first, so a crash mid-wipe doesn't leave a half snapshot behind
+	if err := diskdb.Delete(SnapshotRootKey); err != nil {
+		log.Crit("Failed to remove snapshot root", "err", err)
+	}
+	wipeSnapshot(diskdb)
+
+	batch := diskdb.NewBatch()
+	writeSnapshotRoot(batch, root)
+	writeGenerator(batch, []byte{})
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to write initialized state marker", "err", err)
+	}
+	log.Info("Generating state snapshot", "root", root)
+
+	base := newDiskLayer(diskdb, triedb, nil, root, []byte{})
+	base.startGeneration()
+	return base
+}
+
+// wipeSnapshot deletes all the accounts and storage slots of a previous snapshot.
+func wipeSnapshot(db ethdb.Database) {
+	var (
+		start   = time.Now()
+		batch   = db.NewBatch()
+		deleted int
+	)
+	for _, prefix := range []struct {
+		key    []byte
+		length int
+	}{
+		{AccountPrefix, len(AccountPrefix) + common.HashLength},
+		{StoragePrefix, len(StoragePrefix) + 2*common.HashLength},
+	} {
+		it := db.NewIterator(prefix.key, nil)
+		for it.Next() {
+			if key := it.Key(); len(key) == prefix.length {
+				batch.Delete(common.CopyBytes(key))
+				deleted++
+			}
+			if batch.ValueSize() > ethdb.IdealBatchSize {
+				if err := batch.Write(); err != nil {
+					log.Crit("Failed to wipe state snapshot", "err", err)
+				}
+				batch.Reset()
+			}
+		}
+		it.Release()
+	}
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to wipe state snapshot", "err", err)
+	}
+	if deleted > 0 {
+		log.Info("Wiped previous state snapshot", "items", deleted, "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+}
+
+// generate is a background thread that iterates over the state and storage tries
+// and constructs a state snapshot. All the data retrieved from the tries are
+// written into the disk layer, with the progress persisted regularly so it can
+// be resumed after an abort or restart.
+func (dl *diskLayer) generate() {
+	var (
+		marker   = dl.genMarker
+		accounts uint64
+		slots    uint64
+		start    = time.Now()
+		logged   = time.Now()
+		batch    = dl.diskdb.NewBatch()
+	)
+	// flush writes out the batch and moves the generator marker forward
+	flush := func(marker []byte) {
+		writeGenerator(batch, marker)
+		if err := batch.Write(); err != nil {
+			log.Crit("Failed to write state snapshot", "err", err)
+		}
+		batch.Reset()
+
+		dl.lock.Lock()
+		dl.genMarker = marker
+		dl.lock.Unlock()
+	}
+	// checkpoint persists the progress up to the marker if needed or when the
+	// generation is aborted, returning false in the latter case
+	checkpoint := func(marker []byte) bool {
+		select {
+		case abort := <-dl.genAbort:
+			flush(marker)
+			log.Debug("Aborted state snapshot generation", "root", dl.root, "at", fmt.Sprintf("%#x", marker))
+			close(abort)
+			return false
+		default:
+		}
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			flush(marker)
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Generating state snapshot", "root", dl.root, "at", fmt.Sprintf("%#x", marker), "accounts", accounts, "slots", slots, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+		return true
+	}
+	// fail waits for the abort after an error, the next disk layer retries
+	fail := func(err error) {
+		log.Warn("State snapshot generation failed", "root", dl.root, "err", err)
+		abort := <-dl.genAbort
+		close(abort)
+	}
+	accTrie, err := trie.NewSecure(dl.root, dl.triedb, 0)
+	if err != nil {
+		fail(err)
+		return
+	}
+	var accMarker []byte
+	if len(marker) > 0 {
+		accMarker = marker[:common.HashLength]
+	}
+	accIt := trie.NewIterator(accTrie.NodeIterator(accMarker))
+	for accIt.Next() {
+		accountHash := common.BytesToHash(accIt.Key)
+
+		var acc account
+		if err := rlp.DecodeBytes(accIt.Value, &acc); err != nil {
+			fail(err)
+			return
+		}
+		batch.Put(accountKey(accountHash), accIt.Value)
+		accounts++
+
+		if acc.Root != emptyRoot {
+			// Resume the storage iteration of an account generated halfway
+			var storeMarker []byte
+			if len(marker) > common.HashLength && accountHash == common.BytesToHash(accMarker) {
+				storeMarker = marker[common.HashLength:]
+			}
+			storeTrie, err := trie.NewSecure(acc.Root, dl.triedb, 0)
+			if err != nil {
+				fail(err)
+				return
+			}
+			storeIt := trie.NewIterator(storeTrie.NodeIterator(storeMarker))
+			for storeIt.Next() {
+				batch.Put(storageKey(accountHash, common.BytesToHash(storeIt.Key)), storeIt.Value)
+				slots++
+
+				if !checkpoint(append(accountHash[:], storeIt.Key...)) {
+					return
+				}
+			}
+			if storeIt.Err != nil {
+				fail(storeIt.Err)
+				return
+			}
+		}
+		if !checkpoint(accountHash[:]) {
+			return
+		}
+	}
+	if accIt.Err != nil {
+		fail(accIt.Err)
+		return
+	}
+	// Snapshot fully generated, set the marker to nil
+	flush(nil)
+	log.Info("Generated state snapshot", "root", dl.root, "accounts", accounts, "slots", slots, "elapsed", common.PrettyDuration(time.Since(start)))
+
+	// Someone will be looking for us, wait it out
+	abort := <-dl.genAbort
+	close(abort)
+}
@@ -0,0 +1,336 @@
+// Copyright 2018 The mesh-chain Authors
+// This file is part of the mesh-chain library.
+//
+// The mesh-chain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The mesh-chain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the mesh-chain library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package snapshot implements a flat key-value view of the state, so accounts
+// and storage slots can be read without walking the tries.
+//
+// The snapshot is a tree of layers: a persisted disk layer at some older state
+// root, with an in-memory diff layer per recent block stacked on top of it. Any
+// block sharing an ancestor with the head within the retained layers has its
+// own view, so reorgs don't invalidate the snapshot.
+package snapshot
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/ethdb"
+	"github.com/MeshBoxTech/mesh-chain/log"
+	"github.com/MeshBoxTech/mesh-chain/trie"
+)
+
+var (
+	// ErrSnapshotStale is returned from data accessors if the underlying snapshot
+	// layer had been invalidated due to the chain progressing forward far enough
+	// to not maintain the layer's original state.
+	ErrSnapshotStale = errors.New("snapshot stale")
+
+	// ErrNotCoveredYet is returned from data accessors if the underlying snapshot
+	// is being generated currently and the requested data item is not yet in the
+	// range of accounts covered.
+	ErrNotCoveredYet = errors.New("not covered yet")
+
+	// errSnapshotCycle is returned if a snapshot is attempted to be inserted
+	// that forms a cycle in the snapshot tree.
+	errSnapshotCycle = errors.New("snapshot cycle")
+)
+
+// Snapshot represents the functionality supported by a snapshot storage layer.
+// Accounts are returned as their RLP encoded trie value and storage slots as
+// their RLP encoded trie value, a nil blob meaning the item doesn't exist.
+type Snapshot interface {
+	// Root returns the root hash for which this snapshot was made.
+	Root() common.Hash
+
+	// Account directly retrieves the account associated with a particular hash
+	// in the snapshot.
+	Account(hash common.Hash) ([]byte, error)
+
+	// Storage directly retrieves the storage data associated with a particular
+	// hash, within a particular account.
+	Storage(accountHash, storageHash common.Hash) ([]byte, error)
+}
+
+// snapshot is the internal version of the snapshot data layer that supports
+// some additional methods compared to the public API.
+type snapshot interface {
+	Snapshot
+
+	// Parent returns the subsequent layer of a snapshot, or nil if the base was
+	// reached.
+	Parent() snapshot
+
+	// Stale returns whether this layer has become stale (was flattened across)
+	// or if it's still live.
+	Stale() bool
+}
+
+// Tree is an Ethereum state snapshot tree. It consists of one persistent base
+// layer backed by a key-value store, on top of which arbitrarily many in-memory
+// diff layers are topped. The memory diffs can form a tree with branching, but
+// the disk layer is singleton and common to all. If a reorg goes deeper than the
+// disk layer, everything needs to be regenerated.
+//
+// The goal of a state snapshot is to allow direct access to account and storage
+// data to avoid expensive multi-level trie lookups.
+type Tree struct {
+	diskdb ethdb.Database           // Persistent database to store the snapshot
+	triedb *trie.NodeDatabase       // In-memory cache to access the trie through
+	layers map[common.Hash]snapshot // Collection of all known layers
+	lock   sync.RWMutex
+}
+
+// New attempts to load an already existing snapshot from a persistent key-value
+// store, ensuring that the head of the snapshot matches the expected one.
+//
+// If the snapshot is missing or belongs to another state, it's wiped and a new
+// one is generated in the background from the tries. Until the generator covers
+// an item, reads of it return ErrNotCoveredYet.
+func New(diskdb ethdb.Database, triedb *trie.NodeDatabase, root common.Hash) *Tree {
+	var base *diskLayer
+	if readSnapshotRoot(diskdb) == root {
+		base = newDiskLayer(diskdb, triedb, nil, root, readGenerator(diskdb))
+		if base.genMarker != nil {
+			log.Info("Resuming state snapshot generation", "root", root, "at", fmt.Sprintf("%#x", base.genMarker))
+			base.startGeneration()
+		}
+	} else {
+		base = generateSnapshot(diskdb, triedb, root)
+	}
+	return &Tree{
+		diskdb: diskdb,
+		triedb: triedb,
+		layers: map[common.Hash]snapshot{root: base},
+	}
+}
+
+// Snapshot retrieves a snapshot belonging to the given block root, or nil if no
+// snapshot is maintained for that block.
+func (t *Tree) Snapshot(blockRoot common.Hash) Snapshot {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if snap, ok := t.layers[blockRoot]; ok {
+		return snap
+	}
+	return nil
+}
+
+// Update adds a new snapshot into the tree, if that can be linked to an existing
+// old parent. It is disallowed to insert a disk layer (the origin of all).
+//
+// The destructs mark accounts whose storage was wiped, the accounts map holds
+// the new trie values of the changed accounts and the storage map the new trie
+// values of the changed slots, nil for deleted ones.
+func (t *Tree) Update(blockRoot common.Hash, parentRoot common.Hash, destructs map[common.Hash]struct{}, accounts map[common.Hash][]byte, storage map[common.Hash]map[common.Hash][]byte) error {
+	// Reject noop updates to avoid self-loops in the snapshot tree
+	if blockRoot == parentRoot {
+		return errSnapshotCycle
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	// The same state was already reached through another block
+	if _, ok := t.layers[blockRoot]; ok {
+		return nil
+	}
+	parent, ok := t.layers[parentRoot]
+	if !ok {
+		return fmt.Errorf("parent [%#x] snapshot missing", parentRoot)
+	}
+	t.layers[blockRoot] = newDiffLayer(parent, blockRoot, destructs, accounts, storage)
+	return nil
+}
+
+// Cap traverses downwards the snapshot tree from a head block hash until the
+// number of allowed layers are crossed. All layers beyond the permitted number
+// are flattened downwards into the disk layer, and every layer no longer
+// descending from the new disk layer is dropped.
+func (t *Tree) Cap(root common.Hash, layers int) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	snap, ok := t.layers[root]
+	if !ok {
+		return fmt.Errorf("snapshot [%#x] missing", root)
+	}
+	// Gather the diff layers from the head down to the disk layer
+	var chain []*diffLayer
+	for diff, ok := snap.(*diffLayer); ok; diff, ok = diff.Parent().(*diffLayer) {
+		chain = append(chain, diff)
+	}
+	if len(chain) <= layers {
+		return nil
+	}
+	// Flatten the surplus layers into the disk one by one, oldest first
+	base := chain[len(chain)-1].Parent().(*diskLayer)
+	for i := len(chain) - 1; i >= layers; i-- {
+		base = diffToDisk(chain[i], base)
+	}
+	if layers > 0 {
+		chain[layers-1].setParent(base)
+	}
+	t.layers[base.root] = base
+
+	// Drop all the layers that don't lead to the new disk layer any more
+	for root, snap := range t.layers {
+		if snap == base {
+			continue
+		}
+		if !t.descends(snap, base) {
+			if diff, ok := snap.(*diffLayer); ok {
+				diff.markStale()
+			}
+			delete(t.layers, root)
+		}
+	}
+	return nil
+}
+
+// descends reports whether the given layer is built on top of the base.
+func (t *Tree) descends(snap snapshot, base *diskLayer) bool {
+	for {
+		diff, ok := snap.(*diffLayer)
+		if !ok {
+			return snap == base
+		}
+		if diff.Stale() {
+			return false
+		}
+		snap = diff.Parent()
+	}
+}
+
+// Rebuild wipes all available snapshot data from the persistent database and
+// discards all caches and diff layers. Afterwards, it starts a new snapshot
+// generator with the given root hash.
+func (t *Tree) Rebuild(root common.Hash) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.release()
+	t.layers = map[common.Hash]snapshot{
+		root: generateSnapshot(t.diskdb, t.triedb, root),
+	}
+}
+
+// Stop terminates any running snapshot generation and marks all the layers as
+// stale. The generation progress is persisted, so it's resumed on the next New.
+func (t *Tree) Stop() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.release()
+	t.layers = make(map[common.Hash]snapshot)
+}
+
+// release stops the generator and invalidates every layer of the tree.
+func (t *Tree) release() {
+	for _, snap := range t.layers {
+		switch layer := snap.(type) {
+		case *diskLayer:
+			layer.stopGeneration()
+			layer.markStale()
+		case *diffLayer:
+			layer.markStale()
+		}
+	}
+}
+
+// diffToDisk merges a bottom-most diff into the persistent disk layer underneath
+// it. The method will panic if called onto a non-bottom-most diff layer.
+func diffToDisk(bottom *diffLayer, base *diskLayer) *diskLayer {
+	// Stop any generation running on the old disk layer, it'll be restarted on
+	// the new root from where it got to
+	base.stopGeneration()
+
+	// Mark the original base and the diff as stale as we're going to create new
+	// ones right after
+	base.markStale()
+	bottom.markStale()
+
+	var (
+		batch  = base.diskdb.NewBatch()
+		marker = base.genMarker
+		cache  = base.cache
+	)
+	// write stores an item in the batch and keeps the cache in sync with it
+	write := func(key []byte, data []byte) {
+		if len(data) > 0 {
+			batch.Put(key, data)
+		} else {
+			batch.Delete(key)
+		}
+		cache.Add(string(key), data)
+	}
+	// Wipe the storage of the destructed accounts, then write the account and
+	// storage changes. Nothing beyond the generator marker is written, the
+	// generator will pick those up from the trie.
+	for hash := range bottom.destructSet {
+		if marker != nil && compareMarker(hash[:], marker) > 0 {
+			continue
+		}
+		write(accountKey(hash), nil)
+
+		it := base.diskdb.NewIterator(storagePrefix(hash), nil)
+		for it.Next() {
+			write(common.CopyBytes(it.Key()), nil)
+		}
+		it.Release()
+	}
+	for hash, data := range bottom.accountData {
+		if marker != nil && compareMarker(hash[:], marker) > 0 {
+			continue
+		}
+		write(accountKey(hash), data)
+	}
+	for accountHash, storage := range bottom.storageData {
+		if marker != nil && compareMarker(accountHash[:], marker) > 0 {
+			continue
+		}
+		for storageHash, data := range storage {
+			write(storageKey(accountHash, storageHash), data)
+		}
+	}
+	writeSnapshotRoot(batch, bottom.root)
+	writeGenerator(batch, marker)
+
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to write state snapshot diff", "err", err)
+	}
+	log.Debug("Journalled state snapshot diff", "root", bottom.root, "accounts", len(bottom.accountData), "storage", len(bottom.storageData))
+
+	res := newDiskLayer(base.diskdb, base.triedb, cache, bottom.root, marker)
+	if marker != nil {
+		res.startGeneration()
+	}
+	return res
+}
+
+// compareMarker compares an account hash against the account part of a
+// generator marker.
+func compareMarker(hash []byte, marker []byte) int {
+	if len(marker) > common.HashLength {
+		marker = marker[:common.HashLength]
+	}
+	if len(marker) == 0 {
+		return 1
+	}
+	return bytes.Compare(hash, marker)
+}
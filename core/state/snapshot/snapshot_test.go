@@ -0,0 +1,282 @@
+// Copyright 2018 The mesh-chain Authors
+// This file is part of the mesh-chain library.
+//
+// The mesh-chain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The mesh-chain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the mesh-chain library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/crypto"
+	"github.com/MeshBoxTech/mesh-chain/ethdb"
+	"github.com/MeshBoxTech/mesh-chain/rlp"
+	"github.com/MeshBoxTech/mesh-chain/trie"
+)
+
+// newTestTree creates a snapshot tree over a complete persisted disk layer at the
+// given root.
+func newTestTree(root common.Hash, accounts map[common.Hash][]byte, storage map[common.Hash]map[common.Hash][]byte) (*Tree, ethdb.Database) {
+	db, _ := ethdb.NewMemDatabase()
+	for hash, data := range accounts {
+		db.Put(accountKey(hash), data)
+	}
+	for accountHash, slots := range storage {
+		for storageHash, data := range slots {
+			db.Put(storageKey(accountHash, storageHash), data)
+		}
+	}
+	writeSnapshotRoot(db, root)
+	return New(db, trie.NewNodeDatabase(db, nil), root), db
+}
+
+// waitGeneration waits for the disk layer of the tree to be fully generated.
+func waitGeneration(t *testing.T, tree *Tree, root common.Hash) {
+	dl, ok := tree.layers[root].(*diskLayer)
+	if !ok {
+		t.Fatalf("no disk layer at %x", root)
+	}
+	for i := 0; i < 1000; i++ {
+		dl.lock.RLock()
+		done := dl.genMarker == nil
+		dl.lock.RUnlock()
+		if done {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("snapshot generation timed out")
+}
+
+func checkAccount(t *testing.T, snap Snapshot, hash common.Hash, want []byte) {
+	have, err := snap.Account(hash)
+	if err != nil {
+		t.Fatalf("account %x: %v", hash[:4], err)
+	}
+	if !bytes.Equal(have, want) {
+		t.Errorf("account %x: have %x, want %x", hash[:4], have, want)
+	}
+}
+
+func checkStorage(t *testing.T, snap Snapshot, accountHash, storageHash common.Hash, want []byte) {
+	have, err := snap.Storage(accountHash, storageHash)
+	if err != nil {
+		t.Fatalf("slot %x/%x: %v", accountHash[:4], storageHash[:4], err)
+	}
+	if !bytes.Equal(have, want) {
+		t.Errorf("slot %x/%x: have %x, want %x", accountHash[:4], storageHash[:4], have, want)
+	}
+}
+
+// Tests that diff layers shadow their parents, including the deletion of all
+// storage of destructed accounts.
+func TestDiffLayerLookup(t *testing.T) {
+	var (
+		acc1, acc2, acc3 = common.HexToHash("0x01"), common.HexToHash("0x02"), common.HexToHash("0x03")
+		slot1, slot2     = common.HexToHash("0x11"), common.HexToHash("0x12")
+	)
+	tree, _ := newTestTree(common.HexToHash("0xff01"),
+		map[common.Hash][]byte{acc1: {0x01}, acc2: {0x02}},
+		map[common.Hash]map[common.Hash][]byte{acc1: {slot1: {0x01}, slot2: {0x02}}, acc2: {slot1: {0x03}}},
+	)
+	// Modify an account and a slot, delete a slot and recreate another account
+	if err := tree.Update(common.HexToHash("0xff02"), common.HexToHash("0xff01"),
+		map[common.Hash]struct{}{acc2: {}},
+		map[common.Hash][]byte{acc1: {0x11}, acc2: {0x12}, acc3: {0x13}},
+		map[common.Hash]map[common.Hash][]byte{acc1: {slot1: {0x21}, slot2: nil}},
+	); err != nil {
+		t.Fatal(err)
+	}
+	// Delete an account altogether on top
+	if err := tree.Update(common.HexToHash("0xff03"), common.HexToHash("0xff02"),
+		map[common.Hash]struct{}{acc1: {}}, nil, nil,
+	); err != nil {
+		t.Fatal(err)
+	}
+	if err := tree.Update(common.HexToHash("0xff04"), common.HexToHash("0xffff"), nil, nil, nil); err == nil {
+		t.Errorf("layer without parent accepted")
+	}
+	base, mid, head := tree.Snapshot(common.HexToHash("0xff01")), tree.Snapshot(common.HexToHash("0xff02")), tree.Snapshot(common.HexToHash("0xff03"))
+
+	checkAccount(t, base, acc1, []byte{0x01})
+	checkAccount(t, base, acc3, nil)
+	checkStorage(t, base, acc2, slot1, []byte{0x03})
+
+	checkAccount(t, mid, acc1, []byte{0x11})
+	checkAccount(t, mid, acc2, []byte{0x12})
+	checkAccount(t, mid, acc3, []byte{0x13})
+	checkStorage(t, mid, acc1, slot1, []byte{0x21})
+	checkStorage(t, mid, acc1, slot2, nil)
+	checkStorage(t, mid, acc2, slot1, nil)
+
+	checkAccount(t, head, acc1, nil)
+	checkAccount(t, head, acc3, []byte{0x13})
+	checkStorage(t, head, acc1, slot1, nil)
+}
+
+// Tests that capping the tree flattens the old layers into the disk, drops the
+// branches no longer connected to it and keeps the rest readable.
+func TestTreeCap(t *testing.T) {
+	var (
+		acc1, acc2 = common.HexToHash("0x01"), common.HexToHash("0x02")
+		slot       = common.HexToHash("0x11")
+	)
+	tree, db := newTestTree(common.HexToHash("0xff00"),
+		map[common.Hash][]byte{acc1: {0x01}, acc2: {0x02}},
+		map[common.Hash]map[common.Hash][]byte{acc2: {slot: {0x01}}},
+	)
+	// Chain ff00 -> ff01 -> ff02 -> ff03, with a side branch ff00 -> fe01
+	tree.Update(common.HexToHash("0xff01"), common.HexToHash("0xff00"), nil, map[common.Hash][]byte{acc1: {0x11}}, nil)
+	tree.Update(common.HexToHash("0xff02"), common.HexToHash("0xff01"), map[common.Hash]struct{}{acc2: {}}, nil, nil)
+	tree.Update(common.HexToHash("0xff03"), common.HexToHash("0xff02"), nil, map[common.Hash][]byte{acc1: {0x31}}, nil)
+	tree.Update(common.HexToHash("0xfe01"), common.HexToHash("0xff00"), nil, map[common.Hash][]byte{acc1: {0x21}}, nil)
+
+	side, old := tree.Snapshot(common.HexToHash("0xfe01")), tree.Snapshot(common.HexToHash("0xff01"))
+	if err := tree.Cap(common.HexToHash("0xff03"), 1); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(tree.layers); n != 2 {
+		t.Errorf("layer count mismatch: have %d, want 2", n)
+	}
+	if _, err := side.Account(acc1); err != ErrSnapshotStale {
+		t.Errorf("dropped branch: have error %v, want %v", err, ErrSnapshotStale)
+	}
+	if _, err := old.Account(acc1); err != ErrSnapshotStale {
+		t.Errorf("flattened layer: have error %v, want %v", err, ErrSnapshotStale)
+	}
+	if root := readSnapshotRoot(db); root != common.HexToHash("0xff02") {
+		t.Errorf("persisted root mismatch: have %x, want ff02", root)
+	}
+	if _, err := db.Get(storageKey(acc2, slot)); err == nil {
+		t.Errorf("destructed storage left on disk")
+	}
+	disk, head := tree.Snapshot(common.HexToHash("0xff02")), tree.Snapshot(common.HexToHash("0xff03"))
+	checkAccount(t, disk, acc1, []byte{0x11})
+	checkAccount(t, disk, acc2, nil)
+	checkStorage(t, disk, acc2, slot, nil)
+	checkAccount(t, head, acc1, []byte{0x31})
+
+	// Flattening everything persists the head
+	if err := tree.Cap(common.HexToHash("0xff03"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if root := readSnapshotRoot(db); root != common.HexToHash("0xff03") {
+		t.Errorf("persisted root mismatch: have %x, want ff03", root)
+	}
+	checkAccount(t, tree.Snapshot(common.HexToHash("0xff03")), acc1, []byte{0x31})
+}
+
+// Tests that the snapshot is generated from the tries in the background and the
+// layers stacked on top meanwhile are merged correctly.
+func TestGenerateSnapshot(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	triedb := trie.NewNodeDatabase(db, nil)
+
+	// Create an account trie with a few accounts, some with storage
+	accTrie, _ := trie.NewSecure(common.Hash{}, triedb, 0)
+	wantAccounts := make(map[common.Hash][]byte)
+	wantStorage := make(map[common.Hash]map[common.Hash][]byte)
+	for i := byte(1); i <= 10; i++ {
+		root := emptyRoot
+		if i%3 == 0 {
+			storeTrie, _ := trie.NewSecure(common.Hash{}, triedb, 0)
+			slots := make(map[common.Hash][]byte)
+			for j := byte(1); j <= 5; j++ {
+				key, value := common.BytesToHash([]byte{i, j}), []byte{0x80 + j}
+				storeTrie.Update(key[:], value)
+				slots[crypto.Keccak256Hash(key[:])] = value
+			}
+			root, _ = storeTrie.CommitTo(triedb)
+			wantStorage[crypto.Keccak256Hash([]byte{i})] = slots
+		}
+		blob, _ := rlp.EncodeToBytes(&account{Nonce: uint64(i), Balance: big.NewInt(int64(i)), Root: root, CodeHash: crypto.Keccak256(nil)})
+		accTrie.Update([]byte{i}, blob)
+		wantAccounts[crypto.Keccak256Hash([]byte{i})] = blob
+	}
+	root, _ := accTrie.CommitTo(triedb)
+	if err := triedb.Commit(root, false); err != nil {
+		t.Fatal(err)
+	}
+	// Leave junk of an old snapshot around, it must be wiped
+	junk := common.HexToHash("0xdead")
+	db.Put(accountKey(junk), []byte{0x01})
+
+	tree := New(db, triedb, root)
+	defer tree.Stop()
+
+	waitGeneration(t, tree, root)
+	snap := tree.Snapshot(root)
+	checkAccount(t, snap, junk, nil)
+	for hash, blob := range wantAccounts {
+		checkAccount(t, snap, hash, blob)
+	}
+	for accountHash, slots := range wantStorage {
+		for storageHash, value := range slots {
+			checkStorage(t, snap, accountHash, storageHash, value)
+		}
+	}
+	if marker := readGenerator(db); marker != nil {
+		t.Errorf("generator marker left behind: %x", marker)
+	}
+	// Reopening the database must not regenerate
+	tree.Stop()
+	tree = New(db, triedb, root)
+	if dl := tree.layers[root].(*diskLayer); dl.genMarker != nil {
+		t.Errorf("complete snapshot regenerated")
+	}
+}
+
+// Tests that an interrupted generation persists its progress and resumes.
+func TestGenerateSnapshotResume(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	triedb := trie.NewNodeDatabase(db, nil)
+
+	accTrie, _ := trie.NewSecure(common.Hash{}, triedb, 0)
+	for i := 0; i < 100; i++ {
+		blob, _ := rlp.EncodeToBytes(&account{Nonce: uint64(i), Balance: new(big.Int), Root: emptyRoot, CodeHash: crypto.Keccak256(nil)})
+		accTrie.Update(big.NewInt(int64(i)).Bytes(), blob)
+	}
+	root, _ := accTrie.CommitTo(triedb)
+	triedb.Commit(root, false)
+
+	// Pretend a previous run generated the first half
+	it := trie.NewIterator(accTrie.NodeIterator(nil))
+	for i := 0; i < 50 && it.Next(); i++ {
+		db.Put(accountKey(common.BytesToHash(it.Key)), it.Value)
+	}
+	marker := common.CopyBytes(it.Key)
+	writeSnapshotRoot(db, root)
+	writeGenerator(db, marker)
+
+	tree := New(db, triedb, root)
+	defer tree.Stop()
+
+	dl := tree.layers[root].(*diskLayer)
+	dl.lock.RLock()
+	resumed := dl.genMarker != nil
+	dl.lock.RUnlock()
+	if !resumed {
+		t.Fatalf("generation not resumed")
+	}
+	waitGeneration(t, tree, root)
+
+	it = trie.NewIterator(accTrie.NodeIterator(nil))
+	for it.Next() {
+		checkAccount(t, tree.Snapshot(root), common.BytesToHash(it.Key), it.Value)
+	}
+}
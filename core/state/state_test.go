@@ -24,6 +24,7 @@ import (
 	"github.com/MeshBoxTech/mesh-chain/common"
 	"github.com/MeshBoxTech/mesh-chain/crypto"
 	"github.com/MeshBoxTech/mesh-chain/ethdb"
+	"github.com/MeshBoxTech/mesh-chain/params"
 	checker "gopkg.in/check.v1"
 )
 
@@ -132,6 +133,73 @@ func (s *StateSuite) TestSnapshotEmpty(c *checker.C) {
 
 // use testing instead of checker because checker does not support
 // printing/logging in tests (-check.vv does not work)
+func TestIterativeDump(t *testing.T) {
+	var (
+		db, _ = ethdb.NewMemDatabase()
+		sdb   = NewDatabase(db)
+		addrs = []common.Address{toAddr([]byte{0x01}), toAddr([]byte{0x02}), toAddr([]byte{0x03})}
+	)
+	state, _ := New(common.Hash{}, sdb)
+	for i, addr := range addrs {
+		state.SetNonce(addr, uint64(i+1))
+	}
+	state.SetState(addrs[0], common.HexToHash("0x11"), common.HexToHash("0xaa"))
+	state.SetState(params.SmartMeshContractAddress, GetOVMBalanceKey(addrs[1]), common.BigToHash(big.NewInt(100)))
+	state.SetState(params.MeshContractAddress, GetOVMBalanceKey(addrs[1]), common.BigToHash(big.NewInt(200)))
+	state.SetState(params.MeshContractAddress, params.TotalMeshHash, common.BigToHash(big.NewInt(200)))
+	root, _ := state.CommitTo(db, false)
+	state, _ = New(root, sdb)
+
+	// Page through the whole state two accounts at a time
+	var (
+		accounts []IterativeAccount
+		start    []byte
+	)
+	for {
+		dump, err := state.IteratorDump(&DumpConfig{Start: start, Max: 2})
+		if err != nil {
+			t.Fatalf("failed to dump state: %v", err)
+		}
+		if dump.Root != root || dump.TotalMesh != "200" {
+			t.Fatalf("dump header mismatch: have %x/%s, want %x/200", dump.Root, dump.TotalMesh, root)
+		}
+		accounts = append(accounts, dump.Accounts...)
+		if start = dump.Next; start == nil {
+			break
+		}
+	}
+	if len(accounts) != 5 {
+		t.Fatalf("account count mismatch: have %d, want 5", len(accounts))
+	}
+	for i := 1; i < len(accounts); i++ {
+		if bytes.Compare(accounts[i-1].Key, accounts[i].Key) >= 0 {
+			t.Errorf("accounts out of order at %d", i)
+		}
+	}
+	// Dump the filtered accounts and check the resolved balances
+	dump, err := state.IteratorDump(&DumpConfig{Addresses: []common.Address{addrs[1], addrs[0], toAddr([]byte{0x04})}})
+	if err != nil {
+		t.Fatalf("failed to dump state: %v", err)
+	}
+	if len(dump.Accounts) != 2 || dump.Next != nil {
+		t.Fatalf("filtered dump mismatch: have %d accounts, next %x", len(dump.Accounts), dump.Next)
+	}
+	for _, account := range dump.Accounts {
+		switch *account.Address {
+		case addrs[0]:
+			if account.Nonce != 1 || account.Storage[common.HexToHash("0x11")] != common.HexToHash("0xaa") {
+				t.Errorf("account %x mismatch: have %+v", addrs[0], account)
+			}
+		case addrs[1]:
+			if account.SMTBalance != "100" || account.MESHBalance != "200" {
+				t.Errorf("account %x balance mismatch: have %s/%s, want 100/200", addrs[1], account.SMTBalance, account.MESHBalance)
+			}
+		default:
+			t.Errorf("unexpected account %x", *account.Address)
+		}
+	}
+}
+
 func TestSnapshot2(t *testing.T) {
 	db, _ := ethdb.NewMemDatabase()
 	state, _ := New(common.Hash{}, NewDatabase(db))
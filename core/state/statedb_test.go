@@ -27,12 +27,17 @@ import (
 	"strings"
 	"testing"
 	"testing/quick"
+	"time"
 
 	check "gopkg.in/check.v1"
 
 	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/core/state/snapshot"
 	"github.com/MeshBoxTech/mesh-chain/core/types"
+	"github.com/MeshBoxTech/mesh-chain/crypto"
 	"github.com/MeshBoxTech/mesh-chain/ethdb"
+	"github.com/MeshBoxTech/mesh-chain/rlp"
+	"github.com/MeshBoxTech/mesh-chain/trie"
 )
 
 // Tests that updating a state trie does not leak any database writes prior to
@@ -432,3 +437,195 @@ func (s *StateSuite) TestTouchDelete(c *check.C) {
 		c.Fatal("expected no dirty state object")
 	}
 }
+
+// Tests that states backed by a snapshot tree read the same data as the trie and
+// add their changes to the tree on commit.
+func TestSnapshotTreeReads(t *testing.T) {
+	var (
+		db, _  = ethdb.NewMemDatabase()
+		sdb    = NewDatabase(db)
+		addr1  = common.HexToAddress("0x01")
+		addr2  = common.HexToAddress("0x02")
+		k1, k2 = common.HexToHash("0x11"), common.HexToHash("0x12")
+	)
+	state, _ := New(common.Hash{}, sdb)
+	state.SetNonce(addr1, 1)
+	state.SetState(addr1, k1, common.HexToHash("0xa1"))
+	state.SetState(addr1, k2, common.HexToHash("0xa2"))
+	state.SetNonce(addr2, 2)
+	state.SetState(addr2, k1, common.HexToHash("0xb1"))
+	root1, _ := state.CommitTo(sdb.TrieDB(), false)
+	sdb.TrieDB().Commit(root1, false)
+
+	snaps := snapshot.New(db, sdb.TrieDB(), root1)
+	defer snaps.Stop()
+
+	// Wait for the snapshot to be generated
+	for i := 0; ; i++ {
+		_, err1 := snaps.Snapshot(root1).Storage(crypto.Keccak256Hash(addr1[:]), crypto.Keccak256Hash(k2[:]))
+		_, err2 := snaps.Snapshot(root1).Storage(crypto.Keccak256Hash(addr2[:]), crypto.Keccak256Hash(k1[:]))
+		if err1 == nil && err2 == nil {
+			break
+		}
+		if i == 1000 {
+			t.Fatalf("snapshot generation timed out")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	state, _ = NewWithSnapshot(root1, sdb, snaps)
+	if nonce := state.GetNonce(addr2); nonce != 2 {
+		t.Errorf("nonce mismatch: have %d, want 2", nonce)
+	}
+	if value := state.GetState(addr1, k2); value != common.HexToHash("0xa2") {
+		t.Errorf("storage mismatch: have %x, want a2", value)
+	}
+	// Change a slot, delete another and destruct an account
+	state.SetState(addr1, k1, common.HexToHash("0xc1"))
+	state.SetState(addr1, k2, common.Hash{})
+	state.Suicide(addr2)
+	root2, _ := state.CommitTo(sdb.TrieDB(), false)
+
+	if snaps.Snapshot(root2) == nil {
+		t.Fatalf("snapshot layer missing for committed state")
+	}
+	snapState, _ := NewWithSnapshot(root2, sdb, snaps)
+	trieState, _ := New(root2, sdb)
+	for _, st := range []*StateDB{snapState, trieState} {
+		if value := st.GetState(addr1, k1); value != common.HexToHash("0xc1") {
+			t.Errorf("storage mismatch: have %x, want c1", value)
+		}
+		if value := st.GetState(addr1, k2); value != (common.Hash{}) {
+			t.Errorf("deleted slot mismatch: have %x", value)
+		}
+		if st.Exist(addr2) {
+			t.Errorf("destructed account exists")
+		}
+	}
+	// Overwriting an account drops its storage, unless reverted
+	state, _ = NewWithSnapshot(root2, sdb, snaps)
+	rev := state.Snapshot()
+	state.CreateAccount(addr1)
+	if value := state.GetState(addr1, k1); value != (common.Hash{}) {
+		t.Errorf("storage of overwritten account: have %x", value)
+	}
+	state.RevertToSnapshot(rev)
+	if value := state.GetState(addr1, k1); value != common.HexToHash("0xc1") {
+		t.Errorf("storage after revert: have %x, want c1", value)
+	}
+}
+
+func TestStateProofs(t *testing.T) {
+	var (
+		db, _ = ethdb.NewMemDatabase()
+		sdb   = NewDatabase(db)
+		addr  = common.HexToAddress("0x01")
+		key   = common.HexToHash("0x11")
+	)
+	state, _ := New(common.Hash{}, sdb)
+	state.SetNonce(addr, 1)
+	state.SetState(addr, key, common.HexToHash("0xa1"))
+	state.SetNonce(common.HexToAddress("0x02"), 2)
+	root, _ := state.CommitTo(sdb.TrieDB(), false)
+	state, _ = New(root, sdb)
+
+	// verify checks a proof against the root, returning the proven value
+	verify := func(root common.Hash, key []byte, proof [][]byte) []byte {
+		proofDb, _ := ethdb.NewMemDatabase()
+		for _, node := range proof {
+			proofDb.Put(crypto.Keccak256(node), node)
+		}
+		value, err, _ := trie.VerifyProof(root, crypto.Keccak256(key), proofDb)
+		if err != nil {
+			t.Fatalf("failed to verify proof: %v", err)
+		}
+		return value
+	}
+	proof, err := state.GetProof(addr)
+	if err != nil {
+		t.Fatalf("failed to prove account: %v", err)
+	}
+	var account Account
+	if err := rlp.DecodeBytes(verify(root, addr[:], proof), &account); err != nil {
+		t.Fatalf("failed to decode proven account: %v", err)
+	}
+	if account.Nonce != 1 || account.Root != state.StorageTrie(addr).Hash() {
+		t.Errorf("proven account mismatch: have %+v", account)
+	}
+	proof, err = state.GetStorageProof(addr, key)
+	if err != nil {
+		t.Fatalf("failed to prove slot: %v", err)
+	}
+	var value []byte
+	if err := rlp.DecodeBytes(verify(account.Root, key[:], proof), &value); err != nil {
+		t.Fatalf("failed to decode proven slot: %v", err)
+	}
+	if common.BytesToHash(value) != common.HexToHash("0xa1") {
+		t.Errorf("proven slot mismatch: have %x, want a1", value)
+	}
+	// Missing accounts and slots are proven absent
+	missing := common.HexToAddress("0x03")
+	if proof, _ = state.GetProof(missing); verify(root, missing[:], proof) != nil {
+		t.Errorf("missing account proven present")
+	}
+	other := common.HexToHash("0x12")
+	if proof, _ = state.GetStorageProof(addr, other); verify(account.Root, other[:], proof) != nil {
+		t.Errorf("missing slot proven present")
+	}
+}
+
+func TestStateDBAccessList(t *testing.T) {
+	mem, _ := ethdb.NewMemDatabase()
+	state, _ := New(common.Hash{}, NewDatabase(mem))
+
+	var (
+		sender  = common.HexToAddress("0xaa")
+		dest    = common.HexToAddress("0xbb")
+		precomp = common.HexToAddress("0x01")
+		listed  = common.HexToAddress("0xcc")
+		slot1   = common.HexToHash("0x01")
+		slot2   = common.HexToHash("0x02")
+	)
+	state.PrepareAccessList(sender, &dest, []common.Address{precomp}, types.AccessList{
+		{Address: listed, StorageKeys: []common.Hash{slot1}},
+	})
+	for _, addr := range []common.Address{sender, dest, precomp, listed} {
+		if !state.AddressInAccessList(addr) {
+			t.Errorf("address %x missing from prepared access list", addr)
+		}
+	}
+	if addrOk, slotOk := state.SlotInAccessList(listed, slot1); !addrOk || !slotOk {
+		t.Errorf("prepared slot missing: address %v, slot %v", addrOk, slotOk)
+	}
+	// Additions after a snapshot are reverted with it
+	snap := state.Snapshot()
+	state.AddSlotToAccessList(listed, slot2)
+	state.AddSlotToAccessList(dest, slot1)
+	state.AddAddressToAccessList(common.HexToAddress("0xdd"))
+
+	if _, slotOk := state.SlotInAccessList(dest, slot1); !slotOk {
+		t.Errorf("added slot missing")
+	}
+	state.RevertToSnapshot(snap)
+
+	if _, slotOk := state.SlotInAccessList(listed, slot2); slotOk {
+		t.Errorf("reverted slot still present")
+	}
+	if addrOk, slotOk := state.SlotInAccessList(dest, slot1); !addrOk || slotOk {
+		t.Errorf("reverted slot mismatch: address %v, slot %v", addrOk, slotOk)
+	}
+	if state.AddressInAccessList(common.HexToAddress("0xdd")) {
+		t.Errorf("reverted address still present")
+	}
+	if _, slotOk := state.SlotInAccessList(listed, slot1); !slotOk {
+		t.Errorf("prepared slot lost on revert")
+	}
+	// Copies carry the access list, but are independent
+	cpy := state.Copy()
+	cpy.AddAddressToAccessList(common.HexToAddress("0xee"))
+	if !cpy.AddressInAccessList(sender) {
+		t.Errorf("copy lost the access list")
+	}
+	if state.AddressInAccessList(common.HexToAddress("0xee")) {
+		t.Errorf("copy modified the original access list")
+	}
+}
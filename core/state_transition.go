@@ -19,6 +19,8 @@ package core
 import (
 	"errors"
 	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/common/math"
+	"github.com/MeshBoxTech/mesh-chain/core/types"
 	"github.com/MeshBoxTech/mesh-chain/core/vm"
 	"github.com/MeshBoxTech/mesh-chain/log"
 	"github.com/MeshBoxTech/mesh-chain/params"
@@ -67,19 +69,23 @@ type Message interface {
 	To() *common.Address
 
 	GasPrice() *big.Int
+	GasFeeCap() *big.Int
+	GasTipCap() *big.Int
 	Gas() *big.Int
 	Value() *big.Int
 
 	Nonce() uint64
 	CheckNonce() bool
 	Data() []byte
+	AccessList() types.AccessList
+	Sponsor() *common.Address
 }
 
 // IntrinsicGas computes the 'intrinsic gas' for a message
-// with the given data.
+// with the given data and access list.
 //
 // TODO convert to uint64
-func IntrinsicGas(data []byte, contractCreation, homestead bool) *big.Int {
+func IntrinsicGas(data []byte, accessList types.AccessList, contractCreation, homestead bool) *big.Int {
 	igas := new(big.Int)
 	if contractCreation && homestead {
 		igas.SetUint64(params.TxGasContractCreation)
@@ -100,6 +106,12 @@ func IntrinsicGas(data []byte, contractCreation, homestead bool) *big.Int {
 		m.Mul(m, new(big.Int).SetUint64(params.TxDataZeroGas))
 		igas.Add(igas, m)
 	}
+	if accessList != nil {
+		m := new(big.Int).SetUint64(uint64(len(accessList)))
+		igas.Add(igas, m.Mul(m, new(big.Int).SetUint64(params.TxAccessListAddressGas)))
+		m = new(big.Int).SetUint64(uint64(accessList.StorageKeys()))
+		igas.Add(igas, m.Mul(m, new(big.Int).SetUint64(params.TxAccessListStorageKeyGas)))
+	}
 	return igas
 }
 
@@ -157,6 +169,15 @@ func (st *StateTransition) to() vm.AccountRef {
 	return reference
 }
 
+// payer returns the account paying for the gas, the sponsor of sponsored
+// messages and the sender otherwise.
+func (st *StateTransition) payer() common.Address {
+	if sponsor := st.msg.Sponsor(); sponsor != nil {
+		return *sponsor
+	}
+	return st.from().Address()
+}
+
 func (st *StateTransition) useGas(amount uint64) error {
 	if st.gas < amount {
 		return vm.ErrOutOfGas
@@ -175,9 +196,15 @@ func (st *StateTransition) buyGas() error {
 	mgval := new(big.Int).Mul(mgas, st.gasPrice)
 
 	var (
-		state  = st.state
-		sender = st.from()
+		state = st.state
+		payer = st.payer()
 	)
+	// Dynamic fee messages must be able to cover their fee cap, even
+	// though only the effective gas price is charged.
+	balanceCheck := mgval
+	if st.msg.GasFeeCap() != nil {
+		balanceCheck = new(big.Int).Mul(mgas, st.msg.GasFeeCap())
+	}
 
 	if err := st.gp.SubGas(mgas); err != nil {
 		return err
@@ -185,12 +212,12 @@ func (st *StateTransition) buyGas() error {
 
 	st.gas += mgas.Uint64()
 	st.initialGas.Set(mgas)
-	if state.GetBalance(sender.Address()).Cmp(mgval) < 0 {
+	if state.GetBalance(payer).Cmp(balanceCheck) < 0 {
 		return errInsufficientBalanceForGas
 	}
 
-	log.Debug("<<StateTransition.buyGas>> 1", "num", st.evm.BlockNumber, "from", sender.Address().Hex(), "gas*price", mgval)
-	state.SubBalance(sender.Address(), mgval)
+	log.Debug("<<StateTransition.buyGas>> 1", "num", st.evm.BlockNumber, "from", st.msg.From().Hex(), "payer", payer.Hex(), "gas*price", mgval)
+	state.SubBalance(payer, mgval)
 
 	return nil
 }
@@ -209,6 +236,26 @@ func (st *StateTransition) preCheck() error {
 			return ErrNonceTooLow
 		}
 	}
+	// Make sure the sponsor is allowed to pay for the destination
+	if sponsor := msg.Sponsor(); sponsor != nil {
+		num, config := st.evm.BlockNumber, st.evm.ChainConfig()
+		if !config.IsSponsor(num) || !config.Sponsor.Allowed(num, *sponsor, msg.To()) {
+			return ErrSponsorNotAllowed
+		}
+	}
+	// Make sure the fee caps cover the base fee of the block, unless the
+	// message does not pay for gas at all (eth_call without a gas price).
+	if st.evm.ChainConfig().IsLondon(st.evm.BlockNumber) {
+		feeCap, tipCap := msg.GasFeeCap(), msg.GasTipCap()
+		if !st.evm.Config().NoBaseFee || feeCap.Sign() > 0 || tipCap.Sign() > 0 {
+			if feeCap.Cmp(tipCap) < 0 {
+				return ErrTipAboveFeeCap
+			}
+			if feeCap.Cmp(st.evm.BaseFee) < 0 {
+				return ErrFeeCapTooLow
+			}
+		}
+	}
 	return st.buyGas()
 }
 
@@ -230,7 +277,7 @@ func (st *StateTransition) TransitionDb() (ret []byte, requiredGas, usedGas *big
 	// TODO convert to uint64
 
 	// add by liangc
-	intrinsicGas := IntrinsicGas(st.data, contractCreation, homestead)
+	intrinsicGas := IntrinsicGas(st.data, msg.AccessList(), contractCreation, homestead)
 
 	if intrinsicGas.BitLen() > 64 {
 		return nil, nil, nil, false, vm.ErrOutOfGas
@@ -246,6 +293,11 @@ func (st *StateTransition) TransitionDb() (ret []byte, requiredGas, usedGas *big
 		// error.
 		vmerr error
 	)
+	// Warm up the sender, destination, precompiles and the declared
+	// access list as defined by EIP-2929 and EIP-2930.
+	if rules := evm.ChainConfig().Rules(num); rules.IsBerlin {
+		st.state.PrepareAccessList(msg.From(), msg.To(), vm.ActivePrecompiles(rules), msg.AccessList())
+	}
 
 	if contractCreation {
 		ret, _, st.gas, vmerr = evm.Create(sender, st.data, st.gas, st.value)
@@ -270,7 +322,13 @@ func (st *StateTransition) TransitionDb() (ret []byte, requiredGas, usedGas *big
 
 	st.refundGas()
 	_m := st.evm.Coinbase
-	_r := new(big.Int).Mul(st.gasUsed(), st.gasPrice)
+	// Past London the validator only earns the tip, the base fee portion is
+	// left to the consensus engine to burn or credit at finalization.
+	effectiveTip := st.gasPrice
+	if st.evm.ChainConfig().IsLondon(num) {
+		effectiveTip = math.BigMax(new(big.Int).Sub(st.gasPrice, st.evm.BaseFee), common.Big0)
+	}
+	_r := new(big.Int).Mul(st.gasUsed(), effectiveTip)
 	log.Debug("<<StateTransition.TransitionDb>> 3", "num", st.evm.BlockNumber, "from", sender.Address().Hex(), "miner", st.evm.Coinbase.Hex(), "gas_reward", _r)
 	st.state.AddBalance(_m, _r)
 	return ret, requiredGas, st.gasUsed(), vmerr != nil, err
@@ -309,9 +367,9 @@ func (st *StateTransition) refundGas() {
 		refund = st.state.GetRefund()
 	}
 	st.gas += refund
-	// Return ETH for remaining gas, exchanged at the original rate.
+	// Return ETH for remaining gas to the payer, exchanged at the original rate.
 	remaining := new(big.Int).Mul(new(big.Int).SetUint64(st.gas), st.gasPrice)
-	st.state.AddBalance(st.msg.From(), remaining)
+	st.state.AddBalance(st.payer(), remaining)
 
 	// Also return remaining gas to the block gas counter so it is
 	// available for the next transaction.
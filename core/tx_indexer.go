@@ -0,0 +1,237 @@
+// Copyright 2018 The mesh-chain Authors
+// This file is part of the mesh-chain library.
+//
+// The mesh-chain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The mesh-chain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the mesh-chain library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/ethdb"
+	"github.com/MeshBoxTech/mesh-chain/log"
+)
+
+// errTxIndexInterrupted is returned if the transaction indexing is aborted.
+var errTxIndexInterrupted = errors.New("transaction indexing interrupted")
+
+// TxIndexProgress is the progress of the transaction indexer in bringing the
+// indexed blocks in line with the lookup limit.
+type TxIndexProgress struct {
+	Indexed   uint64 // Number of recent blocks whose transactions are indexed
+	Remaining uint64 // Number of blocks still to be indexed
+}
+
+// Done reports whether all the blocks within the lookup limit are indexed.
+func (p TxIndexProgress) Done() bool {
+	return p.Remaining == 0
+}
+
+// IndexTransactions writes the transaction lookup entries of the canonical
+// blocks in [from, to), moving the index tail down to from. Blocks are indexed
+// from the highest, so the tail stays accurate if the indexing is interrupted.
+func IndexTransactions(db ethdb.Database, from uint64, to uint64, interrupt <-chan struct{}) error {
+	if from >= to {
+		return nil
+	}
+	var (
+		batch  = db.NewBatch()
+		start  = time.Now()
+		logged = time.Now()
+		txs    int
+	)
+	for number := to; number > from; number-- {
+		select {
+		case <-interrupt:
+			return flushTxIndex(batch, number, errTxIndexInterrupted)
+		default:
+		}
+		block := GetBlock(db, GetCanonicalHash(db, number-1), number-1)
+		if block == nil {
+			return flushTxIndex(batch, number, fmt.Errorf("canonical block #%d missing", number-1))
+		}
+		if err := WriteTxLookupEntries(batch, block); err != nil {
+			return err
+		}
+		txs += len(block.Transactions())
+
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := flushTxIndex(batch, number-1, nil); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Indexing transactions", "blocks", to-number+1, "txs", txs, "tail", number-1, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := flushTxIndex(batch, from, nil); err != nil {
+		return err
+	}
+	log.Info("Indexed transactions", "blocks", to-from, "txs", txs, "tail", from, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// UnindexTransactions removes the transaction lookup entries of the canonical
+// blocks in [from, to), moving the index tail up to to. Blocks are unindexed
+// from the lowest, so the tail stays accurate if the unindexing is interrupted.
+func UnindexTransactions(db ethdb.Database, from uint64, to uint64, interrupt <-chan struct{}) error {
+	if from >= to {
+		return nil
+	}
+	var (
+		batch  = db.NewBatch()
+		start  = time.Now()
+		logged = time.Now()
+		txs    int
+	)
+	for number := from; number < to; number++ {
+		select {
+		case <-interrupt:
+			return flushTxIndex(batch, number, errTxIndexInterrupted)
+		default:
+		}
+		block := GetBlock(db, GetCanonicalHash(db, number), number)
+		if block == nil {
+			return flushTxIndex(batch, number, fmt.Errorf("canonical block #%d missing", number))
+		}
+		for _, tx := range block.Transactions() {
+			DeleteTxLookupEntry(batch, tx.Hash())
+		}
+		txs += len(block.Transactions())
+
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := flushTxIndex(batch, number+1, nil); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Unindexing transactions", "blocks", number-from+1, "txs", txs, "tail", number+1, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := flushTxIndex(batch, to, nil); err != nil {
+		return err
+	}
+	log.Info("Unindexed transactions", "blocks", to-from, "txs", txs, "tail", to, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// flushTxIndex writes out the pending index changes along with the tail they
+// lead to, returning err if the batch was written successfully.
+func flushTxIndex(batch ethdb.Batch, tail uint64, err error) error {
+	WriteTxIndexTail(batch, tail)
+	if werr := batch.Write(); werr != nil {
+		return werr
+	}
+	return err
+}
+
+// txIndexTarget returns the oldest block whose transactions should be indexed
+// given the chain head.
+func (bc *BlockChain) txIndexTarget(head uint64) uint64 {
+	if limit := bc.cacheConfig.TxLookupLimit; limit != 0 && head >= limit {
+		return head - limit + 1
+	}
+	return 0
+}
+
+// TxIndexProgress returns the progress of the transaction indexer.
+func (bc *BlockChain) TxIndexProgress() TxIndexProgress {
+	var (
+		head   = bc.CurrentBlock().NumberU64()
+		target = bc.txIndexTarget(head)
+		tail   uint64
+	)
+	if stored := GetTxIndexTail(bc.chainDb); stored != nil {
+		tail = *stored
+	}
+	var progress TxIndexProgress
+	if tail <= head {
+		progress.Indexed = head - tail + 1
+	}
+	if tail > target {
+		progress.Remaining = tail - target
+	}
+	return progress
+}
+
+// indexBlocks brings the transaction index in line with the lookup limit for
+// the given head, indexing missing blocks or unindexing stale ones.
+func (bc *BlockChain) indexBlocks(head uint64, done chan struct{}) {
+	defer close(done)
+
+	// Without a tail all blocks are indexed, as before the lookup limit existed
+	var tail uint64
+	if stored := GetTxIndexTail(bc.chainDb); stored != nil {
+		tail = *stored
+	}
+	target := bc.txIndexTarget(head)
+
+	var err error
+	switch {
+	case target < tail:
+		err = IndexTransactions(bc.chainDb, target, tail, bc.quit)
+	case target > tail:
+		err = UnindexTransactions(bc.chainDb, tail, target, bc.quit)
+	}
+	if err != nil && err != errTxIndexInterrupted {
+		log.Error("Failed to maintain transaction index", "head", head, "tail", tail, "target", target, "err", err)
+	}
+}
+
+// maintainTxIndex keeps the transaction index in line with the lookup limit as
+// the chain progresses, one indexing run at a time.
+func (bc *BlockChain) maintainTxIndex() {
+	defer bc.wg.Done()
+
+	headCh := make(chan ChainHeadEvent, 1)
+	sub := bc.SubscribeChainHeadEvent(headCh)
+	if sub == nil {
+		return // Chain already stopped
+	}
+	defer sub.Unsubscribe()
+
+	// Bring the index in line with the current head before waiting for new ones
+	done := make(chan struct{})
+	go bc.indexBlocks(bc.CurrentBlock().NumberU64(), done)
+
+	for {
+		select {
+		case head := <-headCh:
+			if done == nil {
+				done = make(chan struct{})
+				go bc.indexBlocks(head.Block.NumberU64(), done)
+			}
+		case <-done:
+			done = nil
+		case <-sub.Err():
+			if done != nil {
+				<-done
+			}
+			return
+		case <-bc.quit:
+			if done != nil {
+				log.Info("Waiting for the transaction indexer to exit")
+				<-done
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2018 The mesh-chain Authors
+// This file is part of the mesh-chain library.
+//
+// The mesh-chain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The mesh-chain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the mesh-chain library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/consensus/ethash"
+	"github.com/MeshBoxTech/mesh-chain/core/types"
+	"github.com/MeshBoxTech/mesh-chain/core/vm"
+	"github.com/MeshBoxTech/mesh-chain/ethdb"
+	"github.com/MeshBoxTech/mesh-chain/params"
+)
+
+// checkTxIndex verifies that the transactions of the blocks at or above tail are
+// indexed and the ones below aren't.
+func checkTxIndex(t *testing.T, db ethdb.Database, blocks []*types.Block, tail uint64) {
+	if stored := GetTxIndexTail(db); stored == nil || *stored != tail {
+		t.Fatalf("index tail mismatch: have %v, want %d", stored, tail)
+	}
+	for _, block := range blocks {
+		for _, tx := range block.Transactions() {
+			hash, _, _ := GetTxLookupEntry(db, tx.Hash())
+			if indexed := hash != (common.Hash{}); indexed != (block.NumberU64() >= tail) {
+				t.Errorf("block #%d: indexed mismatch: have %v, want %v", block.NumberU64(), indexed, !indexed)
+			}
+		}
+	}
+}
+
+func TestTxIndexing(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+
+	var blocks []*types.Block
+	for i := 0; i < 10; i++ {
+		tx := types.NewTransaction(uint64(i), common.Address{0x01}, big.NewInt(1), big.NewInt(21000), big.NewInt(1), nil)
+		block := types.NewBlock(&types.Header{Number: big.NewInt(int64(i))}, []*types.Transaction{tx}, nil, nil)
+		WriteBlock(db, block)
+		WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		blocks = append(blocks, block)
+	}
+	if err := IndexTransactions(db, 5, 10, nil); err != nil {
+		t.Fatalf("failed to index transactions: %v", err)
+	}
+	checkTxIndex(t, db, blocks, 5)
+
+	if err := IndexTransactions(db, 0, 5, nil); err != nil {
+		t.Fatalf("failed to index transactions: %v", err)
+	}
+	checkTxIndex(t, db, blocks, 0)
+
+	if err := UnindexTransactions(db, 0, 7, nil); err != nil {
+		t.Fatalf("failed to unindex transactions: %v", err)
+	}
+	checkTxIndex(t, db, blocks, 7)
+
+	// An interrupted run leaves the tail where it got to
+	interrupt := make(chan struct{})
+	close(interrupt)
+	if err := IndexTransactions(db, 0, 7, interrupt); err != errTxIndexInterrupted {
+		t.Fatalf("interrupt error mismatch: have %v, want %v", err, errTxIndexInterrupted)
+	}
+	checkTxIndex(t, db, blocks, 7)
+}
+
+func TestTxIndexLimit(t *testing.T) {
+	var (
+		gspec = &Genesis{Config: params.TestChainConfig}
+		db, _ = ethdb.NewMemDatabase()
+	)
+	genesis := gspec.MustCommit(db)
+	blocks, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 10, nil)
+
+	// waitTail waits for the indexer of the chain to move the tail to the given block
+	waitTail := func(chain *BlockChain, tail uint64) {
+		for i := 0; i < 100; i++ {
+			if stored := GetTxIndexTail(db); stored != nil && *stored == tail {
+				if progress := chain.TxIndexProgress(); !progress.Done() || progress.Indexed != chain.CurrentBlock().NumberU64()-tail+1 {
+					t.Errorf("index progress mismatch: have %+v, want %d indexed", progress, chain.CurrentBlock().NumberU64()-tail+1)
+				}
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatalf("index tail mismatch: have %v, want %d", GetTxIndexTail(db), tail)
+	}
+	chain, _ := NewBlockChain(db, &CacheConfig{TxLookupLimit: 4}, gspec.Config, ethash.NewFaker(), vm.Config{})
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatal(err)
+	}
+	waitTail(chain, 7)
+	chain.Stop()
+
+	// Raising the limit reindexes the older blocks
+	chain, _ = NewBlockChain(db, &CacheConfig{TxLookupLimit: 8}, gspec.Config, ethash.NewFaker(), vm.Config{})
+	waitTail(chain, 3)
+	chain.Stop()
+
+	// Dropping the limit indexes the entire chain again
+	chain, _ = NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{})
+	defer chain.Stop()
+	waitTail(chain, 0)
+}
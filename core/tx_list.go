@@ -274,11 +274,10 @@ func (l *txList) Add(tx *types.Transaction, priceBump uint64) (bool, *types.Tran
 	// If there's an older better transaction, abort
 	old := l.txs.Get(tx.Nonce())
 	if old != nil {
-		threshold := new(big.Int).Div(new(big.Int).Mul(old.GasPrice(), big.NewInt(100+int64(priceBump))), big.NewInt(100))
-		// Have to ensure that the new gas price is higher than the old gas
-		// price as well as checking the percentage threshold to ensure that
+		// Have to ensure that both the new fee cap and tip are higher than the
+		// old ones as well as checking the percentage threshold to ensure that
 		// this is accurate for low (Wei-level) gas price replacements
-		if old.GasPrice().Cmp(tx.GasPrice()) >= 0 || threshold.Cmp(tx.GasPrice()) > 0 {
+		if !bumped(old.GasFeeCap(), tx.GasFeeCap(), priceBump) || !bumped(old.GasTipCap(), tx.GasTipCap(), priceBump) {
 			return false, nil
 		}
 	}
@@ -293,6 +292,13 @@ func (l *txList) Add(tx *types.Transaction, priceBump uint64) (bool, *types.Tran
 	return true, old
 }
 
+// bumped returns whether the new price is higher than the old one by at least
+// priceBump percent.
+func bumped(old, price *big.Int, priceBump uint64) bool {
+	threshold := new(big.Int).Div(new(big.Int).Mul(old, big.NewInt(100+int64(priceBump))), big.NewInt(100))
+	return old.Cmp(price) < 0 && threshold.Cmp(price) <= 0
+}
+
 // Forward removes all transactions from the list with a nonce lower than the
 // provided threshold. Every removed transaction is returned for any post-removal
 // maintenance.
@@ -335,6 +341,25 @@ func (l *txList) Filter(costLimit, gasLimit *big.Int) (types.Transactions, types
 	return removed, invalids
 }
 
+// FilterInvalid removes all transactions from the list failing the given
+// validity check, returning them along with, if the list is strict, the ones
+// with a higher nonce that can't be executed any more.
+func (l *txList) FilterInvalid(valid func(*types.Transaction) bool) (types.Transactions, types.Transactions) {
+	removed := l.txs.Filter(func(tx *types.Transaction) bool { return !valid(tx) })
+
+	var invalids types.Transactions
+	if l.strict && len(removed) > 0 {
+		lowest := uint64(math.MaxUint64)
+		for _, tx := range removed {
+			if nonce := tx.Nonce(); lowest > nonce {
+				lowest = nonce
+			}
+		}
+		invalids = l.txs.Filter(func(tx *types.Transaction) bool { return tx.Nonce() > lowest })
+	}
+	return removed, invalids
+}
+
 // Cap places a hard limit on the number of items, returning all transactions
 // exceeding that limit.
 func (l *txList) Cap(threshold int) types.Transactions {
@@ -386,22 +411,37 @@ func (l *txList) Flatten() types.Transactions {
 }
 
 // priceHeap is a heap.Interface implementation over transactions for retrieving
-// price-sorted transactions to discard when the pool fills up.
-type priceHeap []*types.Transaction
+// price-sorted transactions to discard when the pool fills up. Transactions are
+// ordered by the tip they pay on top of the base fee, if one is set.
+type priceHeap struct {
+	baseFee *big.Int // heap should always be re-sorted after baseFee is changed
+	list    []*types.Transaction
+}
 
-func (h priceHeap) Len() int           { return len(h) }
-func (h priceHeap) Less(i, j int) bool { return h[i].GasPrice().Cmp(h[j].GasPrice()) < 0 }
-func (h priceHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *priceHeap) Len() int           { return len(h.list) }
+func (h *priceHeap) Less(i, j int) bool { return h.cmp(h.list[i], h.list[j]) < 0 }
+func (h *priceHeap) Swap(i, j int)      { h.list[i], h.list[j] = h.list[j], h.list[i] }
+
+// cmp compares the effective tips of two transactions at the current base fee,
+// falling back to their fee caps on equal tips.
+func (h *priceHeap) cmp(a, b *types.Transaction) int {
+	tipA, _ := a.EffectiveGasTip(h.baseFee)
+	tipB, _ := b.EffectiveGasTip(h.baseFee)
+	if c := tipA.Cmp(tipB); c != 0 {
+		return c
+	}
+	return a.GasFeeCap().Cmp(b.GasFeeCap())
+}
 
 func (h *priceHeap) Push(x interface{}) {
-	*h = append(*h, x.(*types.Transaction))
+	h.list = append(h.list, x.(*types.Transaction))
 }
 
 func (h *priceHeap) Pop() interface{} {
-	old := *h
+	old := h.list
 	n := len(old)
 	x := old[n-1]
-	*h = old[0 : n-1]
+	h.list = old[0 : n-1]
 	return x
 }
 
@@ -432,26 +472,39 @@ func (l *txPricedList) Put(tx *types.Transaction) {
 func (l *txPricedList) Removed() {
 	// Bump the stale counter, but exit if still too low (< 25%)
 	l.stales++
-	if l.stales <= len(*l.items)/4 {
+	if l.stales <= l.items.Len()/4 {
 		return
 	}
 	// Seems we've reached a critical number of stale transactions, reheap
-	reheap := make(priceHeap, 0, len(*l.all))
+	l.Reheap()
+}
 
-	l.stales, l.items = 0, &reheap
+// Reheap forcibly rebuilds the heap from the pool contents, dropping all the
+// stale price points.
+func (l *txPricedList) Reheap() {
+	reheap := &priceHeap{baseFee: l.items.baseFee, list: make([]*types.Transaction, 0, len(*l.all))}
+
+	l.stales, l.items = 0, reheap
 	for _, tx := range *l.all {
-		*l.items = append(*l.items, tx)
+		l.items.list = append(l.items.list, tx)
 	}
 	heap.Init(l.items)
 }
 
+// SetBaseFee updates the base fee the transactions are ordered at and re-sorts
+// the heap.
+func (l *txPricedList) SetBaseFee(baseFee *big.Int) {
+	l.items.baseFee = baseFee
+	l.Reheap()
+}
+
 // Cap finds all the transactions below the given price threshold, drops them
 // from the priced list and returs them for further removal from the entire pool.
 func (l *txPricedList) Cap(threshold *big.Int, local *accountSet) types.Transactions {
 	drop := make(types.Transactions, 0, 128) // Remote underpriced transactions to drop
 	save := make(types.Transactions, 0, 64)  // Local underpriced transactions to keep
 
-	for len(*l.items) > 0 {
+	for l.items.Len() > 0 {
 		// Discard stale transactions if found during cleanup
 		tx := heap.Pop(l.items).(*types.Transaction)
 		if _, ok := (*l.all)[tx.Hash()]; !ok {
@@ -459,7 +512,7 @@ func (l *txPricedList) Cap(threshold *big.Int, local *accountSet) types.Transact
 			continue
 		}
 		// Stop the discards if we've reached the threshold
-		if tx.GasPrice().Cmp(threshold) >= 0 {
+		if tip, _ := tx.EffectiveGasTip(l.items.baseFee); tip.Cmp(threshold) >= 0 {
 			save = append(save, tx)
 			break
 		}
@@ -484,8 +537,8 @@ func (l *txPricedList) Underpriced(tx *types.Transaction, local *accountSet) boo
 		return false
 	}
 	// Discard stale price points if found at the heap start
-	for len(*l.items) > 0 {
-		head := []*types.Transaction(*l.items)[0]
+	for l.items.Len() > 0 {
+		head := l.items.list[0]
 		if _, ok := (*l.all)[head.Hash()]; !ok {
 			l.stales--
 			heap.Pop(l.items)
@@ -494,12 +547,12 @@ func (l *txPricedList) Underpriced(tx *types.Transaction, local *accountSet) boo
 		break
 	}
 	// Check if the transaction is underpriced or not
-	if len(*l.items) == 0 {
+	if l.items.Len() == 0 {
 		log.Error("Pricing query for empty pool") // This cannot happen, print to catch programming errors
 		return false
 	}
-	cheapest := []*types.Transaction(*l.items)[0]
-	return cheapest.GasPrice().Cmp(tx.GasPrice()) >= 0
+	cheapest := l.items.list[0]
+	return l.items.cmp(cheapest, tx) >= 0
 }
 
 // Discard finds a number of most underpriced transactions, removes them from the
@@ -508,7 +561,7 @@ func (l *txPricedList) Discard(count int, local *accountSet) types.Transactions
 	drop := make(types.Transactions, 0, count) // Remote underpriced transactions to drop
 	save := make(types.Transactions, 0, 64)    // Local underpriced transactions to keep
 
-	for len(*l.items) > 0 && count > 0 {
+	for l.items.Len() > 0 && count > 0 {
 		// Discard stale transactions if found during cleanup
 		tx := heap.Pop(l.items).(*types.Transaction)
 		if _, ok := (*l.all)[tx.Hash()]; !ok {
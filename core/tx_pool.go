@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/consensus/misc"
 	"github.com/MeshBoxTech/mesh-chain/core/state"
 	"github.com/MeshBoxTech/mesh-chain/core/types"
 	"github.com/MeshBoxTech/mesh-chain/event"
@@ -48,6 +49,10 @@ var (
 	// ErrInvalidSender is returned if the transaction contains an invalid signature.
 	ErrInvalidSender = errors.New("invalid sender")
 
+	// ErrInvalidSponsor is returned if a sponsored transaction contains an
+	// invalid sponsor signature.
+	ErrInvalidSponsor = errors.New("invalid sponsor")
+
 	// ErrNonceTooLow is returned if the nonce of a transaction is lower than the
 	// one present in the local chain.
 	ErrNonceTooLow = errors.New("nonce too low")
@@ -64,6 +69,10 @@ var (
 	// is higher than the balance of the user's account.
 	ErrInsufficientFunds = errors.New("insufficient funds for gas * price + value")
 
+	// ErrInsufficientSponsorFunds is returned if the gas cost of a sponsored
+	// transaction is higher than the balance of the sponsor's account.
+	ErrInsufficientSponsorFunds = errors.New("insufficient sponsor funds for gas * price")
+
 	// ErrIntrinsicGas is returned if the transaction is specified to use less gas
 	// than required to start the invocation.
 	ErrIntrinsicGas = errors.New("intrinsic gas too low")
@@ -80,6 +89,14 @@ var (
 	// than some meaningful limit a user might use. This is not a consensus error
 	// making the transaction invalid, rather a DOS protection.
 	ErrOversizedData = errors.New("oversized data")
+
+	// ErrTxTypeNotSupported is returned if a transaction is not supported in the
+	// current network configuration.
+	ErrTxTypeNotSupported = types.ErrTxTypeNotSupported
+
+	// ErrPrivateTxExpired is returned if a private transaction is submitted with
+	// a maximum block number that is already part of the chain.
+	ErrPrivateTxExpired = errors.New("private transaction expired")
 )
 
 var (
@@ -103,8 +120,25 @@ var (
 	// General tx metrics
 	invalidTxCounter     = metrics.NewCounter("txpool/invalid")
 	underpricedTxCounter = metrics.NewCounter("txpool/underpriced")
+
+	// Metrics for the private transactions
+	privateDropCounter    = metrics.NewCounter("txpool/private/drop")    // Dropped once expired
+	privatePublishCounter = metrics.NewCounter("txpool/private/publish") // Made public once expired
 )
 
+// PrivateTx is a transaction submitted for inclusion by upcoming validators
+// only, which is kept out of the public broadcast until it expires.
+type PrivateTx struct {
+	Tx             *types.Transaction
+	MaxBlockNumber uint64 // Last block the transaction may be included in
+}
+
+// privateTx tracks the expiry of a private transaction in the pool.
+type privateTx struct {
+	maxBlockNumber uint64 // Last block the transaction may be included in
+	publish        bool   // Whether to broadcast instead of drop the transaction once expired
+}
+
 // TxStatus is the current status of a transaction as seen by the pool.
 type TxStatus uint
 
@@ -271,10 +305,15 @@ type TxPool struct {
 	all     map[common.Hash]*types.Transaction // All transactions to allow lookups
 	fail    map[common.Hash]int32              // add by liangc : when apply tx error , put in this map and if counter greate then failLimit do removeTx
 	priced  *txPricedList                      // All transactions sorted by price
+	private map[common.Hash]*privateTx         // Transactions kept out of the public broadcast
 
 	wg sync.WaitGroup // for shutdown sync
 
 	homestead bool
+	eip2718   bool     // Fork indicator whether we are using EIP-2718 type transactions.
+	eip1559   bool     // Fork indicator whether we are using EIP-1559 type transactions.
+	sponsored bool     // Fork indicator whether we are accepting sponsored transactions.
+	nextNum   *big.Int // Number of the next pending block, for the sponsor allowances
 }
 
 // NewTxPool creates a new transaction pool to gather, sort and filter inbound
@@ -287,13 +326,14 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 		config:      config,
 		chainconfig: chainconfig,
 		chain:       chain,
-		signer:      types.NewEIP155Signer(chainconfig.ChainId),
+		signer:      types.LatestSigner(chainconfig),
 		//pending:     make(map[common.Address]*txList),
 		pending:     newSafePending(),
 		queue:       make(map[common.Address]*txList),
 		beats:       make(map[common.Address]time.Time),
 		all:         make(map[common.Hash]*types.Transaction),
 		fail:        make(map[common.Hash]int32),
+		private:     make(map[common.Hash]*privateTx),
 		chainHeadCh: make(chan ChainHeadEvent, chainHeadChanSize),
 		gasPrice:    new(big.Int).SetUint64(config.PriceLimit),
 	}
@@ -485,6 +525,18 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	pool.pendingState = state.ManageState(statedb)
 	pool.currentMaxGas = newHead.GasLimit
 
+	// Update the fork indicator for the next pending block
+	next := new(big.Int).Add(newHead.Number, big.NewInt(1))
+	pool.eip2718 = pool.chainconfig.IsBerlin(next)
+	pool.eip1559 = pool.chainconfig.IsLondon(next)
+	pool.sponsored = pool.chainconfig.IsSponsor(next)
+	pool.nextNum = next
+
+	// Order the transactions by the tip they pay on top of the next base fee
+	if pool.eip1559 {
+		pool.priced.SetBaseFee(misc.CalcBaseFee(pool.chainconfig, newHead))
+	}
+
 	// Inject any transactions discarded due to reorgs
 	log.Debug("Reinjecting stale transactions", "count", len(reinject))
 	pool.addTxsLocked(reinject, false)
@@ -514,6 +566,36 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	// Check the queue and move transactions over to the pending if possible
 	// or remove those that have become invalid
 	pool.promoteExecutables(nil)
+
+	// Expire the private transactions that can no longer make it in privately
+	pool.expirePrivates(newHead.Number.Uint64())
+}
+
+// expirePrivates forgets the private transactions no longer in the pool, and
+// drops or publishes the ones that missed their last block.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) expirePrivates(number uint64) {
+	for hash, private := range pool.private {
+		tx := pool.all[hash]
+		if tx == nil {
+			delete(pool.private, hash)
+			continue
+		}
+		if private.maxBlockNumber > number {
+			continue
+		}
+		delete(pool.private, hash)
+		if private.publish {
+			log.Debug("Publishing expired private transaction", "hash", hash, "max", private.maxBlockNumber)
+			privatePublishCounter.Inc(1)
+			go pool.txFeed.Send(TxPreEvent{tx})
+		} else {
+			log.Debug("Dropping expired private transaction", "hash", hash, "max", private.maxBlockNumber)
+			privateDropCounter.Inc(1)
+			pool.removeTx(hash)
+		}
+	}
 }
 
 // Stop terminates the transaction pool.
@@ -590,6 +672,15 @@ func (pool *TxPool) stats() (int, int) {
 	return pending, queued
 }
 
+// PrivateStats retrieves the number of private transactions in the pool, which
+// are also counted as pending or queued.
+func (pool *TxPool) PrivateStats() int {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	return len(pool.private)
+}
+
 // Content retrieves the data content of the transaction pool, returning all the
 // pending as well as queued transactions, grouped by account and sorted by nonce.
 func (pool *TxPool) Content() (map[common.Address]types.Transactions, map[common.Address]types.Transactions) {
@@ -646,6 +737,18 @@ func (pool *TxPool) local() map[common.Address]types.Transactions {
 // validateTx checks whether a transaction is valid according to the consensus
 // rules and adheres to some heuristic limits of the local node (price and size).
 func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
+	// Accept only legacy transactions until EIP-2718/2930 activates.
+	if !pool.eip2718 && tx.Type() != types.LegacyTxType {
+		return ErrTxTypeNotSupported
+	}
+	// Accept dynamic fee transactions only once the fee market activates.
+	if !pool.eip1559 && tx.Type() == types.DynamicFeeTxType {
+		return ErrTxTypeNotSupported
+	}
+	// Accept sponsored transactions only once their fork activates.
+	if !pool.sponsored && tx.Type() == types.SponsoredTxType {
+		return ErrTxTypeNotSupported
+	}
 	// Heuristic limit, reject transactions over 32KB to prevent DOS attacks
 	if tx.Size() > 32*1024 {
 		return ErrOversizedData
@@ -659,15 +762,19 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	if pool.currentMaxGas.Cmp(tx.Gas()) < 0 {
 		return ErrGasLimit
 	}
+	// Ensure the tip does not exceed the fee cap.
+	if tx.GasFeeCap().Cmp(tx.GasTipCap()) < 0 {
+		return ErrTipAboveFeeCap
+	}
 	// Make sure the transaction is signed properly
 
 	from, err := types.Sender(pool.signer, tx)
 	if err != nil {
 		return ErrInvalidSender
 	}
-	// Drop non-local transactions under our own minimal accepted gas price
+	// Drop non-local transactions under our own minimal accepted tip
 	local = local || pool.locals.contains(from) // account may be local even if the transaction arrived from the network
-	if !local && pool.gasPrice.Cmp(tx.GasPrice()) > 0 {
+	if !local && pool.gasPrice.Cmp(tx.GasTipCap()) > 0 {
 		return ErrUnderpriced
 	}
 	// Ensure the transaction adheres to nonce ordering
@@ -679,13 +786,43 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	if pool.currentState.GetBalance(from).Cmp(tx.Cost()) < 0 {
 		return ErrInsufficientFunds
 	}
-	intrGas := IntrinsicGas(tx.Data(), tx.To() == nil, pool.homestead)
+	// The sponsor of a sponsored transaction should be allowed for the
+	// destination and cover the gas
+	if err := pool.validateSponsor(tx); err != nil {
+		return err
+	}
+	intrGas := IntrinsicGas(tx.Data(), tx.AccessList(), tx.To() == nil, pool.homestead)
 	if tx.Gas().Cmp(intrGas) < 0 {
 		return ErrIntrinsicGas
 	}
 	return nil
 }
 
+// validateSponsor checks that the sponsor of a sponsored transaction is allowed
+// to pay for calls to its destination in the next block and that the current
+// state covers the gas. Other transactions are always valid.
+func (pool *TxPool) validateSponsor(tx *types.Transaction) error {
+	if tx.Type() != types.SponsoredTxType {
+		return nil
+	}
+	sponsor, err := types.Sponsor(pool.signer, tx)
+	if err != nil {
+		return ErrInvalidSponsor
+	}
+	if !pool.chainconfig.Sponsor.Allowed(pool.nextNum, sponsor, tx.To()) {
+		return ErrSponsorNotAllowed
+	}
+	if pool.currentState.GetBalance(sponsor).Cmp(tx.GasCost()) < 0 {
+		return ErrInsufficientSponsorFunds
+	}
+	return nil
+}
+
+// sponsorValid reports whether the sponsor of the transaction can still pay for it.
+func (pool *TxPool) sponsorValid(tx *types.Transaction) bool {
+	return pool.validateSponsor(tx) == nil
+}
+
 // add validates a transaction and inserts it into the non-executable queue for
 // later pending promotion and execution. If the transaction is a replacement for
 // an already pending or queued one, it overwrites the previous and returns this
@@ -895,6 +1032,61 @@ func (pool *TxPool) AddRemotes(txs []*types.Transaction) []error {
 	return pool.addTxs(txs, false)
 }
 
+// AddPrivate enqueues a single transaction into the pool if it is valid, keeping
+// it out of the public broadcast until it is included or maxBlockNumber passes.
+// An expired transaction is dropped, or broadcast if publish is set.
+func (pool *TxPool) AddPrivate(tx *types.Transaction, maxBlockNumber uint64, publish bool) error {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	if maxBlockNumber <= pool.chain.CurrentBlock().NumberU64() {
+		return ErrPrivateTxExpired
+	}
+	// Mark the transaction private before it is announced to the subscribers
+	hash := tx.Hash()
+	if pool.all[hash] != nil {
+		return fmt.Errorf("known transaction: %x", hash)
+	}
+	pool.private[hash] = &privateTx{maxBlockNumber: maxBlockNumber, publish: publish}
+
+	replace, err := pool.add(tx, false)
+	if err != nil {
+		delete(pool.private, hash)
+		return err
+	}
+	if !replace {
+		from, _ := types.Sender(pool.signer, tx) // already validated
+		pool.promoteExecutables([]common.Address{from})
+	}
+	return nil
+}
+
+// Private returns the last block a private transaction may be included in, and
+// false if the transaction is not private.
+func (pool *TxPool) Private(hash common.Hash) (uint64, bool) {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	if private := pool.private[hash]; private != nil {
+		return private.maxBlockNumber, true
+	}
+	return 0, false
+}
+
+// Privates retrieves the private transactions in the pool.
+func (pool *TxPool) Privates() []*PrivateTx {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	txs := make([]*PrivateTx, 0, len(pool.private))
+	for hash, private := range pool.private {
+		if tx := pool.all[hash]; tx != nil {
+			txs = append(txs, &PrivateTx{Tx: tx, MaxBlockNumber: private.maxBlockNumber})
+		}
+	}
+	return txs
+}
+
 // addTx enqueues a single transaction into the pool if it is valid.
 func (pool *TxPool) addTx(tx *types.Transaction, local bool) error {
 	log.Debug("TODO<<TxPool.addTx>> take_lock_begin", "tx", tx.Hash().Hex())
@@ -1060,6 +1252,15 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 			pool.priced.Removed()
 			queuedNofundsCounter.Inc(1)
 		}
+		// Drop all sponsored transactions their sponsor can't pay for any more
+		drops, _ = list.FilterInvalid(pool.sponsorValid)
+		for _, tx := range drops {
+			hash := tx.Hash()
+			log.Trace("Removed unsponsored queued transaction", "hash", hash)
+			delete(pool.all, hash)
+			pool.priced.Removed()
+			queuedNofundsCounter.Inc(1)
+		}
 		// Gather all executable transactions and promote them
 		for _, tx := range list.Ready(pool.pendingState.GetNonce(addr)) {
 			hash := tx.Hash()
@@ -1243,6 +1444,20 @@ func (pool *TxPool) demoteUnexecutables() {
 			log.Trace("Demoting pending transaction", "hash", hash)
 			pool.enqueueTx(hash, tx)
 		}
+		// Drop all sponsored transactions their sponsor can't pay for any more
+		drops, invalids = list.FilterInvalid(pool.sponsorValid)
+		for _, tx := range drops {
+			hash := tx.Hash()
+			log.Trace("Removed unsponsored pending transaction", "hash", hash)
+			delete(pool.all, hash)
+			pool.priced.Removed()
+			pendingNofundsCounter.Inc(1)
+		}
+		for _, tx := range invalids {
+			hash := tx.Hash()
+			log.Trace("Demoting pending transaction", "hash", hash)
+			pool.enqueueTx(hash, tx)
+		}
 		// If there's a gap in front, warn (should never happen) and postpone all transactions
 		if list.Len() > 0 && list.txs.Get(nonce) == nil {
 			for _, tx := range list.Cap(0) {
@@ -264,6 +264,284 @@ func TestInvalidTransactions(t *testing.T) {
 	}
 }
 
+// Tests that access list transactions are only accepted once the Berlin fork
+// is active for the next block, and that their access list is charged for.
+func TestTransactionTypes(t *testing.T) {
+	t.Parallel()
+
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	acl := types.AccessList{{Address: common.Address{0x01}, StorageKeys: []common.Hash{{0x01}}}}
+
+	config := *params.TestChainConfig
+	for i, berlin := range []*big.Int{nil, big.NewInt(1)} {
+		config.BerlinBlock = berlin
+
+		db, _ := ethdb.NewMemDatabase()
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+		blockchain := &testBlockChain{statedb, big.NewInt(1000000), new(event.Feed)}
+		pool := NewTxPool(testTxPoolConfig, &config, blockchain)
+		pool.currentState.AddBalance(from, big.NewInt(0xffffffffffffff))
+
+		signer := types.LatestSignerForChainID(config.ChainId)
+		intrinsic := new(big.Int).SetUint64(params.TxGas + params.TxAccessListAddressGas + params.TxAccessListStorageKeyGas)
+		tx, err := types.SignNewTx(key, signer, &types.AccessListTx{
+			ChainID:    config.ChainId,
+			Nonce:      0,
+			GasPrice:   big.NewInt(1),
+			Gas:        new(big.Int).Sub(intrinsic, common.Big1),
+			To:         &common.Address{},
+			Value:      big.NewInt(100),
+			AccessList: acl,
+		})
+		if err != nil {
+			t.Fatalf("test %d: failed to sign transaction: %v", i, err)
+		}
+		if berlin == nil {
+			if err := pool.AddRemote(tx); err != ErrTxTypeNotSupported {
+				t.Errorf("test %d: pre-fork error mismatch: have %v, want %v", i, err, ErrTxTypeNotSupported)
+			}
+			pool.Stop()
+			continue
+		}
+		if err := pool.AddRemote(tx); err != ErrIntrinsicGas {
+			t.Errorf("test %d: intrinsic gas error mismatch: have %v, want %v", i, err, ErrIntrinsicGas)
+		}
+		tx, _ = types.SignNewTx(key, signer, &types.AccessListTx{
+			ChainID:    config.ChainId,
+			Nonce:      0,
+			GasPrice:   big.NewInt(1),
+			Gas:        intrinsic,
+			To:         &common.Address{},
+			Value:      big.NewInt(100),
+			AccessList: acl,
+		})
+		if err := pool.AddRemote(tx); err != nil {
+			t.Errorf("test %d: failed to add access list transaction: %v", i, err)
+		}
+		pool.Stop()
+	}
+}
+
+// Tests that sponsored transactions are only accepted after their fork, from
+// allowed sponsors holding enough funds for the gas.
+func TestSponsoredTransactions(t *testing.T) {
+	t.Parallel()
+
+	key, _ := crypto.GenerateKey()
+	sponsorKey, _ := crypto.GenerateKey()
+	sponsor := crypto.PubkeyToAddress(sponsorKey.PublicKey)
+
+	config := *params.TestChainConfig
+	config.BerlinBlock = big.NewInt(1)
+	config.Sponsor = &params.SponsorConfig{
+		Block: big.NewInt(1),
+		Allowances: []*params.SponsorAllowance{
+			{Block: big.NewInt(1), Sponsor: sponsor, Contracts: []common.Address{params.PomContractAddr}},
+		},
+	}
+	db, _ := ethdb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	blockchain := &testBlockChain{statedb, big.NewInt(1000000), new(event.Feed)}
+	pool := NewTxPool(testTxPoolConfig, &config, blockchain)
+	defer pool.Stop()
+
+	signer := types.LatestSigner(&config)
+	sponsored := func(nonce uint64, to common.Address, sponsorKey *ecdsa.PrivateKey) *types.Transaction {
+		tx, err := types.SignNewTx(key, signer, &types.SponsoredTx{
+			ChainID:  config.ChainId,
+			Nonce:    nonce,
+			GasPrice: big.NewInt(1),
+			Gas:      big.NewInt(100000),
+			To:       &to,
+			Value:    big.NewInt(0),
+		})
+		if err != nil {
+			t.Fatalf("failed to sign transaction: %v", err)
+		}
+		if sponsorKey == nil {
+			return tx
+		}
+		if tx, err = types.SignSponsor(tx, signer, sponsorKey); err != nil {
+			t.Fatalf("failed to sponsor transaction: %v", err)
+		}
+		return tx
+	}
+	if err := pool.AddRemote(sponsored(0, params.PomContractAddr, nil)); err != ErrInvalidSponsor {
+		t.Errorf("unsponsored error mismatch: have %v, want %v", err, ErrInvalidSponsor)
+	}
+	if err := pool.AddRemote(sponsored(0, common.Address{0x01}, sponsorKey)); err != ErrSponsorNotAllowed {
+		t.Errorf("foreign destination error mismatch: have %v, want %v", err, ErrSponsorNotAllowed)
+	}
+	if err := pool.AddRemote(sponsored(0, params.PomContractAddr, key)); err != ErrSponsorNotAllowed {
+		t.Errorf("foreign sponsor error mismatch: have %v, want %v", err, ErrSponsorNotAllowed)
+	}
+	if err := pool.AddRemote(sponsored(0, params.PomContractAddr, sponsorKey)); err != ErrInsufficientSponsorFunds {
+		t.Errorf("sponsor funds error mismatch: have %v, want %v", err, ErrInsufficientSponsorFunds)
+	}
+	// The sender holds no funds at all, the sponsor pays for the gas.
+	pool.currentState.AddBalance(sponsor, big.NewInt(100000))
+	if err := pool.AddRemote(sponsored(0, params.PomContractAddr, sponsorKey)); err != nil {
+		t.Errorf("failed to add sponsored transaction: %v", err)
+	}
+	if err := pool.AddRemote(sponsored(2, params.PomContractAddr, sponsorKey)); err != nil {
+		t.Errorf("failed to add queued sponsored transaction: %v", err)
+	}
+	if pending, queued := pool.Stats(); pending != 1 || queued != 1 {
+		t.Errorf("transactions mismatch: have %d pending, %d queued, want 1 and 1", pending, queued)
+	}
+	// Draining the sponsor drops its transactions on the next reset
+	pool.currentState.SubBalance(sponsor, big.NewInt(1))
+	pool.lockedReset(nil, nil)
+
+	if pending, queued := pool.Stats(); pending != 0 || queued != 0 {
+		t.Errorf("transactions mismatch after drain: have %d pending, %d queued, want none", pending, queued)
+	}
+	if len(pool.all) != 0 {
+		t.Errorf("drained sponsor transactions still known: %d", len(pool.all))
+	}
+}
+
+func TestDynamicFeeTransactions(t *testing.T) {
+	t.Parallel()
+
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+
+	config := *params.TestChainConfig
+	config.BerlinBlock = big.NewInt(1)
+	config.LondonBlock = big.NewInt(1)
+
+	db, _ := ethdb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	blockchain := &testBlockChain{statedb, big.NewInt(1000000), new(event.Feed)}
+	pool := NewTxPool(testTxPoolConfig, &config, blockchain)
+	defer pool.Stop()
+
+	pool.currentState.AddBalance(from, big.NewInt(100000000))
+
+	signer := types.LatestSigner(&config)
+	dynamic := func(nonce uint64, tip, feeCap int64) *types.Transaction {
+		tx, err := types.SignNewTx(key, signer, &types.DynamicFeeTx{
+			ChainID:   config.ChainId,
+			Nonce:     nonce,
+			GasTipCap: big.NewInt(tip),
+			GasFeeCap: big.NewInt(feeCap),
+			Gas:       big.NewInt(100000),
+			To:        &common.Address{},
+			Value:     big.NewInt(0),
+		})
+		if err != nil {
+			t.Fatalf("failed to sign transaction: %v", err)
+		}
+		return tx
+	}
+	if err := pool.AddRemote(dynamic(0, 2, 1)); err != ErrTipAboveFeeCap {
+		t.Errorf("tip above fee cap error mismatch: have %v, want %v", err, ErrTipAboveFeeCap)
+	}
+	if err := pool.AddRemote(dynamic(0, 0, 10)); err != ErrUnderpriced {
+		t.Errorf("underpriced error mismatch: have %v, want %v", err, ErrUnderpriced)
+	}
+	if err := pool.AddRemote(dynamic(0, 10, 100)); err != nil {
+		t.Fatalf("failed to add dynamic fee transaction: %v", err)
+	}
+	// Replacements have to bump both the fee cap and the tip
+	if err := pool.AddRemote(dynamic(0, 10, 200)); err != ErrReplaceUnderpriced {
+		t.Errorf("tip replacement error mismatch: have %v, want %v", err, ErrReplaceUnderpriced)
+	}
+	if err := pool.AddRemote(dynamic(0, 20, 200)); err != nil {
+		t.Errorf("failed to replace dynamic fee transaction: %v", err)
+	}
+	if pending, _ := pool.Stats(); pending != 1 {
+		t.Errorf("pending transactions mismatch: have %d, want %d", pending, 1)
+	}
+	// Dynamic fee transactions are rejected before the fork
+	config.LondonBlock = big.NewInt(2)
+	legacyPool := NewTxPool(testTxPoolConfig, &config, blockchain)
+	defer legacyPool.Stop()
+
+	if err := legacyPool.AddRemote(dynamic(0, 10, 100)); err != ErrTxTypeNotSupported {
+		t.Errorf("pre-fork error mismatch: have %v, want %v", err, ErrTxTypeNotSupported)
+	}
+}
+
+// Tests that private transactions are tracked until their last block, after
+// which they are either dropped or announced for the public broadcast.
+func TestPrivateTransactions(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000))
+
+	events := make(chan TxPreEvent, 4)
+	sub := pool.txFeed.Subscribe(events)
+	defer sub.Unsubscribe()
+
+	signer := types.NewEIP155Signer(params.TestChainConfig.ChainId)
+	private := func(nonce uint64) *types.Transaction {
+		tx, _ := types.SignTx(types.NewTransaction(nonce, common.Address{}, big.NewInt(100), big.NewInt(100000), big.NewInt(1), nil), signer, key)
+		return tx
+	}
+	if err := pool.AddPrivate(private(0), 0, false); err != ErrPrivateTxExpired {
+		t.Errorf("expired error mismatch: have %v, want %v", err, ErrPrivateTxExpired)
+	}
+	published, dropped := private(0), private(1)
+	if err := pool.AddPrivate(published, 3, true); err != nil {
+		t.Fatalf("failed to add private transaction: %v", err)
+	}
+	if err := pool.AddPrivate(dropped, 2, false); err != nil {
+		t.Fatalf("failed to add private transaction: %v", err)
+	}
+	if number, ok := pool.Private(dropped.Hash()); !ok || number != 2 {
+		t.Errorf("private transaction mismatch: have %d/%v, want %d/%v", number, ok, 2, true)
+	}
+	if private := pool.PrivateStats(); private != 2 {
+		t.Errorf("private transactions mismatch: have %d, want %d", private, 2)
+	}
+	if pending, _ := pool.Stats(); pending != 2 {
+		t.Errorf("pending transactions mismatch: have %d, want %d", pending, 2)
+	}
+	if err := validateEvents(events, 2); err != nil {
+		t.Fatalf("original event firing failed: %v", err)
+	}
+	// Expire the first transaction, which gets dropped
+	pool.mu.Lock()
+	pool.expirePrivates(2)
+	pool.mu.Unlock()
+
+	if pool.Get(dropped.Hash()) != nil {
+		t.Errorf("expired private transaction not dropped")
+	}
+	if _, ok := pool.Private(published.Hash()); !ok {
+		t.Errorf("unexpired private transaction made public")
+	}
+	if err := validateEvents(events, 0); err != nil {
+		t.Fatalf("drop event firing failed: %v", err)
+	}
+	// Expire the second transaction, which gets published
+	pool.mu.Lock()
+	pool.expirePrivates(3)
+	pool.mu.Unlock()
+
+	if _, ok := pool.Private(published.Hash()); ok {
+		t.Errorf("expired private transaction not made public")
+	}
+	if pool.Get(published.Hash()) == nil {
+		t.Errorf("expired private transaction dropped instead of published")
+	}
+	if private := pool.PrivateStats(); private != 0 {
+		t.Errorf("private transactions mismatch: have %d, want %d", private, 0)
+	}
+	if err := validateEvents(events, 1); err != nil {
+		t.Fatalf("publish event firing failed: %v", err)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 func TestTransactionQueue(t *testing.T) {
 	t.Parallel()
 
@@ -55,7 +55,7 @@ func TestBlockEncoding(t *testing.T) {
 
 	tx1, _ = tx1.WithSignature(HomesteadSigner{}, common.Hex2Bytes("9bea4c4daac7c7c52e093e6a4c35dbbcf8856f1af7b059ba20253e70848d094f8a8fae537ce25ed8cb5af9adac3f141af69bd515bd2ba031522df09b97dd72b100"))
 	fmt.Println(block.Transactions()[0].Hash())
-	fmt.Println(tx1.data)
+	fmt.Println(tx1.inner)
 	fmt.Println(tx1.Hash())
 	check("len(Transactions)", len(block.Transactions()), 1)
 	check("Transactions[0].Hash", block.Transactions()[0].Hash(), tx1.Hash())
@@ -68,3 +68,46 @@ func TestBlockEncoding(t *testing.T) {
 		t.Errorf("encoded block mismatch:\ngot:  %x\nwant: %x", ourBlockEnc, blockEnc)
 	}
 }
+
+// Tests that the base fee is only encoded into headers that carry one, keeping
+// the hashes of legacy headers unchanged.
+func TestHeaderBaseFeeEncoding(t *testing.T) {
+	legacy := &Header{
+		Difficulty: big.NewInt(131072),
+		Number:     big.NewInt(1),
+		GasLimit:   big.NewInt(3141592),
+		GasUsed:    big.NewInt(21000),
+		Time:       big.NewInt(1426516743),
+		Extra:      []byte{},
+	}
+	enc, err := rlp.EncodeToBytes(legacy)
+	if err != nil {
+		t.Fatal("encode error: ", err)
+	}
+	var legacyDec Header
+	if err := rlp.DecodeBytes(enc, &legacyDec); err != nil {
+		t.Fatal("decode error: ", err)
+	}
+	if legacyDec.BaseFee != nil {
+		t.Fatalf("legacy header has base fee %v", legacyDec.BaseFee)
+	}
+	header := CopyHeader(legacy)
+	header.BaseFee = big.NewInt(18000000000)
+
+	if enc, err = rlp.EncodeToBytes(header); err != nil {
+		t.Fatal("encode error: ", err)
+	}
+	var dec Header
+	if err := rlp.DecodeBytes(enc, &dec); err != nil {
+		t.Fatal("decode error: ", err)
+	}
+	if dec.BaseFee == nil || dec.BaseFee.Cmp(header.BaseFee) != 0 {
+		t.Errorf("base fee mismatch: have %v, want %v", dec.BaseFee, header.BaseFee)
+	}
+	if dec.Hash() != header.Hash() {
+		t.Errorf("hash mismatch: have %x, want %x", dec.Hash(), header.Hash())
+	}
+	if header.Hash() == legacy.Hash() {
+		t.Errorf("base fee not covered by the header hash")
+	}
+}
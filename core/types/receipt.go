@@ -18,6 +18,7 @@ package types
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"math/big"
@@ -34,6 +35,9 @@ var (
 	receiptStatusSuccessfulRLP = []byte{0x01}
 )
 
+// errEmptyTypedReceipt is returned if a typed receipt envelope has no content.
+var errEmptyTypedReceipt = errors.New("empty typed receipt bytes")
+
 const (
 	// ReceiptStatusFailed is the status code of a transaction if execution failed.
 	ReceiptStatusFailed = uint(0)
@@ -45,6 +49,7 @@ const (
 // Receipt represents the results of a transaction.
 type Receipt struct {
 	// Consensus fields
+	Type              uint8    `json:"type,omitempty"`
 	PostState         []byte   `json:"root"`
 	Status            uint     `json:"status"`
 	CumulativeGasUsed *big.Int `json:"cumulativeGasUsed" gencodec:"required"`
@@ -58,6 +63,7 @@ type Receipt struct {
 }
 
 type receiptMarshaling struct {
+	Type              hexutil.Uint64
 	PostState         hexutil.Bytes
 	Status            hexutil.Uint
 	CumulativeGasUsed *hexutil.Big
@@ -95,24 +101,81 @@ func NewReceipt(root []byte, failed bool, cumulativeGasUsed *big.Int) *Receipt {
 
 // EncodeRLP implements rlp.Encoder, and flattens the consensus fields of a receipt
 // into an RLP stream. If no post state is present, byzantium fork is assumed.
+// Typed receipts are wrapped into an RLP string, like typed transactions.
 func (r *Receipt) EncodeRLP(w io.Writer) error {
-	return rlp.Encode(w, &receiptRLP{r.statusEncoding(), r.CumulativeGasUsed, r.Bloom, r.Logs})
+	data := &receiptRLP{r.statusEncoding(), r.CumulativeGasUsed, r.Bloom, r.Logs}
+	if r.Type == LegacyTxType {
+		return rlp.Encode(w, data)
+	}
+	buf, err := encodeTypedReceipt(r.Type, data)
+	if err != nil {
+		return err
+	}
+	return rlp.Encode(w, buf)
 }
 
 // DecodeRLP implements rlp.Decoder, and loads the consensus fields of a receipt
 // from an RLP stream.
 func (r *Receipt) DecodeRLP(s *rlp.Stream) error {
 	var dec receiptRLP
-	if err := s.Decode(&dec); err != nil {
+	typ, err := decodeTypedReceipt(s, &dec)
+	if err != nil {
 		return err
 	}
 	if err := r.setStatus(dec.PostStateOrStatus); err != nil {
 		return err
 	}
-	r.CumulativeGasUsed, r.Bloom, r.Logs = dec.CumulativeGasUsed, dec.Bloom, dec.Logs
+	r.Type, r.CumulativeGasUsed, r.Bloom, r.Logs = typ, dec.CumulativeGasUsed, dec.Bloom, dec.Logs
 	return nil
 }
 
+// MarshalBinary returns the canonical encoding of the receipt, which is the type
+// byte followed by the RLP encoding of the consensus fields for typed receipts.
+func (r *Receipt) MarshalBinary() ([]byte, error) {
+	data := &receiptRLP{r.statusEncoding(), r.CumulativeGasUsed, r.Bloom, r.Logs}
+	if r.Type == LegacyTxType {
+		return rlp.EncodeToBytes(data)
+	}
+	return encodeTypedReceipt(r.Type, data)
+}
+
+// encodeTypedReceipt prefixes the RLP encoding of data with the receipt type.
+func encodeTypedReceipt(typ uint8, data interface{}) ([]byte, error) {
+	payload, err := rlp.EncodeToBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{typ}, payload...), nil
+}
+
+// decodeTypedReceipt decodes a legacy RLP list or an RLP string wrapped typed
+// receipt envelope into dec, returning the receipt type.
+func decodeTypedReceipt(s *rlp.Stream, dec interface{}) (uint8, error) {
+	kind, _, err := s.Kind()
+	switch {
+	case err != nil:
+		return 0, err
+	case kind == rlp.List:
+		return LegacyTxType, s.Decode(dec)
+	case kind == rlp.String:
+		b, err := s.Bytes()
+		if err != nil {
+			return 0, err
+		}
+		if len(b) == 0 {
+			return 0, errEmptyTypedReceipt
+		}
+		switch b[0] {
+		case AccessListTxType, DynamicFeeTxType, SponsoredTxType:
+			return b[0], rlp.DecodeBytes(b[1:], dec)
+		default:
+			return 0, ErrTxTypeNotSupported
+		}
+	default:
+		return 0, rlp.ErrExpectedList
+	}
+}
+
 func (r *Receipt) setStatus(postStateOrStatus []byte) error {
 	switch {
 	case bytes.Equal(postStateOrStatus, receiptStatusSuccessfulRLP):
@@ -147,6 +210,7 @@ func (r *Receipt) String() string {
 
 // ReceiptForStorage is a wrapper around a Receipt that flattens and parses the
 // entire content of a receipt, as opposed to only the consensus fields originally.
+// Typed receipts are stored in the same envelope as their consensus encoding.
 type ReceiptForStorage Receipt
 
 // EncodeRLP implements rlp.Encoder, and flattens all content fields of a receipt
@@ -164,16 +228,25 @@ func (r *ReceiptForStorage) EncodeRLP(w io.Writer) error {
 	for i, log := range r.Logs {
 		enc.Logs[i] = (*LogForStorage)(log)
 	}
-	return rlp.Encode(w, enc)
+	if r.Type == LegacyTxType {
+		return rlp.Encode(w, enc)
+	}
+	buf, err := encodeTypedReceipt(r.Type, enc)
+	if err != nil {
+		return err
+	}
+	return rlp.Encode(w, buf)
 }
 
 // DecodeRLP implements rlp.Decoder, and loads both consensus and implementation
 // fields of a receipt from an RLP stream.
 func (r *ReceiptForStorage) DecodeRLP(s *rlp.Stream) error {
 	var dec receiptStorageRLP
-	if err := s.Decode(&dec); err != nil {
+	typ, err := decodeTypedReceipt(s, &dec)
+	if err != nil {
 		return err
 	}
+	r.Type = typ
 	if err := (*Receipt)(r).setStatus(dec.PostStateOrStatus); err != nil {
 		return err
 	}
@@ -194,9 +267,10 @@ type Receipts []*Receipt
 // Len returns the number of receipts in this list.
 func (r Receipts) Len() int { return len(r) }
 
-// GetRlp returns the RLP encoding of one receipt from the list.
+// GetRlp returns the canonical encoding of one receipt from the list, which is
+// the RLP encoding for legacy receipts.
 func (r Receipts) GetRlp(i int) []byte {
-	bytes, err := rlp.EncodeToBytes(r[i])
+	bytes, err := r[i].MarshalBinary()
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,73 @@
+// Copyright 2018 The mesh-chain Authors
+// This file is part of the mesh-chain library.
+//
+// The mesh-chain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The mesh-chain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the mesh-chain library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/rlp"
+)
+
+// Tests that typed receipts are enveloped both in their consensus and storage
+// encodings and decode back to the same receipt.
+func TestTypedReceiptEncoding(t *testing.T) {
+	receipt := NewReceipt(nil, false, big.NewInt(21000))
+	receipt.Type = AccessListTxType
+	receipt.TxHash = common.Hash{0x11}
+	receipt.GasUsed = big.NewInt(21000)
+	receipt.Logs = []*Log{{Address: testAddr, Topics: []common.Hash{{0x22}}, Data: []byte{0x33}}}
+	receipt.Bloom = CreateBloom(Receipts{receipt})
+
+	// Consensus encoding: type byte followed by the legacy payload.
+	bin, err := receipt.MarshalBinary()
+	if err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+	if bin[0] != AccessListTxType {
+		t.Fatalf("type prefix mismatch: have %d, want %d", bin[0], AccessListTxType)
+	}
+	enc, err := rlp.EncodeToBytes(receipt)
+	if err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+	var dec Receipt
+	if err := rlp.DecodeBytes(enc, &dec); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if dec.Type != AccessListTxType || dec.Status != receipt.Status || dec.CumulativeGasUsed.Cmp(receipt.CumulativeGasUsed) != 0 {
+		t.Errorf("consensus receipt mismatch: have %v, want %v", &dec, receipt)
+	}
+	// Storage encoding keeps the type and the non-consensus fields.
+	enc, err = rlp.EncodeToBytes((*ReceiptForStorage)(receipt))
+	if err != nil {
+		t.Fatalf("storage encode error: %v", err)
+	}
+	var stored ReceiptForStorage
+	if err := rlp.DecodeBytes(enc, &stored); err != nil {
+		t.Fatalf("storage decode error: %v", err)
+	}
+	if stored.Type != AccessListTxType || stored.TxHash != receipt.TxHash || stored.GasUsed.Cmp(receipt.GasUsed) != 0 {
+		t.Errorf("stored receipt mismatch: have %v, want %v", (*Receipt)(&stored), receipt)
+	}
+	// The receipt root must hash the enveloped encoding.
+	if have := (Receipts{receipt}).GetRlp(0); !bytes.Equal(have, bin) {
+		t.Errorf("derive sha encoding mismatch: have %x, want %x", have, bin)
+	}
+}
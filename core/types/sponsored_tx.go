@@ -0,0 +1,113 @@
+// Copyright 2018 The mesh-chain Authors
+// This file is part of the mesh-chain library.
+//
+// The mesh-chain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The mesh-chain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the mesh-chain library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"math/big"
+
+	"github.com/MeshBoxTech/mesh-chain/common"
+)
+
+// SponsoredTx is the data of sponsored (fee delegated) transactions. The sender
+// signs the transaction like an access list transaction, the sponsor then
+// countersigns it together with the sender address and pays for its gas.
+type SponsoredTx struct {
+	ChainID    *big.Int        // destination chain ID
+	Nonce      uint64          // nonce of sender account
+	GasPrice   *big.Int        // wei per gas
+	Gas        *big.Int        // gas limit
+	To         *common.Address `rlp:"nil"` // nil means contract creation
+	Value      *big.Int        // wei amount
+	Data       []byte          // contract invocation input data
+	AccessList AccessList      // EIP-2930 access list
+	V, R, S    *big.Int        // sender signature values
+
+	SponsorV, SponsorR, SponsorS *big.Int // sponsor signature values
+}
+
+// copy creates a deep copy of the transaction data and initializes all fields.
+func (tx *SponsoredTx) copy() TxData {
+	cpy := &SponsoredTx{
+		Nonce: tx.Nonce,
+		To:    copyAddressPtr(tx.To),
+		Data:  common.CopyBytes(tx.Data),
+		// These are copied below.
+		AccessList: make(AccessList, len(tx.AccessList)),
+		ChainID:    new(big.Int),
+		GasPrice:   new(big.Int),
+		Gas:        new(big.Int),
+		Value:      new(big.Int),
+		V:          new(big.Int),
+		R:          new(big.Int),
+		S:          new(big.Int),
+		SponsorV:   new(big.Int),
+		SponsorR:   new(big.Int),
+		SponsorS:   new(big.Int),
+	}
+	copy(cpy.AccessList, tx.AccessList)
+	if tx.ChainID != nil {
+		cpy.ChainID.Set(tx.ChainID)
+	}
+	if tx.GasPrice != nil {
+		cpy.GasPrice.Set(tx.GasPrice)
+	}
+	if tx.Gas != nil {
+		cpy.Gas.Set(tx.Gas)
+	}
+	if tx.Value != nil {
+		cpy.Value.Set(tx.Value)
+	}
+	if tx.V != nil {
+		cpy.V.Set(tx.V)
+	}
+	if tx.R != nil {
+		cpy.R.Set(tx.R)
+	}
+	if tx.S != nil {
+		cpy.S.Set(tx.S)
+	}
+	if tx.SponsorV != nil {
+		cpy.SponsorV.Set(tx.SponsorV)
+	}
+	if tx.SponsorR != nil {
+		cpy.SponsorR.Set(tx.SponsorR)
+	}
+	if tx.SponsorS != nil {
+		cpy.SponsorS.Set(tx.SponsorS)
+	}
+	return cpy
+}
+
+// accessors for innerTx.
+func (tx *SponsoredTx) txType() byte           { return SponsoredTxType }
+func (tx *SponsoredTx) chainID() *big.Int      { return tx.ChainID }
+func (tx *SponsoredTx) accessList() AccessList { return tx.AccessList }
+func (tx *SponsoredTx) data() []byte           { return tx.Data }
+func (tx *SponsoredTx) gas() *big.Int          { return tx.Gas }
+func (tx *SponsoredTx) gasPrice() *big.Int     { return tx.GasPrice }
+func (tx *SponsoredTx) gasTipCap() *big.Int    { return tx.GasPrice }
+func (tx *SponsoredTx) gasFeeCap() *big.Int    { return tx.GasPrice }
+func (tx *SponsoredTx) value() *big.Int        { return tx.Value }
+func (tx *SponsoredTx) nonce() uint64          { return tx.Nonce }
+func (tx *SponsoredTx) to() *common.Address    { return tx.To }
+func (tx *SponsoredTx) rawSignatureValues() (v, r, s *big.Int) {
+	return tx.V, tx.R, tx.S
+}
+
+func (tx *SponsoredTx) setSignatureValues(chainID, v, r, s *big.Int) {
+	tx.ChainID, tx.V, tx.R, tx.S = chainID, v, r, s
+}
@@ -0,0 +1,258 @@
+// Copyright 2018 The mesh-chain Authors
+// This file is part of the mesh-chain library.
+//
+// The mesh-chain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The mesh-chain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the mesh-chain library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+
+	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/common/hexutil"
+)
+
+// txJSON is the JSON representation of transactions.
+type txJSON struct {
+	Type hexutil.Uint64 `json:"type"`
+
+	// Common transaction fields:
+	Nonce    *hexutil.Uint64 `json:"nonce"`
+	GasPrice *hexutil.Big    `json:"gasPrice,omitempty"`
+	Gas      *hexutil.Big    `json:"gas"`
+	Value    *hexutil.Big    `json:"value"`
+	Data     *hexutil.Bytes  `json:"input"`
+	V        *hexutil.Big    `json:"v"`
+	R        *hexutil.Big    `json:"r"`
+	S        *hexutil.Big    `json:"s"`
+	To       *common.Address `json:"to"`
+
+	// Access list transaction fields:
+	ChainID    *hexutil.Big `json:"chainId,omitempty"`
+	AccessList *AccessList  `json:"accessList,omitempty"`
+
+	// Dynamic fee transaction fields:
+	GasTipCap *hexutil.Big `json:"maxPriorityFeePerGas,omitempty"`
+	GasFeeCap *hexutil.Big `json:"maxFeePerGas,omitempty"`
+
+	// Sponsored transaction fields:
+	SponsorV *hexutil.Big `json:"sponsorV,omitempty"`
+	SponsorR *hexutil.Big `json:"sponsorR,omitempty"`
+	SponsorS *hexutil.Big `json:"sponsorS,omitempty"`
+
+	// Only used for encoding:
+	Hash common.Hash `json:"hash"`
+}
+
+// MarshalJSON encodes the web3 RPC transaction format.
+func (tx *Transaction) MarshalJSON() ([]byte, error) {
+	var enc txJSON
+	// These are set for all tx types.
+	enc.Hash = tx.Hash()
+	enc.Type = hexutil.Uint64(tx.Type())
+
+	// Other fields are set conditionally depending on tx type.
+	switch tx := tx.inner.(type) {
+	case *LegacyTx:
+		enc.Nonce = (*hexutil.Uint64)(&tx.Nonce)
+		enc.Gas = (*hexutil.Big)(tx.Gas)
+		enc.GasPrice = (*hexutil.Big)(tx.GasPrice)
+		enc.Value = (*hexutil.Big)(tx.Value)
+		enc.Data = (*hexutil.Bytes)(&tx.Data)
+		enc.To = tx.To
+		enc.V = (*hexutil.Big)(tx.V)
+		enc.R = (*hexutil.Big)(tx.R)
+		enc.S = (*hexutil.Big)(tx.S)
+	case *AccessListTx:
+		enc.ChainID = (*hexutil.Big)(tx.ChainID)
+		enc.AccessList = &tx.AccessList
+		enc.Nonce = (*hexutil.Uint64)(&tx.Nonce)
+		enc.Gas = (*hexutil.Big)(tx.Gas)
+		enc.GasPrice = (*hexutil.Big)(tx.GasPrice)
+		enc.Value = (*hexutil.Big)(tx.Value)
+		enc.Data = (*hexutil.Bytes)(&tx.Data)
+		enc.To = tx.To
+		enc.V = (*hexutil.Big)(tx.V)
+		enc.R = (*hexutil.Big)(tx.R)
+		enc.S = (*hexutil.Big)(tx.S)
+	case *DynamicFeeTx:
+		enc.ChainID = (*hexutil.Big)(tx.ChainID)
+		enc.AccessList = &tx.AccessList
+		enc.Nonce = (*hexutil.Uint64)(&tx.Nonce)
+		enc.Gas = (*hexutil.Big)(tx.Gas)
+		enc.GasTipCap = (*hexutil.Big)(tx.GasTipCap)
+		enc.GasFeeCap = (*hexutil.Big)(tx.GasFeeCap)
+		enc.Value = (*hexutil.Big)(tx.Value)
+		enc.Data = (*hexutil.Bytes)(&tx.Data)
+		enc.To = tx.To
+		enc.V = (*hexutil.Big)(tx.V)
+		enc.R = (*hexutil.Big)(tx.R)
+		enc.S = (*hexutil.Big)(tx.S)
+	case *SponsoredTx:
+		enc.ChainID = (*hexutil.Big)(tx.ChainID)
+		enc.AccessList = &tx.AccessList
+		enc.Nonce = (*hexutil.Uint64)(&tx.Nonce)
+		enc.Gas = (*hexutil.Big)(tx.Gas)
+		enc.GasPrice = (*hexutil.Big)(tx.GasPrice)
+		enc.Value = (*hexutil.Big)(tx.Value)
+		enc.Data = (*hexutil.Bytes)(&tx.Data)
+		enc.To = tx.To
+		enc.V = (*hexutil.Big)(tx.V)
+		enc.R = (*hexutil.Big)(tx.R)
+		enc.S = (*hexutil.Big)(tx.S)
+		enc.SponsorV = (*hexutil.Big)(tx.SponsorV)
+		enc.SponsorR = (*hexutil.Big)(tx.SponsorR)
+		enc.SponsorS = (*hexutil.Big)(tx.SponsorS)
+	}
+	return json.Marshal(&enc)
+}
+
+// UnmarshalJSON decodes the web3 RPC transaction format.
+func (tx *Transaction) UnmarshalJSON(input []byte) error {
+	var dec txJSON
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	// Decode / verify fields according to transaction type.
+	var inner TxData
+	switch dec.Type {
+	case LegacyTxType:
+		var itx LegacyTx
+		inner = &itx
+		if err := dec.decodeCommon(&itx.Nonce, &itx.GasPrice, &itx.Gas, &itx.Value, &itx.Data, &itx.V, &itx.R, &itx.S); err != nil {
+			return err
+		}
+		itx.To = dec.To
+		if err := sanityCheckSignature(itx.V, itx.R, itx.S, true); err != nil {
+			return err
+		}
+
+	case AccessListTxType:
+		var itx AccessListTx
+		inner = &itx
+		if dec.ChainID == nil {
+			return errors.New("missing required field 'chainId' in transaction")
+		}
+		itx.ChainID = (*big.Int)(dec.ChainID)
+		if dec.AccessList != nil {
+			itx.AccessList = *dec.AccessList
+		}
+		if err := dec.decodeCommon(&itx.Nonce, &itx.GasPrice, &itx.Gas, &itx.Value, &itx.Data, &itx.V, &itx.R, &itx.S); err != nil {
+			return err
+		}
+		itx.To = dec.To
+		if err := sanityCheckSignature(itx.V, itx.R, itx.S, false); err != nil {
+			return err
+		}
+
+	case DynamicFeeTxType:
+		var itx DynamicFeeTx
+		inner = &itx
+		if dec.ChainID == nil {
+			return errors.New("missing required field 'chainId' in transaction")
+		}
+		itx.ChainID = (*big.Int)(dec.ChainID)
+		if dec.AccessList != nil {
+			itx.AccessList = *dec.AccessList
+		}
+		if dec.GasTipCap == nil {
+			return errors.New("missing required field 'maxPriorityFeePerGas' for txdata")
+		}
+		itx.GasTipCap = (*big.Int)(dec.GasTipCap)
+		if dec.GasFeeCap == nil {
+			return errors.New("missing required field 'maxFeePerGas' for txdata")
+		}
+		itx.GasFeeCap = (*big.Int)(dec.GasFeeCap)
+		// The fee cap stands in for the gas price of the common fields.
+		dec.GasPrice = dec.GasFeeCap
+		var feeCap *big.Int
+		if err := dec.decodeCommon(&itx.Nonce, &feeCap, &itx.Gas, &itx.Value, &itx.Data, &itx.V, &itx.R, &itx.S); err != nil {
+			return err
+		}
+		itx.To = dec.To
+		if err := sanityCheckSignature(itx.V, itx.R, itx.S, false); err != nil {
+			return err
+		}
+
+	case SponsoredTxType:
+		var itx SponsoredTx
+		inner = &itx
+		if dec.ChainID == nil {
+			return errors.New("missing required field 'chainId' in transaction")
+		}
+		itx.ChainID = (*big.Int)(dec.ChainID)
+		if dec.AccessList != nil {
+			itx.AccessList = *dec.AccessList
+		}
+		if err := dec.decodeCommon(&itx.Nonce, &itx.GasPrice, &itx.Gas, &itx.Value, &itx.Data, &itx.V, &itx.R, &itx.S); err != nil {
+			return err
+		}
+		itx.To = dec.To
+		if err := sanityCheckSignature(itx.V, itx.R, itx.S, false); err != nil {
+			return err
+		}
+		if dec.SponsorV == nil || dec.SponsorR == nil || dec.SponsorS == nil {
+			return errors.New("missing required sponsor signature in transaction")
+		}
+		itx.SponsorV, itx.SponsorR, itx.SponsorS = (*big.Int)(dec.SponsorV), (*big.Int)(dec.SponsorR), (*big.Int)(dec.SponsorS)
+		if err := sanityCheckSignature(itx.SponsorV, itx.SponsorR, itx.SponsorS, false); err != nil {
+			return err
+		}
+
+	default:
+		return ErrTxTypeNotSupported
+	}
+	// Now set the inner transaction.
+	tx.setDecoded(inner, 0)
+	return nil
+}
+
+// decodeCommon checks and assigns the fields shared by all transaction types.
+func (dec *txJSON) decodeCommon(nonce *uint64, gasPrice, gas, value **big.Int, data *[]byte, v, r, s **big.Int) error {
+	if dec.Nonce == nil {
+		return errors.New("missing required field 'nonce' in transaction")
+	}
+	*nonce = uint64(*dec.Nonce)
+	if dec.GasPrice == nil {
+		return errors.New("missing required field 'gasPrice' in transaction")
+	}
+	*gasPrice = (*big.Int)(dec.GasPrice)
+	if dec.Gas == nil {
+		return errors.New("missing required field 'gas' in transaction")
+	}
+	*gas = (*big.Int)(dec.Gas)
+	if dec.Value == nil {
+		return errors.New("missing required field 'value' in transaction")
+	}
+	*value = (*big.Int)(dec.Value)
+	if dec.Data == nil {
+		return errors.New("missing required field 'input' in transaction")
+	}
+	*data = *dec.Data
+	if dec.V == nil {
+		return errors.New("missing required field 'v' in transaction")
+	}
+	*v = (*big.Int)(dec.V)
+	if dec.R == nil {
+		return errors.New("missing required field 'r' in transaction")
+	}
+	*r = (*big.Int)(dec.R)
+	if dec.S == nil {
+		return errors.New("missing required field 's' in transaction")
+	}
+	*s = (*big.Int)(dec.S)
+	return nil
+}
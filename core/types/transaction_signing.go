@@ -28,7 +28,8 @@ import (
 )
 
 var (
-	ErrInvalidChainId = errors.New("invalid chain id for signer")
+	ErrInvalidChainId       = errors.New("invalid chain id for signer")
+	ErrUnexpectedProtection = errors.New("transaction type does not support EIP-155 protected signatures")
 )
 
 // sigCache is used to cache the derived sender and contains
@@ -42,6 +43,12 @@ type sigCache struct {
 func MakeSigner(config *params.ChainConfig, blockNumber *big.Int) Signer {
 	var signer Signer
 	switch {
+	case config.IsLondon(blockNumber):
+		signer = NewLondonSigner(config.ChainId)
+	case config.IsSponsor(blockNumber):
+		signer = NewSponsorSigner(config.ChainId)
+	case config.IsBerlin(blockNumber):
+		signer = NewEIP2930Signer(config.ChainId)
 	case config.IsEIP155(blockNumber):
 		signer = NewEIP155Signer(config.ChainId)
 	case config.IsHomestead(blockNumber):
@@ -52,6 +59,44 @@ func MakeSigner(config *params.ChainConfig, blockNumber *big.Int) Signer {
 	return signer
 }
 
+// LatestSigner returns the 'most permissive' Signer available for the given chain
+// configuration. Specifically, this enables support of typed transactions once
+// a Berlin fork block is scheduled, regardless of whether it has activated yet.
+//
+// Use this in transaction-handling code where the current block number is unknown.
+// If you have the current block number available, use MakeSigner instead.
+func LatestSigner(config *params.ChainConfig) Signer {
+	if config.ChainId != nil {
+		if config.LondonBlock != nil {
+			return NewLondonSigner(config.ChainId)
+		}
+		if config.Sponsor != nil {
+			return NewSponsorSigner(config.ChainId)
+		}
+		if config.BerlinBlock != nil {
+			return NewEIP2930Signer(config.ChainId)
+		}
+		if config.EIP155Block != nil {
+			return NewEIP155Signer(config.ChainId)
+		}
+	}
+	return HomesteadSigner{}
+}
+
+// LatestSignerForChainID returns the 'most permissive' Signer available. Specifically,
+// this enables support for EIP-155 replay protection and all implemented EIP-2718
+// transaction types if chainID is non-nil.
+//
+// Use this in transaction-handling code where the current block number and fork
+// configuration are unknown. If you have a ChainConfig, use LatestSigner instead.
+// If you have a ChainConfig and know the current block number, use MakeSigner instead.
+func LatestSignerForChainID(chainID *big.Int) Signer {
+	if chainID == nil {
+		return HomesteadSigner{}
+	}
+	return NewLondonSigner(chainID)
+}
+
 // SignTx signs the transaction using the given signer and private key
 func SignTx(tx *Transaction, s Signer, prv *ecdsa.PrivateKey) (*Transaction, error) {
 	h := s.Hash(tx)
@@ -62,6 +107,26 @@ func SignTx(tx *Transaction, s Signer, prv *ecdsa.PrivateKey) (*Transaction, err
 	return tx.WithSignature(s, sig)
 }
 
+// SignNewTx creates a transaction and signs it.
+func SignNewTx(prv *ecdsa.PrivateKey, s Signer, txdata TxData) (*Transaction, error) {
+	return SignTx(NewTx(txdata), s, prv)
+}
+
+// SignSponsor countersigns a sponsored transaction as its sponsor. The sender
+// signature has to be present already, as the sponsor signs for that sender.
+func SignSponsor(tx *Transaction, s Signer, prv *ecdsa.PrivateKey) (*Transaction, error) {
+	from, err := Sender(s, tx)
+	if err != nil {
+		return nil, err
+	}
+	h := SponsorHash(tx, from)
+	sig, err := crypto.Sign(h[:], prv)
+	if err != nil {
+		return nil, err
+	}
+	return tx.WithSponsorSignature(sig)
+}
+
 // Sender returns the address derived from the signature (V, R, S) using secp256k1
 // elliptic curve and an error if it failed deriving or upon an incorrect
 // signature.
@@ -88,6 +153,59 @@ func Sender(signer Signer, tx *Transaction) (common.Address, error) {
 	return addr, nil
 }
 
+// Sponsor returns the address paying for the gas of a sponsored transaction,
+// derived from the sponsor signature over the transaction and its sender.
+//
+// Sponsor may cache the address like Sender does.
+func Sponsor(signer Signer, tx *Transaction) (common.Address, error) {
+	if tx.Type() != SponsoredTxType {
+		return common.Address{}, ErrTxTypeNotSupported
+	}
+	if sc := tx.sponsor.Load(); sc != nil {
+		sigCache := sc.(sigCache)
+		if sigCache.signer.Equal(signer) {
+			return sigCache.from, nil
+		}
+	}
+	from, err := Sender(signer, tx)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if tx.ChainId().Cmp(signer.ChainID()) != 0 {
+		return common.Address{}, ErrInvalidChainId
+	}
+	V, R, S := tx.RawSponsorSignatureValues()
+	if V == nil || R == nil || S == nil {
+		return common.Address{}, ErrInvalidSig
+	}
+	V = new(big.Int).Add(V, big.NewInt(27))
+	addr, err := recoverPlain(SponsorHash(tx, from), R, S, V, true)
+	if err != nil {
+		return common.Address{}, err
+	}
+	tx.sponsor.Store(sigCache{signer: signer, from: addr})
+	return addr, nil
+}
+
+// SponsorHash returns the hash to be signed by the sponsor of a transaction
+// sent by from. It commits to the sender so that a sponsor signature can not
+// be reused for another account.
+func SponsorHash(tx *Transaction, from common.Address) common.Hash {
+	return prefixedRlpHash(
+		tx.Type(),
+		[]interface{}{
+			tx.ChainId(),
+			tx.Nonce(),
+			tx.inner.gasPrice(),
+			tx.inner.gas(),
+			tx.inner.to(),
+			tx.inner.value(),
+			tx.inner.data(),
+			tx.AccessList(),
+			from,
+		})
+}
+
 // Signer encapsulates transaction signature handling. Note that this interface is not a
 // stable API and may change at any time to accommodate new protocol rules.
 type Signer interface {
@@ -96,12 +214,201 @@ type Signer interface {
 	// SignatureValues returns the raw R, S, V values corresponding to the
 	// given signature.
 	SignatureValues(tx *Transaction, sig []byte) (r, s, v *big.Int, err error)
+	// ChainID returns the chain id the signer signs for, nil if unprotected.
+	ChainID() *big.Int
 	// Hash returns the hash to be signed.
 	Hash(tx *Transaction) common.Hash
 	// Equal returns true if the given signer is the same as the receiver.
 	Equal(Signer) bool
 }
 
+// eip2930Signer implements Signer using the EIP-2930 rules, accepting access
+// list transactions on top of the EIP-155 legacy ones.
+type eip2930Signer struct{ EIP155Signer }
+
+// NewEIP2930Signer returns a signer that accepts EIP-2930 access list transactions,
+// EIP-155 replay protected transactions, and legacy Homestead transactions.
+func NewEIP2930Signer(chainId *big.Int) Signer {
+	return eip2930Signer{NewEIP155Signer(chainId)}
+}
+
+func (s eip2930Signer) ChainID() *big.Int {
+	return s.chainId
+}
+
+func (s eip2930Signer) Equal(s2 Signer) bool {
+	x, ok := s2.(eip2930Signer)
+	return ok && x.chainId.Cmp(s.chainId) == 0
+}
+
+func (s eip2930Signer) Sender(tx *Transaction) (common.Address, error) {
+	if tx.Type() != AccessListTxType {
+		return s.EIP155Signer.Sender(tx)
+	}
+	V, R, S := tx.RawSignatureValues()
+	// AL txs are defined to use 0 and 1 as their recovery id, add
+	// 27 to become equivalent to unprotected Homestead signatures.
+	V = new(big.Int).Add(V, big.NewInt(27))
+	if tx.ChainId().Cmp(s.chainId) != 0 {
+		return common.Address{}, ErrInvalidChainId
+	}
+	return recoverPlain(s.Hash(tx), R, S, V, true)
+}
+
+func (s eip2930Signer) SignatureValues(tx *Transaction, sig []byte) (R, S, V *big.Int, err error) {
+	if tx.Type() != AccessListTxType {
+		return s.EIP155Signer.SignatureValues(tx, sig)
+	}
+	// Check that chain ID of tx matches the signer. We also accept ID zero here,
+	// because it indicates that the chain ID was not specified in the tx.
+	if chainID := tx.ChainId(); chainID != nil && chainID.Sign() != 0 && chainID.Cmp(s.chainId) != 0 {
+		return nil, nil, nil, ErrInvalidChainId
+	}
+	R, S, V = decodeSignature(sig)
+	return R, S, V, nil
+}
+
+// Hash returns the hash to be signed by the sender.
+// It does not uniquely identify the transaction.
+func (s eip2930Signer) Hash(tx *Transaction) common.Hash {
+	if tx.Type() != AccessListTxType {
+		return s.EIP155Signer.Hash(tx)
+	}
+	return prefixedRlpHash(
+		tx.Type(),
+		[]interface{}{
+			s.chainId,
+			tx.Nonce(),
+			tx.inner.gasPrice(),
+			tx.inner.gas(),
+			tx.inner.to(),
+			tx.inner.value(),
+			tx.inner.data(),
+			tx.AccessList(),
+		})
+}
+
+// londonSigner implements Signer using the EIP-1559 rules, accepting dynamic fee
+// transactions on top of all the types accepted by the sponsor signer.
+type londonSigner struct{ sponsorSigner }
+
+// NewLondonSigner returns a signer that accepts EIP-1559 dynamic fee transactions
+// as well as all transactions accepted by the sponsor signer.
+func NewLondonSigner(chainId *big.Int) Signer {
+	return londonSigner{sponsorSigner{eip2930Signer{NewEIP155Signer(chainId)}}}
+}
+
+func (s londonSigner) Equal(s2 Signer) bool {
+	x, ok := s2.(londonSigner)
+	return ok && x.chainId.Cmp(s.chainId) == 0
+}
+
+func (s londonSigner) Sender(tx *Transaction) (common.Address, error) {
+	if tx.Type() != DynamicFeeTxType {
+		return s.sponsorSigner.Sender(tx)
+	}
+	V, R, S := tx.RawSignatureValues()
+	// DynamicFee txs are defined to use 0 and 1 as their recovery
+	// id, add 27 to become equivalent to unprotected Homestead signatures.
+	V = new(big.Int).Add(V, big.NewInt(27))
+	if tx.ChainId().Cmp(s.chainId) != 0 {
+		return common.Address{}, ErrInvalidChainId
+	}
+	return recoverPlain(s.Hash(tx), R, S, V, true)
+}
+
+func (s londonSigner) SignatureValues(tx *Transaction, sig []byte) (R, S, V *big.Int, err error) {
+	if tx.Type() != DynamicFeeTxType {
+		return s.sponsorSigner.SignatureValues(tx, sig)
+	}
+	if chainID := tx.ChainId(); chainID != nil && chainID.Sign() != 0 && chainID.Cmp(s.chainId) != 0 {
+		return nil, nil, nil, ErrInvalidChainId
+	}
+	R, S, V = decodeSignature(sig)
+	return R, S, V, nil
+}
+
+// Hash returns the hash to be signed by the sender.
+// It does not uniquely identify the transaction.
+func (s londonSigner) Hash(tx *Transaction) common.Hash {
+	if tx.Type() != DynamicFeeTxType {
+		return s.sponsorSigner.Hash(tx)
+	}
+	return prefixedRlpHash(
+		tx.Type(),
+		[]interface{}{
+			s.chainId,
+			tx.Nonce(),
+			tx.inner.gasTipCap(),
+			tx.inner.gasFeeCap(),
+			tx.inner.gas(),
+			tx.inner.to(),
+			tx.inner.value(),
+			tx.inner.data(),
+			tx.AccessList(),
+		})
+}
+
+// sponsorSigner implements Signer for sponsored transactions on top of the
+// EIP-2930 rules. It only covers the sender signature, see Sponsor for the
+// sponsor one.
+type sponsorSigner struct{ eip2930Signer }
+
+// NewSponsorSigner returns a signer that accepts sponsored transactions as well
+// as all transactions accepted by the EIP-2930 signer.
+func NewSponsorSigner(chainId *big.Int) Signer {
+	return sponsorSigner{eip2930Signer{NewEIP155Signer(chainId)}}
+}
+
+func (s sponsorSigner) Equal(s2 Signer) bool {
+	x, ok := s2.(sponsorSigner)
+	return ok && x.chainId.Cmp(s.chainId) == 0
+}
+
+func (s sponsorSigner) Sender(tx *Transaction) (common.Address, error) {
+	if tx.Type() != SponsoredTxType {
+		return s.eip2930Signer.Sender(tx)
+	}
+	V, R, S := tx.RawSignatureValues()
+	// Sponsored txs use 0 and 1 as their recovery id like AL txs.
+	V = new(big.Int).Add(V, big.NewInt(27))
+	if tx.ChainId().Cmp(s.chainId) != 0 {
+		return common.Address{}, ErrInvalidChainId
+	}
+	return recoverPlain(s.Hash(tx), R, S, V, true)
+}
+
+func (s sponsorSigner) SignatureValues(tx *Transaction, sig []byte) (R, S, V *big.Int, err error) {
+	if tx.Type() != SponsoredTxType {
+		return s.eip2930Signer.SignatureValues(tx, sig)
+	}
+	if chainID := tx.ChainId(); chainID != nil && chainID.Sign() != 0 && chainID.Cmp(s.chainId) != 0 {
+		return nil, nil, nil, ErrInvalidChainId
+	}
+	R, S, V = decodeSignature(sig)
+	return R, S, V, nil
+}
+
+// Hash returns the hash to be signed by the sender.
+// It does not uniquely identify the transaction.
+func (s sponsorSigner) Hash(tx *Transaction) common.Hash {
+	if tx.Type() != SponsoredTxType {
+		return s.eip2930Signer.Hash(tx)
+	}
+	return prefixedRlpHash(
+		tx.Type(),
+		[]interface{}{
+			s.chainId,
+			tx.Nonce(),
+			tx.inner.gasPrice(),
+			tx.inner.gas(),
+			tx.inner.to(),
+			tx.inner.value(),
+			tx.inner.data(),
+			tx.AccessList(),
+		})
+}
+
 // EIP155Transaction implements Signer using the EIP155 rules.
 type EIP155Signer struct {
 	chainId, chainIdMul *big.Int
@@ -117,6 +424,10 @@ func NewEIP155Signer(chainId *big.Int) EIP155Signer {
 	}
 }
 
+func (s EIP155Signer) ChainID() *big.Int {
+	return s.chainId
+}
+
 func (s EIP155Signer) Equal(s2 Signer) bool {
 	eip155, ok := s2.(EIP155Signer)
 	return ok && eip155.chainId.Cmp(s.chainId) == 0
@@ -125,6 +436,9 @@ func (s EIP155Signer) Equal(s2 Signer) bool {
 var big8 = big.NewInt(8)
 
 func (s EIP155Signer) Sender(tx *Transaction) (common.Address, error) {
+	if tx.Type() != LegacyTxType {
+		return common.Address{}, ErrTxTypeNotSupported
+	}
 	if !tx.Protected() {
 		return HomesteadSigner{}.Sender(tx)
 	}
@@ -132,14 +446,18 @@ func (s EIP155Signer) Sender(tx *Transaction) (common.Address, error) {
 	if tx.ChainId().Cmp(s.chainId) != 0 {
 		return common.Address{}, ErrInvalidChainId
 	}
-	V := new(big.Int).Sub(tx.data.V, s.chainIdMul)
+	V, R, S := tx.RawSignatureValues()
+	V = new(big.Int).Sub(V, s.chainIdMul)
 	V.Sub(V, big8)
-	return recoverPlain(s.Hash(tx), tx.data.R, tx.data.S, V, true)
+	return recoverPlain(s.Hash(tx), R, S, V, true)
 }
 
 // WithSignature returns a new transaction with the given signature. This signature
 // needs to be in the [R || S || V] format where V is 0 or 1.
 func (s EIP155Signer) SignatureValues(tx *Transaction, sig []byte) (R, S, V *big.Int, err error) {
+	if tx.Type() != LegacyTxType {
+		return nil, nil, nil, ErrTxTypeNotSupported
+	}
 	R, S, V, err = HomesteadSigner{}.SignatureValues(tx, sig)
 	if err != nil {
 		return nil, nil, nil, err
@@ -155,12 +473,12 @@ func (s EIP155Signer) SignatureValues(tx *Transaction, sig []byte) (R, S, V *big
 // It does not uniquely identify the transaction.
 func (s EIP155Signer) Hash(tx *Transaction) common.Hash {
 	return rlpHash([]interface{}{
-		tx.data.AccountNonce,
-		tx.data.Price,
-		tx.data.GasLimit,
-		tx.data.Recipient,
-		tx.data.Amount,
-		tx.data.Payload,
+		tx.inner.nonce(),
+		tx.inner.gasPrice(),
+		tx.inner.gas(),
+		tx.inner.to(),
+		tx.inner.value(),
+		tx.inner.data(),
 		s.chainId, uint(0), uint(0),
 	})
 }
@@ -181,11 +499,19 @@ func (hs HomesteadSigner) SignatureValues(tx *Transaction, sig []byte) (r, s, v
 }
 
 func (hs HomesteadSigner) Sender(tx *Transaction) (common.Address, error) {
-	return recoverPlain(hs.Hash(tx), tx.data.R, tx.data.S, tx.data.V, true)
+	if tx.Type() != LegacyTxType {
+		return common.Address{}, ErrTxTypeNotSupported
+	}
+	v, r, s := tx.RawSignatureValues()
+	return recoverPlain(hs.Hash(tx), r, s, v, true)
 }
 
 type FrontierSigner struct{}
 
+func (s FrontierSigner) ChainID() *big.Int {
+	return nil
+}
+
 func (s FrontierSigner) Equal(s2 Signer) bool {
 	_, ok := s2.(FrontierSigner)
 	return ok
@@ -194,30 +520,45 @@ func (s FrontierSigner) Equal(s2 Signer) bool {
 // SignatureValues returns signature values. This signature
 // needs to be in the [R || S || V] format where V is 0 or 1.
 func (fs FrontierSigner) SignatureValues(tx *Transaction, sig []byte) (r, s, v *big.Int, err error) {
+	if tx.Type() != LegacyTxType {
+		return nil, nil, nil, ErrTxTypeNotSupported
+	}
+	r, s, v = decodeSignature(sig)
+	v.Add(v, big.NewInt(27))
+	return r, s, v, nil
+}
+
+// decodeSignature splits a signature in [R || S || V] format, where V is the
+// plain recovery id.
+func decodeSignature(sig []byte) (r, s, v *big.Int) {
 	if len(sig) != 65 {
 		panic(fmt.Sprintf("wrong size for signature: got %d, want 65", len(sig)))
 	}
 	r = new(big.Int).SetBytes(sig[:32])
 	s = new(big.Int).SetBytes(sig[32:64])
-	v = new(big.Int).SetBytes([]byte{sig[64] + 27})
-	return r, s, v, nil
+	v = new(big.Int).SetBytes([]byte{sig[64]})
+	return r, s, v
 }
 
 // Hash returns the hash to be signed by the sender.
 // It does not uniquely identify the transaction.
 func (fs FrontierSigner) Hash(tx *Transaction) common.Hash {
 	return rlpHash([]interface{}{
-		tx.data.AccountNonce,
-		tx.data.Price,
-		tx.data.GasLimit,
-		tx.data.Recipient,
-		tx.data.Amount,
-		tx.data.Payload,
+		tx.inner.nonce(),
+		tx.inner.gasPrice(),
+		tx.inner.gas(),
+		tx.inner.to(),
+		tx.inner.value(),
+		tx.inner.data(),
 	})
 }
 
 func (fs FrontierSigner) Sender(tx *Transaction) (common.Address, error) {
-	return recoverPlain(fs.Hash(tx), tx.data.R, tx.data.S, tx.data.V, false)
+	if tx.Type() != LegacyTxType {
+		return common.Address{}, ErrTxTypeNotSupported
+	}
+	v, r, s := tx.RawSignatureValues()
+	return recoverPlain(fs.Hash(tx), r, s, v, false)
 }
 
 func recoverPlain(sighash common.Hash, R, S, Vb *big.Int, homestead bool) (common.Address, error) {
@@ -21,6 +21,7 @@ import (
 	"crypto/ecdsa"
 	"encoding/json"
 	"math/big"
+	"reflect"
 	"testing"
 
 	"container/heap"
@@ -51,6 +52,23 @@ var (
 		HomesteadSigner{},
 		common.Hex2Bytes("98ff921201554726367d2be8c804a7ff89ccf285ebc57dff8ae4c44b9c19ac4a8887321be575c8095f789dd4c743dfe42c1820f9231f98a962b210e3ac2452a301"),
 	)
+
+	emptyEip2718Tx = NewTx(&AccessListTx{
+		ChainID:  big.NewInt(1),
+		Nonce:    3,
+		To:       &testAddr,
+		Value:    big.NewInt(10),
+		Gas:      big.NewInt(25000),
+		GasPrice: big.NewInt(1),
+		Data:     common.FromHex("5544"),
+	})
+
+	signedEip2718Tx, _ = emptyEip2718Tx.WithSignature(
+		NewEIP2930Signer(big.NewInt(1)),
+		common.Hex2Bytes("c9519f4f2b30335884581971573fadf60c6204f59a911df35ee8a540456b266032f1e8e2c5dd761f9e4f88f41c8310aeaba26a8bfcdacfedfa12ec3862d3752101"),
+	)
+
+	testAddr = common.HexToAddress("b94f5374fce5edbc8e2a8697c15331677e6ebf0b")
 )
 
 func TestTransactionSigHash(t *testing.T) {
@@ -74,6 +92,172 @@ func TestTransactionEncode(t *testing.T) {
 	}
 }
 
+func TestEIP2718TransactionSigHash(t *testing.T) {
+	s := NewEIP2930Signer(big.NewInt(1))
+	if s.Hash(emptyEip2718Tx) != common.HexToHash("49b486f0ec0a60dfbbca2d30cb07c9e8ffb2a2ff41f29a1ab6737475f6ff69f3") {
+		t.Errorf("empty EIP-2718 transaction hash mismatch, got %x", s.Hash(emptyEip2718Tx))
+	}
+	if s.Hash(signedEip2718Tx) != common.HexToHash("49b486f0ec0a60dfbbca2d30cb07c9e8ffb2a2ff41f29a1ab6737475f6ff69f3") {
+		t.Errorf("signed EIP-2718 transaction hash mismatch, got %x", s.Hash(signedEip2718Tx))
+	}
+}
+
+func TestEIP2718TransactionEncode(t *testing.T) {
+	want := common.FromHex("01f8630103018261a894b94f5374fce5edbc8e2a8697c15331677e6ebf0b0a825544c001a0c9519f4f2b30335884581971573fadf60c6204f59a911df35ee8a540456b2660a032f1e8e2c5dd761f9e4f88f41c8310aeaba26a8bfcdacfedfa12ec3862d37521")
+	// Binary (consensus) encoding.
+	have, err := signedEip2718Tx.MarshalBinary()
+	if err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+	if !bytes.Equal(have, want) {
+		t.Errorf("encoded binary mismatch, got %x", have)
+	}
+	// RLP (network) encoding wraps the binary form in a string.
+	have, err = rlp.EncodeToBytes(signedEip2718Tx)
+	if err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+	wantRLP, _ := rlp.EncodeToBytes(want)
+	if !bytes.Equal(have, wantRLP) {
+		t.Errorf("encoded RLP mismatch, got %x", have)
+	}
+	// Both encodings must decode back to the same transaction.
+	var fromBinary, fromRLP Transaction
+	if err := fromBinary.UnmarshalBinary(want); err != nil {
+		t.Fatalf("binary decode error: %v", err)
+	}
+	if err := rlp.DecodeBytes(wantRLP, &fromRLP); err != nil {
+		t.Fatalf("RLP decode error: %v", err)
+	}
+	for _, tx := range []*Transaction{&fromBinary, &fromRLP} {
+		if tx.Hash() != signedEip2718Tx.Hash() {
+			t.Errorf("decoded hash mismatch: have %x, want %x", tx.Hash(), signedEip2718Tx.Hash())
+		}
+		if tx.Type() != AccessListTxType {
+			t.Errorf("decoded type mismatch: have %d, want %d", tx.Type(), AccessListTxType)
+		}
+	}
+}
+
+// Tests that typed transactions are only accepted by the EIP-2930 signer and
+// that it recovers the same sender as the legacy signers for legacy txs.
+func TestEIP2718TransactionSigner(t *testing.T) {
+	key, addr := defaultTestKey()
+	signer := NewEIP2930Signer(big.NewInt(1))
+
+	acl := AccessList{{Address: testAddr, StorageKeys: []common.Hash{{0x01}}}}
+	tx, err := SignNewTx(key, signer, &AccessListTx{
+		ChainID:    big.NewInt(1),
+		Nonce:      1,
+		To:         &testAddr,
+		Value:      big.NewInt(1),
+		Gas:        big.NewInt(30000),
+		GasPrice:   big.NewInt(1),
+		AccessList: acl,
+	})
+	if err != nil {
+		t.Fatalf("could not sign transaction: %v", err)
+	}
+	if from, err := Sender(signer, tx); err != nil || from != addr {
+		t.Errorf("sender mismatch: have %x (%v), want %x", from, err, addr)
+	}
+	if _, err := Sender(NewEIP155Signer(big.NewInt(1)), tx); err != ErrTxTypeNotSupported {
+		t.Errorf("EIP-155 signer error mismatch: have %v, want %v", err, ErrTxTypeNotSupported)
+	}
+	if _, err := Sender(NewEIP2930Signer(big.NewInt(2)), tx); err != ErrInvalidChainId {
+		t.Errorf("foreign chain error mismatch: have %v, want %v", err, ErrInvalidChainId)
+	}
+	legacy, err := SignTx(NewTransaction(0, testAddr, common.Big0, big.NewInt(21000), common.Big1, nil), NewEIP155Signer(big.NewInt(1)), key)
+	if err != nil {
+		t.Fatalf("could not sign transaction: %v", err)
+	}
+	if from, err := Sender(signer, legacy); err != nil || from != addr {
+		t.Errorf("legacy sender mismatch: have %x (%v), want %x", from, err, addr)
+	}
+}
+
+// Tests that sponsored transactions carry both signatures through the binary
+// encoding and that the sponsor signature is bound to the sender.
+func TestSponsoredTransaction(t *testing.T) {
+	key, addr := defaultTestKey()
+	sponsorKey, _ := crypto.GenerateKey()
+	sponsorAddr := crypto.PubkeyToAddress(sponsorKey.PublicKey)
+	signer := NewSponsorSigner(big.NewInt(1))
+
+	tx, err := SignNewTx(key, signer, &SponsoredTx{
+		ChainID:  big.NewInt(1),
+		Nonce:    3,
+		To:       &testAddr,
+		Value:    big.NewInt(0),
+		Gas:      big.NewInt(50000),
+		GasPrice: big.NewInt(10),
+		Data:     []byte{0xde, 0xad},
+	})
+	if err != nil {
+		t.Fatalf("could not sign transaction: %v", err)
+	}
+	if _, err := Sponsor(signer, tx); err == nil {
+		t.Errorf("expected error recovering missing sponsor signature")
+	}
+	if tx, err = SignSponsor(tx, signer, sponsorKey); err != nil {
+		t.Fatalf("could not sponsor transaction: %v", err)
+	}
+	if tx.Cost().Sign() != 0 {
+		t.Errorf("sender cost mismatch: have %v, want 0", tx.Cost())
+	}
+	bin, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+	if bin[0] != SponsoredTxType {
+		t.Fatalf("type prefix mismatch: have %d, want %d", bin[0], SponsoredTxType)
+	}
+	var dec Transaction
+	if err := dec.UnmarshalBinary(bin); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if dec.Hash() != tx.Hash() {
+		t.Errorf("decoded hash mismatch: have %x, want %x", dec.Hash(), tx.Hash())
+	}
+	msg, err := dec.AsMessage(signer, nil)
+	if err != nil {
+		t.Fatalf("could not derive message: %v", err)
+	}
+	if msg.From() != addr {
+		t.Errorf("sender mismatch: have %x, want %x", msg.From(), addr)
+	}
+	if msg.Sponsor() == nil || *msg.Sponsor() != sponsorAddr {
+		t.Errorf("sponsor mismatch: have %x, want %x", msg.Sponsor(), sponsorAddr)
+	}
+	if _, err := Sender(NewEIP2930Signer(big.NewInt(1)), &dec); err != ErrTxTypeNotSupported {
+		t.Errorf("EIP-2930 signer error mismatch: have %v, want %v", err, ErrTxTypeNotSupported)
+	}
+	// A sponsor signature given for another sender must not verify.
+	other, err := SignTx(NewTx(&SponsoredTx{
+		ChainID:  big.NewInt(1),
+		Nonce:    3,
+		To:       &testAddr,
+		Value:    big.NewInt(0),
+		Gas:      big.NewInt(50000),
+		GasPrice: big.NewInt(10),
+		Data:     []byte{0xde, 0xad},
+	}), signer, sponsorKey)
+	if err != nil {
+		t.Fatalf("could not sign transaction: %v", err)
+	}
+	v, r, s := tx.RawSponsorSignatureValues()
+	sig := make([]byte, 65)
+	copy(sig[32-len(r.Bytes()):32], r.Bytes())
+	copy(sig[64-len(s.Bytes()):64], s.Bytes())
+	sig[64] = byte(v.Uint64())
+	if other, err = other.WithSponsorSignature(sig); err != nil {
+		t.Fatalf("could not set sponsor signature: %v", err)
+	}
+	if sponsor, err := Sponsor(signer, other); err == nil && sponsor == sponsorAddr {
+		t.Errorf("sponsor signature reused for another sender")
+	}
+}
+
 func decodeTx(data []byte) (*Transaction, error) {
 	var tx Transaction
 	t, err := &tx, rlp.Decode(bytes.NewReader(data), &tx)
@@ -87,6 +271,79 @@ func defaultTestKey() (*ecdsa.PrivateKey, common.Address) {
 	return key, addr
 }
 
+func TestDynamicFeeTransaction(t *testing.T) {
+	key, addr := defaultTestKey()
+	signer := NewLondonSigner(big.NewInt(1))
+
+	tx, err := SignNewTx(key, signer, &DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     3,
+		To:        &testAddr,
+		Value:     big.NewInt(10),
+		Gas:       big.NewInt(50000),
+		GasTipCap: big.NewInt(2),
+		GasFeeCap: big.NewInt(20),
+		Data:      []byte{0xde, 0xad},
+	})
+	if err != nil {
+		t.Fatalf("could not sign transaction: %v", err)
+	}
+	bin, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+	if bin[0] != DynamicFeeTxType {
+		t.Fatalf("type prefix mismatch: have %d, want %d", bin[0], DynamicFeeTxType)
+	}
+	var dec Transaction
+	if err := dec.UnmarshalBinary(bin); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if dec.Hash() != tx.Hash() {
+		t.Errorf("decoded hash mismatch: have %x, want %x", dec.Hash(), tx.Hash())
+	}
+	if _, err := Sender(NewEIP2930Signer(big.NewInt(1)), &dec); err != ErrTxTypeNotSupported {
+		t.Errorf("EIP-2930 signer error mismatch: have %v, want %v", err, ErrTxTypeNotSupported)
+	}
+	// The effective price is capped by the fee cap
+	for _, tt := range []struct {
+		baseFee, price, tip int64
+	}{
+		{10, 12, 2},
+		{18, 20, 2},
+		{19, 20, 1},
+	} {
+		msg, err := dec.AsMessage(signer, big.NewInt(tt.baseFee))
+		if err != nil {
+			t.Fatalf("could not derive message: %v", err)
+		}
+		if msg.From() != addr {
+			t.Errorf("sender mismatch: have %x, want %x", msg.From(), addr)
+		}
+		if msg.GasPrice().Int64() != tt.price {
+			t.Errorf("base fee %d: price mismatch: have %v, want %d", tt.baseFee, msg.GasPrice(), tt.price)
+		}
+		if tip, _ := dec.EffectiveGasTip(big.NewInt(tt.baseFee)); tip.Int64() != tt.tip {
+			t.Errorf("base fee %d: tip mismatch: have %v, want %d", tt.baseFee, tip, tt.tip)
+		}
+	}
+	if _, err := dec.EffectiveGasTip(big.NewInt(21)); err != ErrGasFeeCapTooLow {
+		t.Errorf("fee cap error mismatch: have %v, want %v", err, ErrGasFeeCapTooLow)
+	}
+	// Round trip through JSON
+	data, err := json.Marshal(tx)
+	if err != nil {
+		t.Fatalf("json encode error: %v", err)
+	}
+	var parsed Transaction
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		t.Fatalf("json decode error: %v", err)
+	}
+	if parsed.Hash() != tx.Hash() {
+		t.Errorf("json hash mismatch: have %x, want %x", parsed.Hash(), tx.Hash())
+	}
+}
+
 func TestRecipientEmpty(t *testing.T) {
 	_, addr := defaultTestKey()
 	tx, err := decodeTx(common.Hex2Bytes("f8498080808080011ca09b16de9d5bdee2cf56c28d16275a4da68cd30273e2525f3959f5d62557489921a0372ebd8fb3345f7db7b5a86d42e24d36e983e259b0664ceb8c227ec9af572f3d"))
@@ -146,7 +403,7 @@ func TestTransactionPriceNonceSort(t *testing.T) {
 		}
 	}
 	// Sort the transactions and cross check the nonce ordering
-	txset := NewTransactionsByPriceAndNonce(signer, groups)
+	txset := NewTransactionsByPriceAndNonce(signer, groups, nil)
 
 	txs := Transactions{}
 	for tx := txset.Peek(); tx != nil; tx = txset.Peek() {
@@ -194,6 +451,48 @@ func TestTransactionPriceNonceSort(t *testing.T) {
 	}
 }
 
+// Tests that transactions are ordered by the tip they pay on top of the base fee
+// and that transactions not covering the base fee are left out.
+func TestTransactionTipSort(t *testing.T) {
+	signer := NewLondonSigner(big.NewInt(1))
+	baseFee := big.NewInt(10)
+
+	// The fee caps sort the other way around than the effective tips
+	caps := []struct{ tip, feeCap int64 }{
+		{1, 30}, // tip 1
+		{5, 13}, // tip 3
+		{2, 20}, // tip 2
+		{9, 9},  // below the base fee
+	}
+	groups := map[common.Address]Transactions{}
+	for i, c := range caps {
+		key, _ := crypto.GenerateKey()
+		tx, err := SignNewTx(key, signer, &DynamicFeeTx{
+			ChainID:   big.NewInt(1),
+			Nonce:     uint64(i),
+			Gas:       big.NewInt(21000),
+			GasTipCap: big.NewInt(c.tip),
+			GasFeeCap: big.NewInt(c.feeCap),
+			Value:     big.NewInt(0),
+		})
+		if err != nil {
+			t.Fatalf("could not sign transaction: %v", err)
+		}
+		groups[crypto.PubkeyToAddress(key.PublicKey)] = Transactions{tx}
+	}
+	txset := NewTransactionsByPriceAndNonce(signer, groups, baseFee)
+
+	var tips []int64
+	for tx := txset.Peek(); tx != nil; tx = txset.Peek() {
+		tip, _ := tx.EffectiveGasTip(baseFee)
+		tips = append(tips, tip.Int64())
+		txset.Shift()
+	}
+	if want := []int64{3, 2, 1}; !reflect.DeepEqual(tips, want) {
+		t.Errorf("tip ordering mismatch: have %v, want %v", tips, want)
+	}
+}
+
 // TestTransactionJSON tests serializing/de-serializing to/from JSON.
 func TestTransactionJSON(t *testing.T) {
 	key, err := crypto.GenerateKey()
@@ -236,6 +535,39 @@ func TestTransactionJSON(t *testing.T) {
 	}
 }
 
+func TestAccessListTransactionJSON(t *testing.T) {
+	key, _ := defaultTestKey()
+	signer := NewEIP2930Signer(common.Big1)
+
+	acl := AccessList{{Address: testAddr, StorageKeys: []common.Hash{{0x01}, {0x02}}}}
+	tx, err := SignNewTx(key, signer, &AccessListTx{
+		ChainID:    common.Big1,
+		Nonce:      7,
+		Gas:        big.NewInt(50000),
+		GasPrice:   common.Big2,
+		Value:      common.Big0,
+		Data:       []byte("abcdef"),
+		AccessList: acl,
+	})
+	if err != nil {
+		t.Fatalf("could not sign transaction: %v", err)
+	}
+	data, err := json.Marshal(tx)
+	if err != nil {
+		t.Fatalf("json.Marshal failed: %v", err)
+	}
+	var parsedTx *Transaction
+	if err := json.Unmarshal(data, &parsedTx); err != nil {
+		t.Fatalf("json.Unmarshal failed: %v", err)
+	}
+	if tx.Hash() != parsedTx.Hash() {
+		t.Errorf("parsed tx differs from original tx, want %v, got %v", tx, parsedTx)
+	}
+	if parsedTx.Type() != AccessListTxType || parsedTx.AccessList().StorageKeys() != 2 {
+		t.Errorf("parsed tx lost its access list: %v", parsedTx)
+	}
+}
+
 type IntHeap []int
 
 func (h IntHeap) Len() int { return len(h) }
@@ -616,6 +616,12 @@ func opSelfBalance(pc *uint64, interpreter *Interpreter, scope *ScopeContext) ([
 	return nil, nil
 }
 
+func opBaseFee(pc *uint64, interpreter *Interpreter, scope *ScopeContext) ([]byte, error) {
+	baseFee, _ := uint256.FromBig(interpreter.evm.Context.BaseFee)
+	scope.Stack.push(baseFee)
+	return nil, nil
+}
+
 func opCreate2(pc *uint64, interpreter *Interpreter, scope *ScopeContext) ([]byte, error) {
 	var (
 		endowment    = scope.Stack.pop()
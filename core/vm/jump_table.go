@@ -65,10 +65,55 @@ var (
 	spuriousDragonInstructionSet   = NewSpuriousDragonInstructionSet()
 	byzantiumInstructionSet        = NewByzantiumInstructionSet()
 	si004InstructionSet            = NewSip004InstructionSet()
+	berlinInstructionSet           = NewBerlinInstructionSet()
+	londonInstructionSet           = NewLondonInstructionSet()
 )
 
 type JumpTable [256]*operation
 
+// NewLondonInstructionSet returns the berlin instructions with the
+// EIP-3198 BASEFEE opcode.
+func NewLondonInstructionSet() JumpTable {
+	instructionSet := NewBerlinInstructionSet()
+	instructionSet[BASEFEE] = &operation{
+		execute:     opBaseFee,
+		constantGas: GasQuickStep,
+		minStack:    minStack(0, 1),
+		maxStack:    maxStack(0, 1),
+	}
+	return instructionSet
+}
+
+// NewBerlinInstructionSet returns the si004 instructions with the
+// EIP-2929 access list gas repricing applied.
+func NewBerlinInstructionSet() JumpTable {
+	instructionSet := NewSip004InstructionSet()
+	instructionSet[SSTORE].dynamicGas = gasSStoreEIP2929
+	instructionSet[SLOAD].constantGas = 0
+	instructionSet[SLOAD].dynamicGas = gasSLoadEIP2929
+	instructionSet[EXTCODECOPY].constantGas = params.WarmStorageReadCostEIP2929
+	instructionSet[EXTCODECOPY].dynamicGas = gasExtCodeCopyEIP2929
+	instructionSet[EXTCODESIZE].constantGas = params.WarmStorageReadCostEIP2929
+	instructionSet[EXTCODESIZE].dynamicGas = gasEip2929AccountCheck
+	instructionSet[EXTCODEHASH].constantGas = params.WarmStorageReadCostEIP2929
+	instructionSet[EXTCODEHASH].dynamicGas = gasEip2929AccountCheck
+	instructionSet[BALANCE].constantGas = params.WarmStorageReadCostEIP2929
+	instructionSet[BALANCE].dynamicGas = gasEip2929AccountCheck
+	instructionSet[CALL].constantGas = params.WarmStorageReadCostEIP2929
+	instructionSet[CALL].dynamicGas = gasCallEIP2929
+	instructionSet[CALLCODE].constantGas = params.WarmStorageReadCostEIP2929
+	instructionSet[CALLCODE].dynamicGas = gasCallCodeEIP2929
+	instructionSet[STATICCALL].constantGas = params.WarmStorageReadCostEIP2929
+	instructionSet[STATICCALL].dynamicGas = gasStaticCallEIP2929
+	instructionSet[DELEGATECALL].constantGas = params.WarmStorageReadCostEIP2929
+	instructionSet[DELEGATECALL].dynamicGas = gasDelegateCallEIP2929
+	// The base cost was previously part of the dynamic cost, it is charged
+	// as constant gas now.
+	instructionSet[SELFDESTRUCT].constantGas = params.SelfdestructGasEIP150
+	instructionSet[SELFDESTRUCT].dynamicGas = gasSelfdestructEIP2929
+	return instructionSet
+}
+
 // NewSip004InstructionSet returns the frontier, homestead byzantium and
 // si004 instructions.
 func NewSip004InstructionSet() JumpTable {
@@ -25,6 +25,7 @@ import (
 	"github.com/MeshBoxTech/mesh-chain/accounts"
 	"github.com/MeshBoxTech/mesh-chain/common"
 	"github.com/MeshBoxTech/mesh-chain/common/math"
+	"github.com/MeshBoxTech/mesh-chain/consensus"
 	"github.com/MeshBoxTech/mesh-chain/core"
 	"github.com/MeshBoxTech/mesh-chain/core/bloombits"
 	"github.com/MeshBoxTech/mesh-chain/core/state"
@@ -49,6 +50,10 @@ func (b *EthApiBackend) ChainConfig() *params.ChainConfig {
 	return b.eth.chainConfig
 }
 
+func (b *EthApiBackend) Engine() consensus.Engine {
+	return b.eth.engine
+}
+
 func (b *EthApiBackend) CurrentBlock() *types.Block {
 	return b.eth.blockchain.CurrentBlock()
 }
@@ -162,6 +167,10 @@ func (b *EthApiBackend) SendTx(ctx context.Context, signedTx *types.Transaction)
 	return b.eth.txPool.AddLocal(signedTx)
 }
 
+func (b *EthApiBackend) SendPrivateTx(ctx context.Context, signedTx *types.Transaction, maxBlockNumber uint64, publish bool) error {
+	return b.eth.txPool.AddPrivate(signedTx, maxBlockNumber, publish)
+}
+
 func (b *EthApiBackend) GetPoolTransactions() (types.Transactions, error) {
 	pending, err := b.eth.txPool.Pending()
 	if err != nil {
@@ -186,6 +195,10 @@ func (b *EthApiBackend) Stats() (pending int, queued int) {
 	return b.eth.txPool.Stats()
 }
 
+func (b *EthApiBackend) PrivateStats() int {
+	return b.eth.txPool.PrivateStats()
+}
+
 func (b *EthApiBackend) TxPoolContent() (map[common.Address]types.Transactions, map[common.Address]types.Transactions) {
 	return b.eth.TxPool().Content()
 }
@@ -194,6 +207,19 @@ func (b *EthApiBackend) SubscribeTxPreEvent(ch chan<- core.TxPreEvent) event.Sub
 	return b.eth.TxPool().SubscribeTxPreEvent(ch)
 }
 
+func (b *EthApiBackend) TxIndexProgress() core.TxIndexProgress {
+	return b.eth.blockchain.TxIndexProgress()
+}
+
+// BlockRecords returns the records the consensus engine kept of the block when it
+// was written to the chain, if it keeps any.
+func (b *EthApiBackend) BlockRecords(header *types.Header) interface{} {
+	if recorder, ok := b.eth.engine.(consensus.Recorder); ok {
+		return recorder.BlockRecords(header)
+	}
+	return nil
+}
+
 func (b *EthApiBackend) Downloader() *downloader.Downloader {
 	return b.eth.Downloader()
 }
@@ -206,6 +232,14 @@ func (b *EthApiBackend) SuggestPrice(ctx context.Context) (*big.Int, error) {
 	return b.gpo.SuggestPrice(ctx)
 }
 
+func (b *EthApiBackend) SuggestTipCap(ctx context.Context) (*big.Int, error) {
+	return b.gpo.SuggestTipCap(ctx)
+}
+
+func (b *EthApiBackend) FeeHistory(ctx context.Context, blocks int, lastBlock rpc.BlockNumber, rewardPercentiles []float64) (*big.Int, [][]*big.Int, []*big.Int, []float64, error) {
+	return b.gpo.FeeHistory(ctx, blocks, lastBlock, rewardPercentiles)
+}
+
 func (b *EthApiBackend) ChainDb() ethdb.Database {
 	return b.eth.ChainDb()
 }
@@ -95,8 +95,8 @@ type txTraceTask struct {
 // state tries for intermediate blocks without serializing to disk, but at the
 // same time to allow disk fallback for reads that do no hit the memory layer.
 type ephemeralDatabase struct {
-	diskdb ethdb.Database     // Persistent disk database to fall back to with reads
-	memdb  *ethdb.MemDatabase // Ephemeral memory database for primary reads and writes
+	diskdb trie.DatabaseReader // Chain trie database to fall back to with reads
+	memdb  *ethdb.MemDatabase  // Ephemeral memory database for primary reads and writes
 }
 
 func (db *ephemeralDatabase) Put(key []byte, value []byte) error { return db.memdb.Put(key, value) }
@@ -105,6 +105,13 @@ func (db *ephemeralDatabase) Close()                             { db.memdb.Clos
 func (db *ephemeralDatabase) NewBatch() ethdb.Batch {
 	return db.memdb.NewBatch()
 }
+func (db *ephemeralDatabase) NewIterator(prefix []byte, start []byte) ethdb.Iterator {
+	return db.memdb.NewIterator(prefix, start) // Only the memory layer is iterated
+}
+func (db *ephemeralDatabase) Stat(property string) (string, error) { return db.memdb.Stat(property) }
+func (db *ephemeralDatabase) Compact(start []byte, limit []byte) error {
+	return db.memdb.Compact(start, limit)
+}
 func (db *ephemeralDatabase) Has(key []byte) (bool, error) {
 	if has, _ := db.memdb.Has(key); has {
 		return has, nil
@@ -191,7 +198,7 @@ func (api *PrivateDebugAPI) traceChain(ctx context.Context, start, end *types.Bl
 
 	memdb, _ := ethdb.NewMemDatabase()
 	db := &ephemeralDatabase{
-		diskdb: api.eth.ChainDb(),
+		diskdb: api.eth.blockchain.StateCache().TrieDB(),
 		memdb:  memdb,
 	}
 	if number := start.NumberU64(); number > 0 {
@@ -250,7 +257,7 @@ func (api *PrivateDebugAPI) traceChain(ctx context.Context, start, end *types.Bl
 
 				// Trace all the transactions contained within
 				for i, tx := range task.block.Transactions() {
-					msg, _ := tx.AsMessage(signer)
+					msg, _ := tx.AsMessage(signer, task.block.BaseFee())
 					vmctx := core.NewEVMContext(msg, task.block.Header(), api.eth.blockchain, nil)
 					txctx := &tracers.Context{
 						BlockHash: task.block.Hash(),
@@ -498,7 +505,7 @@ func (api *PrivateDebugAPI) traceBlock(ctx context.Context, block *types.Block,
 
 			// Fetch and execute the next transaction trace tasks
 			for task := range jobs {
-				msg, _ := txs[task.index].AsMessage(signer)
+				msg, _ := txs[task.index].AsMessage(signer, block.BaseFee())
 				vmctx := core.NewEVMContext(msg, block.Header(), api.eth.blockchain, nil)
 
 				txctx := &tracers.Context{
@@ -523,7 +530,7 @@ func (api *PrivateDebugAPI) traceBlock(ctx context.Context, block *types.Block,
 		jobs <- &txTraceTask{statedb: statedb.Copy(), index: i}
 
 		// Generate the next state snapshot fast without tracing
-		msg, _ := tx.AsMessage(signer)
+		msg, _ := tx.AsMessage(signer, block.BaseFee())
 		vmctx := core.NewEVMContext(msg, block.Header(), api.eth.blockchain, nil)
 
 		vmenv := vm.NewEVM(vmctx, statedb, api.config, vm.Config{})
@@ -558,7 +565,7 @@ func (api *PrivateDebugAPI) computeStateDB(block *types.Block, reexec uint64) (*
 
 	memdb, _ := ethdb.NewMemDatabase()
 	db := &ephemeralDatabase{
-		diskdb: api.eth.ChainDb(),
+		diskdb: api.eth.blockchain.StateCache().TrieDB(),
 		memdb:  memdb,
 	}
 	for i := uint64(0); i < reexec; i++ {
@@ -730,7 +737,7 @@ func (api *PrivateDebugAPI) computeTxEnv(blockHash common.Hash, txIndex int, ree
 
 	for idx, tx := range block.Transactions() {
 		// Assemble the transaction call message and return if the requested offset
-		msg, _ := tx.AsMessage(signer)
+		msg, _ := tx.AsMessage(signer, block.BaseFee())
 		context := core.NewEVMContext(msg, block.Header(), api.eth.blockchain, nil)
 		if idx == txIndex {
 			return msg, context, statedb, nil
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"path/filepath"
 	"runtime"
 	"sync"
 	"sync/atomic"
@@ -34,6 +35,7 @@ import (
 	"github.com/MeshBoxTech/mesh-chain/consensus/clique"
 	"github.com/MeshBoxTech/mesh-chain/consensus/ethash"
 	"github.com/MeshBoxTech/mesh-chain/consensus/tribe"
+	"github.com/MeshBoxTech/mesh-chain/contracts/pom"
 	"github.com/MeshBoxTech/mesh-chain/core"
 	"github.com/MeshBoxTech/mesh-chain/core/bloombits"
 	"github.com/MeshBoxTech/mesh-chain/core/types"
@@ -82,8 +84,9 @@ type Ethereum struct {
 	engine         consensus.Engine
 	accountManager *accounts.Manager
 
-	bloomRequests chan chan *bloombits.Retrieval // Channel receiving bloom data retrieval requests
-	bloomIndexer  *core.ChainIndexer             // Bloom indexer operating during block imports
+	bloomRequests     chan chan *bloombits.Retrieval // Channel receiving bloom data retrieval requests
+	bloomIndexer      *core.ChainIndexer             // Bloom indexer operating during block imports
+	tribeStatsIndexer *core.ChainIndexer             // Validator statistics indexer of the tribe engine, nil otherwise
 
 	ApiBackend *EthApiBackend
 
@@ -111,7 +114,7 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 	if !config.SyncMode.IsValid() {
 		return nil, fmt.Errorf("invalid sync mode %d", config.SyncMode)
 	}
-	chainDb, err := CreateDB(ctx, config, "chaindata")
+	chainDb, err := CreateDBWithFreezer(ctx, config, "chaindata")
 	if err != nil {
 		return nil, err
 	}
@@ -147,8 +150,11 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 		}
 		core.WriteBlockChainVersion(chainDb, core.BlockChainVersion)
 	}
-	vmConfig := vm.Config{EnablePreimageRecording: config.EnablePreimageRecording}
-	eth.blockchain, err = core.NewBlockChain(chainDb, eth.chainConfig, eth.engine, vmConfig)
+	var (
+		vmConfig    = vm.Config{EnablePreimageRecording: config.EnablePreimageRecording}
+		cacheConfig = &core.CacheConfig{Disabled: config.NoPruning, TrieNodeLimit: config.TrieCache, TxLookupLimit: config.TxLookupLimit}
+	)
+	eth.blockchain, err = core.NewBlockChain(chainDb, cacheConfig, eth.chainConfig, eth.engine, vmConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -159,6 +165,10 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 		core.WriteChainConfig(chainDb, genesisHash, chainConfig)
 	}
 	eth.bloomIndexer.Start(eth.blockchain)
+	if tribe, ok := eth.engine.(*tribe.Tribe); ok {
+		eth.tribeStatsIndexer = tribe.NewStatsIndexer(eth.blockchain)
+		eth.tribeStatsIndexer.Start(eth.blockchain)
+	}
 
 	if config.TxPool.Journal != "" {
 		config.TxPool.Journal = ctx.ResolvePath(config.TxPool.Journal)
@@ -166,6 +176,9 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 	eth.txPool = core.NewTxPool(config.TxPool, eth.chainConfig, eth.blockchain)
 	//设置默认的GasPrice 18Gwei
 	eth.txPool.SetGasPrice(DefaultConfig.GasPrice)
+	if tribe, ok := eth.engine.(*tribe.Tribe); ok {
+		tribe.SetTxPool(eth.txPool)
+	}
 
 	if eth.protocolManager, err = NewProtocolManager(eth.chainConfig, config.SyncMode, config.NetworkId, eth.eventMux, eth.txPool, eth.engine, eth.blockchain, chainDb); err != nil {
 		return nil, err
@@ -213,6 +226,30 @@ func CreateDB(ctx *node.ServiceContext, config *Config, name string) (ethdb.Data
 	return db, nil
 }
 
+// CreateDBWithFreezer creates the chain database, moving blocks older than the
+// configured threshold into an ancient store. Ephemeral databases are returned
+// without one.
+func CreateDBWithFreezer(ctx *node.ServiceContext, config *Config, name string) (ethdb.Database, error) {
+	db, err := CreateDB(ctx, config, name)
+	if err != nil {
+		return nil, err
+	}
+	ldb, ok := db.(*ethdb.LDBDatabase)
+	if !ok {
+		return db, nil
+	}
+	freezer := filepath.Join(ldb.Path(), "ancient")
+	if config.DatabaseFreezer != "" {
+		freezer = ctx.ResolvePath(config.DatabaseFreezer)
+	}
+	fdb, err := core.NewDatabaseWithFreezer(db, freezer, "eth/db/chaindata/", config.FreezerThreshold)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return fdb, nil
+}
+
 // CreateConsensusEngine creates the required type of consensus engine instance for an Ethereum service
 func CreateConsensusEngine(ctx *node.ServiceContext, config *ethash.Config, chainConfig *params.ChainConfig, db ethdb.Database) consensus.Engine {
 	if chainConfig.Tribe != nil {
@@ -255,6 +292,20 @@ func (s *Ethereum) APIs() []rpc.API {
 	// Append any APIs exposed explicitly by the consensus engine
 	apis = append(apis, s.engine.APIs(s.BlockChain())...)
 
+	// Append the client of the POM system contract on tribe chains
+	if s.chainConfig.Tribe != nil {
+		pomAPI, err := pom.NewPrivatePOMAPI(NewContractBackend(s.ApiBackend), s.accountManager, s.chainConfig, s.blockchain.CurrentHeader)
+		if err != nil {
+			log.Error("Failed to create POM API", "err", err)
+		} else {
+			apis = append(apis, rpc.API{
+				Namespace: "pom",
+				Version:   "1.0",
+				Service:   pomAPI,
+			})
+		}
+	}
+
 	// Append all the local APIs and return
 	return append(apis, []rpc.API{
 		{
@@ -429,6 +480,9 @@ func (s *Ethereum) Stop() error {
 		s.stopDbUpgrade()
 	}
 	s.bloomIndexer.Close()
+	if s.tribeStatsIndexer != nil {
+		s.tribeStatsIndexer.Close()
+	}
 	s.blockchain.Stop()
 	s.protocolManager.Stop()
 	if s.lesServer != nil {
@@ -42,10 +42,12 @@ var DefaultConfig = Config{
 		DatasetsInMem:  1,
 		DatasetsOnDisk: 2,
 	},
-	NetworkId:     1,
-	LightPeers:    20,
-	DatabaseCache: 128,
-	GasPrice:      big.NewInt(18 * params.Shannon),
+	NetworkId:        1,
+	LightPeers:       20,
+	DatabaseCache:    128,
+	FreezerThreshold: core.DefaultFreezerThreshold,
+	TrieCache:        256,
+	GasPrice:         big.NewInt(18 * params.Shannon),
 
 	TxPool: core.DefaultTxPoolConfig,
 	GPO: gasprice.Config{
@@ -87,6 +89,11 @@ type Config struct {
 	SkipBcVersionCheck bool `toml:"-"`
 	DatabaseHandles    int  `toml:"-"`
 	DatabaseCache      int
+	DatabaseFreezer    string // Directory of the ancient store, inside the chain database if empty
+	FreezerThreshold   uint64 // Number of recent blocks kept in the database before freezing
+	TrieCache          int    // Megabytes of trie nodes cached in memory before flushing to disk
+	NoPruning          bool   // Whether to persist the state of every block (archive node)
+	TxLookupLimit      uint64 // Number of recent blocks to keep transaction lookups for (0 = all blocks)
 
 	// Mining-related options
 	Etherbase    common.Address `toml:",omitempty"`
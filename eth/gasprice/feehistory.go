@@ -0,0 +1,180 @@
+// Copyright 2018 The mesh-chain Authors
+// This file is part of the mesh-chain library.
+//
+// The mesh-chain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The mesh-chain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the mesh-chain library. If not, see <http://www.gnu.org/licenses/>.
+
+package gasprice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/consensus/misc"
+	"github.com/MeshBoxTech/mesh-chain/core/types"
+	"github.com/MeshBoxTech/mesh-chain/rpc"
+)
+
+// maxFeeHistory is the maximum number of blocks a single fee history query
+// may cover.
+const maxFeeHistory = 1024
+
+var (
+	errInvalidPercentile = errors.New("invalid reward percentile")
+	errRequestBeyondHead = errors.New("request beyond head block")
+)
+
+// txGasAndReward pairs the gas used by a transaction with the tip it paid.
+type txGasAndReward struct {
+	gasUsed uint64
+	reward  *big.Int
+}
+
+type sortGasAndReward []txGasAndReward
+
+func (s sortGasAndReward) Len() int           { return len(s) }
+func (s sortGasAndReward) Less(i, j int) bool { return s[i].reward.Cmp(s[j].reward) < 0 }
+func (s sortGasAndReward) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// FeeHistory returns the base fees, gas usage ratios and, if requested, the
+// tips paid at the given percentiles of gas usage for a range of blocks ending
+// at lastBlock. The base fees include the one of the block following the range.
+// Blocks before the fee market have a zero base fee.
+func (gpo *Oracle) FeeHistory(ctx context.Context, blocks int, lastBlock rpc.BlockNumber, rewardPercentiles []float64) (*big.Int, [][]*big.Int, []*big.Int, []float64, error) {
+	if blocks < 1 {
+		return nil, nil, nil, nil, nil
+	}
+	if blocks > maxFeeHistory {
+		blocks = maxFeeHistory
+	}
+	for i, p := range rewardPercentiles {
+		if p < 0 || p > 100 {
+			return nil, nil, nil, nil, fmt.Errorf("%v: %f", errInvalidPercentile, p)
+		}
+		if i > 0 && p < rewardPercentiles[i-1] {
+			return nil, nil, nil, nil, fmt.Errorf("%v: #%d:%f > #%d:%f", errInvalidPercentile, i-1, rewardPercentiles[i-1], i, p)
+		}
+	}
+	// Resolve the last block of the range, pending and latest alike
+	if lastBlock == rpc.PendingBlockNumber {
+		lastBlock = rpc.LatestBlockNumber
+	}
+	head, err := gpo.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if head == nil {
+		return nil, nil, nil, nil, err
+	}
+	last := head.Number.Uint64()
+	if lastBlock != rpc.LatestBlockNumber {
+		if uint64(lastBlock) > last {
+			return nil, nil, nil, nil, fmt.Errorf("%v: requested %d, head %d", errRequestBeyondHead, lastBlock, last)
+		}
+		last = uint64(lastBlock)
+	}
+	if uint64(blocks) > last+1 {
+		blocks = int(last + 1)
+	}
+	oldest := last + 1 - uint64(blocks)
+
+	var (
+		reward       = make([][]*big.Int, 0, blocks)
+		baseFee      = make([]*big.Int, blocks+1)
+		gasUsedRatio = make([]float64, blocks)
+		config       = gpo.backend.ChainConfig()
+	)
+	for i := 0; i < blocks; i++ {
+		header, err := gpo.backend.HeaderByNumber(ctx, rpc.BlockNumber(oldest+uint64(i)))
+		if header == nil {
+			return nil, nil, nil, nil, err
+		}
+		baseFee[i] = new(big.Int)
+		if header.BaseFee != nil {
+			baseFee[i].Set(header.BaseFee)
+		}
+		if header.GasLimit.Sign() > 0 {
+			gasUsedRatio[i], _ = new(big.Float).Quo(new(big.Float).SetInt(header.GasUsed), new(big.Float).SetInt(header.GasLimit)).Float64()
+		}
+		if i == blocks-1 {
+			baseFee[blocks] = new(big.Int)
+			if next := new(big.Int).Add(header.Number, common.Big1); config.IsLondon(next) {
+				baseFee[blocks] = misc.CalcBaseFee(config, header)
+			}
+		}
+		if len(rewardPercentiles) == 0 {
+			continue
+		}
+		rewards, err := gpo.blockRewards(ctx, header, rewardPercentiles)
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+		reward = append(reward, rewards)
+	}
+	if len(rewardPercentiles) == 0 {
+		reward = nil
+	}
+	return new(big.Int).SetUint64(oldest), reward, baseFee, gasUsedRatio, nil
+}
+
+// blockRewards returns the tips paid in a block at the given percentiles of its
+// gas usage.
+func (gpo *Oracle) blockRewards(ctx context.Context, header *types.Header, percentiles []float64) ([]*big.Int, error) {
+	rewards := make([]*big.Int, len(percentiles))
+
+	block, err := gpo.backend.GetBlock(ctx, header.Hash())
+	if block == nil {
+		return nil, err
+	}
+	txs := block.Transactions()
+	if len(txs) == 0 {
+		// Return an all zero row if there are no transactions to gather data from
+		for i := range rewards {
+			rewards[i] = new(big.Int)
+		}
+		return rewards, nil
+	}
+	receipts, err := gpo.backend.GetReceipts(ctx, header.Hash())
+	if err != nil {
+		return nil, err
+	}
+	if len(receipts) != len(txs) {
+		return nil, fmt.Errorf("receipt count mismatch: have %d, want %d", len(receipts), len(txs))
+	}
+	// Derive the gas used by each transaction from the cumulative counters,
+	// receipts retrieved on demand do not carry it
+	sorter := make(sortGasAndReward, len(txs))
+	for i, tx := range txs {
+		gasUsed := receipts[i].CumulativeGasUsed.Uint64()
+		if i > 0 {
+			gasUsed -= receipts[i-1].CumulativeGasUsed.Uint64()
+		}
+		tip, _ := tx.EffectiveGasTip(header.BaseFee)
+		sorter[i] = txGasAndReward{gasUsed: gasUsed, reward: tip}
+	}
+	sort.Sort(sorter)
+
+	var txIndex int
+	sumGasUsed := sorter[0].gasUsed
+
+	for i, p := range percentiles {
+		thresholdGasUsed := uint64(float64(header.GasUsed.Uint64()) * p / 100)
+		for sumGasUsed < thresholdGasUsed && txIndex < len(txs)-1 {
+			txIndex++
+			sumGasUsed += sorter[txIndex].gasUsed
+		}
+		rewards[i] = sorter[txIndex].reward
+	}
+	return rewards, nil
+}
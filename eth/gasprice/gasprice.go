@@ -72,8 +72,23 @@ func NewOracle(backend ethapi.Backend, params Config) *Oracle {
 	}
 }
 
-// SuggestPrice returns the recommended gas price.
+// SuggestPrice returns the recommended gas price of legacy transactions, the
+// recommended tip on top of the base fee of the latest block.
 func (gpo *Oracle) SuggestPrice(ctx context.Context) (*big.Int, error) {
+	tip, err := gpo.SuggestTipCap(ctx)
+	if err != nil {
+		return tip, err
+	}
+	head, _ := gpo.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if head.BaseFee != nil {
+		tip = new(big.Int).Add(tip, head.BaseFee)
+	}
+	return tip, nil
+}
+
+// SuggestTipCap returns the recommended tip paid to the validator on top of the
+// base fee, the plain gas price before the fee market activates.
+func (gpo *Oracle) SuggestTipCap(ctx context.Context) (*big.Int, error) {
 	gpo.cacheLock.RLock()
 	lastHead := gpo.lastHead
 	lastPrice := gpo.lastPrice
@@ -151,8 +166,8 @@ type getBlockPricesResult struct {
 	err    error
 }
 
-// getLowestPrice calculates the lowest transaction gas price in a given block
-// and sends it to the result channel. If the block is empty, price is nil.
+// getBlockPrices calculates the tips paid by the transactions of a given block
+// and sends them to the result channel. If the block is empty, prices is nil.
 func (gpo *Oracle) getBlockPrices(ctx context.Context, blockNum uint64, ch chan getBlockPricesResult) {
 	block, err := gpo.backend.BlockByNumber(ctx, rpc.BlockNumber(blockNum))
 	if block == nil {
@@ -162,7 +177,7 @@ func (gpo *Oracle) getBlockPrices(ctx context.Context, blockNum uint64, ch chan
 	txs := block.Transactions()
 	prices := make([]*big.Int, len(txs))
 	for i, tx := range txs {
-		prices[i] = tx.GasPrice()
+		prices[i], _ = tx.EffectiveGasTip(block.BaseFee())
 	}
 	ch <- getBlockPricesResult{prices, nil}
 }
@@ -29,6 +29,7 @@ import (
 	"github.com/MeshBoxTech/mesh-chain/common"
 	"github.com/MeshBoxTech/mesh-chain/consensus"
 	"github.com/MeshBoxTech/mesh-chain/consensus/misc"
+	"github.com/MeshBoxTech/mesh-chain/consensus/tribe"
 	"github.com/MeshBoxTech/mesh-chain/core"
 	"github.com/MeshBoxTech/mesh-chain/core/types"
 	"github.com/MeshBoxTech/mesh-chain/crypto"
@@ -50,6 +51,12 @@ const (
 	// txChanSize is the size of channel listening to TxPreEvent.
 	// The number is referenced from the size of tx pool.
 	txChanSize = 4096
+	// chainHeadChanSize is the size of channel listening to ChainHeadEvent.
+	chainHeadChanSize = 10
+
+	// privateTxFanout is the number of upcoming in-turn validators a private
+	// transaction is forwarded to.
+	privateTxFanout = 3
 )
 
 var (
@@ -71,6 +78,7 @@ type ProtocolManager struct {
 	acceptTxs uint32 // Flag whether we're considered synchronised (enables transaction processing)
 
 	txpool      txPool
+	engine      consensus.Engine
 	blockchain  *core.BlockChain
 	chaindb     ethdb.Database
 	chainconfig *params.ChainConfig
@@ -85,6 +93,8 @@ type ProtocolManager struct {
 	eventMux      *event.TypeMux
 	txCh          chan core.TxPreEvent
 	txSub         event.Subscription
+	chainHeadCh   chan core.ChainHeadEvent
+	chainHeadSub  event.Subscription
 	minedBlockSub *event.TypeMuxSubscription
 
 	// channels for fetcher, syncer, txsyncLoop
@@ -106,6 +116,7 @@ func NewProtocolManager(config *params.ChainConfig, mode downloader.SyncMode, ne
 		networkId:   networkId,
 		eventMux:    mux,
 		txpool:      txpool,
+		engine:      engine,
 		blockchain:  blockchain,
 		chaindb:     chaindb,
 		chainconfig: config,
@@ -214,6 +225,11 @@ func (pm *ProtocolManager) Start(maxPeers int) {
 	pm.txSub = pm.txpool.SubscribeTxPreEvent(pm.txCh)
 	go pm.txBroadcastLoop()
 
+	// forward private transactions to the validators coming in turn
+	pm.chainHeadCh = make(chan core.ChainHeadEvent, chainHeadChanSize)
+	pm.chainHeadSub = pm.blockchain.SubscribeChainHeadEvent(pm.chainHeadCh)
+	go pm.privateTxLoop()
+
 	// broadcast mined blocks
 	pm.minedBlockSub = pm.eventMux.Subscribe(core.NewMinedBlockEvent{})
 	go pm.minedBroadcastLoop()
@@ -227,6 +243,7 @@ func (pm *ProtocolManager) Stop() {
 	log.Info("Stopping Ethereum protocol")
 
 	pm.txSub.Unsubscribe()         // quits txBroadcastLoop
+	pm.chainHeadSub.Unsubscribe()  // quits privateTxLoop
 	pm.minedBlockSub.Unsubscribe() // quits blockBroadcastLoop
 
 	// Quit the sync loop.
@@ -687,6 +704,26 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 		}
 		pm.txpool.AddRemotes(txs)
 
+	case p.version >= eth64 && msg.Code == PrivateTxMsg:
+		// Private transactions arrived, handle them like the public ones but
+		// keep them out of the broadcast
+		if atomic.LoadUint32(&pm.acceptTxs) == 0 {
+			break
+		}
+		var txs []*core.PrivateTx
+		if err := msg.Decode(&txs); err != nil {
+			return errResp(ErrDecode, "msg %v: %v", msg, err)
+		}
+		for i, tx := range txs {
+			if tx == nil || tx.Tx == nil {
+				return errResp(ErrDecode, "private transaction %d is nil", i)
+			}
+			p.MarkTransaction(tx.Tx.Hash())
+			if err := pm.txpool.AddPrivate(tx.Tx, tx.MaxBlockNumber, false); err != nil {
+				log.Trace("Discarded private transaction", "hash", tx.Tx.Hash(), "err", err)
+			}
+		}
+
 	default:
 		return errResp(ErrInvalidMsgCode, "%v", msg.Code)
 	}
@@ -744,6 +781,42 @@ func (pm *ProtocolManager) BroadcastTx(hash common.Hash, tx *types.Transaction)
 	log.Trace("Broadcast transaction", "hash", hash, "recipients", len(peers))
 }
 
+// ForwardPrivateTxs sends private transactions to the peers of the validators
+// in turn for the next blocks, up to the last block each may be included in.
+func (pm *ProtocolManager) ForwardPrivateTxs(txs []*core.PrivateTx) {
+	engine, ok := pm.engine.(*tribe.Tribe)
+	if !ok || len(txs) == 0 {
+		return
+	}
+	head := pm.blockchain.CurrentHeader()
+	inturn, err := engine.InturnValidators(pm.blockchain, head, privateTxFanout)
+	if err != nil {
+		log.Warn("Failed to retrieve in-turn validators", "number", head.Number, "err", err)
+		return
+	}
+	// Batch the transactions of each validator peer
+	sends := make(map[*peer][]*core.PrivateTx)
+	for _, tx := range txs {
+		if tx.MaxBlockNumber <= head.Number.Uint64() {
+			continue
+		}
+		left := tx.MaxBlockNumber - head.Number.Uint64()
+		validators := make(map[common.Address]struct{})
+		for i := 0; i < len(inturn) && uint64(i) < left; i++ {
+			validators[inturn[i]] = struct{}{}
+		}
+		for _, peer := range pm.peers.ValidatorPeersWithoutTx(tx.Tx.Hash(), validators) {
+			sends[peer] = append(sends[peer], tx)
+		}
+	}
+	for peer, txs := range sends {
+		if err := peer.SendPrivateTransactions(txs); err != nil {
+			log.Debug("Failed to forward private transactions", "peer", peer.String(), "err", err)
+		}
+	}
+	log.Trace("Forwarded private transactions", "txs", len(txs), "recipients", len(sends))
+}
+
 // Mined broadcast loop
 func (self *ProtocolManager) minedBroadcastLoop() {
 	// automatically stops if unsubscribe
@@ -767,7 +840,12 @@ func (self *ProtocolManager) txBroadcastLoop() {
 	for {
 		select {
 		case event := <-self.txCh:
-			self.BroadcastTx(event.Tx.Hash(), event.Tx)
+			hash := event.Tx.Hash()
+			if number, private := self.txpool.Private(hash); private {
+				self.ForwardPrivateTxs([]*core.PrivateTx{{Tx: event.Tx, MaxBlockNumber: number}})
+				continue
+			}
+			self.BroadcastTx(hash, event.Tx)
 
 		// Err() channel will be closed when unsubscribing.
 		case <-self.txSub.Err():
@@ -776,6 +854,21 @@ func (self *ProtocolManager) txBroadcastLoop() {
 	}
 }
 
+// privateTxLoop forwards the private transactions to the validators coming in
+// turn with every new head.
+func (self *ProtocolManager) privateTxLoop() {
+	for {
+		select {
+		case <-self.chainHeadCh:
+			self.ForwardPrivateTxs(self.txpool.Privates())
+
+		// Err() channel will be closed when unsubscribing.
+		case <-self.chainHeadSub.Err():
+			return
+		}
+	}
+}
+
 // NodeInfo represents a short summary of the Ethereum sub-protocol metadata
 // known about the host peer.
 type NodeInfo struct {
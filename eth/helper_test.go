@@ -59,7 +59,7 @@ func newTestProtocolManager(mode downloader.SyncMode, blocks int, generator func
 			Alloc:  core.GenesisAlloc{testBank: {Balance: big.NewInt(1000000)}},
 		}
 		genesis       = gspec.MustCommit(db)
-		blockchain, _ = core.NewBlockChain(db, gspec.Config, engine, vm.Config{})
+		blockchain, _ = core.NewBlockChain(db, nil, gspec.Config, engine, vm.Config{})
 	)
 	chain, _ := core.GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, blocks, generator)
 	if _, err := blockchain.InsertChain(chain); err != nil {
@@ -108,6 +108,22 @@ func (p *testTxPool) AddRemotes(txs []*types.Transaction) []error {
 	return make([]error, len(txs))
 }
 
+// AddPrivate appends a transaction to the pool like a remote one, the fake pool
+// does not keep track of private transactions.
+func (p *testTxPool) AddPrivate(tx *types.Transaction, maxBlockNumber uint64, publish bool) error {
+	return p.AddRemotes([]*types.Transaction{tx})[0]
+}
+
+// Private reports every transaction of the fake pool as public.
+func (p *testTxPool) Private(hash common.Hash) (uint64, bool) {
+	return 0, false
+}
+
+// Privates returns no transactions, the fake pool has no private ones.
+func (p *testTxPool) Privates() []*core.PrivateTx {
+	return nil
+}
+
 // Pending returns all the transactions known to the pool
 func (p *testTxPool) Pending(excludeSigner bool) (map[common.Address]types.Transactions, error) {
 	p.lock.RLock()
@@ -20,7 +20,9 @@ import (
 	"errors"
 	"fmt"
 	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/core"
 	"github.com/MeshBoxTech/mesh-chain/core/types"
+	"github.com/MeshBoxTech/mesh-chain/crypto"
 	"github.com/MeshBoxTech/mesh-chain/p2p"
 	"github.com/MeshBoxTech/mesh-chain/rlp"
 	mapset "github.com/deckarep/golang-set"
@@ -138,6 +140,15 @@ func (p *peer) SendTransactions(txs types.Transactions) error {
 	return p2p.Send(p.rw, TxMsg, txs)
 }
 
+// SendPrivateTransactions sends private transactions to the peer and includes
+// the hashes in its transaction hash set for future reference.
+func (p *peer) SendPrivateTransactions(txs []*core.PrivateTx) error {
+	for _, tx := range txs {
+		p.knownTxs.Add(tx.Tx.Hash())
+	}
+	return p2p.Send(p.rw, PrivateTxMsg, txs)
+}
+
 // SendNewBlockHashes announces the availability of a number of blocks through
 // a hash notification.
 func (p *peer) SendNewBlockHashes(hashes []common.Hash, numbers []uint64) error {
@@ -386,6 +397,29 @@ func (ps *peerSet) PeersWithoutTx(hash common.Hash) []*peer {
 	return list
 }
 
+// ValidatorPeersWithoutTx retrieves a list of peers speaking eth/64 that do not
+// have a given transaction in their set of known hashes, and whose node key is
+// the one of a given validator.
+func (ps *peerSet) ValidatorPeersWithoutTx(hash common.Hash, validators map[common.Address]struct{}) []*peer {
+	ps.lock.RLock()
+	defer ps.lock.RUnlock()
+
+	list := make([]*peer, 0, len(validators))
+	for _, p := range ps.peers {
+		if p.version < eth64 || p.knownTxs.Contains(hash) {
+			continue
+		}
+		pub, err := p.ID().Pubkey()
+		if err != nil {
+			continue
+		}
+		if _, ok := validators[crypto.PubkeyToAddress(*pub)]; ok {
+			list = append(list, p)
+		}
+	}
+	return list
+}
+
 // BestPeer retrieves the known peer with the currently highest total difficulty.
 func (ps *peerSet) BestPeer() *peer {
 	ps.lock.RLock()
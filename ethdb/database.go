@@ -27,8 +27,8 @@ import (
 	"github.com/syndtr/goleveldb/leveldb"
 	"github.com/syndtr/goleveldb/leveldb/errors"
 	"github.com/syndtr/goleveldb/leveldb/filter"
-	"github.com/syndtr/goleveldb/leveldb/iterator"
 	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
 
 	gometrics "github.com/rcrowley/go-metrics"
 )
@@ -147,8 +147,29 @@ func (db *LDBDatabase) Delete(key []byte) error {
 	return db.db.Delete(key, nil)
 }
 
-func (db *LDBDatabase) NewIterator() iterator.Iterator {
-	return db.db.NewIterator(nil, nil)
+// NewIterator creates a binary-alphabetical iterator over a subset of database
+// content with a particular key prefix, starting at a particular initial key.
+func (db *LDBDatabase) NewIterator(prefix []byte, start []byte) Iterator {
+	return db.db.NewIterator(bytesPrefixRange(prefix, start), nil)
+}
+
+// Stat returns a particular internal stat of the database.
+func (db *LDBDatabase) Stat(property string) (string, error) {
+	return db.db.GetProperty(property)
+}
+
+// Compact flattens the underlying data store for the given key range. A nil start
+// is treated as a key before all keys and a nil limit as a key after all keys.
+func (db *LDBDatabase) Compact(start []byte, limit []byte) error {
+	return db.db.CompactRange(util.Range{Start: start, Limit: limit})
+}
+
+// bytesPrefixRange returns the key range that satisfies the given prefix and
+// starts at the given key relative to the prefix.
+func bytesPrefixRange(prefix, start []byte) *util.Range {
+	r := util.BytesPrefix(prefix)
+	r.Start = append(append([]byte{}, prefix...), start...)
+	return r
 }
 
 func (db *LDBDatabase) Close() {
@@ -292,6 +313,12 @@ func (b *ldbBatch) Put(key, value []byte) error {
 	return nil
 }
 
+func (b *ldbBatch) Delete(key []byte) error {
+	b.b.Delete(key)
+	b.size += len(key)
+	return nil
+}
+
 func (b *ldbBatch) Write() error {
 	return b.db.Write(b.b, nil)
 }
@@ -300,6 +327,44 @@ func (b *ldbBatch) ValueSize() int {
 	return b.size
 }
 
+func (b *ldbBatch) Reset() {
+	b.b.Reset()
+	b.size = 0
+}
+
+func (b *ldbBatch) Replay(w Writer) error {
+	replayer := &ldbReplayer{writer: w}
+	if err := b.b.Replay(replayer); err != nil {
+		return err
+	}
+	return replayer.failure
+}
+
+// ldbReplayer is a small wrapper to implement the correct replay methods, as
+// the LevelDB batch replay doesn't report errors.
+type ldbReplayer struct {
+	writer  Writer
+	failure error
+}
+
+// Put inserts the given value into the key-value data store.
+func (r *ldbReplayer) Put(key, value []byte) {
+	// If the replay already failed, stop executing ops
+	if r.failure != nil {
+		return
+	}
+	r.failure = r.writer.Put(key, value)
+}
+
+// Delete removes the key from the key-value data store.
+func (r *ldbReplayer) Delete(key []byte) {
+	// If the replay already failed, stop executing ops
+	if r.failure != nil {
+		return
+	}
+	r.failure = r.writer.Delete(key)
+}
+
 type table struct {
 	db     Database
 	prefix string
@@ -334,6 +399,65 @@ func (dt *table) Close() {
 	// Do nothing; don't close the underlying DB.
 }
 
+// NewIterator creates an iterator over the keys of the table with a particular
+// prefix, starting at a particular initial key. The table prefix is stripped
+// from the returned keys.
+func (dt *table) NewIterator(prefix []byte, start []byte) Iterator {
+	return &tableIterator{
+		iter:   dt.db.NewIterator(append([]byte(dt.prefix), prefix...), start),
+		prefix: dt.prefix,
+	}
+}
+
+// Stat returns a particular internal stat of the underlying database.
+func (dt *table) Stat(property string) (string, error) {
+	return dt.db.Stat(property)
+}
+
+// Compact flattens the underlying data store for the given key range within the
+// table. A nil start or limit is treated as the first or last key of the table.
+func (dt *table) Compact(start []byte, limit []byte) error {
+	r := util.BytesPrefix([]byte(dt.prefix))
+	start = append([]byte(dt.prefix), start...)
+	if limit != nil {
+		limit = append([]byte(dt.prefix), limit...)
+	} else {
+		limit = r.Limit
+	}
+	return dt.db.Compact(start, limit)
+}
+
+// tableIterator is a wrapper around a database iterator that strips the table
+// prefix from the keys.
+type tableIterator struct {
+	iter   Iterator
+	prefix string
+}
+
+func (it *tableIterator) Next() bool {
+	return it.iter.Next()
+}
+
+func (it *tableIterator) Error() error {
+	return it.iter.Error()
+}
+
+func (it *tableIterator) Key() []byte {
+	key := it.iter.Key()
+	if key == nil {
+		return nil
+	}
+	return key[len(it.prefix):]
+}
+
+func (it *tableIterator) Value() []byte {
+	return it.iter.Value()
+}
+
+func (it *tableIterator) Release() {
+	it.iter.Release()
+}
+
 type tableBatch struct {
 	batch  Batch
 	prefix string
@@ -352,6 +476,10 @@ func (tb *tableBatch) Put(key, value []byte) error {
 	return tb.batch.Put(append([]byte(tb.prefix), key...), value)
 }
 
+func (tb *tableBatch) Delete(key []byte) error {
+	return tb.batch.Delete(append([]byte(tb.prefix), key...))
+}
+
 func (tb *tableBatch) Write() error {
 	return tb.batch.Write()
 }
@@ -359,3 +487,28 @@ func (tb *tableBatch) Write() error {
 func (tb *tableBatch) ValueSize() int {
 	return tb.batch.ValueSize()
 }
+
+func (tb *tableBatch) Reset() {
+	tb.batch.Reset()
+}
+
+// Replay replays the batch contents into the given writer, with the table
+// prefix stripped from the keys.
+func (tb *tableBatch) Replay(w Writer) error {
+	return tb.batch.Replay(&tableReplayer{w: w, prefix: tb.prefix})
+}
+
+// tableReplayer is a wrapper around a batch replayer which truncates the added
+// prefix.
+type tableReplayer struct {
+	w      Writer
+	prefix string
+}
+
+func (r *tableReplayer) Put(key []byte, value []byte) error {
+	return r.w.Put(key[len(r.prefix):], value)
+}
+
+func (r *tableReplayer) Delete(key []byte) error {
+	return r.w.Delete(key[len(r.prefix):])
+}
@@ -190,3 +190,112 @@ func testParallelPutGet(db ethdb.Database, t *testing.T) {
 	}
 	pending.Wait()
 }
+
+func TestLDB_Iterator(t *testing.T) {
+	db, remove := newTestLDB()
+	defer remove()
+	testIterator(db, t)
+}
+
+func TestMemoryDB_Iterator(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	testIterator(db, t)
+}
+
+func TestTable_Iterator(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	db.Put([]byte("other"), []byte("value"))
+	testIterator(ethdb.NewTable(db, "tbl-"), t)
+}
+
+func testIterator(db ethdb.Database, t *testing.T) {
+	content := map[string]string{"1": "a", "2": "b", "3": "c", "10": "d", "11": "e", "20": "f"}
+	for key, val := range content {
+		if err := db.Put([]byte(key), []byte(val)); err != nil {
+			t.Fatalf("put failed: %v", err)
+		}
+	}
+	tests := []struct {
+		prefix, start string
+		keys          []string
+	}{
+		{"", "", []string{"1", "10", "11", "2", "20", "3"}},
+		{"1", "", []string{"1", "10", "11"}},
+		{"1", "0", []string{"10", "11"}},
+		{"1", "05", []string{"11"}},
+		{"", "2", []string{"2", "20", "3"}},
+		{"2", "1", []string{}},
+		{"4", "", []string{}},
+	}
+	for i, tt := range tests {
+		it := db.NewIterator([]byte(tt.prefix), []byte(tt.start))
+		keys := []string{}
+		for it.Next() {
+			if want := content[string(it.Key())]; string(it.Value()) != want {
+				t.Errorf("test %d: value mismatch for %q: have %q, want %q", i, it.Key(), it.Value(), want)
+			}
+			keys = append(keys, string(it.Key()))
+		}
+		if err := it.Error(); err != nil {
+			t.Errorf("test %d: iteration failed: %v", i, err)
+		}
+		it.Release()
+
+		if fmt.Sprint(keys) != fmt.Sprint(tt.keys) {
+			t.Errorf("test %d: keys mismatch: have %v, want %v", i, keys, tt.keys)
+		}
+	}
+	if err := db.Compact(nil, nil); err != nil {
+		t.Errorf("compaction failed: %v", err)
+	}
+}
+
+func TestLDB_Batch(t *testing.T) {
+	db, remove := newTestLDB()
+	defer remove()
+	testBatch(db, t)
+}
+
+func TestMemoryDB_Batch(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	testBatch(db, t)
+}
+
+func TestTable_Batch(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	testBatch(ethdb.NewTable(db, "tbl-"), t)
+}
+
+func testBatch(db ethdb.Database, t *testing.T) {
+	db.Put([]byte("deleted"), []byte("value"))
+
+	batch := db.NewBatch()
+	batch.Put([]byte("reset"), []byte("value"))
+	batch.Reset()
+	if size := batch.ValueSize(); size != 0 {
+		t.Fatalf("batch not empty after reset: %d", size)
+	}
+	batch.Put([]byte("added"), []byte("value"))
+	batch.Delete([]byte("deleted"))
+	if err := batch.Write(); err != nil {
+		t.Fatalf("batch write failed: %v", err)
+	}
+	if ok, _ := db.Has([]byte("reset")); ok {
+		t.Errorf("reset write applied")
+	}
+	if ok, _ := db.Has([]byte("added")); !ok {
+		t.Errorf("batched put missing")
+	}
+	if ok, _ := db.Has([]byte("deleted")); ok {
+		t.Errorf("batched delete missing")
+	}
+	// Replaying the batch into another database applies the same writes
+	replica, _ := ethdb.NewMemDatabase()
+	replica.Put([]byte("deleted"), []byte("value"))
+	if err := batch.Replay(replica); err != nil {
+		t.Fatalf("batch replay failed: %v", err)
+	}
+	if keys := replica.Keys(); len(keys) != 1 || string(keys[0]) != "added" {
+		t.Errorf("replayed keys mismatch: have %q, want [added]", keys)
+	}
+}
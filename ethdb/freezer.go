@@ -0,0 +1,195 @@
+// Copyright 2018 The mesh-chain Authors
+// This file is part of the mesh-chain library.
+//
+// The mesh-chain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The mesh-chain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the mesh-chain library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethdb
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync/atomic"
+
+	"github.com/MeshBoxTech/mesh-chain/log"
+	"github.com/MeshBoxTech/mesh-chain/metrics"
+)
+
+var (
+	// errUnknownTable is returned if the user attempts to read from a table that is
+	// not tracked by the freezer.
+	errUnknownTable = errors.New("unknown table")
+
+	// errIncompleteItems is returned if an append doesn't carry an item for every
+	// table of the freezer.
+	errIncompleteItems = errors.New("missing item for table")
+)
+
+// Freezer is an append-only store of immutable chain segments. It is made up of
+// a fixed set of tables, each holding one item per block, which are always kept
+// at the same length.
+type Freezer struct {
+	frozen uint64 // Number of blocks already frozen, accessed atomically
+
+	tables map[string]*freezerTable // Data tables for storing everything
+}
+
+// NewFreezer opens the freezer tables in the given directory, creating them if
+// they don't exist yet. The tables map gives the name of each table and whether
+// its items are stored without snappy compression.
+func NewFreezer(datadir string, namespace string, tables map[string]bool) (*Freezer, error) {
+	freezer := &Freezer{
+		tables: make(map[string]*freezerTable),
+	}
+	for name, noCompression := range tables {
+		readMeter := metrics.NewMeter(namespace + "ancient/" + name + "/read")
+		writeMeter := metrics.NewMeter(namespace + "ancient/" + name + "/write")
+
+		table, err := newTable(datadir, name, readMeter, writeMeter, noCompression)
+		if err != nil {
+			freezer.Close()
+			return nil, err
+		}
+		freezer.tables[name] = table
+	}
+	if err := freezer.repair(); err != nil {
+		freezer.Close()
+		return nil, err
+	}
+	log.Info("Opened ancient database", "database", datadir, "items", atomic.LoadUint64(&freezer.frozen))
+	return freezer, nil
+}
+
+// repair truncates all data tables to the length of the shortest one, dropping
+// any half-appended block left by an unclean shutdown.
+func (f *Freezer) repair() error {
+	min := uint64(math.MaxUint64)
+	for _, table := range f.tables {
+		if items := atomic.LoadUint64(&table.items); items < min {
+			min = items
+		}
+	}
+	if min == math.MaxUint64 {
+		min = 0
+	}
+	for _, table := range f.tables {
+		if err := table.truncate(min); err != nil {
+			return err
+		}
+	}
+	atomic.StoreUint64(&f.frozen, min)
+	return nil
+}
+
+// Close terminates the freezer and closes all its data files.
+func (f *Freezer) Close() error {
+	var errs []error
+	for _, table := range f.tables {
+		if err := table.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if errs != nil {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+// HasAncient returns an indicator whether the specified ancient data exists
+// in the freezer.
+func (f *Freezer) HasAncient(kind string, number uint64) (bool, error) {
+	if table := f.tables[kind]; table != nil {
+		return table.has(number), nil
+	}
+	return false, nil
+}
+
+// Ancient retrieves an ancient binary blob from the append-only immutable files.
+func (f *Freezer) Ancient(kind string, number uint64) ([]byte, error) {
+	if table := f.tables[kind]; table != nil {
+		return table.Retrieve(number)
+	}
+	return nil, errUnknownTable
+}
+
+// Ancients returns the number of blocks frozen into the ancient store.
+func (f *Freezer) Ancients() (uint64, error) {
+	return atomic.LoadUint64(&f.frozen), nil
+}
+
+// AncientSize returns the ancient size of the specified category.
+func (f *Freezer) AncientSize(kind string) (uint64, error) {
+	if table := f.tables[kind]; table != nil {
+		return table.size()
+	}
+	return 0, errUnknownTable
+}
+
+// AppendAncient injects the items of a single block into the immutable store,
+// one for every table. The block number must be the next one in sequence. If
+// any table rejects its item, all of them are rolled back to the previous length
+// so the tables never go out of sync.
+func (f *Freezer) AppendAncient(number uint64, items map[string][]byte) (err error) {
+	if frozen := atomic.LoadUint64(&f.frozen); frozen != number {
+		return errOutOrderInsertion
+	}
+	for name := range f.tables {
+		if _, ok := items[name]; !ok {
+			return fmt.Errorf("%v: %s", errIncompleteItems, name)
+		}
+	}
+	defer func() {
+		if err != nil {
+			if rerr := f.repair(); rerr != nil {
+				log.Crit("Failed to repair freezer", "err", rerr)
+			}
+			log.Error("Append ancient failed", "number", number, "err", err)
+		}
+	}()
+	for name, table := range f.tables {
+		if err := table.Append(number, items[name]); err != nil {
+			return err
+		}
+	}
+	atomic.AddUint64(&f.frozen, 1)
+	return nil
+}
+
+// TruncateAncients discards any recent data above the provided threshold number.
+func (f *Freezer) TruncateAncients(items uint64) error {
+	if atomic.LoadUint64(&f.frozen) <= items {
+		return nil
+	}
+	for _, table := range f.tables {
+		if err := table.truncate(items); err != nil {
+			return err
+		}
+	}
+	atomic.StoreUint64(&f.frozen, items)
+	return nil
+}
+
+// Sync flushes all data tables to disk.
+func (f *Freezer) Sync() error {
+	var errs []error
+	for _, table := range f.tables {
+		if err := table.Sync(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if errs != nil {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
@@ -0,0 +1,454 @@
+// Copyright 2018 The mesh-chain Authors
+// This file is part of the mesh-chain library.
+//
+// The mesh-chain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The mesh-chain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the mesh-chain library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethdb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"github.com/MeshBoxTech/mesh-chain/log"
+	"github.com/golang/snappy"
+	gometrics "github.com/rcrowley/go-metrics"
+)
+
+var (
+	// errClosed is returned if an operation attempts to read from or write to the
+	// freezer table after it has already been closed.
+	errClosed = errors.New("closed")
+
+	// errOutOfBounds is returned if the item requested is not contained within the
+	// freezer table.
+	errOutOfBounds = errors.New("out of bounds")
+
+	// errOutOrderInsertion is returned if the user attempts to inject out-of-order
+	// binary blobs into the freezer.
+	errOutOrderInsertion = errors.New("the append operation is out-order")
+)
+
+// indexEntrySize is the size of a single index entry: a 2 byte data file number
+// followed by the 4 byte offset within that file where the item ends.
+const indexEntrySize = 6
+
+// freezerTableSize is the maximum size of a single data file of a freezer table.
+const freezerTableSize = 2 * 1000 * 1000 * 1000
+
+type indexEntry struct {
+	filenum uint32 // stored as uint16 ( 2 bytes)
+	offset  uint32 // stored as uint32 ( 4 bytes)
+}
+
+func (i *indexEntry) unmarshalBinary(b []byte) {
+	i.filenum = uint32(binary.BigEndian.Uint16(b[:2]))
+	i.offset = binary.BigEndian.Uint32(b[2:6])
+}
+
+func (i *indexEntry) marshallBinary() []byte {
+	b := make([]byte, indexEntrySize)
+	binary.BigEndian.PutUint16(b[:2], uint16(i.filenum))
+	binary.BigEndian.PutUint32(b[2:6], i.offset)
+	return b
+}
+
+// freezerTable is an append-only table of binary blobs. The blobs are stored in
+// a sequence of data files capped at a maximum size, while a separate index file
+// records where each item ends. The first index entry is a zero sentinel, so the
+// boundaries of item n are the entries n and n+1.
+type freezerTable struct {
+	items uint64 // Number of items stored in the table, accessed atomically
+
+	noCompression bool   // Whether to skip snappy compression of the blobs
+	maxFileSize   uint32 // Maximum size of a data file before rolling over to the next
+	name          string // Name of the table, used in file names and logs
+	path          string // Directory holding the table files
+
+	index     *os.File            // File holding the item end offsets
+	files     map[uint32]*os.File // Open data files, keyed by file number
+	head      *os.File            // Data file currently being appended to
+	headId    uint32              // Number of the head data file
+	headBytes uint32              // Number of bytes written to the head file
+
+	readMeter  gometrics.Meter // Meter for measuring the effective amount of data read
+	writeMeter gometrics.Meter // Meter for measuring the effective amount of data written
+
+	logger log.Logger
+	lock   sync.RWMutex // Mutex protecting the data files and index from concurrent access
+}
+
+// newTable opens a freezer table with the default data file size limit.
+func newTable(path string, name string, readMeter, writeMeter gometrics.Meter, noCompression bool) (*freezerTable, error) {
+	return newCustomTable(path, name, readMeter, writeMeter, freezerTableSize, noCompression)
+}
+
+// newCustomTable opens a freezer table, creating the data and index files if
+// they don't exist yet, and repairs any inconsistency left by an unclean shutdown.
+func newCustomTable(path string, name string, readMeter, writeMeter gometrics.Meter, maxFileSize uint32, noCompression bool) (*freezerTable, error) {
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return nil, err
+	}
+	idxName := fmt.Sprintf("%s.ridx", name)
+	if !noCompression {
+		idxName = fmt.Sprintf("%s.cidx", name)
+	}
+	offsets, err := os.OpenFile(filepath.Join(path, idxName), os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	tab := &freezerTable{
+		noCompression: noCompression,
+		maxFileSize:   maxFileSize,
+		name:          name,
+		path:          path,
+		index:         offsets,
+		files:         make(map[uint32]*os.File),
+		readMeter:     readMeter,
+		writeMeter:    writeMeter,
+		logger:        log.New("table", name),
+	}
+	if err := tab.repair(); err != nil {
+		tab.Close()
+		return nil, err
+	}
+	return tab, nil
+}
+
+// repair cross checks the head of the index against the size of the head data
+// file, truncating whichever of them runs ahead of the other.
+func (t *freezerTable) repair() error {
+	buffer := make([]byte, indexEntrySize)
+
+	// Write the zero sentinel into a fresh index
+	stat, err := t.index.Stat()
+	if err != nil {
+		return err
+	}
+	if stat.Size() == 0 {
+		if _, err := t.index.Write(buffer); err != nil {
+			return err
+		}
+	}
+	// Drop any partially written trailing index entry
+	if stat, err = t.index.Stat(); err != nil {
+		return err
+	}
+	offsetsSize := stat.Size()
+	if overflow := offsetsSize % indexEntrySize; overflow != 0 {
+		offsetsSize -= overflow
+		if err := t.index.Truncate(offsetsSize); err != nil {
+			return err
+		}
+	}
+	var lastIndex indexEntry
+	if _, err := t.index.ReadAt(buffer, offsetsSize-indexEntrySize); err != nil {
+		return err
+	}
+	lastIndex.unmarshalBinary(buffer)
+
+	head, err := t.openFile(lastIndex.filenum)
+	if err != nil {
+		return err
+	}
+	if stat, err = head.Stat(); err != nil {
+		return err
+	}
+	contentSize := stat.Size()
+
+	// Keep truncating both files until they come in sync
+	contentExp := int64(lastIndex.offset)
+	for contentExp != contentSize {
+		if contentExp < contentSize {
+			t.logger.Warn("Truncating dangling head", "indexed", contentExp, "stored", contentSize)
+			if err := head.Truncate(contentExp); err != nil {
+				return err
+			}
+			contentSize = contentExp
+		}
+		if contentExp > contentSize {
+			t.logger.Warn("Truncating dangling indexes", "indexed", contentExp, "stored", contentSize)
+			offsetsSize -= indexEntrySize
+			if err := t.index.Truncate(offsetsSize); err != nil {
+				return err
+			}
+			if _, err := t.index.ReadAt(buffer, offsetsSize-indexEntrySize); err != nil {
+				return err
+			}
+			var newLastIndex indexEntry
+			newLastIndex.unmarshalBinary(buffer)
+
+			// The previous item may end in an earlier data file
+			if newLastIndex.filenum != lastIndex.filenum {
+				if head, err = t.openFile(newLastIndex.filenum); err != nil {
+					return err
+				}
+				if stat, err = head.Stat(); err != nil {
+					return err
+				}
+				contentSize = stat.Size()
+			}
+			lastIndex = newLastIndex
+			contentExp = int64(lastIndex.offset)
+		}
+	}
+	if err := t.index.Sync(); err != nil {
+		return err
+	}
+	if err := head.Sync(); err != nil {
+		return err
+	}
+	t.head, t.headId, t.headBytes = head, lastIndex.filenum, uint32(contentSize)
+	atomic.StoreUint64(&t.items, uint64(offsetsSize/indexEntrySize-1))
+
+	// Open all older data files for reading and drop any newer leftovers
+	for i := uint32(0); i < t.headId; i++ {
+		if _, err := t.openFile(i); err != nil {
+			return err
+		}
+	}
+	return t.removeFilesAfter(t.headId)
+}
+
+// fileName returns the name of the data file with the given number.
+func (t *freezerTable) fileName(num uint32) string {
+	if t.noCompression {
+		return fmt.Sprintf("%s.%04d.rdat", t.name, num)
+	}
+	return fmt.Sprintf("%s.%04d.cdat", t.name, num)
+}
+
+// openFile opens the data file with the given number, or returns the handle if
+// it's already open.
+func (t *freezerTable) openFile(num uint32) (*os.File, error) {
+	if f, exist := t.files[num]; exist {
+		return f, nil
+	}
+	f, err := os.OpenFile(filepath.Join(t.path, t.fileName(num)), os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	t.files[num] = f
+	return f, nil
+}
+
+// removeFilesAfter closes and deletes all data files numbered above num.
+func (t *freezerTable) removeFilesAfter(num uint32) error {
+	for i := num + 1; ; i++ {
+		if f, exist := t.files[i]; exist {
+			f.Close()
+			delete(t.files, i)
+		}
+		err := os.Remove(filepath.Join(t.path, t.fileName(i)))
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// truncate discards any items beyond the given number from the table.
+func (t *freezerTable) truncate(items uint64) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.index == nil {
+		return errClosed
+	}
+	existing := atomic.LoadUint64(&t.items)
+	if existing <= items {
+		return nil
+	}
+	t.logger.Warn("Truncating freezer table", "items", existing, "limit", items)
+	if err := t.index.Truncate(int64(items+1) * indexEntrySize); err != nil {
+		return err
+	}
+	buffer := make([]byte, indexEntrySize)
+	if _, err := t.index.ReadAt(buffer, int64(items)*indexEntrySize); err != nil {
+		return err
+	}
+	var expected indexEntry
+	expected.unmarshalBinary(buffer)
+
+	// The new head item may reside in an earlier data file
+	if expected.filenum != t.headId {
+		head, err := t.openFile(expected.filenum)
+		if err != nil {
+			return err
+		}
+		if err := t.removeFilesAfter(expected.filenum); err != nil {
+			return err
+		}
+		t.head, t.headId = head, expected.filenum
+	}
+	if err := t.head.Truncate(int64(expected.offset)); err != nil {
+		return err
+	}
+	t.headBytes = expected.offset
+	atomic.StoreUint64(&t.items, items)
+	return nil
+}
+
+// Close closes all open files of the table.
+func (t *freezerTable) Close() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	var errs []error
+	if t.index != nil {
+		if err := t.index.Close(); err != nil {
+			errs = append(errs, err)
+		}
+		t.index = nil
+	}
+	for num, f := range t.files {
+		if err := f.Close(); err != nil {
+			errs = append(errs, err)
+		}
+		delete(t.files, num)
+	}
+	t.head = nil
+
+	if errs != nil {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+// Append injects a binary blob at the end of the freezer table. The item number
+// must be the next one in sequence, otherwise the append is rejected.
+func (t *freezerTable) Append(item uint64, blob []byte) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.index == nil {
+		return errClosed
+	}
+	if atomic.LoadUint64(&t.items) != item {
+		return errOutOrderInsertion
+	}
+	if !t.noCompression {
+		blob = snappy.Encode(nil, blob)
+	}
+	// Roll over to a new data file if the item doesn't fit into the current one
+	bLen := uint32(len(blob))
+	if t.headBytes+bLen < bLen || t.headBytes+bLen > t.maxFileSize {
+		head, err := t.openFile(t.headId + 1)
+		if err != nil {
+			return err
+		}
+		t.head, t.headId, t.headBytes = head, t.headId+1, 0
+	}
+	if _, err := t.head.Write(blob); err != nil {
+		return err
+	}
+	t.headBytes += bLen
+
+	idx := indexEntry{filenum: t.headId, offset: t.headBytes}
+	if _, err := t.index.Write(idx.marshallBinary()); err != nil {
+		return err
+	}
+	t.writeMeter.Mark(int64(bLen + indexEntrySize))
+	atomic.AddUint64(&t.items, 1)
+	return nil
+}
+
+// Retrieve looks up the data offset of an item with the given number and
+// retrieves the raw binary blob from the data file.
+func (t *freezerTable) Retrieve(item uint64) ([]byte, error) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if t.index == nil {
+		return nil, errClosed
+	}
+	if atomic.LoadUint64(&t.items) <= item {
+		return nil, errOutOfBounds
+	}
+	buffer := make([]byte, 2*indexEntrySize)
+	if _, err := t.index.ReadAt(buffer, int64(item)*indexEntrySize); err != nil {
+		return nil, err
+	}
+	var start, end indexEntry
+	start.unmarshalBinary(buffer[:indexEntrySize])
+	end.unmarshalBinary(buffer[indexEntrySize:])
+
+	// An item starting a new data file begins at its very start
+	startOffset := start.offset
+	if start.filenum != end.filenum {
+		startOffset = 0
+	}
+	dataFile, exist := t.files[end.filenum]
+	if !exist {
+		return nil, fmt.Errorf("missing data file %d", end.filenum)
+	}
+	blob := make([]byte, end.offset-startOffset)
+	if _, err := dataFile.ReadAt(blob, int64(startOffset)); err != nil {
+		return nil, err
+	}
+	t.readMeter.Mark(int64(len(blob) + 2*indexEntrySize))
+
+	if t.noCompression {
+		return blob, nil
+	}
+	return snappy.Decode(nil, blob)
+}
+
+// has reports whether the item with the given number is contained in the table.
+func (t *freezerTable) has(number uint64) bool {
+	return atomic.LoadUint64(&t.items) > number
+}
+
+// size returns the total number of bytes stored in the data and index files.
+func (t *freezerTable) size() (uint64, error) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if t.index == nil {
+		return 0, errClosed
+	}
+	stat, err := t.index.Stat()
+	if err != nil {
+		return 0, err
+	}
+	total := uint64(stat.Size())
+	for _, f := range t.files {
+		stat, err := f.Stat()
+		if err != nil {
+			return 0, err
+		}
+		total += uint64(stat.Size())
+	}
+	return total, nil
+}
+
+// Sync pushes any pending data from memory out to disk.
+func (t *freezerTable) Sync() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.index == nil {
+		return errClosed
+	}
+	if err := t.index.Sync(); err != nil {
+		return err
+	}
+	return t.head.Sync()
+}
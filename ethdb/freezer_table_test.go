@@ -0,0 +1,231 @@
+// Copyright 2018 The mesh-chain Authors
+// This file is part of the mesh-chain library.
+//
+// The mesh-chain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The mesh-chain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the mesh-chain library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethdb
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MeshBoxTech/mesh-chain/metrics"
+)
+
+// getChunk returns a test blob of the given size filled with b.
+func getChunk(size int, b int) []byte {
+	return bytes.Repeat([]byte{byte(b)}, size)
+}
+
+func newTestTable(t *testing.T, dir string, noCompression bool) *freezerTable {
+	meter := metrics.NewMeter("test")
+	table, err := newCustomTable(dir, "test", meter, meter, 50, noCompression)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return table
+}
+
+func checkTableItems(t *testing.T, table *freezerTable, items int) {
+	if have := table.items; have != uint64(items) {
+		t.Fatalf("item count mismatch: have %d, want %d", have, items)
+	}
+	for i := 0; i < items; i++ {
+		blob, err := table.Retrieve(uint64(i))
+		if err != nil {
+			t.Fatalf("item %d: %v", i, err)
+		}
+		if want := getChunk(15, i); !bytes.Equal(blob, want) {
+			t.Fatalf("item %d: blob mismatch: have %x, want %x", i, blob, want)
+		}
+	}
+	if _, err := table.Retrieve(uint64(items)); err != errOutOfBounds {
+		t.Fatalf("item past the end: have error %v, want %v", err, errOutOfBounds)
+	}
+}
+
+func TestFreezerTableRoundtrip(t *testing.T) {
+	for _, noCompression := range []bool{false, true} {
+		dir, err := ioutil.TempDir("", "freezer")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+
+		table := newTestTable(t, dir, noCompression)
+		for i := 0; i < 255; i++ {
+			if err := table.Append(uint64(i), getChunk(15, i)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := table.Append(300, getChunk(15, 0)); err != errOutOrderInsertion {
+			t.Fatalf("out of order append: have error %v, want %v", err, errOutOrderInsertion)
+		}
+		checkTableItems(t, table, 255)
+		table.Close()
+
+		// Reopen the table and make sure everything is still there
+		table = newTestTable(t, dir, noCompression)
+		checkTableItems(t, table, 255)
+		if noCompression && table.headId == 0 {
+			t.Errorf("data files not rolled over")
+		}
+		table.Close()
+	}
+}
+
+func TestFreezerTableRepair(t *testing.T) {
+	dir, err := ioutil.TempDir("", "freezer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	table := newTestTable(t, dir, true)
+	for i := 0; i < 10; i++ {
+		if err := table.Append(uint64(i), getChunk(15, i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	head := filepath.Join(dir, table.fileName(table.headId))
+	table.Close()
+
+	// Crop the head data file, the index entries past it must be dropped
+	stat, err := os.Stat(head)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(head, stat.Size()-5); err != nil {
+		t.Fatal(err)
+	}
+	table = newTestTable(t, dir, true)
+	checkTableItems(t, table, 9)
+	if _, err := os.Stat(head); !os.IsNotExist(err) {
+		t.Errorf("emptied data file not removed: %v", err)
+	}
+	head = filepath.Join(dir, table.fileName(table.headId))
+	table.Close()
+
+	// Add a partial index entry and dangling data, both must be dropped
+	index, err := os.OpenFile(filepath.Join(dir, "test.ridx"), os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	index.Write([]byte{0, 0, 1})
+	index.Close()
+
+	data, err := os.OpenFile(head, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data.Write(getChunk(7, 0xff))
+	data.Close()
+
+	table = newTestTable(t, dir, true)
+	checkTableItems(t, table, 9)
+
+	// The repaired table must accept new items
+	if err := table.Append(9, getChunk(15, 9)); err != nil {
+		t.Fatal(err)
+	}
+	checkTableItems(t, table, 10)
+	table.Close()
+}
+
+func TestFreezerTableTruncate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "freezer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	table := newTestTable(t, dir, true)
+	for i := 0; i < 30; i++ {
+		if err := table.Append(uint64(i), getChunk(15, i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Truncate back across several data files
+	if err := table.truncate(4); err != nil {
+		t.Fatal(err)
+	}
+	checkTableItems(t, table, 4)
+	if _, err := os.Stat(filepath.Join(dir, table.fileName(table.headId+1))); !os.IsNotExist(err) {
+		t.Errorf("data file past the head not removed: %v", err)
+	}
+	for i := 4; i < 8; i++ {
+		if err := table.Append(uint64(i), getChunk(15, i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	table.Close()
+
+	table = newTestTable(t, dir, true)
+	checkTableItems(t, table, 8)
+	table.Close()
+}
+
+func TestFreezerRepair(t *testing.T) {
+	dir, err := ioutil.TempDir("", "freezer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tables := map[string]bool{"a": true, "b": false}
+	freezer, err := NewFreezer(dir, "", tables)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		if err := freezer.AppendAncient(uint64(i), map[string][]byte{"a": getChunk(15, i), "b": getChunk(15, i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := freezer.AppendAncient(5, map[string][]byte{"a": getChunk(15, 5)}); err == nil {
+		t.Fatal("incomplete append accepted")
+	}
+	// Simulate a crash between two tables being appended to
+	if err := freezer.tables["a"].Append(5, getChunk(15, 5)); err != nil {
+		t.Fatal(err)
+	}
+	freezer.Close()
+
+	if freezer, err = NewFreezer(dir, "", tables); err != nil {
+		t.Fatal(err)
+	}
+	defer freezer.Close()
+
+	if frozen, _ := freezer.Ancients(); frozen != 5 {
+		t.Fatalf("frozen count mismatch: have %d, want 5", frozen)
+	}
+	for _, kind := range []string{"a", "b"} {
+		if ok, _ := freezer.HasAncient(kind, 5); ok {
+			t.Errorf("table %s: dangling item kept", kind)
+		}
+		blob, err := freezer.Ancient(kind, 4)
+		if err != nil || !bytes.Equal(blob, getChunk(15, 4)) {
+			t.Errorf("table %s: item mismatch: have %x, %v", kind, blob, err)
+		}
+	}
+	if err := freezer.TruncateAncients(2); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := freezer.HasAncient("b", 2); ok {
+		t.Errorf("truncated item kept")
+	}
+}
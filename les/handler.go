@@ -214,6 +214,18 @@ func NewProtocolManager(chainConfig *params.ChainConfig, lightSync bool, protoco
 }
 
 // removePeer initiates disconnection from a peer by removing it from the peer set
+// stateDatabase returns the database the served state is read from. Full nodes
+// keep the tries of the recent blocks in memory, so they are read through the
+// chain's trie database.
+func (pm *ProtocolManager) stateDatabase() trie.Database {
+	if bc, ok := pm.blockchain.(interface {
+		StateCache() state.Database
+	}); ok {
+		return bc.StateCache().TrieDB()
+	}
+	return pm.chainDb
+}
+
 func (pm *ProtocolManager) removePeer(id string) {
 	pm.peers.Unregister(id)
 }
@@ -569,11 +581,11 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 		for _, req := range req.Reqs {
 			// Retrieve the requested state entry, stopping if enough was found
 			if header := core.GetHeader(pm.chainDb, req.BHash, core.GetBlockNumber(pm.chainDb, req.BHash)); header != nil {
-				if trie, _ := trie.New(header.Root, pm.chainDb); trie != nil {
+				if trie, _ := trie.New(header.Root, pm.stateDatabase()); trie != nil {
 					sdata := trie.Get(req.AccKey)
 					var acc state.Account
 					if err := rlp.DecodeBytes(sdata, &acc); err == nil {
-						entry, _ := pm.chainDb.Get(acc.CodeHash)
+						entry, _ := pm.stateDatabase().Get(acc.CodeHash)
 						if bytes+len(entry) >= softResponseLimit {
 							break
 						}
@@ -696,13 +708,13 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 			}
 			// Retrieve the requested state entry, stopping if enough was found
 			if header := core.GetHeader(pm.chainDb, req.BHash, core.GetBlockNumber(pm.chainDb, req.BHash)); header != nil {
-				if tr, _ := trie.New(header.Root, pm.chainDb); tr != nil {
+				if tr, _ := trie.New(header.Root, pm.stateDatabase()); tr != nil {
 					if len(req.AccKey) > 0 {
 						sdata := tr.Get(req.AccKey)
 						tr = nil
 						var acc state.Account
 						if err := rlp.DecodeBytes(sdata, &acc); err == nil {
-							tr, _ = trie.New(acc.Root, pm.chainDb)
+							tr, _ = trie.New(acc.Root, pm.stateDatabase())
 						}
 					}
 					if tr != nil {
@@ -747,7 +759,7 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 			}
 			if tr == nil || req.BHash != lastBHash {
 				if header := core.GetHeader(pm.chainDb, req.BHash, core.GetBlockNumber(pm.chainDb, req.BHash)); header != nil {
-					tr, _ = trie.New(header.Root, pm.chainDb)
+					tr, _ = trie.New(header.Root, pm.stateDatabase())
 				} else {
 					tr = nil
 				}
@@ -761,7 +773,7 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 						str = nil
 						var acc state.Account
 						if err := rlp.DecodeBytes(sdata, &acc); err == nil {
-							str, _ = trie.New(acc.Root, pm.chainDb)
+							str, _ = trie.New(acc.Root, pm.stateDatabase())
 						}
 						lastAccKey = common.CopyBytes(req.AccKey)
 					}
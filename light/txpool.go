@@ -19,6 +19,7 @@ package light
 import (
 	"context"
 	"fmt"
+	"math/big"
 	"sync"
 	"time"
 
@@ -67,6 +68,9 @@ type TxPool struct {
 	clearIdx     uint64                               // earliest block nr that can contain mined tx info
 
 	homestead bool
+	eip2718   bool // Fork indicator whether we are using EIP-2718 type transactions.
+	eip1559   bool // Fork indicator whether we are using EIP-1559 type transactions.
+	sponsored bool // Fork indicator whether we are accepting sponsored transactions.
 }
 
 // TxRelayBackend provides an interface to the mechanism that forwards transacions
@@ -308,6 +312,9 @@ func (pool *TxPool) setNewHead(head *types.Header) {
 	m, r := txc.getLists()
 	pool.relay.NewHead(pool.head, m, r)
 	pool.homestead = pool.config.IsHomestead(head.Number)
+	pool.eip2718 = pool.config.IsBerlin(new(big.Int).Add(head.Number, big.NewInt(1)))
+	pool.eip1559 = pool.config.IsLondon(new(big.Int).Add(head.Number, big.NewInt(1)))
+	pool.sponsored = pool.config.IsSponsor(new(big.Int).Add(head.Number, big.NewInt(1)))
 	pool.signer = types.MakeSigner(pool.config, head.Number)
 }
 
@@ -343,6 +350,22 @@ func (pool *TxPool) validateTx(ctx context.Context, tx *types.Transaction) error
 		from common.Address
 		err  error
 	)
+	// Accept only legacy transactions until EIP-2718/2930 activates.
+	if !pool.eip2718 && tx.Type() != types.LegacyTxType {
+		return core.ErrTxTypeNotSupported
+	}
+	// Accept dynamic fee transactions only once the fee market activates.
+	if !pool.eip1559 && tx.Type() == types.DynamicFeeTxType {
+		return core.ErrTxTypeNotSupported
+	}
+	// Accept sponsored transactions only once their fork activates.
+	if !pool.sponsored && tx.Type() == types.SponsoredTxType {
+		return core.ErrTxTypeNotSupported
+	}
+	// Ensure the tip does not exceed the fee cap.
+	if tx.GasFeeCap().Cmp(tx.GasTipCap()) < 0 {
+		return core.ErrTipAboveFeeCap
+	}
 
 	// Validate the transaction sender and it's sig. Throw
 	// if the from fields is invalid.
@@ -375,8 +398,22 @@ func (pool *TxPool) validateTx(ctx context.Context, tx *types.Transaction) error
 		return core.ErrInsufficientFunds
 	}
 
+	// The sponsor should be allowed for the destination and cover the gas
+	if tx.Type() == types.SponsoredTxType {
+		sponsor, err := types.Sponsor(pool.signer, tx)
+		if err != nil {
+			return core.ErrInvalidSponsor
+		}
+		if !pool.config.Sponsor.Allowed(new(big.Int).Add(header.Number, big.NewInt(1)), sponsor, tx.To()) {
+			return core.ErrSponsorNotAllowed
+		}
+		if b := currentState.GetBalance(sponsor); b.Cmp(tx.GasCost()) < 0 {
+			return core.ErrInsufficientSponsorFunds
+		}
+	}
+
 	// Should supply enough intrinsic gas
-	if tx.Gas().Cmp(core.IntrinsicGas(tx.Data(), tx.To() == nil, pool.homestead)) < 0 {
+	if tx.Gas().Cmp(core.IntrinsicGas(tx.Data(), tx.AccessList(), tx.To() == nil, pool.homestead)) < 0 {
 		return core.ErrIntrinsicGas
 	}
 
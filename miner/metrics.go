@@ -0,0 +1,31 @@
+// Copyright 2018 The mesh-chain Authors
+// This file is part of the mesh-chain library.
+//
+// The mesh-chain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The mesh-chain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the mesh-chain library. If not, see <http://www.gnu.org/licenses/>.
+
+// Contains the metrics collected by the miner.
+
+package miner
+
+import (
+	"github.com/MeshBoxTech/mesh-chain/metrics"
+)
+
+var (
+	buildTimer          = metrics.NewTimer("miner/build")           // Time spent assembling a block to seal
+	recommitMeter       = metrics.NewMeter("miner/recommit")        // Pending blocks rebuilt with newly arrived transactions
+	interruptMeter      = metrics.NewMeter("miner/interrupt")       // Blocks cut short by the sealing deadline
+	includedGasMeter    = metrics.NewMeter("miner/gas")             // Gas included in locally sealed blocks
+	missedDeadlineMeter = metrics.NewMeter("miner/deadline/missed") // Blocks assembled after their sealing deadline
+)
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"github.com/MeshBoxTech/mesh-chain/common"
 	"github.com/MeshBoxTech/mesh-chain/consensus"
+	"github.com/MeshBoxTech/mesh-chain/consensus/misc"
 	"github.com/MeshBoxTech/mesh-chain/consensus/tribe"
 	"github.com/MeshBoxTech/mesh-chain/core"
 	"github.com/MeshBoxTech/mesh-chain/core/state"
@@ -47,6 +48,13 @@ const (
 	chainHeadChanSize = 10
 	// chainSideChanSize is the size of channel listening to ChainSideEvent.
 	chainSideChanSize = 10
+
+	// sealMargin is how long before the timestamp of a tribe block the worker
+	// stops adding transactions to it, leaving time to finalize and seal it.
+	sealMargin = time.Second
+	// recommitInterval is how often the pending tribe block is rebuilt while
+	// mining, to pick up transactions that arrived after it was assembled.
+	recommitInterval = 3 * time.Second
 )
 
 func appendToFailTx(txHash common.Hash) {
@@ -81,6 +89,8 @@ type Work struct {
 	receipts []*types.Receipt
 
 	createdAt time.Time
+	deadline  time.Time     // time by which transactions must be committed, zero if unbounded
+	elapsed   time.Duration // time it took to assemble the block
 }
 
 type Result struct {
@@ -253,6 +263,11 @@ func (self *worker) update() {
 	defer self.chainHeadSub.Unsubscribe()
 	defer self.chainSideSub.Unsubscribe()
 
+	recommit := time.NewTicker(recommitInterval)
+	defer recommit.Stop()
+
+	// dirty tracks whether transactions arrived since the pending block was built
+	dirty := false
 	for {
 		// A real event arrived, process interesting content
 		select {
@@ -260,6 +275,7 @@ func (self *worker) update() {
 		case <-self.chainHeadCh:
 			log.Debug("worker commitNewWork because of chainHeadCh")
 			self.commitNewWork()
+			dirty = false
 
 			// Handle ChainSideEvent
 		case ev := <-self.chainSideCh:
@@ -274,17 +290,26 @@ func (self *worker) update() {
 				self.currentMu.Lock()
 				acc, _ := types.Sender(self.current.signer, ev.Tx)
 				txs := map[common.Address]types.Transactions{acc: {ev.Tx}}
-				txset := types.NewTransactionsByPriceAndNonce(self.current.signer, txs)
+				txset := types.NewTransactionsByPriceAndNonce(self.current.signer, txs, self.current.header.BaseFee)
 				//fmt.Println("1111111",ev.Tx.Hash().Hex())
-				self.current.commitTransactions(self.mux, txset, self.chain, self.coinbase, nil)
+				self.current.commitTransactions(self.mux, txset, self.chain, self.coinbase, time.Time{})
 				self.currentMu.Unlock()
 			} else {
+				dirty = true
 				// If we're mining, but nothing is being processed, wake on new transactions
 				if self.config.Clique != nil && self.config.Clique.Period == 0 {
 					log.Debug("worker commitNewWork because of TxPreEvent")
 					self.commitNewWork()
 				}
 			}
+			// Rebuild the pending block if new transactions arrived in the meantime
+		case <-recommit.C:
+			if dirty && self.shouldRecommit() {
+				log.Debug("worker commitNewWork because of recommit")
+				recommitMeter.Mark(1)
+				self.commitNewWork()
+				dirty = false
+			}
 			// System stopped
 		case <-self.txSub.Err():
 			return
@@ -296,6 +321,22 @@ func (self *worker) update() {
 	}
 }
 
+// shouldRecommit reports whether the block being sealed should be rebuilt,
+// which is only the case while mining with a sealing deadline that leaves
+// enough time to assemble the block again.
+func (self *worker) shouldRecommit() bool {
+	if atomic.LoadInt32(&self.mining) == 0 {
+		return false
+	}
+	self.currentMu.Lock()
+	defer self.currentMu.Unlock()
+
+	if self.current == nil || self.current.deadline.IsZero() {
+		return false
+	}
+	return self.current.deadline.Sub(time.Now()) > self.current.elapsed+sealMargin
+}
+
 func (self *worker) wait() {
 	for {
 		mustCommitNewWork := true
@@ -341,6 +382,7 @@ func (self *worker) wait() {
 
 			// Insert the block into the set of pending ones to wait for confirmations
 			self.unconfirmed.Insert(block.NumberU64(), block.Hash())
+			includedGasMeter.Mark(block.GasUsed().Int64())
 
 			if mustCommitNewWork {
 				self.commitNewWork()
@@ -371,7 +413,7 @@ func (self *worker) makeCurrent(parent *types.Block, header *types.Header) error
 	}
 	work := &Work{
 		config:    self.config,
-		signer:    types.NewEIP155Signer(self.config.ChainId),
+		signer:    types.LatestSigner(self.config),
 		state:     state,
 		ancestors: mapset.NewSet(),
 		family:    mapset.NewSet(),
@@ -427,6 +469,10 @@ func (self *worker) commitNewWork() {
 		Extra:      self.extra,
 		Time:       big.NewInt(tstamp),
 	}
+	// Set the base fee of the block once the fee market is active
+	if self.config.IsLondon(header.Number) {
+		header.BaseFee = misc.CalcBaseFee(self.config, parent.Header())
+	}
 
 	// Only set the coinbase if we are mining (avoid spurious block rewards)
 	if atomic.LoadInt32(&self.mining) == 1 {
@@ -445,14 +491,40 @@ func (self *worker) commitNewWork() {
 	}
 	// Create the current work task and check any fork transitions needed
 	work := self.current
+	misc.ApplyEvidenceFork(self.config, header.Number, work.state)
+
+	// Tribe seals the block at its timestamp, which includes the backoff of
+	// validators out of turn, transactions must be in well before that. An
+	// overdue block still gets a short window to include transactions.
+	if _, ok := self.engine.(*tribe.Tribe); ok && atomic.LoadInt32(&self.mining) == 1 {
+		work.deadline = sealDeadline(header, tstart)
+	}
 
 	pending, err := self.eth.TxPool().Pending()
 	if err != nil {
 		log.Error("Failed to fetch pending transactions", "err", err)
 		return
 	}
-	txs := types.NewTransactionsByPriceAndNonce(self.current.signer, pending)
-	work.commitTransactions(self.mux, txs, self.chain, self.coinbase, header)
+	txs := types.NewTransactionsByPriceAndNonce(self.current.signer, pending, header.BaseFee)
+	work.commitTransactions(self.mux, txs, self.chain, self.coinbase, work.deadline)
+
+	// Submit any double-sign evidence after the pool transactions, so that it
+	// takes the nonces following the node key transactions already included
+	if tribe, ok := self.engine.(*tribe.Tribe); ok && atomic.LoadInt32(&self.mining) == 1 {
+		evidence, err := tribe.EvidenceTransactions(self.chain, header, work.state)
+		if err != nil {
+			log.Error("Failed to assemble double-sign evidence", "err", err)
+		}
+		gp := new(core.GasPool).AddGas(new(big.Int).Sub(header.GasLimit, header.GasUsed))
+		for _, tx := range evidence {
+			work.state.Prepare(tx.Hash(), common.Hash{}, work.tcount)
+			if err, _ := work.commitTransaction(tx, self.chain, self.coinbase, gp); err != nil {
+				log.Error("Failed to submit double-sign evidence", "hash", tx.Hash(), "err", err)
+				continue
+			}
+			work.tcount++
+		}
+	}
 
 	// compute uncles for the new block.
 	var (
@@ -482,13 +554,31 @@ func (self *worker) commitNewWork() {
 		return
 	}
 	// We only care about logging if we're actually mining.
+	work.elapsed = time.Since(tstart)
 	if atomic.LoadInt32(&self.mining) == 1 {
-		log.Debug("Commit new mining work", "number", work.Block.Number(), "txs", work.tcount, "uncles", len(uncles), "elapsed", common.PrettyDuration(time.Since(tstart)))
+		log.Debug("Commit new mining work", "number", work.Block.Number(), "txs", work.tcount, "uncles", len(uncles), "elapsed", common.PrettyDuration(work.elapsed))
 		self.unconfirmed.Shift(work.Block.NumberU64() - 1)
+
+		buildTimer.Update(work.elapsed)
+		if !work.deadline.IsZero() && time.Now().After(work.deadline.Add(sealMargin)) {
+			log.Warn("Sealing deadline missed", "number", work.Block.Number(), "txs", work.tcount, "elapsed", common.PrettyDuration(work.elapsed))
+			missedDeadlineMeter.Mark(1)
+		}
 	}
 	self.push(work)
 }
 
+// sealDeadline returns the time by which the transactions of a tribe block
+// assembled from start must be committed, a margin ahead of its timestamp but
+// never less than a margin after start.
+func sealDeadline(header *types.Header, start time.Time) time.Time {
+	deadline := time.Unix(header.Time.Int64(), 0).Add(-sealMargin)
+	if min := start.Add(sealMargin); deadline.Before(min) {
+		return min
+	}
+	return deadline
+}
+
 func (self *worker) commitUncle(work *Work, uncle *types.Header) error {
 	hash := uncle.Hash()
 	if work.uncles.Contains(hash) {
@@ -504,23 +594,23 @@ func (self *worker) commitUncle(work *Work, uncle *types.Header) error {
 	return nil
 }
 
-func (env *Work) commitTransactions(mux *event.TypeMux, txs *types.TransactionsByPriceAndNonce, bc *core.BlockChain, coinbase common.Address, currentHeader *types.Header) {
-	gp := new(core.GasPool).AddGas(env.header.GasLimit)
+// commitTransactions applies transactions from txs to the work until the block
+// is full, the set is exhausted or, if non-zero, the deadline is reached.
+func (env *Work) commitTransactions(mux *event.TypeMux, txs *types.TransactionsByPriceAndNonce, bc *core.BlockChain, coinbase common.Address, deadline time.Time) {
+	gp := new(core.GasPool).AddGas(new(big.Int).Sub(env.header.GasLimit, env.header.GasUsed))
 
 	var coalescedLogs []*types.Log
-	var timeout time.Time
-	if currentHeader != nil {
-		timeout = time.Unix(currentHeader.Time.Int64(), 0)
-	}
 	for {
 		// Retrieve the next transaction and abort if all done
 		tx := txs.Peek()
 		if tx == nil {
 			break
 		}
-		log.Debug(fmt.Sprintf("timeout=%s", timeout))
-		//there may be too many transactions, thus missing the block time
-		if currentHeader != nil && timeout.Before(time.Now()) && len(env.txs) > 0 {
+		// There may be too many transactions to execute before the block is
+		// sealed, leave the rest for the next block
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			log.Debug("Sealing deadline reached, interrupting block", "number", env.header.Number, "txs", env.tcount)
+			interruptMeter.Mark(1)
 			break
 		}
 		// Error may be ignored here. The error has already been checked
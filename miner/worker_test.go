@@ -0,0 +1,108 @@
+// Copyright 2018 The mesh-chain Authors
+// This file is part of the mesh-chain library.
+//
+// The mesh-chain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The mesh-chain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the mesh-chain library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/core/state"
+	"github.com/MeshBoxTech/mesh-chain/core/types"
+	"github.com/MeshBoxTech/mesh-chain/crypto"
+	"github.com/MeshBoxTech/mesh-chain/ethdb"
+	"github.com/MeshBoxTech/mesh-chain/params"
+)
+
+// Tests that transactions must be in a margin before the block timestamp, but
+// that an overdue block still gets a margin to include some.
+func TestSealDeadline(t *testing.T) {
+	start := time.Unix(1000, 0)
+
+	tests := []struct {
+		timestamp int64
+		deadline  time.Time
+	}{
+		{1010, time.Unix(1010, 0).Add(-sealMargin)},
+		{1002, time.Unix(1002, 0).Add(-sealMargin)},
+		{1001, start.Add(sealMargin)},
+		{1000, start.Add(sealMargin)},
+		{990, start.Add(sealMargin)},
+	}
+	for i, tt := range tests {
+		header := &types.Header{Time: big.NewInt(tt.timestamp)}
+		if deadline := sealDeadline(header, start); !deadline.Equal(tt.deadline) {
+			t.Errorf("test %d: deadline mismatch: have %v, want %v", i, deadline, tt.deadline)
+		}
+	}
+}
+
+// Tests that the pending block is only rebuilt while mining if there's enough
+// time left to assemble it again before its sealing deadline.
+func TestShouldRecommit(t *testing.T) {
+	tests := []struct {
+		mining bool
+		work   *Work
+		want   bool
+	}{
+		{false, &Work{deadline: time.Now().Add(time.Minute), elapsed: time.Millisecond}, false},
+		{true, nil, false},
+		{true, &Work{elapsed: time.Millisecond}, false},
+		{true, &Work{deadline: time.Now().Add(time.Minute), elapsed: time.Millisecond}, true},
+		{true, &Work{deadline: time.Now().Add(time.Minute), elapsed: time.Minute}, false},
+		{true, &Work{deadline: time.Now().Add(sealMargin / 2), elapsed: time.Millisecond}, false},
+		{true, &Work{deadline: time.Now().Add(-time.Second), elapsed: time.Millisecond}, false},
+	}
+	for i, tt := range tests {
+		w := &worker{current: tt.work}
+		if tt.mining {
+			w.mining = 1
+		}
+		if have := w.shouldRecommit(); have != tt.want {
+			t.Errorf("test %d: recommit mismatch: have %v, want %v", i, have, tt.want)
+		}
+	}
+}
+
+// Tests that no transactions are committed past the sealing deadline.
+func TestCommitTransactionsDeadline(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+
+	db, _ := ethdb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	statedb.AddBalance(from, big.NewInt(params.Ether))
+
+	signer := types.NewEIP155Signer(params.TestChainConfig.ChainId)
+	tx, _ := types.SignTx(types.NewTransaction(0, common.Address{}, big.NewInt(1), big.NewInt(21000), big.NewInt(1), nil), signer, key)
+	txs := types.NewTransactionsByPriceAndNonce(signer, map[common.Address]types.Transactions{from: {tx}}, nil)
+
+	work := &Work{
+		config: params.TestChainConfig,
+		signer: signer,
+		state:  statedb,
+		header: &types.Header{Number: big.NewInt(1), GasLimit: big.NewInt(8000000), GasUsed: new(big.Int), Time: big.NewInt(1000)},
+	}
+	work.commitTransactions(nil, txs, nil, common.Address{}, time.Now().Add(-time.Second))
+	if work.tcount != 0 || len(work.txs) != 0 {
+		t.Errorf("transactions committed past the deadline: %d", len(work.txs))
+	}
+	if txs.Peek() != tx {
+		t.Errorf("uncommitted transaction dropped from the set")
+	}
+}
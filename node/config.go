@@ -34,7 +34,6 @@ import (
 	"github.com/MeshBoxTech/mesh-chain/log"
 	"github.com/MeshBoxTech/mesh-chain/p2p"
 	"github.com/MeshBoxTech/mesh-chain/p2p/discover"
-	"github.com/MeshBoxTech/mesh-chain/params"
 )
 
 const (
@@ -74,6 +73,10 @@ type Config struct {
 	// add by liangc : for devnetwork
 	Devnet, DevReset, DevMaster bool
 
+	// Testnet selects the test network, whose data and IPC endpoint default to a
+	// folder of their own.
+	Testnet bool
+
 	// Configuration of peer-to-peer networking.
 	P2P p2p.Config
 
@@ -191,14 +194,12 @@ func DefaultIPCEndpointWithDir(dir, clientIdentifier string) string {
 	}
 
 	config := &Config{DataDir: dir, IPCPath: clientIdentifier + ".ipc"}
-	//if params.IsTestnet() {
-	//	config = &Config{DataDir: dir, IPCPath: clientIdentifier + ".ipc"}
-	//}
 	return config.IPCEndpoint()
 }
 
-// DefaultIPCEndpoint returns the IPC path used by default.
-func DefaultIPCEndpoint(clientIdentifier string) string {
+// DefaultIPCEndpoint returns the IPC path used by default on the main network,
+// or on the test network if testnet is set.
+func DefaultIPCEndpoint(clientIdentifier string, testnet bool) string {
 	if clientIdentifier == "" {
 		clientIdentifier = strings.TrimSuffix(filepath.Base(os.Args[0]), ".exe")
 		if clientIdentifier == "" {
@@ -207,17 +208,18 @@ func DefaultIPCEndpoint(clientIdentifier string) string {
 	}
 
 	datadir := DefaultDataDir()
-	if params.IsTestnet() {
+	if testnet {
 		datadir = TestDataDir()
 	}
 	config := &Config{DataDir: datadir, IPCPath: clientIdentifier + ".ipc"}
-	path := config.IPCEndpoint()
-	return path
+	return config.IPCEndpoint()
 }
 
-func DefaultNodekeyDir() string {
+// DefaultNodekeyDir returns the folder holding the node key of the main network,
+// or of the test network if testnet is set.
+func DefaultNodekeyDir(testnet bool) string {
 	home := DefaultDataDir()
-	if params.IsTestnet() {
+	if testnet {
 		home = TestDataDir()
 	}
 	return filepath.Join(home, "smc")
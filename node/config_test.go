@@ -93,6 +93,20 @@ func TestIPCPathResolution(t *testing.T) {
 	}
 }
 
+// Tests that the default IPC endpoint of the test network is kept apart from the
+// main network one.
+func TestDefaultIPCEndpoint(t *testing.T) {
+	if runtime.GOOS == "windows" || DefaultDataDir() == "" {
+		t.Skip("no IPC endpoint in the data directory")
+	}
+	if have, want := DefaultIPCEndpoint("smc", false), filepath.Join(DefaultDataDir(), "smc.ipc"); have != want {
+		t.Errorf("main network endpoint mismatch: have %s, want %s", have, want)
+	}
+	if have, want := DefaultIPCEndpoint("smc", true), filepath.Join(TestDataDir(), "smc.ipc"); have != want {
+		t.Errorf("test network endpoint mismatch: have %s, want %s", have, want)
+	}
+}
+
 // Tests that node keys can be correctly created, persisted, loaded and/or made
 // ephemeral.
 func TestNodeKeyPersistency(t *testing.T) {
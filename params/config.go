@@ -17,10 +17,12 @@
 package params
 
 import (
+	"bytes"
 	"fmt"
 	"math/big"
 
 	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/common/hexutil"
 )
 
 var (
@@ -148,6 +150,10 @@ type ChainConfig struct {
 	EIP158Block *big.Int `json:"eip158Block,omitempty"` // EIP158 HF block
 
 	ByzantiumBlock *big.Int `json:"byzantiumBlock,omitempty"` // Byzantium switch block (nil = no fork, 0 = already on byzantium)
+	BerlinBlock    *big.Int `json:"berlinBlock,omitempty"`    // Berlin switch block: typed and access list transactions, access list gas (nil = no fork)
+	LondonBlock    *big.Int `json:"londonBlock,omitempty"`    // London switch block: header base fee, dynamic fee transactions, BASEFEE opcode (nil = no fork)
+
+	Sponsor *SponsorConfig `json:"sponsor,omitempty"` // Sponsored (fee delegated) transactions (nil = no fork)
 
 	// Various consensus engines
 	Ethash *EthashConfig `json:"ethash,omitempty"`
@@ -174,10 +180,88 @@ func (c *CliqueConfig) String() string {
 	return "clique"
 }
 
+// SponsorConfig enables sponsored (fee delegated) transactions, whose gas is
+// paid by a sponsor account instead of the sender. A sponsor only pays for
+// calls to the contracts it has been allowed for.
+type SponsorConfig struct {
+	Block      *big.Int            `json:"block"`                // Activation block of sponsored transactions
+	Allowances []*SponsorAllowance `json:"allowances,omitempty"` // Contracts sponsors pay for, new entries are appended
+}
+
+// SponsorAllowance allows a sponsor to pay for calls to the listed contracts
+// from the given block on.
+type SponsorAllowance struct {
+	Block     *big.Int         `json:"block"`     // Activation block of the allowance
+	Sponsor   common.Address   `json:"sponsor"`   // Account paying for the gas
+	Contracts []common.Address `json:"contracts"` // Contracts the sponsor pays for
+}
+
+// equal returns whether both allowances grant the same contracts to the same
+// sponsor at the same block.
+func (a *SponsorAllowance) equal(o *SponsorAllowance) bool {
+	if !configNumEqual(a.Block, o.Block) || a.Sponsor != o.Sponsor || len(a.Contracts) != len(o.Contracts) {
+		return false
+	}
+	for i, contract := range a.Contracts {
+		if o.Contracts[i] != contract {
+			return false
+		}
+	}
+	return true
+}
+
+// Allowed returns whether sponsor pays for calls to the contract at block num.
+// Contract creations are never sponsored.
+func (c *SponsorConfig) Allowed(num *big.Int, sponsor common.Address, to *common.Address) bool {
+	if to == nil || !isForked(c.Block, num) {
+		return false
+	}
+	for _, a := range c.Allowances {
+		if a.Sponsor != sponsor || !isForked(a.Block, num) {
+			continue
+		}
+		for _, contract := range a.Contracts {
+			if contract == *to {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // TribeConfig is the consensus engine configs.
 type TribeConfig struct {
 	Period uint64 `json:"period"` // Number of seconds between blocks to enforce
 	Epoch  uint64 `json:"epoch"`  // Epoch length to reset votes and checkpoint
+
+	VRFMixBlock  *big.Int `json:"vrfMixBlock,omitempty"`  // Every block carries a VRF over the accumulated randomness (nil = no fork)
+	RecentsBlock *big.Int `json:"recentsBlock,omitempty"` // Validators may seal at most once per len/2+1 blocks (nil = no fork)
+	PomFeeBlock  *big.Int `json:"pomFeeBlock,omitempty"`  // The base fee is credited in MESH to the POM reward pool instead of burned (nil = no fork)
+
+	EvidenceBlock  *big.Int      `json:"evidenceBlock,omitempty"`  // Double-sign evidence is verified and submitted to the Validators contract (nil = no fork)
+	ValidatorsCode hexutil.Bytes `json:"validatorsCode,omitempty"` // Validators contract code installed at the evidence fork block (empty = keep the deployed code)
+
+	Forks []*TribeFork `json:"forks,omitempty"` // Consensus parameter changes, ordered by activation block
+}
+
+// TribeFork is a scheduled change of the tribe consensus parameters. Fields
+// left zero keep the value in effect before the fork.
+type TribeFork struct {
+	Block           *big.Int `json:"block"`                     // Activation block of the new parameters
+	Period          uint64   `json:"period,omitempty"`          // Number of seconds between blocks to enforce
+	Epoch           uint64   `json:"epoch,omitempty"`           // Epoch length to reset votes and checkpoint
+	MaxValidators   uint64   `json:"maxValidators,omitempty"`   // Maximum number of validators per epoch
+	WiggleTime      uint64   `json:"wiggleTime,omitempty"`      // Seconds between consecutive out-of-turn validators
+	BackOffTime     uint64   `json:"backOffTime,omitempty"`     // Seconds an out-of-turn validator waits at least
+	Reward          *big.Int `json:"reward,omitempty"`          // Block reward in wei for the validator
+	HalvingInterval uint64   `json:"halvingInterval,omitempty"` // Number of blocks after which the reward halves
+}
+
+// equal returns whether both forks activate the same parameters at the same block.
+func (f *TribeFork) equal(o *TribeFork) bool {
+	return configNumEqual(f.Block, o.Block) && f.Period == o.Period && f.Epoch == o.Epoch &&
+		f.MaxValidators == o.MaxValidators && f.WiggleTime == o.WiggleTime && f.BackOffTime == o.BackOffTime &&
+		configNumEqual(f.Reward, o.Reward) && f.HalvingInterval == o.HalvingInterval
 }
 
 // String implements the stringer interface, returning the consensus engine details.
@@ -185,6 +269,79 @@ func (c *TribeConfig) String() string {
 	return "tribe"
 }
 
+// IsVRFMix returns whether num is either equal to the VRF mix fork block or greater.
+func (c *TribeConfig) IsVRFMix(num *big.Int) bool {
+	return isForked(c.VRFMixBlock, num)
+}
+
+// IsRecents returns whether num is either equal to the recent signer fork block or greater.
+func (c *TribeConfig) IsRecents(num *big.Int) bool {
+	return isForked(c.RecentsBlock, num)
+}
+
+// IsPomFee returns whether num is either equal to the POM base fee fork block or
+// greater, crediting the base fee of blocks to the POM reward pool.
+func (c *TribeConfig) IsPomFee(num *big.Int) bool {
+	return isForked(c.PomFeeBlock, num)
+}
+
+// IsEvidence returns whether num is either equal to the double-sign evidence
+// fork block or greater.
+func (c *TribeConfig) IsEvidence(num *big.Int) bool {
+	return isForked(c.EvidenceBlock, num)
+}
+
+// ForkAt returns the consensus parameters in effect at block num, accumulated
+// over all forks activated so far on top of the base Period and Epoch. Fields
+// no fork has set yet are left zero for the engine to default.
+func (c *TribeConfig) ForkAt(num *big.Int) *TribeFork {
+	fork := &TribeFork{Block: new(big.Int), Period: c.Period, Epoch: c.Epoch}
+	for _, f := range c.Forks {
+		if !isForked(f.Block, num) {
+			break
+		}
+		fork.Block = f.Block
+		if f.Period != 0 {
+			fork.Period = f.Period
+		}
+		if f.Epoch != 0 {
+			fork.Epoch = f.Epoch
+		}
+		if f.MaxValidators != 0 {
+			fork.MaxValidators = f.MaxValidators
+		}
+		if f.WiggleTime != 0 {
+			fork.WiggleTime = f.WiggleTime
+		}
+		if f.BackOffTime != 0 {
+			fork.BackOffTime = f.BackOffTime
+		}
+		if f.Reward != nil {
+			fork.Reward = f.Reward
+		}
+		if f.HalvingInterval != 0 {
+			fork.HalvingInterval = f.HalvingInterval
+		}
+	}
+	return fork
+}
+
+// CheckForkOrder ensures the tribe forks are scheduled at strictly increasing
+// blocks, as ForkAt relies on.
+func (c *TribeConfig) CheckForkOrder() error {
+	var last *big.Int
+	for i, f := range c.Forks {
+		if f.Block == nil {
+			return fmt.Errorf("tribe fork %d has no activation block", i)
+		}
+		if last != nil && f.Block.Cmp(last) <= 0 {
+			return fmt.Errorf("tribe fork %d at block %v not after block %v", i, f.Block, last)
+		}
+		last = f.Block
+	}
+	return nil
+}
+
 // String implements the fmt.Stringer interface.
 func (c *ChainConfig) String() string {
 	var engine interface{}
@@ -198,7 +355,11 @@ func (c *ChainConfig) String() string {
 	default:
 		engine = "unknown"
 	}
-	return fmt.Sprintf("{ChainID: %v Homestead: %v DAO: %v DAOSupport: %v EIP150: %v EIP155: %v EIP158: %v Byzantium: %v Engine: %v}",
+	var sponsor *big.Int
+	if c.Sponsor != nil {
+		sponsor = c.Sponsor.Block
+	}
+	return fmt.Sprintf("{ChainID: %v Homestead: %v DAO: %v DAOSupport: %v EIP150: %v EIP155: %v EIP158: %v Byzantium: %v Berlin: %v London: %v Sponsor: %v Engine: %v}",
 		c.ChainId,
 		c.HomesteadBlock,
 		c.DAOForkBlock,
@@ -207,6 +368,9 @@ func (c *ChainConfig) String() string {
 		c.EIP155Block,
 		c.EIP158Block,
 		c.ByzantiumBlock,
+		c.BerlinBlock,
+		c.LondonBlock,
+		sponsor,
 		engine,
 	)
 }
@@ -238,6 +402,22 @@ func (c *ChainConfig) IsByzantium(num *big.Int) bool {
 	return isForked(c.ByzantiumBlock, num)
 }
 
+// IsBerlin returns whether num is either equal to the Berlin fork block or greater.
+func (c *ChainConfig) IsBerlin(num *big.Int) bool {
+	return isForked(c.BerlinBlock, num)
+}
+
+// IsLondon returns whether num is either equal to the London fork block or greater.
+func (c *ChainConfig) IsLondon(num *big.Int) bool {
+	return isForked(c.LondonBlock, num)
+}
+
+// IsSponsor returns whether num is either equal to the sponsored transaction
+// fork block or greater.
+func (c *ChainConfig) IsSponsor(num *big.Int) bool {
+	return c.Sponsor != nil && isForked(c.Sponsor.Block, num)
+}
+
 // GasTable returns the gas table corresponding to the current phase (homestead or homestead reprice).
 //
 // The returned GasTable's fields shouldn't, under any circumstances, be changed.
@@ -255,6 +435,44 @@ func (c *ChainConfig) GasTable(num *big.Int) GasTable {
 	}
 }
 
+// CheckConfigForkOrder checks that the transaction forks are scheduled in the
+// order their signers build on each other, as MakeSigner and LatestSigner
+// assume: Berlin, then Sponsor, then London. The Sponsor fork may be left out,
+// but London can't be scheduled without Berlin.
+func (c *ChainConfig) CheckConfigForkOrder() error {
+	type fork struct {
+		name     string
+		block    *big.Int
+		optional bool
+	}
+	var sponsor *big.Int
+	if c.Sponsor != nil {
+		sponsor = c.Sponsor.Block
+	}
+	var last fork
+	for _, cur := range []fork{
+		{name: "berlinBlock", block: c.BerlinBlock},
+		{name: "sponsor.block", block: sponsor, optional: true},
+		{name: "londonBlock", block: c.LondonBlock},
+	} {
+		if last.name != "" {
+			if last.block == nil && cur.block != nil {
+				return fmt.Errorf("unsupported fork ordering: %v not enabled, but %v enabled at %v", last.name, cur.name, cur.block)
+			}
+			if last.block != nil && cur.block != nil && last.block.Cmp(cur.block) > 0 {
+				return fmt.Errorf("unsupported fork ordering: %v enabled at %v, but %v enabled at %v", last.name, last.block, cur.name, cur.block)
+			}
+		}
+		if !cur.optional || cur.block != nil {
+			last = cur
+		}
+	}
+	if c.Tribe != nil {
+		return c.Tribe.CheckForkOrder()
+	}
+	return nil
+}
+
 // CheckCompatible checks whether scheduled fork transitions have been imported
 // with a mismatching chain configuration.
 func (c *ChainConfig) CheckCompatible(newcfg *ChainConfig, height uint64) *ConfigCompatError {
@@ -298,6 +516,94 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int) *Confi
 	if isForkIncompatible(c.ByzantiumBlock, newcfg.ByzantiumBlock, head) {
 		return newCompatError("Byzantium fork block", c.ByzantiumBlock, newcfg.ByzantiumBlock)
 	}
+	if isForkIncompatible(c.BerlinBlock, newcfg.BerlinBlock, head) {
+		return newCompatError("Berlin fork block", c.BerlinBlock, newcfg.BerlinBlock)
+	}
+	if isForkIncompatible(c.LondonBlock, newcfg.LondonBlock, head) {
+		return newCompatError("London fork block", c.LondonBlock, newcfg.LondonBlock)
+	}
+	if err := checkSponsorCompatible(c.Sponsor, newcfg.Sponsor, head); err != nil {
+		return err
+	}
+	if c.Tribe != nil && newcfg.Tribe != nil {
+		if isForkIncompatible(c.Tribe.VRFMixBlock, newcfg.Tribe.VRFMixBlock, head) {
+			return newCompatError("Tribe VRF mix fork block", c.Tribe.VRFMixBlock, newcfg.Tribe.VRFMixBlock)
+		}
+		if isForkIncompatible(c.Tribe.RecentsBlock, newcfg.Tribe.RecentsBlock, head) {
+			return newCompatError("Tribe recent signers fork block", c.Tribe.RecentsBlock, newcfg.Tribe.RecentsBlock)
+		}
+		if isForkIncompatible(c.Tribe.PomFeeBlock, newcfg.Tribe.PomFeeBlock, head) {
+			return newCompatError("Tribe POM base fee fork block", c.Tribe.PomFeeBlock, newcfg.Tribe.PomFeeBlock)
+		}
+		if isForkIncompatible(c.Tribe.EvidenceBlock, newcfg.Tribe.EvidenceBlock, head) {
+			return newCompatError("Tribe evidence fork block", c.Tribe.EvidenceBlock, newcfg.Tribe.EvidenceBlock)
+		}
+		if isForked(c.Tribe.EvidenceBlock, head) && !bytes.Equal(c.Tribe.ValidatorsCode, newcfg.Tribe.ValidatorsCode) {
+			return newCompatError("Tribe validators code", c.Tribe.EvidenceBlock, newcfg.Tribe.EvidenceBlock)
+		}
+		if err := checkTribeForksCompatible(c.Tribe.Forks, newcfg.Tribe.Forks, head); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkTribeForksCompatible compares two tribe fork schedules entry by entry. A
+// fork may only be added, moved, changed or dropped while neither version of it
+// has activated yet.
+func checkTribeForksCompatible(stored, newforks []*TribeFork, head *big.Int) *ConfigCompatError {
+	for i := 0; i < len(stored) || i < len(newforks); i++ {
+		var s1, s2 *TribeFork
+		var b1, b2 *big.Int
+		if i < len(stored) {
+			s1, b1 = stored[i], stored[i].Block
+		}
+		if i < len(newforks) {
+			s2, b2 = newforks[i], newforks[i].Block
+		}
+		if isForkIncompatible(b1, b2, head) {
+			return newCompatError(fmt.Sprintf("Tribe fork %d block", i), b1, b2)
+		}
+		if s1 != nil && s2 != nil && isForked(b1, head) && !s1.equal(s2) {
+			return newCompatError(fmt.Sprintf("Tribe fork %d parameters", i), b1, b2)
+		}
+	}
+	return nil
+}
+
+// checkSponsorCompatible compares two sponsored transaction configs. The fork
+// block may only move while inactive, and allowances follow the same rule as
+// the tribe forks: activated ones must stay unchanged.
+func checkSponsorCompatible(stored, newcfg *SponsorConfig, head *big.Int) *ConfigCompatError {
+	var (
+		b1, b2 *big.Int
+		a1, a2 []*SponsorAllowance
+	)
+	if stored != nil {
+		b1, a1 = stored.Block, stored.Allowances
+	}
+	if newcfg != nil {
+		b2, a2 = newcfg.Block, newcfg.Allowances
+	}
+	if isForkIncompatible(b1, b2, head) {
+		return newCompatError("Sponsor fork block", b1, b2)
+	}
+	for i := 0; i < len(a1) || i < len(a2); i++ {
+		var s1, s2 *SponsorAllowance
+		var n1, n2 *big.Int
+		if i < len(a1) {
+			s1, n1 = a1[i], a1[i].Block
+		}
+		if i < len(a2) {
+			s2, n2 = a2[i], a2[i].Block
+		}
+		if isForkIncompatible(n1, n2, head) {
+			return newCompatError(fmt.Sprintf("Sponsor allowance %d block", i), n1, n2)
+		}
+		if s1 != nil && s2 != nil && isForked(n1, head) && !s1.equal(s2) {
+			return newCompatError(fmt.Sprintf("Sponsor allowance %d contracts", i), n1, n2)
+		}
+	}
 	return nil
 }
 
@@ -364,7 +670,7 @@ func (err *ConfigCompatError) Error() string {
 type Rules struct {
 	ChainId                                   *big.Int
 	IsHomestead, IsEIP150, IsEIP155, IsEIP158 bool
-	IsByzantium                               bool
+	IsByzantium, IsBerlin, IsLondon           bool
 }
 
 func (c *ChainConfig) Rules(num *big.Int) Rules {
@@ -372,5 +678,5 @@ func (c *ChainConfig) Rules(num *big.Int) Rules {
 	if chainId == nil {
 		chainId = new(big.Int)
 	}
-	return Rules{ChainId: new(big.Int).Set(chainId), IsHomestead: c.IsHomestead(num), IsEIP150: c.IsEIP150(num), IsEIP155: c.IsEIP155(num), IsEIP158: c.IsEIP158(num), IsByzantium: c.IsByzantium(num)}
+	return Rules{ChainId: new(big.Int).Set(chainId), IsHomestead: c.IsHomestead(num), IsEIP150: c.IsEIP150(num), IsEIP155: c.IsEIP155(num), IsEIP158: c.IsEIP158(num), IsByzantium: c.IsByzantium(num), IsBerlin: c.IsBerlin(num), IsLondon: c.IsLondon(num)}
 }
@@ -71,6 +71,85 @@ func TestCheckCompatible(t *testing.T) {
 				RewindTo:     9,
 			},
 		},
+		{
+			stored:  &ChainConfig{Tribe: &TribeConfig{Forks: []*TribeFork{{Block: big.NewInt(10), Period: 6}}}},
+			new:     &ChainConfig{Tribe: &TribeConfig{Forks: []*TribeFork{{Block: big.NewInt(20), Period: 6}}}},
+			head:    9,
+			wantErr: nil,
+		},
+		{
+			stored:  &ChainConfig{Tribe: &TribeConfig{Forks: []*TribeFork{{Block: big.NewInt(10), Period: 6}}}},
+			new:     &ChainConfig{Tribe: &TribeConfig{Forks: []*TribeFork{{Block: big.NewInt(10), Period: 6}, {Block: big.NewInt(30), Epoch: 42}}}},
+			head:    20,
+			wantErr: nil,
+		},
+		{
+			stored: &ChainConfig{Tribe: &TribeConfig{Forks: []*TribeFork{{Block: big.NewInt(10), Period: 6}}}},
+			new:    &ChainConfig{Tribe: &TribeConfig{Forks: []*TribeFork{{Block: big.NewInt(20), Period: 6}}}},
+			head:   15,
+			wantErr: &ConfigCompatError{
+				What:         "Tribe fork 0 block",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    big.NewInt(20),
+				RewindTo:     9,
+			},
+		},
+		{
+			stored: &ChainConfig{Tribe: &TribeConfig{Forks: []*TribeFork{{Block: big.NewInt(10), Period: 6}}}},
+			new:    &ChainConfig{Tribe: &TribeConfig{Forks: []*TribeFork{{Block: big.NewInt(10), Period: 3}}}},
+			head:   15,
+			wantErr: &ConfigCompatError{
+				What:         "Tribe fork 0 parameters",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    big.NewInt(10),
+				RewindTo:     9,
+			},
+		},
+		{
+			stored:  &ChainConfig{Tribe: &TribeConfig{EvidenceBlock: big.NewInt(10), ValidatorsCode: []byte{1}}},
+			new:     &ChainConfig{Tribe: &TribeConfig{EvidenceBlock: big.NewInt(20), ValidatorsCode: []byte{2}}},
+			head:    9,
+			wantErr: nil,
+		},
+		{
+			stored: &ChainConfig{Tribe: &TribeConfig{EvidenceBlock: big.NewInt(10), ValidatorsCode: []byte{1}}},
+			new:    &ChainConfig{Tribe: &TribeConfig{EvidenceBlock: big.NewInt(10), ValidatorsCode: []byte{2}}},
+			head:   15,
+			wantErr: &ConfigCompatError{
+				What:         "Tribe validators code",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    big.NewInt(10),
+				RewindTo:     9,
+			},
+		},
+		{
+			stored:  &ChainConfig{Sponsor: &SponsorConfig{Block: big.NewInt(10), Allowances: []*SponsorAllowance{{Block: big.NewInt(10), Contracts: []common.Address{{1}}}}}},
+			new:     &ChainConfig{Sponsor: &SponsorConfig{Block: big.NewInt(10), Allowances: []*SponsorAllowance{{Block: big.NewInt(10), Contracts: []common.Address{{1}}}, {Block: big.NewInt(30), Contracts: []common.Address{{2}}}}}},
+			head:    20,
+			wantErr: nil,
+		},
+		{
+			stored: &ChainConfig{Sponsor: &SponsorConfig{Block: big.NewInt(10)}},
+			new:    &ChainConfig{},
+			head:   15,
+			wantErr: &ConfigCompatError{
+				What:         "Sponsor fork block",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    nil,
+				RewindTo:     9,
+			},
+		},
+		{
+			stored: &ChainConfig{Sponsor: &SponsorConfig{Block: big.NewInt(10), Allowances: []*SponsorAllowance{{Block: big.NewInt(10), Contracts: []common.Address{{1}}}}}},
+			new:    &ChainConfig{Sponsor: &SponsorConfig{Block: big.NewInt(10), Allowances: []*SponsorAllowance{{Block: big.NewInt(10), Contracts: []common.Address{{2}}}}}},
+			head:   15,
+			wantErr: &ConfigCompatError{
+				What:         "Sponsor allowance 0 contracts",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    big.NewInt(10),
+				RewindTo:     9,
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -81,6 +160,109 @@ func TestCheckCompatible(t *testing.T) {
 	}
 }
 
+func TestTribeForkAt(t *testing.T) {
+	config := &TribeConfig{
+		Period: 15,
+		Epoch:  21,
+		Forks: []*TribeFork{
+			{Block: big.NewInt(100), Period: 6, Reward: big.NewInt(5)},
+			{Block: big.NewInt(200), Epoch: 42, MaxValidators: 42},
+		},
+	}
+	if err := config.CheckForkOrder(); err != nil {
+		t.Fatalf("valid schedule rejected: %v", err)
+	}
+	tests := []struct {
+		number int64
+		want   TribeFork
+	}{
+		{0, TribeFork{Block: big.NewInt(0), Period: 15, Epoch: 21}},
+		{99, TribeFork{Block: big.NewInt(0), Period: 15, Epoch: 21}},
+		{100, TribeFork{Block: big.NewInt(100), Period: 6, Epoch: 21, Reward: big.NewInt(5)}},
+		{250, TribeFork{Block: big.NewInt(200), Period: 6, Epoch: 42, MaxValidators: 42, Reward: big.NewInt(5)}},
+	}
+	for _, tt := range tests {
+		if have := config.ForkAt(big.NewInt(tt.number)); !have.equal(&tt.want) {
+			t.Errorf("block %d: parameters mismatch: have %+v, want %+v", tt.number, have, tt.want)
+		}
+	}
+	config.Forks[1].Block = big.NewInt(100)
+	if err := config.CheckForkOrder(); err == nil {
+		t.Fatalf("unordered schedule accepted")
+	}
+}
+
+func TestCheckConfigForkOrder(t *testing.T) {
+	for _, config := range []*ChainConfig{MainnetChainConfig, TestnetChainConfig, AllEthashProtocolChanges, AllCliqueProtocolChanges, TestChainConfig} {
+		if err := config.CheckConfigForkOrder(); err != nil {
+			t.Errorf("default config %v rejected: %v", config, err)
+		}
+	}
+	tests := []struct {
+		berlin, sponsor, london *big.Int
+		ok                      bool
+	}{
+		{nil, nil, nil, true},
+		{big.NewInt(1), nil, nil, true},
+		{big.NewInt(1), nil, big.NewInt(2), true},
+		{big.NewInt(1), big.NewInt(1), big.NewInt(1), true},
+		{big.NewInt(1), big.NewInt(2), big.NewInt(3), true},
+		{big.NewInt(1), big.NewInt(2), nil, true},
+		{nil, nil, big.NewInt(1), false},
+		{nil, big.NewInt(1), nil, false},
+		{big.NewInt(2), big.NewInt(1), nil, false},
+		{big.NewInt(1), big.NewInt(3), big.NewInt(2), false},
+		{big.NewInt(2), nil, big.NewInt(1), false},
+	}
+	for i, tt := range tests {
+		config := &ChainConfig{BerlinBlock: tt.berlin, LondonBlock: tt.london}
+		if tt.sponsor != nil {
+			config.Sponsor = &SponsorConfig{Block: tt.sponsor}
+		}
+		if err := config.CheckConfigForkOrder(); (err == nil) != tt.ok {
+			t.Errorf("test %d: error mismatch: have %v, want ok %v", i, err, tt.ok)
+		}
+	}
+	// The tribe fork schedule is checked along
+	config := &ChainConfig{Tribe: &TribeConfig{Forks: []*TribeFork{{Block: big.NewInt(2)}, {Block: big.NewInt(1)}}}}
+	if err := config.CheckConfigForkOrder(); err == nil {
+		t.Errorf("unordered tribe forks accepted")
+	}
+}
+
+func TestSponsorAllowed(t *testing.T) {
+	var (
+		sponsor = common.Address{0xaa}
+		pom     = common.Address{0x01}
+		other   = common.Address{0x02}
+	)
+	config := &SponsorConfig{
+		Block: big.NewInt(10),
+		Allowances: []*SponsorAllowance{
+			{Block: big.NewInt(10), Sponsor: sponsor, Contracts: []common.Address{pom}},
+			{Block: big.NewInt(20), Sponsor: sponsor, Contracts: []common.Address{other}},
+		},
+	}
+	tests := []struct {
+		number  int64
+		sponsor common.Address
+		to      *common.Address
+		want    bool
+	}{
+		{9, sponsor, &pom, false},    // fork not active
+		{10, sponsor, &pom, true},    // allowed
+		{10, sponsor, &other, false}, // allowance not active
+		{20, sponsor, &other, true},  // allowance active
+		{20, other, &pom, false},     // unknown sponsor
+		{20, sponsor, nil, false},    // contract creation
+	}
+	for i, tt := range tests {
+		if have := config.Allowed(big.NewInt(tt.number), tt.sponsor, tt.to); have != tt.want {
+			t.Errorf("test %d: allowed mismatch: have %v, want %v", i, have, tt.want)
+		}
+	}
+}
+
 func TestHash(t *testing.T) {
 	h := common.Hash{}
 	t.Log(h == common.Hash{})
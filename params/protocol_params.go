@@ -87,6 +87,17 @@ const (
 
 	SloadGasEIP2200 uint64 = 800 // Cost of SLOAD after EIP 2200 (part of Istanbul)
 
+	ColdAccountAccessCostEIP2929 uint64 = 2600 // COLD_ACCOUNT_ACCESS_COST
+	ColdSloadCostEIP2929         uint64 = 2100 // COLD_SLOAD_COST
+	WarmStorageReadCostEIP2929   uint64 = 100  // WARM_STORAGE_READ_COST
+
+	TxAccessListAddressGas    uint64 = 2400 // Per address specified in EIP 2930 access list
+	TxAccessListStorageKeyGas uint64 = 1900 // Per storage key specified in EIP 2930 access list
+
+	BaseFeeChangeDenominator = 8            // Bounds the amount the base fee can change between blocks.
+	ElasticityMultiplier     = 2            // Bounds the maximum gas limit an EIP-1559 block may have.
+	InitialBaseFee           = 18 * Shannon // Initial base fee for EIP-1559 blocks, the former minimal gas price.
+
 	MaxCodeSize = 24576 // Maximum bytecode to permit for a contract
 
 	// Precompiled contract gas prices
@@ -360,6 +360,12 @@ type hasIgnoredField struct {
 	C uint
 }
 
+type optionalFields struct {
+	A uint
+	B uint     `rlp:"optional"`
+	C *big.Int `rlp:"optional"`
+}
+
 var decodeTests = []decodeTest{
 	// booleans
 	{input: "01", ptr: new(bool), value: true},
@@ -518,6 +524,28 @@ var decodeTests = []decodeTest{
 		value: hasIgnoredField{A: 1, C: 2},
 	},
 
+	// struct tag "optional"
+	{
+		input: "C101",
+		ptr:   new(optionalFields),
+		value: optionalFields{A: 1},
+	},
+	{
+		input: "C20102",
+		ptr:   new(optionalFields),
+		value: optionalFields{A: 1, B: 2},
+	},
+	{
+		input: "C3010203",
+		ptr:   new(optionalFields),
+		value: optionalFields{A: 1, B: 2, C: big.NewInt(3)},
+	},
+	{
+		input: "C0",
+		ptr:   new(optionalFields),
+		error: "rlp: too few elements for rlp.optionalFields",
+	},
+
 	// RawValue
 	{input: "01", ptr: new(RawValue), value: RawValue(unhex("01"))},
 	{input: "82FFFF", ptr: new(RawValue), value: RawValue(unhex("82FFFF"))},
@@ -616,6 +644,31 @@ func TestDecodeWithNonByteReader(t *testing.T) {
 	})
 }
 
+// dataEOFReader returns all of its remaining data along with io.EOF.
+type dataEOFReader []byte
+
+func (r *dataEOFReader) Read(buf []byte) (int, error) {
+	n := copy(buf, *r)
+	*r = (*r)[n:]
+	if len(*r) == 0 {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func TestDecodeWithDataEOFReader(t *testing.T) {
+	// Readers may return the last bytes of the input along with io.EOF. The
+	// value must exceed the stream's buffer to be read from r directly.
+	input := append(unhex("B92710"), make([]byte, 10000)...)
+	var dec []byte
+	if err := Decode((*dataEOFReader)(&input), &dec); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if len(dec) != 10000 {
+		t.Errorf("decoded length mismatch: have %d, want 10000", len(dec))
+	}
+}
+
 func TestDecodeStreamReset(t *testing.T) {
 	s := NewStream(nil, 0)
 	runTests(t, func(input []byte, into interface{}) error {
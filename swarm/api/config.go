@@ -19,6 +19,7 @@ package api
 import (
 	"crypto/ecdsa"
 	"fmt"
+	"math/big"
 	"os"
 	"path/filepath"
 
@@ -27,6 +28,7 @@ import (
 	"github.com/MeshBoxTech/mesh-chain/crypto"
 	"github.com/MeshBoxTech/mesh-chain/log"
 	"github.com/MeshBoxTech/mesh-chain/node"
+	"github.com/MeshBoxTech/mesh-chain/params"
 	"github.com/MeshBoxTech/mesh-chain/swarm/network"
 	"github.com/MeshBoxTech/mesh-chain/swarm/services/swap"
 	"github.com/MeshBoxTech/mesh-chain/swarm/storage"
@@ -55,6 +57,7 @@ type Config struct {
 	PublicKey   string
 	BzzKey      string
 	NetworkId   uint64
+	ChainId     *big.Int // Chain id the ENS and SWAP transactions are signed for
 	SwapEnabled bool
 	SyncEnabled bool
 	SwapApi     string
@@ -75,9 +78,10 @@ func NewDefaultConfig() (self *Config) {
 		ListenAddr:    DefaultHTTPListenAddr,
 		Port:          DefaultHTTPPort,
 		Path:          node.DefaultDataDir(),
-		EnsApi:        node.DefaultIPCEndpoint("smc"),
+		EnsApi:        node.DefaultIPCEndpoint("smc", false),
 		EnsRoot:       ens.TestNetAddress,
 		NetworkId:     network.NetworkId,
+		ChainId:       params.MainnetChainConfig.ChainId,
 		SwapEnabled:   false,
 		SyncEnabled:   true,
 		SwapApi:       "",
@@ -106,7 +110,7 @@ func (self *Config) Init(prvKey *ecdsa.PrivateKey) {
 	self.PublicKey = pubkeyhex
 	self.BzzKey = keyhex
 
-	self.Swap.Init(self.Contract, prvKey)
+	self.Swap.Init(self.Contract, self.ChainId, prvKey)
 	self.SyncParams.Init(self.Path)
 	self.HiveParams.Init(self.Path)
 	self.StoreParams.Init(self.Path)
@@ -75,6 +75,7 @@ type PayProfile struct {
 	Beneficiary common.Address // recipient address for swarm sales revenue
 	privateKey  *ecdsa.PrivateKey
 	publicKey   *ecdsa.PublicKey
+	chainId     *big.Int
 	owner       common.Address
 	chbook      *chequebook.Chequebook
 	lock        sync.RWMutex
@@ -104,7 +105,7 @@ func NewDefaultSwapParams() *SwapParams {
 
 //this can only finally be set after all config options (file, cmd line, env vars)
 //have been evaluated
-func (self *SwapParams) Init(contract common.Address, prvkey *ecdsa.PrivateKey) {
+func (self *SwapParams) Init(contract common.Address, chainId *big.Int, prvkey *ecdsa.PrivateKey) {
 	pubkey := &prvkey.PublicKey
 
 	self.PayProfile = &PayProfile{
@@ -113,6 +114,7 @@ func (self *SwapParams) Init(contract common.Address, prvkey *ecdsa.PrivateKey)
 		Beneficiary: crypto.PubkeyToAddress(*pubkey),
 		privateKey:  prvkey,
 		publicKey:   pubkey,
+		chainId:     chainId,
 		owner:       crypto.PubkeyToAddress(*pubkey),
 	}
 }
@@ -142,7 +144,7 @@ func NewSwap(local *SwapParams, remote *SwapProfile, backend chequebook.Backend,
 		log.Info(fmt.Sprintf("invalid contract %v for peer %v: %v)", remote.Contract.Hex()[:8], proto, err))
 	} else {
 		// remote contract valid, create inbox
-		in, err = chequebook.NewInbox(local.privateKey, remote.Contract, local.Beneficiary, crypto.ToECDSAPub(common.FromHex(remote.PublicKey)), backend)
+		in, err = chequebook.NewInbox(local.privateKey, local.chainId, remote.Contract, local.Beneficiary, crypto.ToECDSAPub(common.FromHex(remote.PublicKey)), backend)
 		if err != nil {
 			log.Warn(fmt.Sprintf("unable to set up inbox for chequebook contract %v for peer %v: %v)", remote.Contract.Hex()[:8], proto, err))
 		}
@@ -220,7 +222,7 @@ func (self *SwapParams) SetChequebook(ctx context.Context, backend chequebook.Ba
 }
 
 func (self *SwapParams) deployChequebook(ctx context.Context, backend chequebook.Backend, path string) error {
-	opts := bind.NewKeyedTransactor(self.privateKey)
+	opts := bind.NewKeyedTransactorWithChainID(self.privateKey, self.chainId)
 	opts.Value = self.AutoDepositBuffer
 	opts.Context = ctx
 
@@ -273,10 +275,10 @@ func (self *SwapParams) newChequebookFromContract(path string, backend chequeboo
 	}
 
 	chbookpath := filepath.Join(path, "chequebooks", hexkey+".json")
-	self.chbook, err = chequebook.LoadChequebook(chbookpath, self.privateKey, backend, true)
+	self.chbook, err = chequebook.LoadChequebook(chbookpath, self.privateKey, self.chainId, backend, true)
 
 	if err != nil {
-		self.chbook, err = chequebook.NewChequebook(chbookpath, self.Contract, self.privateKey, backend)
+		self.chbook, err = chequebook.NewChequebook(chbookpath, self.Contract, self.privateKey, self.chainId, backend)
 		if err != nil {
 			log.Warn(fmt.Sprintf("unable to initialise chequebook (owner: %v): %v", self.owner.Hex(), err))
 			return fmt.Errorf("unable to initialise chequebook (owner: %v): %v", self.owner.Hex(), err)
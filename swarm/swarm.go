@@ -134,7 +134,7 @@ func NewSwarm(ctx *node.ServiceContext, backend chequebook.Backend, ensClient *e
 	log.Debug(fmt.Sprintf("-> Content Store API"))
 
 	// set up high level api
-	transactOpts := bind.NewKeyedTransactor(self.privateKey)
+	transactOpts := bind.NewKeyedTransactorWithChainID(self.privateKey, config.ChainId)
 
 	if ensClient == nil {
 		log.Warn("No ENS, please specify non-empty --ens-api to use domain name resolution")
@@ -0,0 +1,458 @@
+// Copyright 2018 The mesh-chain Authors
+// This file is part of the mesh-chain library.
+//
+// The mesh-chain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The mesh-chain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the mesh-chain library. If not, see <http://www.gnu.org/licenses/>.
+
+package trie
+
+import (
+	"sync"
+	"time"
+
+	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/ethdb"
+	"github.com/MeshBoxTech/mesh-chain/log"
+)
+
+// LeafResolver returns the hashes of the database entries a trie leaf refers to,
+// e.g. the storage trie root and code of an account, so that they are kept alive
+// as long as the node holding the leaf.
+type LeafResolver func(leaf []byte) []common.Hash
+
+// NodeDatabase is an intermediate write layer between the trie data structures and
+// the disk database. Nodes committed by the tries are kept in memory with
+// reference counts and are only flushed to disk when a root is committed or the
+// memory allowance is exceeded, so the nodes of stale tries can be garbage
+// collected without ever hitting the disk.
+//
+// NodeDatabase implements Database, so tries open on and commit to it like to
+// any other backing store.
+type NodeDatabase struct {
+	diskdb ethdb.Database // Persistent storage for matured trie nodes
+	leaves LeafResolver   // Resolver of the references held by trie leaves
+
+	nodes  map[common.Hash]*cachedNode // Data and references relationships of the cached nodes
+	oldest common.Hash                 // Oldest tracked node, flush-list head
+	newest common.Hash                 // Newest tracked node, flush-list tail
+
+	preimages map[string][]byte // Preimages of the secure trie keys
+
+	gcnodes uint64             // Nodes garbage collected since the last commit
+	gcsize  common.StorageSize // Data storage garbage collected since the last commit
+	gctime  time.Duration      // Time spent on garbage collection since the last commit
+
+	nodesSize     common.StorageSize // Storage size of the nodes cache
+	preimagesSize common.StorageSize // Storage size of the preimages cache
+
+	lock sync.RWMutex
+}
+
+// cachedNode is a trie node blob with the reference counts of the node and its
+// cached children. The meta root at the empty hash has no blob and holds the
+// references of the tries kept alive by the owner of the database.
+type cachedNode struct {
+	blob     []byte                 // Encoded node
+	parents  uint32                 // Number of live nodes referencing this one
+	children map[common.Hash]uint16 // Cached children referenced by this node
+
+	flushPrev common.Hash // Previous node in the flush-list
+	flushNext common.Hash // Next node in the flush-list
+}
+
+// size returns the storage size of the cached node.
+func (n *cachedNode) size() common.StorageSize {
+	return common.StorageSize(common.HashLength + len(n.blob))
+}
+
+// NewNodeDatabase creates a new trie node database on top of diskdb. The leaf
+// resolver is optional.
+func NewNodeDatabase(diskdb ethdb.Database, leaves LeafResolver) *NodeDatabase {
+	return &NodeDatabase{
+		diskdb: diskdb,
+		leaves: leaves,
+		nodes: map[common.Hash]*cachedNode{
+			{}: {children: make(map[common.Hash]uint16)},
+		},
+		preimages: make(map[string][]byte),
+	}
+}
+
+// DiskDB retrieves the persistent storage backing the trie database.
+func (db *NodeDatabase) DiskDB() ethdb.Database {
+	return db.diskdb
+}
+
+// Put inserts a trie node or a secure key preimage into the memory cache. Nodes
+// are keyed by their hash, everything else is a preimage.
+func (db *NodeDatabase) Put(key, value []byte) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if len(key) != common.HashLength {
+		if _, ok := db.preimages[string(key)]; !ok {
+			db.preimages[string(key)] = common.CopyBytes(value)
+			db.preimagesSize += common.StorageSize(len(key) + len(value))
+		}
+		return nil
+	}
+	db.insert(common.BytesToHash(key), common.CopyBytes(value))
+	return nil
+}
+
+// insert caches a node blob and references the cached nodes it points to. The
+// method assumes the write lock is held.
+func (db *NodeDatabase) insert(hash common.Hash, blob []byte) {
+	if _, ok := db.nodes[hash]; ok {
+		return
+	}
+	entry := &cachedNode{
+		blob:      blob,
+		children:  make(map[common.Hash]uint16),
+		flushPrev: db.newest,
+	}
+	for _, child := range db.references(blob) {
+		if c := db.nodes[child]; c != nil && child != (common.Hash{}) {
+			c.parents++
+			entry.children[child]++
+		}
+	}
+	db.nodes[hash] = entry
+
+	// Append the node to the flush-list, children always precede their parents
+	if db.oldest == (common.Hash{}) {
+		db.oldest, db.newest = hash, hash
+	} else {
+		db.nodes[db.newest].flushNext, db.newest = hash, hash
+	}
+	db.nodesSize += entry.size()
+}
+
+// references returns the hashes of the child nodes and leaf references of a
+// node blob. Blobs that aren't trie nodes, e.g. contract code, have none.
+func (db *NodeDatabase) references(blob []byte) []common.Hash {
+	n, err := decodeNode(nil, blob, 0)
+	if err != nil {
+		return nil
+	}
+	var refs []common.Hash
+	var gather func(n node)
+	gather = func(n node) {
+		switch n := n.(type) {
+		case *shortNode:
+			gather(n.Val)
+		case *fullNode:
+			for _, child := range n.Children {
+				gather(child)
+			}
+		case hashNode:
+			refs = append(refs, common.BytesToHash(n))
+		case valueNode:
+			if db.leaves != nil && len(n) > 0 {
+				refs = append(refs, db.leaves(n)...)
+			}
+		}
+	}
+	gather(n)
+	return refs
+}
+
+// Get retrieves a trie node or preimage from the memory cache, falling back to
+// the disk database.
+func (db *NodeDatabase) Get(key []byte) ([]byte, error) {
+	db.lock.RLock()
+	if len(key) == common.HashLength {
+		if node := db.nodes[common.BytesToHash(key)]; node != nil && node.blob != nil {
+			blob := node.blob
+			db.lock.RUnlock()
+			return blob, nil
+		}
+	} else if preimage, ok := db.preimages[string(key)]; ok {
+		db.lock.RUnlock()
+		return preimage, nil
+	}
+	db.lock.RUnlock()
+
+	return db.diskdb.Get(key)
+}
+
+// Has reports whether a trie node or preimage is in the memory cache or on disk.
+func (db *NodeDatabase) Has(key []byte) (bool, error) {
+	db.lock.RLock()
+	if len(key) == common.HashLength {
+		if node := db.nodes[common.BytesToHash(key)]; node != nil && node.blob != nil {
+			db.lock.RUnlock()
+			return true, nil
+		}
+	} else if _, ok := db.preimages[string(key)]; ok {
+		db.lock.RUnlock()
+		return true, nil
+	}
+	db.lock.RUnlock()
+
+	return db.diskdb.Has(key)
+}
+
+// Size returns the storage size of the nodes and preimages cached in memory.
+func (db *NodeDatabase) Size() common.StorageSize {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	return db.nodesSize + db.preimagesSize
+}
+
+// Reference adds a new reference from a parent node to a child node. An empty
+// parent references the child from the meta root, keeping the whole trie below
+// it alive until it is dereferenced.
+func (db *NodeDatabase) Reference(child common.Hash, parent common.Hash) {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	db.reference(child, parent)
+}
+
+// reference is the private locked version of Reference.
+func (db *NodeDatabase) reference(child common.Hash, parent common.Hash) {
+	node, ok := db.nodes[child]
+	if !ok || child == (common.Hash{}) {
+		return
+	}
+	owner, ok := db.nodes[parent]
+	if !ok {
+		return
+	}
+	// Nodes reference their children once, only the meta root may pin a trie
+	// multiple times, e.g. for blocks with the same state
+	if _, ok := owner.children[child]; ok && parent != (common.Hash{}) {
+		return
+	}
+	node.parents++
+	owner.children[child]++
+}
+
+// Dereference removes a meta root reference of a trie, garbage collecting the
+// nodes no longer referenced by any other live trie.
+func (db *NodeDatabase) Dereference(root common.Hash) {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	nodes, storage, start := len(db.nodes), db.nodesSize, time.Now()
+	db.dereference(root, common.Hash{})
+
+	db.gcnodes += uint64(nodes - len(db.nodes))
+	db.gcsize += storage - db.nodesSize
+	db.gctime += time.Since(start)
+
+	log.Debug("Dereferenced trie from memory database", "nodes", nodes-len(db.nodes), "size", storage-db.nodesSize, "time", time.Since(start),
+		"gcnodes", db.gcnodes, "gcsize", db.gcsize, "gctime", db.gctime, "livenodes", len(db.nodes), "livesize", db.nodesSize)
+}
+
+// dereference is the private locked version of Dereference.
+func (db *NodeDatabase) dereference(child common.Hash, parent common.Hash) {
+	owner := db.nodes[parent]
+	if owner != nil && owner.children[child] > 0 {
+		if owner.children[child]--; owner.children[child] == 0 {
+			delete(owner.children, child)
+		}
+	}
+	node, ok := db.nodes[child]
+	if !ok || child == (common.Hash{}) {
+		return
+	}
+	// The node may have been flushed and cached again since the reference was
+	// taken, in which case it is on disk anyway
+	if node.parents > 0 {
+		node.parents--
+	}
+	if node.parents == 0 {
+		db.unlink(child, node)
+		for hash, count := range node.children {
+			for i := uint16(0); i < count; i++ {
+				db.dereference(hash, child)
+			}
+		}
+		delete(db.nodes, child)
+		db.nodesSize -= node.size()
+	}
+}
+
+// unlink removes a node from the flush-list.
+func (db *NodeDatabase) unlink(hash common.Hash, node *cachedNode) {
+	if hash == db.oldest {
+		db.oldest = node.flushNext
+	} else {
+		db.nodes[node.flushPrev].flushNext = node.flushNext
+	}
+	if hash == db.newest {
+		db.newest = node.flushPrev
+	} else {
+		db.nodes[node.flushNext].flushPrev = node.flushPrev
+	}
+	if db.oldest == (common.Hash{}) {
+		db.newest = common.Hash{}
+	}
+}
+
+// Commit writes the trie below the given node and all cached preimages to disk
+// and drops them from the memory cache. The trie stays referenced by the tries
+// still in memory, as their nodes are content addressed.
+func (db *NodeDatabase) Commit(node common.Hash, report bool) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	start := time.Now()
+	batch := db.diskdb.NewBatch()
+	if err := db.writePreimages(&batch); err != nil {
+		return err
+	}
+	nodes, storage := len(db.nodes), db.nodesSize
+	if err := db.commit(node, make(map[common.Hash]struct{}), &batch); err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	// Everything is on disk, drop the flushed data from memory
+	db.preimages = make(map[string][]byte)
+	db.preimagesSize = 0
+
+	db.uncache(node)
+
+	logger := log.Debug
+	if report {
+		logger = log.Info
+	}
+	logger("Persisted trie from memory database", "nodes", nodes-len(db.nodes), "size", storage-db.nodesSize, "time", time.Since(start),
+		"gcnodes", db.gcnodes, "gcsize", db.gcsize, "gctime", db.gctime, "livenodes", len(db.nodes), "livesize", db.nodesSize)
+
+	// Reset the garbage collection statistics
+	db.gcnodes, db.gcsize, db.gctime = 0, 0, 0
+
+	return nil
+}
+
+// writePreimages writes the cached preimages into the batch.
+func (db *NodeDatabase) writePreimages(batch *ethdb.Batch) error {
+	for key, preimage := range db.preimages {
+		if err := (*batch).Put([]byte(key), preimage); err != nil {
+			return err
+		}
+		if (*batch).ValueSize() >= ethdb.IdealBatchSize {
+			if err := (*batch).Write(); err != nil {
+				return err
+			}
+			*batch = db.diskdb.NewBatch()
+		}
+	}
+	return nil
+}
+
+// commit writes the cached trie below hash into the batch, children first.
+func (db *NodeDatabase) commit(hash common.Hash, done map[common.Hash]struct{}, batch *ethdb.Batch) error {
+	node, ok := db.nodes[hash]
+	if !ok || hash == (common.Hash{}) {
+		return nil
+	}
+	if _, ok := done[hash]; ok {
+		return nil
+	}
+	done[hash] = struct{}{}
+
+	for child := range node.children {
+		if err := db.commit(child, done, batch); err != nil {
+			return err
+		}
+	}
+	if err := (*batch).Put(hash[:], node.blob); err != nil {
+		return err
+	}
+	if (*batch).ValueSize() >= ethdb.IdealBatchSize {
+		if err := (*batch).Write(); err != nil {
+			return err
+		}
+		*batch = db.diskdb.NewBatch()
+	}
+	return nil
+}
+
+// uncache drops the committed trie below hash from the memory cache.
+func (db *NodeDatabase) uncache(hash common.Hash) {
+	node, ok := db.nodes[hash]
+	if !ok || hash == (common.Hash{}) {
+		return
+	}
+	db.unlink(hash, node)
+	for child := range node.children {
+		db.uncache(child)
+	}
+	delete(db.nodes, hash)
+	db.nodesSize -= node.size()
+}
+
+// Cap flushes the oldest cached nodes to disk until the memory cache drops below
+// limit. The flushed nodes are the ones least likely to be garbage collected,
+// as they have outlived the tries referencing them so far.
+func (db *NodeDatabase) Cap(limit common.StorageSize) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	start := time.Now()
+	nodes, storage := len(db.nodes), db.nodesSize
+	size := db.nodesSize + db.preimagesSize
+
+	batch := db.diskdb.NewBatch()
+	if err := db.writePreimages(&batch); err != nil {
+		return err
+	}
+	size -= db.preimagesSize
+
+	// Flush the flush-list oldest first, which never writes a parent before
+	// its cached children
+	oldest := db.oldest
+	for size > limit && oldest != (common.Hash{}) {
+		node := db.nodes[oldest]
+		if err := batch.Put(oldest[:], node.blob); err != nil {
+			return err
+		}
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch = db.diskdb.NewBatch()
+		}
+		size -= node.size()
+		oldest = node.flushNext
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	// Everything up to the new flush-list head is on disk, drop it from memory
+	db.preimages = make(map[string][]byte)
+	db.preimagesSize = 0
+
+	for db.oldest != oldest {
+		node := db.nodes[db.oldest]
+		delete(db.nodes, db.oldest)
+		db.oldest = node.flushNext
+		db.nodesSize -= node.size()
+	}
+	if db.oldest != (common.Hash{}) {
+		db.nodes[db.oldest].flushPrev = common.Hash{}
+	} else {
+		db.newest = common.Hash{}
+	}
+	log.Debug("Persisted nodes from memory database", "nodes", nodes-len(db.nodes), "size", storage-db.nodesSize, "time", time.Since(start),
+		"livenodes", len(db.nodes), "livesize", db.nodesSize)
+
+	return nil
+}
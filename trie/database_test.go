@@ -0,0 +1,205 @@
+// Copyright 2018 The mesh-chain Authors
+// This file is part of the mesh-chain library.
+//
+// The mesh-chain library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The mesh-chain library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the mesh-chain library. If not, see <http://www.gnu.org/licenses/>.
+
+package trie
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/MeshBoxTech/mesh-chain/common"
+	"github.com/MeshBoxTech/mesh-chain/ethdb"
+)
+
+// commitTestTrie fills a trie on top of db with the content, commits it into db
+// and returns its root.
+func commitTestTrie(t *testing.T, db Database, root common.Hash, content map[string]string) common.Hash {
+	trie, err := New(root, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, val := range content {
+		trie.Update([]byte(key), []byte(val))
+	}
+	root, err = trie.CommitTo(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+// checkTestTrie verifies that the trie at root is complete in db.
+func checkTestTrie(t *testing.T, db Database, root common.Hash, content map[string]string) {
+	trie, err := New(root, db)
+	if err != nil {
+		t.Fatalf("root %x: %v", root, err)
+	}
+	for key, val := range content {
+		have, err := trie.TryGet([]byte(key))
+		if err != nil {
+			t.Fatalf("root %x: key %q: %v", root, key, err)
+		}
+		if !bytes.Equal(have, []byte(val)) {
+			t.Fatalf("root %x: key %q: value mismatch: have %q, want %q", root, key, have, val)
+		}
+	}
+	it := trie.NodeIterator(nil)
+	for it.Next(true) {
+	}
+	if it.Error() != nil {
+		t.Fatalf("root %x: %v", root, it.Error())
+	}
+}
+
+func makeTestContent(prefix string, n int) map[string]string {
+	content := make(map[string]string)
+	for i := 0; i < n; i++ {
+		key := string(common.LeftPadBytes([]byte{byte(i), byte(i >> 8)}, 32))
+		content[key] = prefix + string(bytes.Repeat([]byte{byte(i)}, 32))
+	}
+	return content
+}
+
+func TestNodeDatabaseGarbageCollection(t *testing.T) {
+	diskdb, _ := ethdb.NewMemDatabase()
+	triedb := NewNodeDatabase(diskdb, nil)
+
+	base := makeTestContent("a", 500)
+	update := makeTestContent("b", 50)
+
+	root1 := commitTestTrie(t, triedb, common.Hash{}, base)
+	triedb.Reference(root1, common.Hash{})
+
+	root2 := commitTestTrie(t, triedb, root1, update)
+	triedb.Reference(root2, common.Hash{})
+
+	if diskdb.Len() != 0 {
+		t.Fatalf("nodes written to disk before commit: %d", diskdb.Len())
+	}
+	merged := make(map[string]string)
+	for key, val := range base {
+		merged[key] = val
+	}
+	for key, val := range update {
+		merged[key] = val
+	}
+	// Dropping the first trie must keep the nodes shared with the second one
+	size := triedb.Size()
+	triedb.Dereference(root1)
+	if triedb.Size() >= size {
+		t.Errorf("nothing garbage collected: size %v, was %v", triedb.Size(), size)
+	}
+	if ok, _ := triedb.Has(root1[:]); ok {
+		t.Errorf("dereferenced root still cached")
+	}
+	checkTestTrie(t, triedb, root2, merged)
+
+	// Dropping the last trie empties the cache
+	triedb.Dereference(root2)
+	if size := triedb.Size(); size != 0 {
+		t.Errorf("cache not empty: %v", size)
+	}
+	if len(triedb.nodes) != 1 || triedb.oldest != (common.Hash{}) || triedb.newest != (common.Hash{}) {
+		t.Errorf("dangling nodes: %d, oldest %x, newest %x", len(triedb.nodes), triedb.oldest, triedb.newest)
+	}
+}
+
+func TestNodeDatabaseCommit(t *testing.T) {
+	diskdb, _ := ethdb.NewMemDatabase()
+	triedb := NewNodeDatabase(diskdb, nil)
+
+	base := makeTestContent("a", 500)
+	root1 := commitTestTrie(t, triedb, common.Hash{}, base)
+	triedb.Reference(root1, common.Hash{})
+	root2 := commitTestTrie(t, triedb, root1, makeTestContent("b", 50))
+	triedb.Reference(root2, common.Hash{})
+
+	if err := triedb.Commit(root1, false); err != nil {
+		t.Fatal(err)
+	}
+	checkTestTrie(t, diskdb, root1, base)
+
+	// Committed nodes are dropped from memory, the rest stay referenced
+	if ok, _ := diskdb.Has(root2[:]); ok {
+		t.Errorf("uncommitted root written to disk")
+	}
+	triedb.Dereference(root1)
+	triedb.Dereference(root2)
+	if size := triedb.Size(); size != 0 {
+		t.Errorf("cache not empty: %v", size)
+	}
+	checkTestTrie(t, diskdb, root1, base)
+}
+
+func TestNodeDatabaseCap(t *testing.T) {
+	diskdb, _ := ethdb.NewMemDatabase()
+	triedb := NewNodeDatabase(diskdb, nil)
+
+	content := makeTestContent("a", 500)
+	root := commitTestTrie(t, triedb, common.Hash{}, content)
+	triedb.Reference(root, common.Hash{})
+
+	limit := triedb.Size() / 2
+	if err := triedb.Cap(limit); err != nil {
+		t.Fatal(err)
+	}
+	if size := triedb.Size(); size > limit || size == 0 {
+		t.Errorf("cache size mismatch: have %v, want at most %v", size, limit)
+	}
+	// The oldest nodes are flushed first, so the root stays in memory while
+	// the trie is complete across memory and disk
+	if ok, _ := diskdb.Has(root[:]); ok {
+		t.Errorf("root flushed before its children")
+	}
+	checkTestTrie(t, triedb, root, content)
+
+	if err := triedb.Cap(0); err != nil {
+		t.Fatal(err)
+	}
+	if size := triedb.Size(); size != 0 {
+		t.Errorf("cache not empty: %v", size)
+	}
+	checkTestTrie(t, diskdb, root, content)
+}
+
+func TestNodeDatabaseLeafReferences(t *testing.T) {
+	diskdb, _ := ethdb.NewMemDatabase()
+
+	// Leaves of the outer trie hold the root of an inner trie
+	var inner common.Hash
+	triedb := NewNodeDatabase(diskdb, func(leaf []byte) []common.Hash {
+		if bytes.Equal(leaf, inner[:]) {
+			return []common.Hash{inner}
+		}
+		return nil
+	})
+	innerContent := makeTestContent("i", 100)
+	inner = commitTestTrie(t, triedb, common.Hash{}, innerContent)
+
+	outerContent := map[string]string{string(common.LeftPadBytes([]byte{1}, 32)): string(inner[:])}
+	outer := commitTestTrie(t, triedb, common.Hash{}, outerContent)
+	triedb.Reference(outer, common.Hash{})
+
+	if err := triedb.Commit(outer, false); err != nil {
+		t.Fatal(err)
+	}
+	checkTestTrie(t, diskdb, outer, outerContent)
+	checkTestTrie(t, diskdb, inner, innerContent)
+
+	if size := triedb.Size(); size != 0 {
+		t.Errorf("cache not empty: %v", size)
+	}
+}
@@ -106,6 +106,24 @@ func TestSecureGetKey(t *testing.T) {
 	}
 }
 
+func TestSecureProof(t *testing.T) {
+	_, trie, content := makeTestSecureTrie()
+	root := trie.Hash()
+	for key, val := range content {
+		proofs, _ := ethdb.NewMemDatabase()
+		if err := trie.Prove([]byte(key), 0, proofs); err != nil {
+			t.Fatalf("missing key %x while constructing proof: %v", key, err)
+		}
+		have, err, _ := VerifyProof(root, crypto.Keccak256([]byte(key)), proofs)
+		if err != nil {
+			t.Fatalf("VerifyProof error for key %x: %v", key, err)
+		}
+		if !bytes.Equal(have, val) {
+			t.Fatalf("VerifyProof returned wrong value for key %x: got %x, want %x", key, have, val)
+		}
+	}
+}
+
 func TestSecureTrieConcurrency(t *testing.T) {
 	// Create an initial trie and copy if for concurrent access
 	_, trie, _ := makeTestSecureTrie()